@@ -0,0 +1,26 @@
+-- +up
+-- +begin
+CREATE TABLE `sync_cursors`
+(
+    `gid`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`       VARCHAR(30)     NOT NULL,
+    `exchange`      VARCHAR(24)     NOT NULL,
+    `symbol`        VARCHAR(20)     NOT NULL,
+
+    -- last_trade_id and last_order_id are the IDs of the last synced trade and order
+    `last_trade_id` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    `last_order_id` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+
+    -- synced_at is the time the last successful sync started
+    `synced_at`     DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `session_symbol` (`session`, `symbol`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `sync_cursors`;
+-- +end
@@ -0,0 +1,28 @@
+-- +up
+-- +begin
+CREATE TABLE `balance_snapshots`
+(
+    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`        VARCHAR(30)     NOT NULL,
+    `exchange`       VARCHAR(24)     NOT NULL,
+    `currency`       VARCHAR(10)     NOT NULL,
+    `available`      DECIMAL(20, 8)  NOT NULL,
+    `locked`         DECIMAL(20, 8)  NOT NULL,
+
+    -- price is the price of the currency in the quote currency, it's zero if the price is unknown
+    `price`          DECIMAL(20, 8)  NOT NULL DEFAULT 0,
+    `quote_currency` VARCHAR(10)     NOT NULL,
+
+    -- created_at is the time of the snapshot, the balances of a session snapshot share the same time
+    `created_at`     DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `balance_snapshots_session_created_at` (`session`, `created_at`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `balance_snapshots`;
+-- +end
@@ -0,0 +1,54 @@
+-- +up
+-- +begin
+CREATE TABLE trades
+(
+    gid            BIGSERIAL      NOT NULL,
+
+    id             BIGINT,
+    order_id       BIGINT         NOT NULL,
+    exchange       VARCHAR(24)    NOT NULL DEFAULT '',
+    symbol         VARCHAR(20)    NOT NULL,
+    price          NUMERIC(16, 8) NOT NULL,
+    quantity       NUMERIC(16, 8) NOT NULL,
+    quote_quantity NUMERIC(16, 8) NOT NULL,
+    fee            NUMERIC(16, 8) NOT NULL,
+    fee_currency   VARCHAR(10)    NOT NULL,
+    is_buyer       BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_maker       BOOLEAN        NOT NULL DEFAULT FALSE,
+    side           VARCHAR(4)     NOT NULL DEFAULT '',
+    traded_at      TIMESTAMP(3)   NOT NULL,
+
+    is_margin      BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_isolated    BOOLEAN        NOT NULL DEFAULT FALSE,
+
+    pnl            NUMERIC        NULL,
+    strategy       VARCHAR(32)    NULL,
+
+    PRIMARY KEY (gid)
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX trades_id ON trades (exchange, symbol, side, id);
+-- +end
+
+-- +begin
+CREATE INDEX trades_symbol ON trades (exchange, symbol);
+-- +end
+
+-- +begin
+CREATE INDEX trades_symbol_fee_currency ON trades (exchange, symbol, fee_currency, traded_at);
+-- +end
+
+-- +begin
+CREATE INDEX trades_traded_at_symbol ON trades (exchange, traded_at, symbol);
+-- +end
+
+-- +begin
+CREATE INDEX trades_price_quantity ON trades (order_id, price, quantity);
+-- +end
+
+-- +down
+-- +begin
+DROP TABLE IF EXISTS trades;
+-- +end
@@ -0,0 +1,42 @@
+-- +up
+-- +begin
+CREATE TABLE orders
+(
+    gid               BIGSERIAL      NOT NULL,
+
+    exchange          VARCHAR(24)    NOT NULL DEFAULT '',
+    -- order_id is the order id returned from the exchange
+    order_id          BIGINT         NOT NULL,
+    client_order_id   VARCHAR(42)    NOT NULL DEFAULT '',
+    order_type        VARCHAR(16)    NOT NULL,
+    symbol            VARCHAR(20)    NOT NULL,
+    status            VARCHAR(12)    NOT NULL,
+    time_in_force     VARCHAR(4)     NOT NULL,
+    price             NUMERIC(16, 8) NOT NULL,
+    stop_price        NUMERIC(16, 8) NOT NULL,
+    quantity          NUMERIC(16, 8) NOT NULL,
+    executed_quantity NUMERIC(16, 8) NOT NULL DEFAULT 0.0,
+    side              VARCHAR(4)     NOT NULL DEFAULT '',
+    is_working        BOOLEAN        NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMP(3)   NOT NULL,
+    updated_at        TIMESTAMP(3)   NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+
+    is_margin         BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_isolated       BOOLEAN        NOT NULL DEFAULT FALSE,
+
+    PRIMARY KEY (gid)
+);
+-- +end
+
+-- +begin
+CREATE INDEX orders_symbol ON orders (exchange, symbol);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX orders_order_id ON orders (order_id, exchange);
+-- +end
+
+-- +down
+-- +begin
+DROP TABLE IF EXISTS orders;
+-- +end
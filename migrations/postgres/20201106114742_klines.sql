@@ -0,0 +1,55 @@
+-- +up
+-- +begin
+CREATE TABLE klines
+(
+    gid           BIGSERIAL      NOT NULL,
+    exchange      VARCHAR(10)    NOT NULL,
+    start_time    TIMESTAMP(3)   NOT NULL,
+    end_time      TIMESTAMP(3)   NOT NULL,
+    "interval"    VARCHAR(3)     NOT NULL,
+    symbol        VARCHAR(12)    NOT NULL,
+    open          NUMERIC(16, 8) NOT NULL,
+    high          NUMERIC(16, 8) NOT NULL,
+    low           NUMERIC(16, 8) NOT NULL,
+    close         NUMERIC(16, 8) NOT NULL DEFAULT 0.0,
+    volume        NUMERIC(20, 8) NOT NULL DEFAULT 0.0,
+    closed        BOOLEAN        NOT NULL DEFAULT TRUE,
+    last_trade_id INT            NOT NULL DEFAULT 0,
+    num_trades    INT            NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (gid)
+);
+-- +end
+
+-- +begin
+CREATE INDEX klines_end_time_symbol_interval ON klines (end_time, symbol, "interval");
+-- +end
+
+-- +begin
+CREATE TABLE okex_klines (LIKE klines INCLUDING ALL);
+-- +end
+
+-- +begin
+CREATE TABLE binance_klines (LIKE klines INCLUDING ALL);
+-- +end
+
+-- +begin
+CREATE TABLE max_klines (LIKE klines INCLUDING ALL);
+-- +end
+
+-- +down
+-- +begin
+DROP TABLE IF EXISTS binance_klines;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS okex_klines;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS max_klines;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS klines;
+-- +end
@@ -0,0 +1,28 @@
+-- +up
+-- +begin
+CREATE TABLE sync_cursors
+(
+    gid           BIGSERIAL    NOT NULL,
+    session       VARCHAR(30)  NOT NULL,
+    exchange      VARCHAR(24)  NOT NULL,
+    symbol        VARCHAR(20)  NOT NULL,
+
+    -- last_trade_id and last_order_id are the IDs of the last synced trade and order
+    last_trade_id BIGINT       NOT NULL DEFAULT 0,
+    last_order_id BIGINT       NOT NULL DEFAULT 0,
+
+    -- synced_at is the time the last successful sync started
+    synced_at     TIMESTAMP(3) NOT NULL,
+
+    PRIMARY KEY (gid)
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX sync_cursors_session_symbol ON sync_cursors (session, symbol);
+-- +end
+
+-- +down
+-- +begin
+DROP TABLE IF EXISTS sync_cursors;
+-- +end
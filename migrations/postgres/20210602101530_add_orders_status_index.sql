@@ -0,0 +1,9 @@
+-- +up
+-- +begin
+CREATE INDEX orders_symbol_status ON orders (exchange, symbol, status, created_at);
+-- +end
+
+-- +down
+-- +begin
+DROP INDEX IF EXISTS orders_symbol_status;
+-- +end
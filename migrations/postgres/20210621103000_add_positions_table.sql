@@ -0,0 +1,33 @@
+-- +up
+-- +begin
+CREATE TABLE positions
+(
+    gid            BIGSERIAL      NOT NULL,
+    session        VARCHAR(30)    NOT NULL,
+    exchange       VARCHAR(24)    NOT NULL,
+    symbol         VARCHAR(20)    NOT NULL,
+    base_currency  VARCHAR(10)    NOT NULL,
+    quote_currency VARCHAR(10)    NOT NULL,
+
+    -- base is negative for the short position
+    base           NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    quote          NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    average_cost   NUMERIC(20, 8) NOT NULL DEFAULT 0,
+
+    -- last_trade_gid is the gid of the last trade aggregated into the position
+    last_trade_gid BIGINT         NOT NULL DEFAULT 0,
+
+    updated_at     TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY (gid)
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX positions_session_symbol ON positions (session, symbol);
+-- +end
+
+-- +down
+-- +begin
+DROP TABLE IF EXISTS positions;
+-- +end
@@ -0,0 +1,30 @@
+-- +up
+-- +begin
+CREATE TABLE `sync_cursors`
+(
+    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`       VARCHAR(30) NOT NULL,
+    `exchange`      VARCHAR(24) NOT NULL,
+    `symbol`        VARCHAR(20) NOT NULL,
+
+    -- last_trade_id and last_order_id are the IDs of the last synced trade and order
+    `last_trade_id` INTEGER     NOT NULL DEFAULT 0,
+    `last_order_id` INTEGER     NOT NULL DEFAULT 0,
+
+    -- synced_at is the time the last successful sync started
+    `synced_at`     DATETIME(3) NOT NULL
+);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX `sync_cursors_session_symbol` ON `sync_cursors` (`session`, `symbol`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP INDEX IF EXISTS `sync_cursors_session_symbol`;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS `sync_cursors`;
+-- +end
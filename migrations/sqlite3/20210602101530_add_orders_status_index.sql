@@ -0,0 +1,5 @@
+-- +up
+CREATE INDEX orders_symbol_status ON orders (exchange, symbol, status, created_at);
+
+-- +down
+DROP INDEX IF EXISTS orders_symbol_status;
@@ -0,0 +1,32 @@
+-- +up
+-- +begin
+CREATE TABLE `balance_snapshots`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`        VARCHAR(30)    NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL,
+    `currency`       VARCHAR(10)    NOT NULL,
+    `available`      DECIMAL(20, 8) NOT NULL,
+    `locked`         DECIMAL(20, 8) NOT NULL,
+
+    -- price is the price of the currency in the quote currency, it's zero if the price is unknown
+    `price`          DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    `quote_currency` VARCHAR(10)    NOT NULL,
+
+    -- created_at is the time of the snapshot, the balances of a session snapshot share the same time
+    `created_at`     DATETIME(3)    NOT NULL
+);
+-- +end
+-- +begin
+CREATE INDEX `balance_snapshots_session_created_at` ON `balance_snapshots` (`session`, `created_at`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP INDEX IF EXISTS `balance_snapshots_session_created_at`;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS `balance_snapshots`;
+-- +end
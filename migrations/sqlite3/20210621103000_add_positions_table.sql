@@ -0,0 +1,35 @@
+-- +up
+-- +begin
+CREATE TABLE `positions`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`        VARCHAR(30)    NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL,
+    `symbol`         VARCHAR(20)    NOT NULL,
+    `base_currency`  VARCHAR(10)    NOT NULL,
+    `quote_currency` VARCHAR(10)    NOT NULL,
+
+    -- base is negative for the short position
+    `base`           DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    `quote`          DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    `average_cost`   DECIMAL(20, 8) NOT NULL DEFAULT 0,
+
+    -- last_trade_gid is the gid of the last trade aggregated into the position
+    `last_trade_gid` INTEGER        NOT NULL DEFAULT 0,
+
+    `updated_at`     DATETIME(3)    NOT NULL
+);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX `positions_session_symbol` ON `positions` (`session`, `symbol`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP INDEX IF EXISTS `positions_session_symbol`;
+-- +end
+
+-- +begin
+DROP TABLE IF EXISTS `positions`;
+-- +end
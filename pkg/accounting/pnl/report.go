@@ -12,20 +12,44 @@ import (
 )
 
 type AverageCostPnlReport struct {
-	CurrentPrice float64
-	StartTime    time.Time
-	Symbol       string
-	Market       types.Market
-
-	NumTrades        int
-	Profit           float64
-	UnrealizedProfit float64
-	AverageBidCost   float64
-	BuyVolume        float64
-	SellVolume       float64
-	FeeInUSD         float64
-	Stock            float64
-	CurrencyFees     map[string]float64
+	CurrentPrice float64      `json:"currentPrice"`
+	StartTime    time.Time    `json:"startTime"`
+	Symbol       string       `json:"symbol"`
+	Market       types.Market `json:"market"`
+
+	NumTrades        int                `json:"numTrades"`
+	Profit           float64            `json:"profit"`
+	UnrealizedProfit float64            `json:"unrealizedProfit"`
+	AverageBidCost   float64            `json:"averageBidCost"`
+	BuyVolume        float64            `json:"buyVolume"`
+	SellVolume       float64            `json:"sellVolume"`
+	FeeInUSD         float64            `json:"feeInUSD"`
+	Stock            float64            `json:"stock"`
+	CurrencyFees     map[string]float64 `json:"currencyFees"`
+
+	// ReportingCurrency is the currency the profits are converted into, the reporting profits are not set if it's empty
+	ReportingCurrency         string  `json:"reportingCurrency,omitempty"`
+	ReportingProfit           float64 `json:"reportingProfit,omitempty"`
+	ReportingUnrealizedProfit float64 `json:"reportingUnrealizedProfit,omitempty"`
+
+	// ConversionMissing is true if the quote currency of the symbol can not be converted into the reporting currency,
+	// the reporting profits are zero in this case
+	ConversionMissing bool `json:"conversionMissing,omitempty"`
+}
+
+// SetReportingCurrency converts the profits into the reporting currency by the price of the quote currency in the
+// reporting currency, the report is flagged with ConversionMissing if the price is not available
+func (report *AverageCostPnlReport) SetReportingCurrency(currency string, quotePrice float64, ok bool) {
+	report.ReportingCurrency = currency
+	report.ConversionMissing = !ok
+	if !ok {
+		report.ReportingProfit = 0
+		report.ReportingUnrealizedProfit = 0
+		return
+	}
+
+	report.ReportingProfit = report.Profit * quotePrice
+	report.ReportingUnrealizedProfit = report.UnrealizedProfit * quotePrice
 }
 
 func (report AverageCostPnlReport) Print() {
@@ -43,6 +67,15 @@ func (report AverageCostPnlReport) Print() {
 	}
 	log.Infof("PROFIT: %s", types.USD.FormatMoneyFloat64(report.Profit))
 	log.Infof("UNREALIZED PROFIT: %s", types.USD.FormatMoneyFloat64(report.UnrealizedProfit))
+
+	if len(report.ReportingCurrency) > 0 {
+		if report.ConversionMissing {
+			log.Warnf("PROFIT (%s): N/A, the %s price of %s is missing", report.ReportingCurrency, report.ReportingCurrency, report.Market.QuoteCurrency)
+		} else {
+			log.Infof("PROFIT (%s): %f", report.ReportingCurrency, report.ReportingProfit)
+			log.Infof("UNREALIZED PROFIT (%s): %f", report.ReportingCurrency, report.ReportingUnrealizedProfit)
+		}
+	}
 }
 
 func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
@@ -52,21 +85,40 @@ func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
 		color = slackstyle.Green
 	}
 
+	fields := []slack.AttachmentField{
+		{Title: "Profit", Value: types.USD.FormatMoney(report.Profit)},
+		{Title: "Unrealized Profit", Value: types.USD.FormatMoney(report.UnrealizedProfit)},
+	}
+
+	if len(report.ReportingCurrency) > 0 {
+		if report.ConversionMissing {
+			fields = append(fields, slack.AttachmentField{
+				Title: "Profit (" + report.ReportingCurrency + ")",
+				Value: "N/A, the " + report.ReportingCurrency + " price of " + report.Market.QuoteCurrency + " is missing",
+			})
+		} else {
+			fields = append(fields,
+				slack.AttachmentField{Title: "Profit (" + report.ReportingCurrency + ")", Value: strconv.FormatFloat(report.ReportingProfit, 'f', 2, 64), Short: true},
+				slack.AttachmentField{Title: "Unrealized Profit (" + report.ReportingCurrency + ")", Value: strconv.FormatFloat(report.ReportingUnrealizedProfit, 'f', 2, 64), Short: true},
+			)
+		}
+	}
+
+	fields = append(fields,
+		slack.AttachmentField{Title: "Current Price", Value: report.Market.FormatPrice(report.CurrentPrice), Short: true},
+		slack.AttachmentField{Title: "Average Cost", Value: report.Market.FormatPrice(report.AverageBidCost), Short: true},
+		slack.AttachmentField{Title: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeInUSD), Short: true},
+		slack.AttachmentField{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
+		slack.AttachmentField{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
+	)
+
 	return slack.Attachment{
 		Title: report.Symbol + " Profit and Loss report",
 		Text:  "Profit " + types.USD.FormatMoney(report.Profit),
 		Color: color,
 		// Pretext:       "",
 		// Text:          "",
-		Fields: []slack.AttachmentField{
-			{Title: "Profit", Value: types.USD.FormatMoney(report.Profit)},
-			{Title: "Unrealized Profit", Value: types.USD.FormatMoney(report.UnrealizedProfit)},
-			{Title: "Current Price", Value: report.Market.FormatPrice(report.CurrentPrice), Short: true},
-			{Title: "Average Cost", Value: report.Market.FormatPrice(report.AverageBidCost), Short: true},
-			{Title: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeInUSD), Short: true},
-			{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
-			{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
-		},
+		Fields:     fields,
 		Footer:     report.StartTime.Format(time.RFC822),
 		FooterIcon: "",
 	}
@@ -0,0 +1,194 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// DefaultBalanceSnapshotInterval is the interval of the balance snapshots if the interval is not configured
+const DefaultBalanceSnapshotInterval = time.Hour
+
+// DefaultBalanceSnapshotQuoteCurrency is the currency the balances are valued in if the quote currency is not configured
+const DefaultBalanceSnapshotQuoteCurrency = "USDT"
+
+// BalanceSnapshotConfig records the session balances to the database on every interval and right after the
+// significant trades, so that the balance history of the sessions can be queried later.
+type BalanceSnapshotConfig struct {
+	// Interval is the interval between the snapshots, e.g., "1h"
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// QuoteCurrency is the currency the balances are valued in, the prices are queried from the session markets.
+	// It defaults to the reporting currency, or USDT if the reporting currency is not set either.
+	QuoteCurrency string `json:"quoteCurrency,omitempty" yaml:"quoteCurrency,omitempty"`
+
+	// MinTradeQuoteQuantity takes an extra snapshot after the trade of which the quote quantity reaches it,
+	// the extra snapshots are disabled if it's zero
+	MinTradeQuoteQuantity fixedpoint.Value `json:"minTradeQuoteQuantity,omitempty" yaml:"minTradeQuoteQuantity,omitempty"`
+
+	// Sessions are the sessions to snapshot, all sessions are snapshotted if it's not set
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+}
+
+func (environ *Environment) ConfigureBalanceSnapshot(conf *BalanceSnapshotConfig) {
+	environ.balanceSnapshotConfig = conf
+}
+
+// StartBalanceSnapshots snapshots the balances of the configured sessions on every interval in the background
+// until the context is done.
+func (environ *Environment) StartBalanceSnapshots(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultBalanceSnapshotInterval
+	}
+
+	log.Infof("starting the balance snapshots, interval: %s", interval)
+	go environ.runBalanceSnapshots(ctx, interval)
+}
+
+func (environ *Environment) runBalanceSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for _, session := range environ.balanceSnapshotSessions() {
+				if err := environ.SnapshotBalances(ctx, session); err != nil {
+					log.WithError(err).Errorf("can not snapshot the balances of session %s", session.Name)
+				}
+			}
+		}
+	}
+}
+
+// balanceSnapshotSessions returns the configured snapshot sessions sorted by name
+func (environ *Environment) balanceSnapshotSessions() (sessions []*ExchangeSession) {
+	var all = environ.Sessions()
+	var names = environ.balanceSnapshotConfig.Sessions
+	if len(names) == 0 {
+		names = sortedSessionNames(all)
+	} else {
+		names = append([]string(nil), names...)
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		if session, ok := all[name]; ok && !session.PublicOnly {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions
+}
+
+// configureBalanceSnapshotTrigger snapshots the session balances right after the significant trades
+func (environ *Environment) configureBalanceSnapshotTrigger(ctx context.Context, session *ExchangeSession) {
+	conf := environ.balanceSnapshotConfig
+	if conf == nil || conf.MinTradeQuoteQuantity <= 0 || environ.BalanceSnapshotService == nil {
+		return
+	}
+
+	if len(conf.Sessions) > 0 && !util.StringSliceContains(conf.Sessions, session.Name) {
+		return
+	}
+
+	minQuoteQuantity := conf.MinTradeQuoteQuantity.Float64()
+	session.Stream.OnTradeUpdate(func(trade types.Trade) {
+		if trade.QuoteQuantity < minQuoteQuantity {
+			return
+		}
+
+		go func() {
+			if err := environ.SnapshotBalances(ctx, session); err != nil {
+				log.WithError(err).Errorf("can not snapshot the balances of session %s after the trade %d", session.Name, trade.ID)
+			}
+		}()
+	})
+}
+
+// SnapshotBalances queries the balances of the session, values them in the configured quote currency and stores them,
+// the balances without the market of the quote currency are stored with the zero price.
+func (environ *Environment) SnapshotBalances(ctx context.Context, session *ExchangeSession) error {
+	if environ.BalanceSnapshotService == nil {
+		return ErrDatabaseNotConfigured
+	}
+
+	if session.PublicOnly {
+		return ErrPublicOnlySession
+	}
+
+	quoteCurrency := DefaultBalanceSnapshotQuoteCurrency
+	if conf := environ.balanceSnapshotConfig; conf != nil && len(conf.QuoteCurrency) > 0 {
+		quoteCurrency = conf.QuoteCurrency
+	} else if len(environ.reportingCurrency) > 0 {
+		quoteCurrency = environ.reportingCurrency
+	}
+
+	// the snapshots triggered by the trades and the interval are not interleaved
+	environ.balanceSnapshotMutex.Lock()
+	defer environ.balanceSnapshotMutex.Unlock()
+
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return fmt.Errorf("can not query the balances: %w", err)
+	}
+
+	var currencies []string
+	for currency, balance := range balances {
+		if balance.Available == 0 && balance.Locked == 0 {
+			continue
+		}
+
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	now := datatype.Time(time.Now())
+	var snapshots []service.BalanceSnapshot
+	for _, currency := range currencies {
+		balance := balances[currency]
+		price, _ := environ.QueryPrice(ctx, session, currency, quoteCurrency)
+		snapshots = append(snapshots, service.BalanceSnapshot{
+			Session:       session.Name,
+			Exchange:      session.Exchange.Name(),
+			Currency:      currency,
+			Available:     balance.Available,
+			Locked:        balance.Locked,
+			Price:         price,
+			QuoteCurrency: quoteCurrency,
+			Time:          now,
+		})
+	}
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	return environ.BalanceSnapshotService.Insert(ctx, snapshots)
+}
+
+// QueryBalanceHistory queries the balance snapshots of the session in the time range [since, until),
+// each point of the history is the total value of a snapshot in the quote currency
+func (environ *Environment) QueryBalanceHistory(ctx context.Context, sessionName string, since, until time.Time) ([]service.BalanceHistoryPoint, error) {
+	if environ.BalanceSnapshotService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
+
+	if _, ok := environ.Session(sessionName); !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
+	}
+
+	return environ.BalanceSnapshotService.QueryHistory(ctx, sessionName, since, until)
+}
@@ -0,0 +1,81 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testBalanceSnapshotExchange struct {
+	testSymbolsExchange
+}
+
+func (e *testBalanceSnapshotExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *testBalanceSnapshotExchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return &types.Ticker{Last: 30000.0}, nil
+}
+
+func TestEnvironment_SnapshotBalances(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	environ, _ := newTestEnvironment("binance")
+	session := environ.sessions["binance"]
+
+	assert.Equal(t, ErrDatabaseNotConfigured, environ.SnapshotBalances(ctx, session))
+
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	session.Exchange = &testBalanceSnapshotExchange{}
+	session.markets = types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}
+
+	since := time.Now().Add(-time.Minute)
+	if !assert.NoError(t, environ.SnapshotBalances(ctx, session)) {
+		return
+	}
+
+	// the last price of the stream is preferred to the ticker
+	session.lastPrices = map[string]float64{"BTCUSDT": 32000.0}
+	if !assert.NoError(t, environ.SnapshotBalances(ctx, session)) {
+		return
+	}
+
+	history, err := environ.QueryBalanceHistory(ctx, "binance", since, time.Now().Add(time.Minute))
+	if assert.NoError(t, err) && assert.Len(t, history, 2) {
+		// 0.1 BTC and 100 USDT
+		assert.Equal(t, "USDT", history[0].QuoteCurrency)
+		assert.Equal(t, 3100.0, history[0].Value.Float64())
+		assert.Len(t, history[0].Balances, 2)
+		assert.Equal(t, 3300.0, history[1].Value.Float64())
+	}
+
+	_, err = environ.QueryBalanceHistory(ctx, "max", since, time.Now())
+	assert.Error(t, err)
+
+	// the balances are snapshotted after the significant trades only
+	environ.ConfigureBalanceSnapshot(&BalanceSnapshotConfig{
+		MinTradeQuoteQuantity: fixedpoint.NewFromFloat(1000.0),
+	})
+	environ.configureBalanceSnapshotTrigger(ctx, session)
+
+	stream := session.Stream.(*testStream)
+	stream.EmitTradeUpdate(types.Trade{ID: 1, Symbol: "BTCUSDT", QuoteQuantity: 999.0})
+	stream.EmitTradeUpdate(types.Trade{ID: 2, Symbol: "BTCUSDT", QuoteQuantity: 1000.0})
+
+	assert.Eventually(t, func() bool {
+		history, err := environ.QueryBalanceHistory(ctx, "binance", since, time.Now().Add(time.Minute))
+		return err == nil && len(history) == 3
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package bbgo
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CheckConfig checks the config in the validate-only mode, nothing is connected and no api request is sent:
+//
+// - the config is validated by Config.Validate
+// - the exchange sessions are created without the api credentials
+// - the notification system is configured without the chat bots
+// - the strategies are attached to the sessions and validated if they implement Validator
+//
+// The environment is switched to the validate-only mode, so it can't be connected after the check.
+func (environ *Environment) CheckConfig(userConfig *Config) error {
+	environ.SetValidateOnly(true)
+
+	if err := userConfig.Validate(environ); err != nil {
+		return err
+	}
+
+	if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+		return errors.Wrap(err, "exchange session configure error")
+	}
+
+	if err := environ.ConfigureNotificationSystem(userConfig); err != nil {
+		return errors.Wrap(err, "notification configure error")
+	}
+
+	trader := NewTrader(environ)
+	if err := trader.Configure(userConfig); err != nil {
+		return errors.Wrap(err, "strategy configure error")
+	}
+
+	for _, mount := range userConfig.ExchangeStrategies {
+		if v, ok := mount.Strategy.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return errors.Wrapf(err, "strategy %s validation error", mount.Strategy.ID())
+			}
+		}
+	}
+
+	for _, strategy := range userConfig.CrossExchangeStrategies {
+		if v, ok := strategy.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return errors.Wrapf(err, "cross exchange strategy %s validation error", strategy.ID())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_CheckConfig(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	viper.Set("telegram-bot-token", "123:token")
+	viper.Set("telegram-api-url", server.URL)
+	defer func() {
+		viper.Set("telegram-bot-token", "")
+		viper.Set("telegram-api-url", "")
+	}()
+
+	dir, err := ioutil.TempDir("", "bbgo-check")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// the sessions of the config use the env var prefixes, the api keys are not required for the check
+	config, err := Load("testdata/strategy.yaml", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	logPath := filepath.Join(dir, "notifications.log")
+	config.Notifications = &NotificationConfig{
+		File: &FileNotification{Path: logPath},
+	}
+
+	environ := NewEnvironment()
+	if assert.NoError(t, environ.CheckConfig(config)) {
+		assert.True(t, environ.IsValidateOnly())
+		assert.Len(t, environ.Sessions(), 2)
+	}
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests), "the telegram bot should not be created")
+
+	_, err = os.Stat(logPath)
+	assert.True(t, os.IsNotExist(err), "the notification log file should not be created")
+
+	assert.Equal(t, ErrValidateOnly, environ.Connect(context.Background()))
+	assert.Equal(t, ErrValidateOnly, environ.Init(context.Background()))
+
+	// the strategy mounted on the undefined session fails the check
+	config.ExchangeStrategies[0].Mounts = []string{"kucoin"}
+	assert.Error(t, NewEnvironment().CheckConfig(config))
+}
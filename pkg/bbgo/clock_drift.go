@@ -0,0 +1,128 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultClockDriftThreshold is the max clock drift before the warning, e.g., binance rejects the signed requests of
+// which the timestamp is 1 second ahead of the server time
+const DefaultClockDriftThreshold = time.Second
+
+// ClockDriftConfig is the threshold of the clock drift check, the check runs when the environment starts
+type ClockDriftConfig struct {
+	// Threshold is the max drift between the local clock and the exchange server time, defaults to 1s
+	Threshold types.Duration `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+
+	// Notify sends the critical notification if the drift exceeds the threshold, the drift is only logged by default
+	Notify bool `json:"notify,omitempty" yaml:"notify,omitempty"`
+}
+
+func (c *ClockDriftConfig) validate() (problems []string) {
+	if c.Threshold < 0 {
+		problems = append(problems, "threshold can not be negative")
+	}
+
+	return problems
+}
+
+// ClockDrift is the drift of the local clock against the exchange server time of a session
+type ClockDrift struct {
+	Session string `json:"session"`
+
+	// Drift is the local time minus the server time, it's positive if the local clock is ahead. The local time is
+	// estimated at the middle of the round trip of the server time request.
+	Drift time.Duration `json:"drift"`
+
+	RoundTrip time.Duration `json:"roundTrip"`
+
+	Exceeded bool `json:"exceeded"`
+}
+
+// ConfigureClockDrift sets the threshold of the clock drift check
+func (environ *Environment) ConfigureClockDrift(conf *ClockDriftConfig) {
+	environ.clockDriftConfig = conf
+}
+
+// CheckClockDrift queries the server time of the session exchanges and compares it with the local clock, a warning is
+// logged for the drift exceeding the threshold, and it's notified critically if it's configured. The public only
+// sessions, which send no signed request, and the exchanges without the server time endpoint are skipped. All the
+// sessions are checked even if a query fails, the first query error is returned.
+func (environ *Environment) CheckClockDrift(ctx context.Context) (drifts []ClockDrift, err error) {
+	threshold := DefaultClockDriftThreshold
+	notify := false
+	if conf := environ.clockDriftConfig; conf != nil {
+		if conf.Threshold > 0 {
+			threshold = conf.Threshold.Duration()
+		}
+
+		notify = conf.Notify
+	}
+
+	sessions := environ.Sessions()
+	for _, name := range sortedSessionNames(sessions) {
+		session := sessions[name]
+		if session.PublicOnly {
+			continue
+		}
+
+		exchange := session.Exchange
+		if dryRun, ok := exchange.(*DryRunExchange); ok {
+			exchange = dryRun.Exchange
+		}
+
+		service, ok := exchange.(types.ServerTimeExchange)
+		if !ok {
+			log.Debugf("exchange %s of session %s has no server time endpoint, skipping the clock drift check", session.ExchangeName, name)
+			continue
+		}
+
+		start := time.Now()
+		serverTime, queryErr := service.QueryServerTime(ctx)
+		if queryErr != nil {
+			log.WithError(queryErr).Errorf("can not query the server time of session %s", name)
+			if err == nil {
+				err = queryErr
+			}
+			continue
+		}
+
+		roundTrip := time.Since(start)
+		drift := ClockDrift{
+			Session:   name,
+			Drift:     start.Add(roundTrip / 2).Sub(serverTime),
+			RoundTrip: roundTrip,
+		}
+
+		// the drift within the half round trip can not be told apart from the network latency
+		if absDuration(drift.Drift) > threshold+roundTrip/2 {
+			drift.Exceeded = true
+
+			log.Warnf("the local clock drifts %s from the server time of session %s, the signed requests may fail, please sync the clock",
+				drift.Drift, name)
+
+			if notify {
+				environ.NotifyCritical(":alarm_clock: the local clock drifts %s from the server time of session %s, the signed requests may fail, please sync the clock",
+					drift.Drift, name)
+			}
+		} else {
+			log.Infof("the local clock drifts %s from the server time of session %s (round trip %s)", drift.Drift, name, roundTrip)
+		}
+
+		drifts = append(drifts, drift)
+	}
+
+	return drifts, err
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
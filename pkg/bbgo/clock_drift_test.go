@@ -0,0 +1,54 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testServerTimeExchange struct {
+	types.Exchange
+
+	offset time.Duration
+	err    error
+}
+
+func (e *testServerTimeExchange) QueryServerTime(ctx context.Context) (time.Time, error) {
+	return time.Now().Add(e.offset), e.err
+}
+
+func TestEnvironment_CheckClockDrift(t *testing.T) {
+	environ, notifier := newTestEnvironment()
+	environ.ConfigureClockDrift(&ClockDriftConfig{Threshold: types.Duration(time.Second), Notify: true})
+
+	environ.sessions["binance"] = &ExchangeSession{Name: "binance", Exchange: &testServerTimeExchange{offset: -5 * time.Second}}
+	environ.sessions["max"] = &ExchangeSession{Name: "max", Exchange: &testServerTimeExchange{}}
+	environ.sessions["public"] = &ExchangeSession{Name: "public", PublicOnly: true, Exchange: &testServerTimeExchange{offset: time.Hour}}
+	environ.sessions["unsupported"] = &ExchangeSession{Name: "unsupported", Exchange: &testSubmitExchange{}}
+
+	drifts, err := environ.CheckClockDrift(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, drifts, 2, "the public only sessions and the exchanges without the server time should be skipped") {
+		assert.Equal(t, "binance", drifts[0].Session)
+		assert.InDelta(t, 5*time.Second, drifts[0].Drift, float64(100*time.Millisecond), "the local clock is ahead")
+		assert.True(t, drifts[0].Exceeded)
+
+		assert.Equal(t, "max", drifts[1].Session)
+		assert.False(t, drifts[1].Exceeded)
+	}
+
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Contains(t, notifier.notifications[0].text, "server time of session binance")
+	}
+
+	// the failed query doesn't stop checking the other sessions
+	environ.sessions["binance"].Exchange = &testServerTimeExchange{err: errors.New("timeout")}
+	drifts, err = environ.CheckClockDrift(context.Background())
+	assert.EqualError(t, err, "timeout")
+	assert.Len(t, drifts, 1)
+}
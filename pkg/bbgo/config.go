@@ -7,6 +7,9 @@ import (
 	"io/ioutil"
 	"reflect"
 	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/pkg/errors"
@@ -56,6 +59,136 @@ func (m *ExchangeStrategyMount) Map() (map[string]interface{}, error) {
 type SlackNotification struct {
 	DefaultChannel string `json:"defaultChannel,omitempty"  yaml:"defaultChannel,omitempty"`
 	ErrorChannel   string `json:"errorChannel,omitempty"  yaml:"errorChannel,omitempty"`
+
+	// Enabled is false to mute the slack notifier, the error log hook and the slack app interaction
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+type DiscordNotification struct {
+	DefaultChannel string `json:"defaultChannel,omitempty"  yaml:"defaultChannel,omitempty"`
+
+	// Channels maps the channel names used in the routing rules to the discord channel IDs
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// CircuitBreakerConfig is the circuit breaker of the HTTP notifiers, the notifications are dropped for the cool-down
+// period after the consecutive failed deliveries reach the failure threshold
+type CircuitBreakerConfig struct {
+	// FailureThreshold defaults to 5, the breaker is disabled if it's negative
+	FailureThreshold int `json:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty"`
+
+	// Cooldown defaults to 1m
+	Cooldown types.Duration `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+}
+
+type WebhookNotification struct {
+	URL string `json:"url" yaml:"url"`
+
+	// Secret is used for signing the payload with HMAC-SHA256, the signature is sent in the X-BBGO-Signature header
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Channels overrides the webhook URL of the given channel names
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	Timeout    types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxRetries *int           `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+type EmailNotification struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty" env:"SMTP_USERNAME"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty" env:"SMTP_PASSWORD"`
+
+	From    string   `json:"from" yaml:"from"`
+	To      []string `json:"to" yaml:"to"`
+	Subject string   `json:"subject,omitempty" yaml:"subject,omitempty"`
+
+	// TLS is one of "starttls", "tls" and "none", the default is "starttls"
+	TLS                string `json:"tls,omitempty" yaml:"tls,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+
+	// FlushInterval and BatchSize control when the batched notifications are sent
+	FlushInterval types.Duration `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+	BatchSize     int            `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// SMSNotification sends the critical notifications by SMS with Twilio
+type SMSNotification struct {
+	AccountSID string `json:"accountSID,omitempty" yaml:"accountSID,omitempty" env:"TWILIO_ACCOUNT_SID"`
+	AuthToken  string `json:"authToken,omitempty" yaml:"authToken,omitempty" env:"TWILIO_AUTH_TOKEN"`
+
+	From string   `json:"from" yaml:"from"`
+	To   []string `json:"to" yaml:"to"`
+
+	// MinSeverity is one of "info", "warning" and "critical", the default is "critical"
+	MinSeverity string `json:"minSeverity,omitempty" yaml:"minSeverity,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// MatrixNotification posts the notifications to the rooms of a matrix homeserver
+type MatrixNotification struct {
+	// HomeserverURL is the base URL of the homeserver, e.g., "https://matrix.example.org"
+	HomeserverURL string `json:"homeserverURL" yaml:"homeserverURL"`
+	AccessToken   string `json:"accessToken,omitempty" yaml:"accessToken,omitempty" env:"MATRIX_ACCESS_TOKEN"`
+
+	// DefaultRoom is the room ID of the notifications without the channel, e.g., "!abcdef:example.org"
+	DefaultRoom string `json:"defaultRoom,omitempty" yaml:"defaultRoom,omitempty"`
+
+	// Rooms maps the channel names used in the routing rules to the matrix room IDs
+	Rooms map[string]string `json:"rooms,omitempty" yaml:"rooms,omitempty"`
+
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// FileNotification appends the notifications and the attached objects as JSON lines to a log file
+type FileNotification struct {
+	Path string `json:"path" yaml:"path"`
+
+	// RotationSize is the file size to rotate the log file, e.g., "10MB", the file is not rotated if it's empty
+	RotationSize string `json:"rotationSize,omitempty" yaml:"rotationSize,omitempty"`
+
+	// Channels records only the notifications routed to the given channels, all the notifications are recorded if it's empty
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// TelegramNotification configures the telegram bot, the bot token is still set by the telegram-bot-token flag
+type TelegramNotification struct {
+	// APIURL is the telegram bot API endpoint, e.g., a mirror or a proxy, defaults to "https://api.telegram.org"
+	APIURL string `json:"apiURL,omitempty" yaml:"apiURL,omitempty"`
+
+	// PollerTimeout is the long polling timeout of the bot updates, defaults to 10s
+	PollerTimeout types.Duration `json:"pollerTimeout,omitempty" yaml:"pollerTimeout,omitempty"`
+
+	// Enabled is false to mute the telegram notifier, the bot is not created and the updates are not polled
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// Admins are the telegram user IDs who can list and revoke the authorized chats,
+	// the first authorized user is the admin if it's not set
+	Admins []int `json:"admins,omitempty" yaml:"admins,omitempty"`
+}
+
+// notifierEnabled returns false only if the notifier is disabled explicitly by "enabled: false",
+// so that the notifier can be muted without removing its config
+func notifierEnabled(enabled *bool) bool {
+	return enabled == nil || *enabled
 }
 
 type SlackNotificationRouting struct {
@@ -63,15 +196,275 @@ type SlackNotificationRouting struct {
 	Order       string `json:"order,omitempty" yaml:"order,omitempty"`
 	SubmitOrder string `json:"submitOrder,omitempty" yaml:"submitOrder,omitempty"`
 	PnL         string `json:"pnL,omitempty" yaml:"pnL,omitempty"`
+
+	// Strategy routes the notifications sent by the strategies, "$strategy" routes them by the strategy channels,
+	// "$silent" mutes them, and the other value is the channel of all strategies. It's not overridden by session.
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+
+	// MinNotional suppresses the trade and the order notifications of which the notional is below the threshold
+	MinNotional *NotificationMinNotional `json:"minNotional,omitempty" yaml:"minNotional,omitempty"`
+
+	// Channels sends the events of the listed types to the additional channels, they're notified even if the
+	// routing mode of the event type is "$silent"
+	Channels []NotificationChannelRoute `json:"channels,omitempty" yaml:"channels,omitempty"`
+}
+
+// NotificationMinNotional is the minimal notional filters of the trade and the order notifications
+type NotificationMinNotional struct {
+	Trade *MinNotionalFilter `json:"trade,omitempty" yaml:"trade,omitempty"`
+	Order *MinNotionalFilter `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// MinNotionalFilter filters the notifications by the notional in the quote currency, i.e., price * quantity,
+// the threshold of the symbol overrides the default threshold, zero means no filter.
+type MinNotionalFilter struct {
+	Default fixedpoint.Value            `json:"default,omitempty" yaml:"default,omitempty"`
+	Symbols map[string]fixedpoint.Value `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+}
+
+// Threshold returns the minimal notional of the symbol
+func (f *MinNotionalFilter) Threshold(symbol string) fixedpoint.Value {
+	if f == nil {
+		return 0
+	}
+
+	if threshold, ok := f.Symbols[symbol]; ok {
+		return threshold
+	}
+
+	return f.Default
+}
+
+// Allow returns true if the notional of price * quantity reaches the threshold of the symbol.
+// The zero notional is allowed, since the price of the market order is unknown.
+func (f *MinNotionalFilter) Allow(symbol string, price, quantity float64) bool {
+	threshold := f.Threshold(symbol)
+	if threshold <= 0 {
+		return true
+	}
+
+	notional := price * quantity
+	return notional == 0 || notional >= threshold.Float64()
+}
+
+// AllowTrade returns true if the trade notification is not filtered
+func (f *MinNotionalFilter) AllowTrade(trade types.Trade) bool {
+	return f.Allow(trade.Symbol, trade.Price, trade.Quantity)
+}
+
+// AllowOrder returns true if the order notification is not filtered
+func (f *MinNotionalFilter) AllowOrder(order types.Order) bool {
+	return f.Allow(order.Symbol, order.Price, order.Quantity)
+}
+
+// TradeFilter returns the trade notification filter, nil is returned if it's not set
+func (routing *SlackNotificationRouting) TradeFilter() *MinNotionalFilter {
+	if routing == nil || routing.MinNotional == nil {
+		return nil
+	}
+
+	return routing.MinNotional.Trade
+}
+
+// OrderFilter returns the order notification filter, nil is returned if it's not set
+func (routing *SlackNotificationRouting) OrderFilter() *MinNotionalFilter {
+	if routing == nil || routing.MinNotional == nil {
+		return nil
+	}
+
+	return routing.MinNotional.Order
 }
 
 type NotificationConfig struct {
-	Slack *SlackNotification `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Slack   *SlackNotification   `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Discord *DiscordNotification `json:"discord,omitempty" yaml:"discord,omitempty"`
+	Webhook *WebhookNotification `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	Email   *EmailNotification   `json:"email,omitempty" yaml:"email,omitempty"`
+	SMS     *SMSNotification     `json:"sms,omitempty" yaml:"sms,omitempty"`
+	Matrix  *MatrixNotification  `json:"matrix,omitempty" yaml:"matrix,omitempty"`
+	File    *FileNotification    `json:"file,omitempty" yaml:"file,omitempty"`
+
+	Telegram *TelegramNotification `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+
+	// Custom is the configs of the notifiers registered by RegisterNotifier, the key is the registered notifier name
+	Custom map[string]interface{} `json:"custom,omitempty" yaml:"custom,omitempty"`
+
+	SymbolChannels map[string]string `json:"symbolChannels,omitempty" yaml:"symbolChannels,omitempty"`
+
+	// SymbolAliases maps the exchange specific symbols to the canonical symbols, e.g., XBTUSD to BTCUSDT, the symbol
+	// channels are matched against the canonical symbols, see CanonicalSymbol
+	SymbolAliases map[string]string `json:"symbolAliases,omitempty" yaml:"symbolAliases,omitempty"`
 
-	SymbolChannels  map[string]string `json:"symbolChannels,omitempty" yaml:"symbolChannels,omitempty"`
 	SessionChannels map[string]string `json:"sessionChannels,omitempty" yaml:"sessionChannels,omitempty"`
 
+	// StrategyChannels maps the strategy ID patterns to the channels, it's used by the "$strategy" strategy routing
+	StrategyChannels map[string]string `json:"strategyChannels,omitempty" yaml:"strategyChannels,omitempty"`
+
 	Routing *SlackNotificationRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+
+	// SessionRoutings overrides the global routing config by session name
+	SessionRoutings map[string]*SlackNotificationRouting `json:"sessionRouting,omitempty" yaml:"sessionRouting,omitempty"`
+
+	// RateLimit coalesces the similar notifications sent to a channel in a time window
+	RateLimit *NotificationRateLimitConfig `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+
+	// Digest summarizes the trade, the order and the pnl notifications of the digest channels on an interval
+	Digest *NotificationDigestConfig `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// Fallback routes the trade, the order, the submit order and the pnl notifications missed by the routing modes,
+	// they're sent to the default channel if it's not set
+	Fallback *NotificationFallbackConfig `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+
+	// Delivery sets the delivery policy of the notifications, the notifications are broadcast to all the notifiers
+	// by default
+	Delivery *NotificationDeliveryConfig `json:"delivery,omitempty" yaml:"delivery,omitempty"`
+
+	// Format is the output format of the notified objects for the notifiers supporting the structured output,
+	// e.g., the webhook notifier. The chat notifiers always send the text. Valid formats are "text" and "json".
+	Format types.OutputFormat `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// TOTP sets the issuer and the account name of the one-time password key generated for the chat authorization
+	TOTP *TOTPConfig `json:"totp,omitempty" yaml:"totp,omitempty"`
+
+	// Templates overrides the built-in report templates of the trade, the order and the submit order notifications
+	Templates *NotificationTemplates `json:"templates,omitempty" yaml:"templates,omitempty"`
+
+	// PnLReport schedules the pnl reports of the synced trades, the reports are routed by the pnL routing
+	PnLReport *PnLReportConfig `json:"pnlReport,omitempty" yaml:"pnlReport,omitempty"`
+}
+
+// NotificationTemplates are the Go text/template strings of the notification reports, the trade template is rendered
+// with types.Trade, the order template is rendered with types.Order and the submit order template is rendered with
+// types.SubmitOrder. The templates are parsed and validated when the config is loaded, the built-in templates are
+// used for the empty ones.
+type NotificationTemplates struct {
+	Trade       string `json:"trade,omitempty" yaml:"trade,omitempty"`
+	Order       string `json:"order,omitempty" yaml:"order,omitempty"`
+	SubmitOrder string `json:"submitOrder,omitempty" yaml:"submitOrder,omitempty"`
+
+	trade, order, submitOrder *template.Template
+}
+
+func (t *NotificationTemplates) UnmarshalJSON(data []byte) error {
+	type rawTemplates NotificationTemplates
+	var raw rawTemplates
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*t = NotificationTemplates(raw)
+	return t.Parse()
+}
+
+func (t *NotificationTemplates) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawTemplates NotificationTemplates
+	var raw rawTemplates
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	*t = NotificationTemplates(raw)
+	return t.Parse()
+}
+
+// Parse parses the templates and renders them with the empty objects, so that the unknown fields fail fast
+func (t *NotificationTemplates) Parse() (err error) {
+	if t.trade, err = parseReportTemplate("trade", t.Trade, types.Trade{}); err != nil {
+		return err
+	}
+
+	if t.order, err = parseReportTemplate("order", t.Order, types.Order{}); err != nil {
+		return err
+	}
+
+	t.submitOrder, err = parseReportTemplate("submit order", t.SubmitOrder, types.SubmitOrder{})
+	return err
+}
+
+// TradeTemplate returns the parsed trade report template, nil is returned if it's not set
+func (t *NotificationTemplates) TradeTemplate() *template.Template {
+	return t.trade
+}
+
+// OrderTemplate returns the parsed order report template, nil is returned if it's not set
+func (t *NotificationTemplates) OrderTemplate() *template.Template {
+	return t.order
+}
+
+// SubmitOrderTemplate returns the parsed submit order report template, nil is returned if it's not set
+func (t *NotificationTemplates) SubmitOrderTemplate() *template.Template {
+	return t.submitOrder
+}
+
+func parseReportTemplate(name, text string, data interface{}) (*template.Template, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s notification template", name)
+	}
+
+	if err := tmpl.Execute(ioutil.Discard, data); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s notification template", name)
+	}
+
+	return tmpl, nil
+}
+
+// TOTPConfig is shown in the authenticator app to tell the one-time password keys of the bots apart,
+// the account name defaults to the telegram bot username for the telegram key.
+type TOTPConfig struct {
+	Issuer      string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	AccountName string `json:"accountName,omitempty" yaml:"accountName,omitempty"`
+
+	// QRCodeDir is the directory to write the qrcode png image of the generated key, defaults to the temp directory
+	QRCodeDir string `json:"qrCodeDir,omitempty" yaml:"qrCodeDir,omitempty"`
+
+	// ASCIIQRCode prints the qrcode to stdout instead of writing the png image
+	ASCIIQRCode bool `json:"asciiQRCode,omitempty" yaml:"asciiQRCode,omitempty"`
+}
+
+// SessionRouting returns the routing config of the given session,
+// the fields that are not defined in the session routing fall back to the global routing config.
+func (c *NotificationConfig) SessionRouting(session string) *SlackNotificationRouting {
+	override, ok := c.SessionRoutings[session]
+	if !ok || override == nil {
+		return c.Routing
+	}
+
+	// fall back to the global routing for the fields that are not overridden
+	var routing SlackNotificationRouting
+	if c.Routing != nil {
+		routing = *c.Routing
+	}
+
+	if len(override.Trade) > 0 {
+		routing.Trade = override.Trade
+	}
+
+	if len(override.Order) > 0 {
+		routing.Order = override.Order
+	}
+
+	if len(override.SubmitOrder) > 0 {
+		routing.SubmitOrder = override.SubmitOrder
+	}
+
+	if len(override.PnL) > 0 {
+		routing.PnL = override.PnL
+	}
+
+	if override.MinNotional != nil {
+		routing.MinNotional = override.MinNotional
+	}
+
+	if len(override.Channels) > 0 {
+		routing.Channels = override.Channels
+	}
+
+	return &routing
 }
 
 type Session struct {
@@ -135,8 +528,110 @@ func (m BacktestAccountBalanceMap) BalanceMap() types.BalanceMap {
 }
 
 type PersistenceConfig struct {
-	Redis *service.RedisPersistenceConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
-	Json  *service.JsonPersistenceConfig  `json:"json,omitempty" yaml:"json,omitempty"`
+	// Primary is the persistence type used by default, e.g., "redis", "dynamodb", "bolt", "json" or "memory",
+	// if it's not set, the configured persistence is selected in the order of redis, dynamodb, bolt and json.
+	Primary string `json:"primary,omitempty" yaml:"primary,omitempty"`
+
+	Redis    *service.RedisPersistenceConfig    `json:"redis,omitempty" yaml:"redis,omitempty"`
+	DynamoDB *service.DynamoDBPersistenceConfig `json:"dynamodb,omitempty" yaml:"dynamodb,omitempty"`
+	Json     *service.JsonPersistenceConfig     `json:"json,omitempty" yaml:"json,omitempty"`
+	Bolt     *service.BoltPersistenceConfig     `json:"bolt,omitempty" yaml:"bolt,omitempty"`
+
+	// Required fails the startup if the configured persistence can not be initialized, e.g., the redis server is down,
+	// otherwise the failed persistence is disabled with an error log, and the memory persistence may be used instead.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// IsDurableConfigured reports whether any durable persistence is configured
+func (c *PersistenceConfig) IsDurableConfigured() bool {
+	return c.Redis != nil || c.DynamoDB != nil || c.Json != nil || c.Bolt != nil
+}
+
+// SyncSince is the start point of the sync, it's either a duration before the current time, e.g., "720h" or "30d",
+// or an absolute time in RFC3339, e.g., "2021-01-01T00:00:00Z"
+type SyncSince struct {
+	Duration time.Duration
+	Time     time.Time
+}
+
+// StartTime returns the sync start time relative to now
+func (s SyncSince) StartTime(now time.Time) time.Time {
+	if !s.Time.IsZero() {
+		return s.Time
+	}
+
+	return now.Add(-s.Duration)
+}
+
+func (s SyncSince) String() string {
+	if !s.Time.IsZero() {
+		return s.Time.Format(time.RFC3339)
+	}
+
+	return s.Duration.String()
+}
+
+func (s SyncSince) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *SyncSince) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return s.parse(text)
+}
+
+func (s *SyncSince) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+
+	return s.parse(text)
+}
+
+func (s *SyncSince) parse(text string) error {
+	// the day unit is not supported by time.ParseDuration
+	if strings.HasSuffix(text, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(text, "d")); err == nil {
+			*s = SyncSince{Duration: time.Duration(days) * 24 * time.Hour}
+			return nil
+		}
+	}
+
+	if d, err := time.ParseDuration(text); err == nil {
+		*s = SyncSince{Duration: d}
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return fmt.Errorf("invalid sync since %q, expecting a duration like 720h or 30d, or a RFC3339 time", text)
+	}
+
+	*s = SyncSince{Time: t}
+	return nil
+}
+
+type SyncConfig struct {
+	// Since is the start point of the sync, the sync starts from one year ago by default
+	Since *SyncSince `json:"since,omitempty" yaml:"since,omitempty"`
+
+	// Symbols overrides the sync start point of the symbols, e.g., the newly added symbol doesn't need the full history
+	Symbols map[string]*SyncSince `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+
+	// Windows are the maximum time ranges of the order history queries by the exchange name, e.g., "ftx: 168h",
+	// the long sync time range is split into the windows. service.DefaultSyncWindows is used for the unlisted exchanges.
+	Windows map[string]types.Duration `json:"windows,omitempty" yaml:"windows,omitempty"`
+
+	// Scheduled enables the periodic sync while bbgo is running, so that the database is kept fresh without a cron job
+	Scheduled bool `json:"scheduled,omitempty" yaml:"scheduled,omitempty"`
+
+	// Interval is the interval between the scheduled syncs, e.g., "30m", DefaultSyncInterval is used if it's not set
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
 }
 
 type BuildTargetConfig struct {
@@ -175,6 +670,19 @@ type Config struct {
 
 	Persistence *PersistenceConfig `json:"persistence,omitempty" yaml:"persistence,omitempty"`
 
+	Sync *SyncConfig `json:"sync,omitempty" yaml:"sync,omitempty"`
+
+	Health *HealthConfig `json:"health,omitempty" yaml:"health,omitempty"`
+
+	// ClockDrift sets the threshold of the clock drift check against the exchange server time
+	ClockDrift *ClockDriftConfig `json:"clockDrift,omitempty" yaml:"clockDrift,omitempty"`
+
+	Metrics *MetricsConfig `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+
+	BalanceSnapshot *BalanceSnapshotConfig `json:"balanceSnapshot,omitempty" yaml:"balanceSnapshot,omitempty"`
+
+	Logging *LoggingConfig `json:"logging,omitempty" yaml:"logging,omitempty"`
+
 	Sessions map[string]*ExchangeSession `json:"sessions,omitempty" yaml:"sessions,omitempty"`
 
 	RiskControls *RiskControls `json:"riskControls,omitempty" yaml:"riskControls,omitempty"`
@@ -183,6 +691,14 @@ type Config struct {
 	CrossExchangeStrategies []CrossExchangeStrategy `json:"-" yaml:"-"`
 
 	PnLReporters []PnLReporterConfig `json:"reportPnL,omitempty" yaml:"reportPnL,omitempty"`
+
+	// ReportingCurrency is the single currency the PnL reports and the balance snapshots are valued in, e.g., USDT,
+	// the values are converted by the prices of the session markets
+	ReportingCurrency string `json:"reportingCurrency,omitempty" yaml:"reportingCurrency,omitempty"`
+
+	// PriceSources are the price sources registered by RegisterPriceSource, they're consulted in order before the
+	// exchange tickers when the assets are valued
+	PriceSources []PriceSourceConfig `json:"priceSources,omitempty" yaml:"priceSources,omitempty"`
 }
 
 func (c *Config) Map() (map[string]interface{}, error) {
@@ -254,24 +770,15 @@ func (c *Config) YAML() ([]byte, error) {
 
 type Stash map[string]interface{}
 
-func loadStash(config []byte) (Stash, error) {
-	stash := make(Stash)
-	if err := yaml.Unmarshal(config, stash); err != nil {
-		return nil, err
-	}
-
-	return stash, nil
-}
-
 func LoadBuildConfig(configFile string) (*Config, error) {
 	var config Config
 
-	content, err := ioutil.ReadFile(configFile)
+	root, err := readConfigs(configFile)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(content, &config); err != nil {
+	if err := decodeConfigNode(root, &config); err != nil {
 		return nil, err
 	}
 
@@ -292,16 +799,19 @@ func LoadBuildConfig(configFile string) (*Config, error) {
 	return &config, nil
 }
 
-// Load parses the config
+// Load parses the config, the environment variables like ${HOME} and ${REDIS_HOST:-127.0.0.1} in the values are expanded.
+// The config file can also be a http(s) URL or an s3://bucket/key path, see IsRemoteConfig. Multiple config files
+// separated by commas are merged in order before the config is parsed, see mergeConfigNode.
 func Load(configFile string, loadStrategies bool) (*Config, error) {
 	var config Config
+	var stash = make(Stash)
 
-	content, err := ioutil.ReadFile(configFile)
+	root, err := readConfigs(configFile)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(content, &config); err != nil {
+	if err := decodeConfigNode(root, &config, stash); err != nil {
 		return nil, err
 	}
 
@@ -317,11 +827,6 @@ func Load(configFile string, loadStrategies bool) (*Config, error) {
 		}
 	}
 
-	stash, err := loadStash(content)
-	if err != nil {
-		return nil, err
-	}
-
 	if loadStrategies {
 		if err := loadExchangeStrategies(&config, stash); err != nil {
 			return nil, err
@@ -0,0 +1,120 @@
+package bbgo
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfigYAML parses the config content, expands the environment variables in the values,
+// and decodes the document into each of the given values
+func decodeConfigYAML(content []byte, values ...interface{}) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return err
+	}
+
+	return decodeConfigNode(&root, values...)
+}
+
+// decodeConfigNode expands the environment variables in the values of the parsed config document,
+// and decodes the document into each of the given values
+func decodeConfigNode(root *yaml.Node, values ...interface{}) error {
+	// empty document
+	if root == nil || root.Kind == 0 {
+		return nil
+	}
+
+	expandNodeEnv(root)
+
+	for _, v := range values {
+		if err := root.Decode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandNodeEnv expands the environment variables in the scalar values of the node, the mapping keys are not expanded
+func expandNodeEnv(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, n := range node.Content {
+			expandNodeEnv(n)
+		}
+
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandNodeEnv(node.Content[i])
+		}
+
+	case yaml.ScalarNode:
+		value := expandEnv(node.Value)
+		if value == node.Value {
+			return
+		}
+
+		node.Value = value
+
+		// the plain scalar is resolved again after the expansion, so that "${REDIS_PORT}" can be decoded as an integer
+		if node.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+}
+
+// expandEnv expands ${VAR} and ${VAR:-fallback} in the value, the fallback is used if VAR is unset or empty.
+// "$$" is the escape of a literal "$", and the other "$" characters are kept as they are, so that the secrets
+// containing "$" don't need to be escaped.
+func expandEnv(value string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+
+		case '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				// unterminated, keep the rest as it is
+				sb.WriteString(value[i:])
+				return sb.String()
+			}
+
+			sb.WriteString(lookupEnvWithFallback(value[i+2 : i+2+end]))
+			i += 2 + end
+
+		default:
+			sb.WriteByte('$')
+		}
+	}
+
+	return sb.String()
+}
+
+// lookupEnvWithFallback looks up the expression "VAR" or "VAR:-fallback", the unset variable is expanded to the empty string
+func lookupEnvWithFallback(expr string) string {
+	name, fallback, hasFallback := expr, "", false
+	if idx := strings.Index(expr, ":-"); idx >= 0 {
+		name, fallback, hasFallback = expr[:idx], expr[idx+2:], true
+	}
+
+	value := os.Getenv(name)
+	if len(value) == 0 && hasFallback {
+		return fallback
+	}
+
+	return value
+}
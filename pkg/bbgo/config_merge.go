@@ -0,0 +1,87 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFiles splits the config option into the config locations, multiple config files are separated by commas,
+// e.g., "exchanges.yaml,strategies.yaml"
+func ConfigFiles(configFile string) (files []string) {
+	for _, f := range strings.Split(configFile, ",") {
+		if f = strings.TrimSpace(f); len(f) > 0 {
+			files = append(files, f)
+		}
+	}
+
+	return files
+}
+
+// readConfigs reads and merges the config files of the config option in order, the later files override the earlier
+// files, see mergeConfigNode
+func readConfigs(configFile string) (*yaml.Node, error) {
+	files := ConfigFiles(configFile)
+	if len(files) == 0 {
+		return nil, errors.New("config file is not specified")
+	}
+
+	var merged *yaml.Node
+	for _, f := range files {
+		content, err := readConfig(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var root yaml.Node
+		if err := yaml.Unmarshal(content, &root); err != nil {
+			return nil, fmt.Errorf("can not parse config %s: %w", f, err)
+		}
+
+		// empty document
+		if root.Kind == 0 || len(root.Content) == 0 {
+			continue
+		}
+
+		if merged == nil {
+			merged = &root
+			continue
+		}
+
+		mergeConfigNode(merged.Content[0], root.Content[0])
+	}
+
+	return merged, nil
+}
+
+// mergeConfigNode merges the src node into the dst node. The mappings are merged key by key recursively, e.g.,
+// the sessions and the notifiers defined in different files are all kept, and the same session in the later file
+// only overrides the fields it sets. The other values, including the lists like exchangeStrategies, are replaced by
+// the later value as a whole, and a null value removes the earlier value.
+func mergeConfigNode(dst, src *yaml.Node) {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		*dst = *src
+		return
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != key.Value {
+				continue
+			}
+
+			mergeConfigNode(dst.Content[j+1], value)
+			found = true
+			break
+		}
+
+		if !found {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
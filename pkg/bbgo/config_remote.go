@@ -0,0 +1,142 @@
+package bbgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codingconcepts/env"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const remoteConfigTimeout = 30 * time.Second
+
+// IsRemoteConfig returns true if the config location is a http(s) URL or an s3:// path
+func IsRemoteConfig(location string) bool {
+	return strings.HasPrefix(location, "https://") ||
+		strings.HasPrefix(location, "http://") ||
+		strings.HasPrefix(location, "s3://")
+}
+
+// readConfig reads the config content from the local file or the remote location. The fetched remote config is cached
+// in the config cache directory, and the cached config is used if the remote location is unreachable.
+func readConfig(location string) ([]byte, error) {
+	if !IsRemoteConfig(location) {
+		return ioutil.ReadFile(location)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteConfigTimeout)
+	defer cancel()
+
+	cacheFile, cacheErr := remoteConfigCacheFile(location)
+
+	content, err := fetchRemoteConfig(ctx, location)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+
+		cached, readErr := ioutil.ReadFile(cacheFile)
+		if readErr != nil {
+			return nil, err
+		}
+
+		log.WithError(err).Warnf("can not fetch the config from %s, using the cached config %s", location, cacheFile)
+		return cached, nil
+	}
+
+	if cacheErr != nil {
+		log.WithError(cacheErr).Warnf("can not cache the config of %s", location)
+		return content, nil
+	}
+
+	// the config may contain the secrets, so the cache is only readable by the owner
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		log.WithError(err).Warnf("can not create the config cache directory")
+	} else if err := ioutil.WriteFile(cacheFile, content, 0600); err != nil {
+		log.WithError(err).Warnf("can not cache the config of %s", location)
+	}
+
+	return content, nil
+}
+
+// remoteConfigCacheFile returns the cache file of the remote config, the cache directory is BBGO_CONFIG_CACHE_DIR
+// or the bbgo directory in the user cache directory
+func remoteConfigCacheFile(location string) (string, error) {
+	dir := os.Getenv("BBGO_CONFIG_CACHE_DIR")
+	if len(dir) == 0 {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		dir = filepath.Join(cacheDir, "bbgo", "config")
+	}
+
+	hash := sha256.Sum256([]byte(location))
+	return filepath.Join(dir, hex.EncodeToString(hash[:8])+".yaml"), nil
+}
+
+// fetchRemoteConfig downloads the config from the http(s) URL or the s3:// path,
+// the S3 region and credentials are read from the AWS_* env vars
+func fetchRemoteConfig(ctx context.Context, location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "s3":
+		if len(u.Host) == 0 || len(strings.TrimLeft(u.Path, "/")) == 0 {
+			return nil, fmt.Errorf("invalid s3 config location %s, expecting s3://bucket/key", location)
+		}
+
+		var conf service.S3Config
+		if err := env.Set(&conf); err != nil {
+			return nil, err
+		}
+
+		content, err := service.GetS3Object(ctx, &conf, u.Host, u.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not fetch the config from %s", location)
+		}
+
+		return content, nil
+
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not fetch the config from %s", location)
+		}
+
+		response, err := util.NewResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.IsError() {
+			return nil, fmt.Errorf("can not fetch the config from %s: status %d", location, response.StatusCode)
+		}
+
+		return response.Body, nil
+	}
+
+	return nil, fmt.Errorf("unsupported config location %s", location)
+}
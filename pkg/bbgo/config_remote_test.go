@@ -0,0 +1,57 @@
+package bbgo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRemoteConfig(t *testing.T) {
+	assert.True(t, IsRemoteConfig("https://example.com/bbgo.yaml"))
+	assert.True(t, IsRemoteConfig("s3://configs/bbgo.yaml"))
+	assert.False(t, IsRemoteConfig("config/bbgo.yaml"))
+	assert.False(t, IsRemoteConfig("/etc/bbgo/bbgo.yaml"))
+}
+
+func TestLoad_RemoteConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("BBGO_CONFIG_CACHE_DIR", dir)
+	defer os.Unsetenv("BBGO_CONFIG_CACHE_DIR")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bbgo.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte("sessions:\n  max:\n    exchange: max\n"))
+	}))
+
+	location := server.URL + "/bbgo.yaml"
+	config, err := Load(location, false)
+	if assert.NoError(t, err) {
+		assert.Contains(t, config.Sessions, "max")
+	}
+
+	_, err = Load(server.URL+"/missing.yaml", false)
+	assert.Error(t, err, "the error status should fail without the cache")
+
+	// the cached config is used if the remote is unreachable
+	server.Close()
+	config, err = Load(location, false)
+	if assert.NoError(t, err) {
+		assert.Contains(t, config.Sessions, "max")
+	}
+
+	_, err = Load("s3://configs", false)
+	assert.Error(t, err)
+}
@@ -2,11 +2,19 @@ package bbgo
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
@@ -55,6 +63,18 @@ func TestLoadConfig(t *testing.T) {
 				assert.NotNil(t, config.Notifications.Routing)
 				assert.Equal(t, "#dev-bbgo", config.Notifications.Slack.DefaultChannel)
 				assert.Equal(t, "#error", config.Notifications.Slack.ErrorChannel)
+
+				assert.Equal(t, "$silent", config.Notifications.SessionRouting("max").Trade)
+				assert.Equal(t, "$symbol", config.Notifications.SessionRouting("binance").Trade)
+
+				// the fields not defined in the session routing fall back to the global routing
+				assert.Equal(t, "$session", config.Notifications.SessionRouting("max").SubmitOrder)
+				assert.Equal(t, "#bbgo-pnl", config.Notifications.SessionRouting("max").PnL)
+
+				if assert.NotNil(t, config.Notifications.Templates) {
+					assert.NotNil(t, config.Notifications.Templates.TradeTemplate())
+					assert.Nil(t, config.Notifications.Templates.OrderTemplate(), "the built-in order template should be used")
+				}
 			},
 		},
 
@@ -211,3 +231,321 @@ func TestLoadConfig(t *testing.T) {
 	}
 
 }
+
+func TestLoadConfig_ExpandEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"BBGO_TEST_HOME":       "/home/bbgo",
+		"BBGO_TEST_REDIS_HOST": "redis.local",
+		"BBGO_TEST_REDIS_DB":   "2",
+		"BBGO_TEST_SYMBOL":     "ETHUSDT",
+		"BBGO_TEST_QUANTITY":   "0.5",
+		"BBGO_TEST_EMPTY":      "",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	dir, err := ioutil.TempDir("", "bbgo-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "bbgo.yaml")
+	err = ioutil.WriteFile(configFile, []byte(`
+sessions:
+  binance:
+    exchange: binance
+    envVarPrefix: ${BBGO_TEST_EMPTY:-binance}
+
+persistence:
+  json:
+    directory: ${BBGO_TEST_HOME}/bbgo
+  redis:
+    host: ${BBGO_TEST_REDIS_HOST}
+    port: "${BBGO_TEST_REDIS_PORT:-6379}"
+    password: pa$$word$
+    db: ${BBGO_TEST_REDIS_DB}
+
+exchangeStrategies:
+- on: binance
+  test:
+    symbol: ${BBGO_TEST_SYMBOL}
+    interval: ${BBGO_TEST_UNDEFINED}
+    baseQuantity: ${BBGO_TEST_QUANTITY}
+`), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	config, err := Load(configFile, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "binance", config.Sessions["binance"].EnvVarPrefix, "the fallback is used for the empty variable")
+	assert.Equal(t, "/home/bbgo/bbgo", config.Persistence.Json.Directory)
+	assert.Equal(t, "redis.local", config.Persistence.Redis.Host)
+	assert.Equal(t, "6379", config.Persistence.Redis.Port)
+	assert.Equal(t, "pa$word$", config.Persistence.Redis.Password)
+	assert.Equal(t, 2, config.Persistence.Redis.DB, "the expanded plain value is resolved as an integer")
+
+	if assert.Len(t, config.ExchangeStrategies, 1) {
+		assert.Equal(t, &TestStrategy{Symbol: "ETHUSDT", BaseQuantity: 0.5}, config.ExchangeStrategies[0].Strategy)
+	}
+}
+
+func TestLoadConfig_MultipleFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"exchanges.yaml": `
+sessions:
+  binance:
+    exchange: binance
+    envVarPrefix: binance
+    key: binance-key
+  max:
+    exchange: max
+    envVarPrefix: max
+
+persistence:
+  redis:
+    host: redis.local
+    port: "6379"
+`,
+		"strategies.yaml": `
+notifications:
+  slack:
+    defaultChannel: "#bbgo"
+
+exchangeStrategies:
+- on: binance
+  test:
+    symbol: BTCUSDT
+`,
+		"local.yaml": `
+sessions:
+  binance:
+    envVarPrefix: binance_test
+  max: null
+
+persistence:
+  redis:
+    host: 127.0.0.1
+
+exchangeStrategies:
+- on: binance
+  test:
+    symbol: ETHUSDT
+`,
+	}
+
+	var configFiles []string
+	for _, name := range []string{"exchanges.yaml", "strategies.yaml", "local.yaml"} {
+		configFile := filepath.Join(dir, name)
+		if !assert.NoError(t, ioutil.WriteFile(configFile, []byte(files[name]), 0644)) {
+			return
+		}
+
+		configFiles = append(configFiles, configFile)
+	}
+
+	config, err := Load(strings.Join(configFiles, ", "), true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the later file overrides the fields it sets
+	if assert.NotNil(t, config.Sessions["binance"]) {
+		assert.Equal(t, "binance_test", config.Sessions["binance"].EnvVarPrefix)
+		assert.Equal(t, "binance-key", config.Sessions["binance"].Key)
+	}
+	assert.Nil(t, config.Sessions["max"], "the null value removes the session")
+
+	assert.Equal(t, "127.0.0.1", config.Persistence.Redis.Host)
+	assert.Equal(t, "6379", config.Persistence.Redis.Port)
+	assert.Equal(t, "#bbgo", config.Notifications.Slack.DefaultChannel)
+
+	// the lists are replaced
+	if assert.Len(t, config.ExchangeStrategies, 1) {
+		assert.Equal(t, &TestStrategy{Symbol: "ETHUSDT"}, config.ExchangeStrategies[0].Strategy)
+	}
+
+	_, err = Load(configFiles[0]+","+filepath.Join(dir, "missing.yaml"), false)
+	assert.Error(t, err)
+}
+
+func TestExpandEnv(t *testing.T) {
+	os.Setenv("BBGO_TEST_VAR", "value")
+	defer os.Unsetenv("BBGO_TEST_VAR")
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${BBGO_TEST_VAR}", "value"},
+		{"prefix-${BBGO_TEST_VAR}-suffix", "prefix-value-suffix"},
+		{"${BBGO_TEST_UNDEFINED}", ""},
+		{"${BBGO_TEST_UNDEFINED:-fallback}", "fallback"},
+		{"${BBGO_TEST_VAR:-fallback}", "value"},
+		{"${BBGO_TEST_UNDEFINED:-}", ""},
+		{"$${BBGO_TEST_VAR}", "${BBGO_TEST_VAR}"},
+		{"$BBGO_TEST_VAR", "$BBGO_TEST_VAR"},
+		{"price$", "price$"},
+		{"${BBGO_TEST_VAR", "${BBGO_TEST_VAR"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, expandEnv(tt.input), tt.input)
+	}
+}
+
+func TestSyncSince(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text    string
+		want    time.Time
+		wantErr bool
+	}{
+		{text: "720h", want: now.Add(-720 * time.Hour)},
+		{text: "30d", want: now.AddDate(0, 0, -30)},
+		{text: "2021-01-01T00:00:00Z", want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{text: "last month", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var conf SyncConfig
+			err := yaml.Unmarshal([]byte("since: "+tt.text), &conf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) && assert.NotNil(t, conf.Since) {
+				assert.True(t, tt.want.Equal(conf.Since.StartTime(now)), "got %s", conf.Since.StartTime(now))
+			}
+
+			// the value should be kept when the config is converted to json
+			data, err := json.Marshal(conf)
+			assert.NoError(t, err)
+
+			var conf2 SyncConfig
+			assert.NoError(t, json.Unmarshal(data, &conf2))
+			assert.Equal(t, conf.Since, conf2.Since)
+		})
+	}
+}
+
+func TestNotificationTemplates(t *testing.T) {
+	var templates NotificationTemplates
+	err := yaml.Unmarshal([]byte(`order: "{{ .Symbol }} {{ .Status }}"`), &templates)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, templates.OrderTemplate())
+	}
+
+	err = yaml.Unmarshal([]byte(`submitOrder: "{{ .Symbol }} {{ .QuantityString }}"`), &templates)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, templates.SubmitOrderTemplate())
+	}
+
+	err = yaml.Unmarshal([]byte(`trade: "{{ .Symbol "`), &templates)
+	assert.Error(t, err, "the syntax error should fail at load")
+
+	err = json.Unmarshal([]byte(`{"trade": "{{ .Commission }}"}`), &templates)
+	assert.Error(t, err, "the unknown field should fail at load")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config, err := Load("testdata/strategy.yaml", true)
+	if assert.NoError(t, err) {
+		assert.NoError(t, config.Validate(nil), "the strategy mounts are not checked without the sessions")
+	}
+
+	config = &Config{
+		Sessions: map[string]*ExchangeSession{
+			"max":     {ExchangeName: "max"},
+			"binance": {ExchangeName: "binance", Margin: true, IsolatedMargin: true},
+			"ftx":         {ExchangeName: "ftx", Margin: true},
+			"ftx-futures": {ExchangeName: "ftx", Futures: true},
+			"futures":     {ExchangeName: "binance", Futures: true, Margin: true},
+			"okex":        {ExchangeName: "okex"},
+		},
+		Notifications: &NotificationConfig{
+			SymbolChannels:   map[string]string{"^BTC(": "#btc"},
+			SessionChannels:  map[string]string{"^binance$": "#binance", "^bianance$": "#typo"},
+			StrategyChannels: map[string]string{"^grid(": "#grid"},
+			Routing: &SlackNotificationRouting{
+				Trade:    "$session",
+				Order:    "$symbols",
+				Strategy: "$strategies",
+				MinNotional: &NotificationMinNotional{
+					Order: &MinNotionalFilter{Symbols: map[string]fixedpoint.Value{"BTCUSDT": fixedpoint.NewFromFloat(-10.0)}},
+				},
+			},
+			SessionRoutings: map[string]*SlackNotificationRouting{
+				"kucoin": {Trade: "$silent"},
+			},
+			Delivery: &NotificationDeliveryConfig{Policy: "roundrobin"},
+		},
+		ExchangeStrategies: []ExchangeStrategyMount{
+			{Mounts: []string{"max", "maxx"}, Strategy: &TestStrategy{}},
+		},
+		PnLReporters: []PnLReporterConfig{
+			{AverageCostBySymbols: []string{"BTCUSDT"}, Of: []string{"binance"}, When: []string{"@daily", "* * *"}},
+		},
+		Sync: &SyncConfig{
+			Windows: map[string]types.Duration{"ftx": types.Duration(7 * 24 * time.Hour), "kucoin": types.Duration(time.Hour)},
+		},
+		BalanceSnapshot: &BalanceSnapshotConfig{
+			Interval: types.Duration(-time.Hour),
+			Sessions: []string{"binance", "bitfinex"},
+		},
+		Logging:      &LoggingConfig{Format: "xml"},
+		PriceSources: []PriceSourceConfig{{Name: "oracle"}},
+	}
+
+	err = config.Validate(nil)
+	if assert.Error(t, err) {
+		validationErr, ok := err.(*ConfigValidationError)
+		if assert.True(t, ok) {
+			assert.Equal(t, []string{
+				`invalid symbol channel pattern "^BTC(": error parsing regexp: missing closing ): ` + "`^BTC(`",
+				`session channel pattern "^bianance$" matches no session`,
+				`invalid strategy channel pattern "^grid(": error parsing regexp: missing closing ): ` + "`^grid(`",
+				`notification routing: unknown order routing mode $symbols`,
+				`notification routing: order minNotional of BTCUSDT can not be negative`,
+				`notification routing: unknown strategy routing mode $strategies`,
+				`notification delivery: unknown delivery policy roundrobin`,
+				`notification session routing refers to the undefined session kucoin`,
+				`strategy test refers to the undefined session maxx`,
+				`pnl reporter #1: invalid schedule "* * *": expected exactly 5 fields, found 3: [* * *]`,
+				`sync window: invalid exchange name: kucoin`,
+				`balance snapshot refers to the undefined session bitfinex`,
+				`balance snapshot interval can not be negative`,
+				`logging: unknown log format xml, valid formats are text and json`,
+				`price source #1: oracle is not registered`,
+				`session binance: isolated margin requires isolatedMarginSymbol or isolatedMarginSymbols`,
+				`session ftx: exchange ftx does not support margin`,
+				`session ftx-futures: exchange ftx does not support futures`,
+				`session futures: futures can not be used with margin`,
+				`session okex: invalid exchange name: okex`,
+			}, validationErr.Problems)
+		}
+	}
+
+	// the session names are taken from the environment if the sessions config is not set
+	environ, _ := newTestEnvironment("max")
+	config = &Config{
+		ExchangeStrategies: []ExchangeStrategyMount{
+			{Mounts: []string{"max"}, Strategy: &TestStrategy{}},
+		},
+	}
+	assert.NoError(t, config.Validate(environ))
+}
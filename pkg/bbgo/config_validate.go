@@ -0,0 +1,396 @@
+package bbgo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// ConfigValidationError is the list of the problems found by Config.Validate
+type ConfigValidationError struct {
+	Problems []string
+}
+
+func (e *ConfigValidationError) Error() string {
+	return fmt.Sprintf("invalid config: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the config before the environment is initialized, so that the typos fail fast:
+//
+// - the notification routing modes are valid
+// - the symbol, the session and the strategy channel patterns compile, and the session channel patterns match a session
+// - the strategy mounts, the session routings and the pnl reporters refer to the configured sessions
+// - the margin and the isolated margin settings are supported by the session exchange
+//
+// The session names are taken from the sessions config, or the sessions of the environment if the sessions config
+// is not set, e.g., the sessions added by the api key env vars. The session references are not checked
+// if neither of them is available.
+func (c *Config) Validate(environ *Environment) error {
+	var problems []string
+	var addProblem = func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	sessionNames := c.sessionNames(environ)
+	checkSession := func(what, name string) {
+		if sessionNames == nil {
+			return
+		}
+
+		if _, ok := sessionNames[name]; !ok {
+			addProblem("%s refers to the undefined session %s", what, name)
+		}
+	}
+
+	if conf := c.Notifications; conf != nil {
+		for _, pattern := range sortedKeys(conf.SymbolChannels) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				addProblem("invalid symbol channel pattern %q: %v", pattern, err)
+			}
+		}
+
+		for _, problem := range validateSymbolAliases(conf.SymbolAliases) {
+			addProblem("notification symbol aliases: %s", problem)
+		}
+
+		for _, pattern := range sortedKeys(conf.SessionChannels) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				addProblem("invalid session channel pattern %q: %v", pattern, err)
+				continue
+			}
+
+			if sessionNames != nil && !matchAnySession(re, sessionNames) {
+				addProblem("session channel pattern %q matches no session", pattern)
+			}
+		}
+
+		for _, pattern := range sortedKeys(conf.StrategyChannels) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				addProblem("invalid strategy channel pattern %q: %v", pattern, err)
+			}
+		}
+
+		if conf.Routing != nil {
+			for _, problem := range conf.Routing.validate() {
+				addProblem("notification routing: %s", problem)
+			}
+
+			switch mode := conf.Routing.Strategy; mode {
+			case "$strategy", "$silent":
+
+			default:
+				if strings.HasPrefix(mode, "$") {
+					addProblem("notification routing: unknown strategy routing mode %s", mode)
+				}
+			}
+		}
+
+		if conf.Delivery != nil {
+			for _, problem := range conf.Delivery.validate() {
+				addProblem("notification delivery: %s", problem)
+			}
+		}
+
+		if conf.Digest != nil {
+			for _, problem := range conf.Digest.validate() {
+				addProblem("notification digest: %s", problem)
+			}
+		}
+
+		if conf.Fallback != nil {
+			for _, problem := range conf.Fallback.validate() {
+				addProblem("notification fallback: %s", problem)
+			}
+		}
+
+		var names []string
+		for name := range conf.SessionRoutings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			checkSession("notification session routing", name)
+
+			if routing := conf.SessionRoutings[name]; routing != nil {
+				for _, problem := range routing.validate() {
+					addProblem("notification session routing of %s: %s", name, problem)
+				}
+			}
+		}
+	}
+
+	for _, mount := range c.ExchangeStrategies {
+		for _, name := range mount.Mounts {
+			checkSession(fmt.Sprintf("strategy %s", mount.Strategy.ID()), name)
+		}
+	}
+
+	for i, report := range c.PnLReporters {
+		for _, name := range report.Of {
+			checkSession(fmt.Sprintf("pnl reporter #%d", i+1), name)
+		}
+
+		for _, spec := range report.When {
+			if _, err := cron.ParseStandard(spec); err != nil {
+				addProblem("pnl reporter #%d: invalid schedule %q: %v", i+1, spec, err)
+			}
+		}
+	}
+
+	if c.Sync != nil {
+		var names []string
+		for name := range c.Sync.Windows {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, err := types.ValidExchangeName(name); err != nil {
+				addProblem("sync window: %v", err)
+			} else if c.Sync.Windows[name] < 0 {
+				addProblem("sync window of %s can not be negative", name)
+			}
+		}
+	}
+
+	if c.BalanceSnapshot != nil {
+		for _, name := range c.BalanceSnapshot.Sessions {
+			checkSession("balance snapshot", name)
+		}
+
+		if c.BalanceSnapshot.Interval < 0 {
+			addProblem("balance snapshot interval can not be negative")
+		}
+
+		if c.BalanceSnapshot.MinTradeQuoteQuantity < 0 {
+			addProblem("balance snapshot minTradeQuoteQuantity can not be negative")
+		}
+	}
+
+	if c.ClockDrift != nil {
+		for _, problem := range c.ClockDrift.validate() {
+			addProblem("clock drift: %s", problem)
+		}
+	}
+
+	if c.Logging != nil {
+		if _, err := NewLogFormatter(c.Logging.Format); err != nil {
+			addProblem("logging: %s", err.Error())
+		}
+	}
+
+	for i, source := range c.PriceSources {
+		if _, ok := registeredPriceSources[source.Name]; !ok {
+			addProblem("price source #%d: %s is not registered", i+1, source.Name)
+		}
+	}
+
+	for _, name := range sortedSessionNames(c.Sessions) {
+		for _, problem := range validateSessionConfig(c.Sessions[name]) {
+			addProblem("session %s: %s", name, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// sessionNames returns the names of the configured sessions, nil is returned if no session is known
+func (c *Config) sessionNames(environ *Environment) map[string]struct{} {
+	var names = make(map[string]struct{})
+	for name := range c.Sessions {
+		names[name] = struct{}{}
+	}
+
+	if len(names) == 0 && environ != nil {
+		for name := range environ.Sessions() {
+			names[name] = struct{}{}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return names
+}
+
+// validate checks the routing modes, a mode starting with "$" must be one of "$symbol", "$session" and "$silent",
+// the other values are the channel names. The minNotional thresholds can not be negative.
+func (routing *SlackNotificationRouting) validate() (problems []string) {
+	for _, field := range []struct {
+		name, mode string
+	}{
+		{"trade", routing.Trade},
+		{"order", routing.Order},
+		{"submitOrder", routing.SubmitOrder},
+		{"pnL", routing.PnL},
+	} {
+		switch field.mode {
+		case "$symbol", "$session", "$silent":
+
+		default:
+			if strings.HasPrefix(field.mode, "$") {
+				problems = append(problems, fmt.Sprintf("unknown %s routing mode %s", field.name, field.mode))
+			}
+		}
+	}
+
+	for _, route := range routing.Channels {
+		problems = append(problems, route.validate()...)
+	}
+
+	if routing.MinNotional != nil {
+		for _, filter := range []struct {
+			name   string
+			filter *MinNotionalFilter
+		}{
+			{"trade", routing.MinNotional.Trade},
+			{"order", routing.MinNotional.Order},
+		} {
+			if filter.filter == nil {
+				continue
+			}
+
+			if filter.filter.Default < 0 {
+				problems = append(problems, fmt.Sprintf("%s minNotional can not be negative", filter.name))
+			}
+
+			var symbols []string
+			for symbol, threshold := range filter.filter.Symbols {
+				if threshold < 0 {
+					symbols = append(symbols, symbol)
+				}
+			}
+			sort.Strings(symbols)
+
+			for _, symbol := range symbols {
+				problems = append(problems, fmt.Sprintf("%s minNotional of %s can not be negative", filter.name, symbol))
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateSessionConfig checks the exchange name and whether the exchange supports the margin, the futures and the proxy settings of the session,
+// the exchange is created without the credentials, so that no api request is sent
+func validateSessionConfig(session *ExchangeSession) (problems []string) {
+	exchangeName, err := types.ValidExchangeName(session.ExchangeName)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	if session.IsolatedMargin && !session.Margin {
+		problems = append(problems, "isolatedMargin requires margin to be enabled")
+	}
+
+	if session.Futures && session.Margin {
+		problems = append(problems, "futures can not be used with margin")
+	}
+
+	for i := range session.SubscriptionSchedules {
+		for _, problem := range session.SubscriptionSchedules[i].validate() {
+			problems = append(problems, fmt.Sprintf("subscription schedule #%d: %s", i, problem))
+		}
+	}
+
+	if session.Connection != nil {
+		for _, problem := range session.Connection.validate() {
+			problems = append(problems, fmt.Sprintf("connection: %s", problem))
+		}
+	}
+
+	if session.Proxy != nil {
+		if _, err := util.ParseProxyURL(session.Proxy.URL, session.Proxy.Username, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("proxy: %v", err))
+		}
+	}
+
+	if !session.Margin && !session.Futures && session.Proxy == nil {
+		return problems
+	}
+
+	exchange, err := cmdutil.NewExchangeStandard(exchangeName, "", "", "")
+	if err != nil {
+		return append(problems, err.Error())
+	}
+
+	if session.Proxy != nil {
+		if _, ok := exchange.(types.ProxyExchange); !ok {
+			problems = append(problems, fmt.Sprintf("exchange %s does not support the proxy", exchangeName))
+		}
+	}
+
+	if session.Futures {
+		if _, ok := exchange.(types.FuturesExchange); !ok {
+			problems = append(problems, fmt.Sprintf("exchange %s does not support futures", exchangeName))
+		}
+	}
+
+	if !session.Margin {
+		return problems
+	}
+
+	marginExchange, ok := exchange.(types.MarginExchange)
+	if !ok {
+		return append(problems, fmt.Sprintf("exchange %s does not support margin", exchangeName))
+	}
+
+	if session.IsolatedMargin {
+		symbols := session.GetIsolatedMarginSymbols()
+		if len(symbols) == 0 {
+			return append(problems, "isolated margin requires isolatedMarginSymbol or isolatedMarginSymbols")
+		}
+
+		for _, symbol := range symbols {
+			marginExchange.UseIsolatedMargin(symbol)
+		}
+
+		if settings := marginExchange.GetMarginSettings(); len(settings.IsolatedMarginSymbols) < len(symbols) {
+			problems = append(problems, fmt.Sprintf("exchange %s does not support multiple isolated margin symbols", exchangeName))
+		}
+	}
+
+	return problems
+}
+
+func matchAnySession(re *regexp.Regexp, sessionNames map[string]struct{}) bool {
+	for name := range sessionNames {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSessionNames(sessions map[string]*ExchangeSession) (names []string) {
+	for name := range sessions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
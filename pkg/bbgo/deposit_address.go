@@ -0,0 +1,75 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ErrDepositAddressUnsupported is returned by QueryDepositAddress if the exchange of the session can not query
+// the deposit addresses
+var ErrDepositAddressUnsupported = errors.New("deposit address is not supported")
+
+func (environ *Environment) depositAddressStore(sessionName, asset string) service.Store {
+	return environ.PersistenceServiceFacade.Get().NewStore("bbgo", "deposit-address", sessionName, asset)
+}
+
+// QueryDepositAddress returns the deposit address of the asset on the session. The address is cached in the
+// persistence store, so the exchange API is only called if the address is not cached. For the exchanges that require
+// the address to be created before it's used, e.g., MAX, the address is created if it's not found.
+func (environ *Environment) QueryDepositAddress(ctx context.Context, sessionName, asset string) (*types.DepositAddress, error) {
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionName)
+	}
+
+	asset = strings.ToUpper(asset)
+	store := environ.depositAddressStore(sessionName, asset)
+
+	var cached types.DepositAddress
+	if err := store.Load(&cached); err == nil && len(cached.Address) > 0 {
+		return &cached, nil
+	} else if err != nil && err != service.ErrPersistenceNotExists {
+		log.WithError(err).Warnf("can not load the cached deposit address of %s on session %s", asset, sessionName)
+	}
+
+	addressService, ok := session.Exchange.(types.ExchangeDepositAddressService)
+	if !ok {
+		return nil, fmt.Errorf("%w: exchange %s of session %s", ErrDepositAddressUnsupported, session.Exchange.Name(), sessionName)
+	}
+
+	address, err := addressService.QueryDepositAddress(ctx, asset)
+	if errors.Is(err, types.ErrDepositAddressNotFound) {
+		if creator, ok := session.Exchange.(types.DepositAddressCreator); ok {
+			log.Infof("creating the deposit address of %s on session %s...", asset, sessionName)
+			address, err = creator.CreateDepositAddress(ctx, asset)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can not query the deposit address of %s on session %s: %w", asset, sessionName, err)
+	}
+
+	if err := store.Save(address); err != nil {
+		log.WithError(err).Warnf("can not cache the deposit address of %s on session %s", asset, sessionName)
+	}
+
+	return address, nil
+}
+
+// NotifyDepositAddress sends the deposit address to the channel of the object route, or the session channel if the
+// deposit address is not routed
+func (environ *Environment) NotifyDepositAddress(sessionName string, address *types.DepositAddress) {
+	channel, ok := environ.RouteObject(address)
+	if !ok {
+		channel, _ = environ.RouteSession(sessionName)
+	}
+
+	environ.NotifyTo(channel, address.PlainText(), address)
+}
@@ -0,0 +1,82 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testDepositAddressExchange struct {
+	types.Exchange
+
+	addresses map[string]string
+	queries   int
+}
+
+func (e *testDepositAddressExchange) Name() types.ExchangeName {
+	return types.ExchangeMax
+}
+
+func (e *testDepositAddressExchange) QueryDepositAddress(ctx context.Context, asset string) (*types.DepositAddress, error) {
+	e.queries++
+	address, ok := e.addresses[asset]
+	if !ok {
+		return nil, types.ErrDepositAddressNotFound
+	}
+
+	return &types.DepositAddress{Exchange: types.ExchangeMax, Asset: asset, Address: address}, nil
+}
+
+func (e *testDepositAddressExchange) CreateDepositAddress(ctx context.Context, asset string) (*types.DepositAddress, error) {
+	if asset == "DOGE" {
+		return nil, errors.New("currency not supported")
+	}
+
+	e.addresses[asset] = "0x" + asset
+	return e.QueryDepositAddress(ctx, asset)
+}
+
+func TestEnvironment_QueryDepositAddress(t *testing.T) {
+	ctx := context.Background()
+	environ, notifier := newTestEnvironment("binance", "max")
+	environ.SessionChannelRouter.AddRoute(map[string]string{"^max$": "#max"})
+
+	exchange := &testDepositAddressExchange{addresses: map[string]string{"BTC": "bc1qbtc"}}
+	environ.sessions["max"].Exchange = exchange
+	environ.sessions["binance"].Exchange = &testSymbolsExchange{Exchange: exchange}
+
+	address, err := environ.QueryDepositAddress(ctx, "max", "btc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bc1qbtc", address.Address)
+	}
+
+	// the address is cached
+	_, err = environ.QueryDepositAddress(ctx, "max", "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, exchange.queries)
+
+	// the address is created if it's not found
+	address, err = environ.QueryDepositAddress(ctx, "max", "ETH")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0xETH", address.Address)
+	}
+
+	_, err = environ.QueryDepositAddress(ctx, "max", "DOGE")
+	assert.EqualError(t, err, "can not query the deposit address of DOGE on session max: currency not supported")
+
+	_, err = environ.QueryDepositAddress(ctx, "binance", "BTC")
+	assert.True(t, errors.Is(err, ErrDepositAddressUnsupported), err)
+
+	_, err = environ.QueryDepositAddress(ctx, "ftx", "BTC")
+	assert.EqualError(t, err, "session ftx not found")
+
+	environ.NotifyDepositAddress("max", address)
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Equal(t, "#max", notifier.notifications[0].channel)
+		assert.Contains(t, notifier.notifications[0].text, "max ETH deposit address: 0xETH")
+	}
+}
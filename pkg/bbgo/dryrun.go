@@ -0,0 +1,448 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultDryRunFeeRate is the fee rate of the simulated trades, 0.1% for both maker and taker
+const DefaultDryRunFeeRate = 0.001
+
+// DryRunStream forwards the market data and the connection events of the live stream,
+// the private events (orders, trades and balances) are emitted by the DryRunExchange instead.
+type DryRunStream struct {
+	types.StandardStream
+
+	stream types.Stream
+}
+
+func NewDryRunStream(stream types.Stream) *DryRunStream {
+	s := &DryRunStream{stream: stream}
+
+	stream.OnStart(s.EmitStart)
+	stream.OnConnect(s.EmitConnect)
+	stream.OnDisconnect(s.EmitDisconnect)
+	stream.OnKLine(s.EmitKLine)
+	stream.OnKLineClosed(s.EmitKLineClosed)
+	stream.OnBookSnapshot(s.EmitBookSnapshot)
+	stream.OnBookUpdate(s.EmitBookUpdate)
+	return s
+}
+
+func (s *DryRunStream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.stream.Subscribe(channel, symbol, options)
+}
+
+func (s *DryRunStream) SetPublicOnly() {
+	s.stream.SetPublicOnly()
+}
+
+func (s *DryRunStream) Connect(ctx context.Context) error {
+	return s.stream.Connect(ctx)
+}
+
+func (s *DryRunStream) Close() error {
+	return s.stream.Close()
+}
+
+// dryRunEvents collects the events to emit after the exchange lock is released,
+// so that the callbacks can submit or cancel orders.
+type dryRunEvents struct {
+	orders []types.Order
+	trades []types.Trade
+}
+
+// DryRunExchange is a paper trading exchange that wraps the live exchange,
+// the market data queries go to the live exchange while the orders are matched
+// against the order book and the kline updates of the live stream.
+type DryRunExchange struct {
+	types.Exchange
+
+	stream  *DryRunStream
+	account *types.Account
+	markets types.MarketMap
+	feeRate float64
+
+	mu         sync.Mutex
+	orderID    uint64
+	tradeID    int64
+	openOrders map[uint64]types.Order
+	books      map[string]*types.OrderBook
+	lastPrices map[string]float64
+}
+
+func NewDryRunExchange(exchange types.Exchange, stream *DryRunStream, markets types.MarketMap, balances types.BalanceMap) *DryRunExchange {
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+
+	e := &DryRunExchange{
+		Exchange:   exchange,
+		stream:     stream,
+		account:    account,
+		markets:    markets,
+		feeRate:    DefaultDryRunFeeRate,
+		openOrders: make(map[uint64]types.Order),
+		books:      make(map[string]*types.OrderBook),
+		lastPrices: make(map[string]float64),
+	}
+
+	stream.OnBookSnapshot(func(book types.OrderBook) {
+		e.handleBook(book, true)
+	})
+	stream.OnBookUpdate(func(book types.OrderBook) {
+		e.handleBook(book, false)
+	})
+	stream.OnKLine(e.handleKLine)
+	stream.OnKLineClosed(e.handleKLine)
+	return e
+}
+
+func (e *DryRunExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.UpdateBalances(e.account.Balances())
+	return account, nil
+}
+
+func (e *DryRunExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.account.Balances(), nil
+}
+
+func (e *DryRunExchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, orderID := range e.openOrderIDs(symbol) {
+		orders = append(orders, e.openOrders[orderID])
+	}
+
+	return orders, nil
+}
+
+func (e *DryRunExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		var events dryRunEvents
+
+		e.mu.Lock()
+		order, err := e.submitOrder(o, &events)
+		e.mu.Unlock()
+
+		e.emit(events)
+
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, *order)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *DryRunExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		var events dryRunEvents
+
+		e.mu.Lock()
+		err := e.cancelOrder(o, &events)
+		e.mu.Unlock()
+
+		e.emit(events)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *DryRunExchange) emit(events dryRunEvents) {
+	if len(events.orders) == 0 && len(events.trades) == 0 {
+		return
+	}
+
+	// emit the trades before the filled orders, like the exchange streams do
+	for _, trade := range events.trades {
+		e.stream.EmitTradeUpdate(trade)
+	}
+
+	for _, order := range events.orders {
+		e.stream.EmitOrderUpdate(order)
+	}
+
+	e.stream.EmitBalanceUpdate(e.account.Balances())
+}
+
+// bestPrices returns the best bid and the best ask of the symbol, the last price is used when there is no order book
+func (e *DryRunExchange) bestPrices(symbol string) (bid, ask float64) {
+	if book, ok := e.books[symbol]; ok {
+		if pv, ok := book.BestBid(); ok {
+			bid = pv.Price.Float64()
+		}
+
+		if pv, ok := book.BestAsk(); ok {
+			ask = pv.Price.Float64()
+		}
+	}
+
+	if lastPrice, ok := e.lastPrices[symbol]; ok {
+		if bid == 0 {
+			bid = lastPrice
+		}
+
+		if ask == 0 {
+			ask = lastPrice
+		}
+	}
+
+	return bid, ask
+}
+
+func (e *DryRunExchange) submitOrder(o types.SubmitOrder, events *dryRunEvents) (*types.Order, error) {
+	market, ok := e.markets[o.Symbol]
+	if !ok {
+		return nil, fmt.Errorf("market %s is not defined", o.Symbol)
+	}
+
+	bid, ask := e.bestPrices(o.Symbol)
+
+	// lockPrice is the price for locking the quote balance of the buy order
+	var lockPrice = o.Price
+	var takerPrice float64
+
+	switch o.Type {
+	case types.OrderTypeMarket:
+		takerPrice = bid
+		if o.Side == types.SideTypeBuy {
+			takerPrice = ask
+		}
+
+		if takerPrice == 0 {
+			return nil, fmt.Errorf("dry run: no market price of %s yet", o.Symbol)
+		}
+
+		lockPrice = takerPrice
+
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if o.Side == types.SideTypeBuy && ask > 0 && o.Price >= ask {
+			takerPrice = ask
+		} else if o.Side == types.SideTypeSell && bid > 0 && o.Price <= bid {
+			takerPrice = bid
+		}
+
+		if o.Type == types.OrderTypeLimitMaker && takerPrice > 0 {
+			return nil, fmt.Errorf("dry run: the limit maker order would be filled immediately: %s", o.String())
+		}
+
+	default:
+		return nil, fmt.Errorf("dry run: order type %s is not supported", o.Type)
+	}
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		if err := e.account.LockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(lockPrice*o.Quantity)); err != nil {
+			return nil, err
+		}
+
+	case types.SideTypeSell:
+		if err := e.account.LockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(o.Quantity)); err != nil {
+			return nil, err
+		}
+	}
+
+	e.orderID++
+	now := datatype.Time(time.Now())
+	order := types.Order{
+		SubmitOrder:  o,
+		Exchange:     e.Name().String(),
+		OrderID:      e.orderID,
+		Status:       types.OrderStatusNew,
+		IsWorking:    true,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+	events.orders = append(events.orders, order)
+
+	if takerPrice > 0 {
+		order = e.fill(market, order, takerPrice, lockPrice, false, events)
+		return &order, nil
+	}
+
+	e.openOrders[order.OrderID] = order
+	return &order, nil
+}
+
+func (e *DryRunExchange) cancelOrder(o types.Order, events *dryRunEvents) error {
+	order, ok := e.openOrders[o.OrderID]
+	if !ok {
+		return fmt.Errorf("dry run: order %d not found", o.OrderID)
+	}
+
+	market := e.markets[order.Symbol]
+	switch order.Side {
+	case types.SideTypeBuy:
+		if err := e.account.UnlockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(order.Price*order.Quantity)); err != nil {
+			return err
+		}
+
+	case types.SideTypeSell:
+		if err := e.account.UnlockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(order.Quantity)); err != nil {
+			return err
+		}
+	}
+
+	delete(e.openOrders, order.OrderID)
+
+	order.Status = types.OrderStatusCanceled
+	order.IsWorking = false
+	order.UpdateTime = datatype.Time(time.Now())
+	events.orders = append(events.orders, order)
+	return nil
+}
+
+// fill fills the whole order at the price, the locked balance is used and the fee is deducted from the received asset
+func (e *DryRunExchange) fill(market types.Market, order types.Order, price, lockPrice float64, isMaker bool, events *dryRunEvents) types.Order {
+	var quantity = order.Quantity
+	var quoteQuantity = price * quantity
+	var fee float64
+	var feeCurrency string
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		fee = quantity * e.feeRate
+		feeCurrency = market.BaseCurrency
+
+		locked := lockPrice * quantity
+		if err := e.account.UseLockedBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(locked)); err != nil {
+			log.WithError(err).Errorf("dry run: can not use the locked balance of order %d", order.OrderID)
+		}
+
+		// return the quote that is locked but not used
+		if locked > quoteQuantity {
+			_ = e.account.AddBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(locked-quoteQuantity))
+		}
+
+		_ = e.account.AddBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity-fee))
+
+	case types.SideTypeSell:
+		fee = quoteQuantity * e.feeRate
+		feeCurrency = market.QuoteCurrency
+
+		if err := e.account.UseLockedBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity)); err != nil {
+			log.WithError(err).Errorf("dry run: can not use the locked balance of order %d", order.OrderID)
+		}
+
+		_ = e.account.AddBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(quoteQuantity-fee))
+	}
+
+	now := datatype.Time(time.Now())
+
+	e.tradeID++
+	events.trades = append(events.trades, types.Trade{
+		ID:            e.tradeID,
+		OrderID:       order.OrderID,
+		Exchange:      order.Exchange,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          now,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	})
+
+	if order.Type == types.OrderTypeMarket {
+		order.Price = price
+	}
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = quantity
+	order.IsWorking = false
+	order.UpdateTime = now
+	events.orders = append(events.orders, order)
+	return order
+}
+
+// openOrderIDs returns the sorted IDs of the open orders of the symbol
+func (e *DryRunExchange) openOrderIDs(symbol string) (orderIDs []uint64) {
+	for orderID, order := range e.openOrders {
+		if order.Symbol == symbol {
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+
+	sort.Slice(orderIDs, func(i, j int) bool {
+		return orderIDs[i] < orderIDs[j]
+	})
+	return orderIDs
+}
+
+// match fills the open orders of the symbol as the maker orders,
+// the buy orders are filled when the price drops to lowPrice, and the sell orders are filled when the price rises to highPrice.
+func (e *DryRunExchange) match(symbol string, lowPrice, highPrice float64, events *dryRunEvents) {
+	market := e.markets[symbol]
+	for _, orderID := range e.openOrderIDs(symbol) {
+		order := e.openOrders[orderID]
+
+		switch order.Side {
+		case types.SideTypeBuy:
+			if lowPrice == 0 || lowPrice > order.Price {
+				continue
+			}
+
+		case types.SideTypeSell:
+			if highPrice == 0 || highPrice < order.Price {
+				continue
+			}
+		}
+
+		delete(e.openOrders, orderID)
+		e.fill(market, order, order.Price, order.Price, true, events)
+	}
+}
+
+func (e *DryRunExchange) handleBook(book types.OrderBook, snapshot bool) {
+	var events dryRunEvents
+
+	e.mu.Lock()
+	localBook, ok := e.books[book.Symbol]
+	if !ok {
+		localBook = &types.OrderBook{Symbol: book.Symbol}
+		e.books[book.Symbol] = localBook
+	}
+
+	if snapshot {
+		localBook.Load(book)
+	} else {
+		localBook.Update(book)
+	}
+
+	bid, ask := e.bestPrices(book.Symbol)
+	e.match(book.Symbol, ask, bid, &events)
+	e.mu.Unlock()
+
+	e.emit(events)
+}
+
+func (e *DryRunExchange) handleKLine(kline types.KLine) {
+	var events dryRunEvents
+
+	e.mu.Lock()
+	e.lastPrices[kline.Symbol] = kline.Close
+	e.match(kline.Symbol, kline.Low, kline.High, &events)
+	e.mu.Unlock()
+
+	e.emit(events)
+}
@@ -0,0 +1,174 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testDryRunExchange struct {
+	types.Exchange
+}
+
+func (e *testDryRunExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func newTestDryRunExchange() (*DryRunExchange, *testStream) {
+	liveStream := &testStream{}
+	stream := NewDryRunStream(liveStream)
+	markets := types.MarketMap{
+		"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}
+	balances := types.BalanceMap{
+		"BTC":  types.Balance{Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		"USDT": types.Balance{Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	}
+	return NewDryRunExchange(&testDryRunExchange{}, stream, markets, balances), liveStream
+}
+
+func TestDryRunExchange_MarketOrder(t *testing.T) {
+	exchange, liveStream := newTestDryRunExchange()
+
+	var trades []types.Trade
+	var orders []types.Order
+	exchange.stream.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+	exchange.stream.OnOrderUpdate(func(order types.Order) { orders = append(orders, order) })
+
+	_, err := exchange.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1,
+	})
+	assert.EqualError(t, err, "dry run: no market price of BTCUSDT yet")
+
+	liveStream.EmitBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(9990.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(10000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+
+	createdOrders, err := exchange.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, types.OrderStatusFilled, createdOrders[0].Status)
+		assert.Equal(t, 10000.0, createdOrders[0].Price)
+	}
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, 10000.0, trades[0].Price)
+		assert.Equal(t, 0.1, trades[0].Quantity)
+		assert.False(t, trades[0].IsMaker)
+		assert.Equal(t, "BTC", trades[0].FeeCurrency)
+		assert.Equal(t, "binance", trades[0].Exchange)
+	}
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+		assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+	}
+
+	balances, err := exchange.QueryAccountBalances(context.Background())
+	assert.NoError(t, err)
+	assert.InDelta(t, 9000.0, balances["USDT"].Available.Float64(), 1e-6)
+	assert.InDelta(t, 1.0999, balances["BTC"].Available.Float64(), 1e-6)
+}
+
+func TestDryRunExchange_LimitOrder(t *testing.T) {
+	exchange, liveStream := newTestDryRunExchange()
+
+	var trades []types.Trade
+	exchange.stream.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+
+	liveStream.EmitKLine(types.KLine{Symbol: "BTCUSDT", Open: 10000.0, High: 10000.0, Low: 10000.0, Close: 10000.0})
+
+	_, err := exchange.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimitMaker, Quantity: 0.1, Price: 10100.0,
+	})
+	assert.Error(t, err, "the crossed limit maker order should be rejected")
+
+	createdOrders, err := exchange.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.1, Price: 9000.0},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Quantity: 0.5, Price: 11000.0},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 2)
+	assert.Empty(t, trades)
+
+	balances, _ := exchange.QueryAccountBalances(context.Background())
+	assert.InDelta(t, 900.0, balances["USDT"].Locked.Float64(), 1e-6)
+	assert.InDelta(t, 0.5, balances["BTC"].Locked.Float64(), 1e-6)
+
+	// the price drops to the buy order price
+	liveStream.EmitKLine(types.KLine{Symbol: "BTCUSDT", Open: 10000.0, High: 10000.0, Low: 8900.0, Close: 9500.0})
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, createdOrders[0].OrderID, trades[0].OrderID)
+		assert.Equal(t, 9000.0, trades[0].Price)
+		assert.True(t, trades[0].IsMaker)
+	}
+
+	openOrders, err := exchange.QueryOpenOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	if assert.Len(t, openOrders, 1) {
+		assert.Equal(t, createdOrders[1].OrderID, openOrders[0].OrderID)
+	}
+
+	assert.NoError(t, exchange.CancelOrders(context.Background(), openOrders...))
+	assert.Error(t, exchange.CancelOrders(context.Background(), openOrders...), "the canceled order should not be found")
+
+	balances, _ = exchange.QueryAccountBalances(context.Background())
+	assert.InDelta(t, 0.0, balances["BTC"].Locked.Float64(), 1e-6)
+	assert.InDelta(t, 0.0, balances["USDT"].Locked.Float64(), 1e-6)
+}
+
+func TestEnvironment_DryRunNotification(t *testing.T) {
+	environ, notifier := newTestEnvironment()
+	environ.SetDryRun(true)
+
+	liveStream := &testStream{}
+	session := environ.AddExchangeSession("binance", &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testDryRunExchange{},
+		Stream:   liveStream,
+		markets: types.MarketMap{
+			"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+	})
+
+	_, ok := session.Stream.(*DryRunStream)
+	assert.True(t, ok, "the stream should be replaced before the callbacks are registered")
+
+	err := environ.ConfigureNotificationRouting(&NotificationConfig{
+		SessionChannels: map[string]string{"^binance$": "#binance"},
+		Routing: &SlackNotificationRouting{
+			Trade: "$session",
+			Order: "$silent",
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the dry run exchange is created when the session is initialized
+	session.enableDryRun(types.BalanceMap{
+		"USDT": types.Balance{Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	liveStream.EmitBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(10000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+
+	_, err = session.Exchange.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1,
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, notifier.notifications, 1, "the simulated fill should be notified") {
+		assert.Equal(t, "#binance", notifier.notifications[0].channel)
+	}
+}
@@ -5,11 +5,18 @@ import (
 	"context"
 	"fmt"
 	"image/png"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+	"unicode"
 
 	"github.com/codingconcepts/env"
 	"github.com/pkg/errors"
@@ -20,8 +27,16 @@ import (
 
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/notifier/discordnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/emailnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/filenotifier"
+	"github.com/c9s/bbgo/pkg/notifier/matrixnotifier"
 	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
+	"github.com/c9s/bbgo/pkg/notifier/smsnotifier"
 	"github.com/c9s/bbgo/pkg/notifier/telegramnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/webhooknotifier"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/slack/slacklog"
 	"github.com/c9s/bbgo/pkg/types"
@@ -58,7 +73,131 @@ const (
 	SyncDone
 )
 
+// SyncState is the sync status persisted across the restarts
+type SyncState struct {
+	Status SyncStatus `json:"status"`
+
+	// LastSyncTime is the finish time of the last successful sync
+	LastSyncTime time.Time `json:"lastSyncTime,omitempty"`
+
+	// LastError is the error of the last sync, it's empty if the last sync is done successfully
+	LastError string `json:"lastError,omitempty"`
+}
+
+// DefaultSyncConcurrency is the default number of the symbols synced concurrently in a session
+const DefaultSyncConcurrency = 4
+
+// SyncProgress is the progress event of the session sync,
+// note that the symbols are synced concurrently, so the events of different symbols may interleave.
+type SyncProgress struct {
+	Session string
+	service.SyncProgress
+}
+
+// SessionInitError is the combined error of the exchange sessions failed to initialize
+type SessionInitError struct {
+	// Errors maps the session name to the init error of the session
+	Errors map[string]error
+}
+
+func (e *SessionInitError) Error() string {
+	var names []string
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var messages = make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + ": " + e.Errors[name].Error()
+	}
+
+	return fmt.Sprintf("exchange session init failed: %s", strings.Join(messages, "; "))
+}
+
+// CredentialError is the combined error of the exchange sessions with invalid api credentials
+type CredentialError struct {
+	// Errors maps the session name to the credential validation error of the session
+	Errors map[string]error
+}
+
+func (e *CredentialError) Error() string {
+	var names []string
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var messages = make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + ": " + e.Errors[name].Error()
+	}
+
+	return fmt.Sprintf("exchange session credential validation failed: %s", strings.Join(messages, "; "))
+}
+
+// SyncError is the combined error of the failed syncs in a session
+type SyncError struct {
+	Session string
+	Errors  []error
+}
+
+func (e *SyncError) Error() string {
+	var messages = make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("session %s sync failed: %s", e.Session, strings.Join(messages, "; "))
+}
+
+// DatabaseConnectError is returned when the database can not be reached
+type DatabaseConnectError struct {
+	Driver string
+	Err    error
+}
+
+func (e *DatabaseConnectError) Error() string {
+	return fmt.Sprintf("can not connect to the %s database: %s", e.Driver, e.Err.Error())
+}
+
+func (e *DatabaseConnectError) Unwrap() error {
+	return e.Err
+}
+
+// DatabaseMigrationError is returned when the database is reachable but the migrations failed
+type DatabaseMigrationError struct {
+	Driver string
+	Err    error
+}
+
+func (e *DatabaseMigrationError) Error() string {
+	return fmt.Sprintf("%s database migration failed: %s", e.Driver, e.Err.Error())
+}
+
+func (e *DatabaseMigrationError) Unwrap() error {
+	return e.Err
+}
+
+// DatabaseVersionError is returned when the migrations are skipped but the schema version of the database
+// doesn't match the migrations of the binary
+type DatabaseVersionError struct {
+	Driver string
+	Err    error
+}
+
+func (e *DatabaseVersionError) Error() string {
+	return fmt.Sprintf("%s database schema check failed: %s", e.Driver, e.Err.Error())
+}
+
+func (e *DatabaseVersionError) Unwrap() error {
+	return e.Err
+}
+
 // Environment presents the real exchange data layer
+//go:generate callbackgen -type Environment
 type Environment struct {
 	// Notifiability here for environment is for the streaming data notification
 	// note that, for back tests, we don't need notification.
@@ -70,47 +209,226 @@ type Environment struct {
 	TradeService             *service.TradeService
 	BacktestService          *service.BacktestService
 	RewardService            *service.RewardService
+	WithdrawService          *service.WithdrawService
+	DepositService           *service.DepositService
 	SyncService              *service.SyncService
+	BalanceSnapshotService   *service.BalanceSnapshotService
+	PositionService          *service.PositionService
 
 	// startTime is the time of start point (which is used in the backtest)
 	startTime time.Time
 
 	// syncStartTime is the time point we want to start the sync (for trades and orders)
 	syncStartTime time.Time
+
+	// symbolSyncStartTimes overrides the sync start time of the symbols
+	symbolSyncStartTimes map[string]time.Time
 	syncMutex     sync.Mutex
 
+	// fullSync ignores the stored sync cursors and syncs all symbols from syncStartTime
+	fullSync bool
+
+	// syncConcurrency is the number of the symbols synced concurrently in a session
+	syncConcurrency int
+
+	// syncConfig enables the scheduled sync if it's configured
+	syncConfig *SyncConfig
+
+	// syncWindows overrides the sync windows of the exchanges
+	syncWindows map[types.ExchangeName]time.Duration
+
+	// dryRun routes the orders of all sessions to the paper trading exchange
+	dryRun bool
+
+	// maintenance is the pause flag shared with the sessions, see Pause
+	maintenance *maintenanceMode
+
+	// skipDatabaseUpgrade checks the schema version instead of running the migrations
+	skipDatabaseUpgrade bool
+
+	// validateOnly configures the sessions and the notifiers without the credentials, the chat bots and the connections,
+	// it's used for checking the config without touching the network
+	validateOnly bool
+
 	syncStatusMutex sync.Mutex
-	syncStatus      SyncStatus
+	syncState       SyncState
+
+	// syncInterrupted is set when the restored sync status is still syncing,
+	// which means the previous process stopped in the middle of the sync.
+	syncInterrupted bool
+
+	syncProgressCallbacks []func(progress SyncProgress)
+
+	// strategyErrorCallbacks are called with the *StrategyError of the strategies, including the recovered panics
+	strategyErrorCallbacks []func(strategyID string, err error)
+
+	// balanceUpdateCallbacks are called with the balance snapshots and the balance updates of all the connected session streams,
+	// the session account is already updated when the callbacks are called
+	balanceUpdateCallbacks []func(session string, balances types.BalanceMap)
+
+	healthConfig *HealthConfig
+
+	// clockDriftConfig is the threshold of the clock drift check run by Start
+	clockDriftConfig *ClockDriftConfig
 
-	sessions map[string]*ExchangeSession
+	// pnlReportConfig schedules the pnl reports, pnlRoutings are the pnL routing modes of the sessions
+	pnlReportConfig *PnLReportConfig
+	pnlRoutings     map[string]string
+
+	// reportingCurrency is the currency the pnl reports and the balance snapshots are valued in
+	reportingCurrency string
+
+	// priceSources quote the prices for the valuation before the exchange ticker, see QueryPrice
+	priceSources []PriceSource
+
+	// storedPositionMutex serializes the updates of the stored positions
+	storedPositionMutex sync.Mutex
+
+	// subscriptionSchedulers are the subscription schedulers of the sessions with the subscription schedules
+	subscriptionSchedulers map[string]*subscriptionScheduler
+
+	// connections are the stream connections of the connected sessions, they're stopped when the sessions are
+	// removed by Reload
+	connections map[string]*sessionConnection
+
+	// sessionConfigs are the configs of the sessions added from the config, Reload compares them with the reloaded
+	// config to find the added, the removed and the changed sessions
+	sessionConfigs map[string]*ExchangeSession
+
+	// notifications are the trade and order notification handlers of the session streams
+	notificationsMutex sync.Mutex
+	notifications      map[string]*sessionNotifications
+
+	// submitOrderRoutings are the submitOrder routing modes of the sessions, they're applied to the session order
+	// executors when the sessions are initialized
+	submitOrderRoutings map[string]string
+
+	// channelRoutings are the channel routes of the sessions, the events are sent to the channels in addition to the
+	// routing modes
+	channelRoutings map[string][]NotificationChannelRoute
+
+	// balanceSnapshotConfig records the session balances, balanceSnapshotMutex serializes the snapshots
+	balanceSnapshotConfig *BalanceSnapshotConfig
+	balanceSnapshotMutex  sync.Mutex
+
+	// metrics is the prometheus metrics registry, it's nil if the metrics are not configured
+	metricsConfig *MetricsConfig
+	metrics       *Metrics
+
+	// interactions are the chat interactions stopped by Shutdown
+	interactions []interactionStopper
+
+	// tradeReportTemplate, orderReportTemplate and submitOrderReportTemplate render the trade, the order and the
+	// submit order notifications, the built-in templates are used if they're not set
+	tradeReportTemplate       *template.Template
+	orderReportTemplate       *template.Template
+	submitOrderReportTemplate *template.Template
+
+	// sessionsMutex guards the session registry, i.e., sessions, sessionConfigs, connections, subscriptionSchedulers,
+	// pnlRoutings, submitOrderRoutings and channelRoutings. Reload changes the registry while the health check, the sync, the reports
+	// and the api routes read it, so the readers get the sessions by Session and Sessions.
+	sessionsMutex sync.RWMutex
+	sessions      map[string]*ExchangeSession
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
 		// default trade scan time
-		syncStartTime: time.Now().AddDate(-1, 0, 0), // defaults to sync from 1 year ago
-		sessions:      make(map[string]*ExchangeSession),
-		startTime:     time.Now(),
-
-		syncStatus: SyncNotStarted,
+		syncStartTime:   time.Now().AddDate(-1, 0, 0), // defaults to sync from 1 year ago
+		syncConcurrency: DefaultSyncConcurrency,
+		sessions:        make(map[string]*ExchangeSession),
+		startTime:       time.Now(),
+
+		subscriptionSchedulers: make(map[string]*subscriptionScheduler),
+		connections:            make(map[string]*sessionConnection),
+		sessionConfigs:         make(map[string]*ExchangeSession),
+		notifications:          make(map[string]*sessionNotifications),
+
+		syncState:   SyncState{Status: SyncNotStarted},
+		maintenance: &maintenanceMode{},
 		PersistenceServiceFacade: &service.PersistenceServiceFacade{
 			Memory: service.NewMemoryService(),
 		},
 	}
 }
 
+// SetDryRun makes the sessions submit the orders to the paper trading exchange instead of the real exchange,
+// the market data still comes from the live streams. It should be called before Init, and before the notifications
+// are configured, so that the stream callbacks are registered on the dry run streams.
+func (environ *Environment) SetDryRun(dryRun bool) {
+	environ.dryRun = dryRun
+	if !dryRun {
+		return
+	}
+
+	// the public only sessions keep the live stream, they submit no order
+	for _, session := range environ.Sessions() {
+		if !session.PublicOnly {
+			session.enableDryRunStream()
+		}
+	}
+}
+
+func (environ *Environment) IsDryRun() bool {
+	return environ.dryRun
+}
+
+// SetSkipDatabaseUpgrade makes ConfigureDatabaseDriver verify the schema version instead of running the migrations,
+// it's for the deployments that apply the migrations out-of-band and the database user can't run DDL.
+func (environ *Environment) SetSkipDatabaseUpgrade(skip bool) *Environment {
+	environ.skipDatabaseUpgrade = skip
+	return environ
+}
+
+// SetValidateOnly makes the environment configure the sessions and the notification system without the network,
+// the sessions are created without the api credentials, the secrets are not resolved, and the telegram and the slack
+// interactions are not started. Init and Connect return ErrValidateOnly in this mode.
+func (environ *Environment) SetValidateOnly(validateOnly bool) {
+	environ.validateOnly = validateOnly
+}
+
+func (environ *Environment) IsValidateOnly() bool {
+	return environ.validateOnly
+}
+
 func (environ *Environment) Session(name string) (*ExchangeSession, bool) {
+	environ.sessionsMutex.RLock()
+	defer environ.sessionsMutex.RUnlock()
+
 	s, ok := environ.sessions[name]
 	return s, ok
 }
 
+// Sessions returns a copy of the sessions, the copy is not changed when the sessions are added or removed by Reload
 func (environ *Environment) Sessions() map[string]*ExchangeSession {
-	return environ.sessions
+	environ.sessionsMutex.RLock()
+	defer environ.sessionsMutex.RUnlock()
+
+	sessions := make(map[string]*ExchangeSession, len(environ.sessions))
+	for name, session := range environ.sessions {
+		sessions[name] = session
+	}
+
+	return sessions
+}
+
+// pnlRouting returns the pnl routing mode of the session
+func (environ *Environment) pnlRouting(name string) string {
+	environ.sessionsMutex.RLock()
+	defer environ.sessionsMutex.RUnlock()
+	return environ.pnlRoutings[name]
+}
+
+// submitOrderRouting returns the submitOrder routing mode of the session
+func (environ *Environment) submitOrderRouting(name string) string {
+	environ.sessionsMutex.RLock()
+	defer environ.sessionsMutex.RUnlock()
+	return environ.submitOrderRoutings[name]
 }
 
 func (environ *Environment) SelectSessions(names ...string) map[string]*ExchangeSession {
 	if len(names) == 0 {
-		return environ.sessions
+		return environ.Sessions()
 	}
 
 	sessions := make(map[string]*ExchangeSession)
@@ -123,12 +441,29 @@ func (environ *Environment) SelectSessions(names ...string) map[string]*Exchange
 	return sessions
 }
 
+// SelectSessionsByTag returns the sessions labeled with any of the given tags
+func (environ *Environment) SelectSessionsByTag(tags ...string) map[string]*ExchangeSession {
+	sessions := make(map[string]*ExchangeSession)
+	for name, session := range environ.Sessions() {
+		if session.HasTag(tags...) {
+			sessions[name] = session
+		}
+	}
+
+	return sessions
+}
+
 func (environ *Environment) ConfigureDatabase(ctx context.Context) error {
+	// DB_SKIP_UPGRADE or --db-skip-upgrade
+	if viper.GetBool("db-skip-upgrade") {
+		environ.SetSkipDatabaseUpgrade(true)
+	}
+
 	// configureDB configures the database service based on the environment variable
 	if driver, ok := os.LookupEnv("DB_DRIVER"); ok {
 
 		if dsn, ok := os.LookupEnv("DB_DSN"); ok {
-			return environ.ConfigureDatabaseDriver(ctx, driver, dsn)
+			return environ.ConfigureDatabaseDriver(ctx, driver, dsn, os.Getenv("DB_DSN_REPLICA"))
 		}
 
 	} else if dsn, ok := os.LookupEnv("SQLITE3_DSN"); ok {
@@ -139,231 +474,937 @@ func (environ *Environment) ConfigureDatabase(ctx context.Context) error {
 
 		return environ.ConfigureDatabaseDriver(ctx, "mysql", dsn)
 
+	} else if dsn, ok := lookupEnv("PGSQL_URL", "POSTGRES_URL"); ok {
+
+		return environ.ConfigureDatabaseDriver(ctx, "postgres", dsn)
+
 	}
 
 	return nil
 }
 
-func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver string, dsn string) error {
-	environ.DatabaseService = service.NewDatabaseService(driver, dsn)
-	err := environ.DatabaseService.Connect()
-	if err != nil {
-		return err
+// lookupEnv returns the value of the first defined environment variable
+func lookupEnv(keys ...string) (string, bool) {
+	for _, key := range keys {
+		if val, ok := os.LookupEnv(key); ok {
+			return val, true
+		}
 	}
 
-	if err := environ.DatabaseService.Upgrade(ctx); err != nil {
-		return err
+	return "", false
+}
+
+// ConfigureDatabaseDriver connects the database, runs the migrations and creates the services using the database.
+// A *DatabaseConnectError is returned if the database can not be reached in time, and a *DatabaseMigrationError
+// is returned if the migrations failed. If the upgrade is skipped by SetSkipDatabaseUpgrade, the migrations are not run,
+// and a *DatabaseVersionError is returned if the schema version doesn't match the migrations of the binary.
+//
+// The optional replica DSN sets up the read replica, the read-only queries of the reports and the exports are routed to
+// the replica, while the sync inserts and the migrations go to the primary database.
+func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver string, dsn string, replicaDSN ...string) error {
+	var replica string
+	if len(replicaDSN) > 0 {
+		replica = replicaDSN[0]
+	}
+
+	databaseService := service.NewDatabaseServiceWithReplica(driver, dsn, replica)
+	if err := databaseService.Connect(ctx); err != nil {
+		return &DatabaseConnectError{Driver: driver, Err: err}
+	}
+
+	if environ.skipDatabaseUpgrade {
+		if err := databaseService.CheckVersion(ctx); err != nil {
+			if closeErr := databaseService.Close(); closeErr != nil {
+				log.WithError(closeErr).Error("can not close the database")
+			}
+
+			return &DatabaseVersionError{Driver: driver, Err: err}
+		}
+	} else if err := databaseService.Upgrade(ctx); err != nil {
+		if closeErr := databaseService.Close(); closeErr != nil {
+			log.WithError(closeErr).Error("can not close the database")
+		}
+
+		return &DatabaseMigrationError{Driver: driver, Err: err}
 	}
 
+	environ.DatabaseService = databaseService
+
 	// get the db connection pool object to create other services
 	db := environ.DatabaseService.DB
-	environ.OrderService = &service.OrderService{DB: db}
-	environ.TradeService = &service.TradeService{DB: db}
+	readerDB := environ.DatabaseService.ReaderDB
+	environ.OrderService = &service.OrderService{DB: db, ReaderDB: readerDB}
+	environ.TradeService = &service.TradeService{DB: db, ReaderDB: readerDB}
 	environ.RewardService = &service.RewardService{DB: db}
+	environ.WithdrawService = &service.WithdrawService{DB: db}
+	environ.DepositService = &service.DepositService{DB: db}
+	environ.BalanceSnapshotService = &service.BalanceSnapshotService{DB: db}
+	environ.PositionService = &service.PositionService{DB: db}
 
 	environ.SyncService = &service.SyncService{
 		TradeService:    environ.TradeService,
 		OrderService:    environ.OrderService,
 		RewardService:   environ.RewardService,
-		WithdrawService: &service.WithdrawService{DB: db},
-		DepositService:  &service.DepositService{DB: db},
+		WithdrawService: environ.WithdrawService,
+		DepositService:  environ.DepositService,
+		CursorService:   &service.SyncCursorService{DB: db},
+		LockService:     &service.SyncLockService{DB: db},
+		Windows:         environ.syncWindows,
 	}
 
 	return nil
 }
 
-// AddExchangeSession adds the existing exchange session or pre-created exchange session
-func (environ *Environment) AddExchangeSession(name string, session *ExchangeSession) *ExchangeSession {
-	// update Notifiability from the environment
-	session.Notifiability = environ.Notifiability
+// QueryWithdraws queries the synced withdraws of the session since the given time, the empty asset means all assets.
+// The withdraws are synced by the exchange name, so the withdraws of the other sessions on the same exchange, i.e., the other
+// accounts, are included.
+func (environ *Environment) QueryWithdraws(sessionName, asset string, since time.Time) ([]types.Withdraw, error) {
+	if environ.WithdrawService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
 
-	environ.sessions[name] = session
-	return session
-}
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
+	}
 
-// AddExchange adds the given exchange with the session name, this is the default
-func (environ *Environment) AddExchange(name string, exchange types.Exchange) (session *ExchangeSession) {
-	session = NewExchangeSession(name, exchange)
-	return environ.AddExchangeSession(name, session)
+	return environ.WithdrawService.QuerySince(session.Exchange.Name(), asset, since)
 }
 
-func (environ *Environment) ConfigureExchangeSessions(userConfig *Config) error {
-	if len(userConfig.Sessions) == 0 {
-		return environ.AddExchangesByViperKeys()
+// QueryRewards queries the synced rewards of the session in the time range [since, until)
+func (environ *Environment) QueryRewards(ctx context.Context, sessionName string, since, until time.Time) ([]types.Reward, error) {
+	if environ.RewardService == nil {
+		return nil, ErrDatabaseNotConfigured
 	}
 
-	return environ.AddExchangesFromSessionConfig(userConfig.Sessions)
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
+	}
+
+	return environ.RewardService.QueryRange(ctx, session.Exchange.Name(), since, until)
 }
 
-func (environ *Environment) AddExchangesByViperKeys() error {
-	for _, n := range SupportedExchanges {
-		if viper.IsSet(string(n) + "-api-key") {
-			exchange, err := cmdutil.NewExchangeWithEnvVarPrefix(n, "")
-			if err != nil {
-				return err
-			}
+// AggregateRewards sums up the synced rewards of the session in the time range [since, until) by asset
+func (environ *Environment) AggregateRewards(ctx context.Context, sessionName string, since, until time.Time) (map[string]*service.RewardSummary, error) {
+	if environ.RewardService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
 
-			environ.AddExchange(n.String(), exchange)
-		}
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	return nil
+	return environ.RewardService.AggregateByCurrency(ctx, session.Exchange.Name(), since, until)
 }
 
-func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (*ExchangeSession, error) {
-	exchangeName, err := types.ValidExchangeName(sessionConfig.ExchangeName)
-	if err != nil {
-		return nil, err
+// ImportBacktestData imports the klines of the session symbol in the time range [startTime, endTime) for backtesting,
+// the import can be resumed and the stored klines are skipped. The number of the imported klines is returned.
+func (environ *Environment) ImportBacktestData(ctx context.Context, sessionName, symbol string, interval types.Interval, startTime, endTime time.Time) (int, error) {
+	if environ.DatabaseService == nil {
+		return 0, ErrDatabaseNotConfigured
 	}
 
-	var exchange types.Exchange
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return 0, fmt.Errorf("exchange session %s not found", sessionName)
+	}
 
-	if sessionConfig.Key != "" && sessionConfig.Secret != "" {
-		if !sessionConfig.PublicOnly {
-			if len(sessionConfig.Key) == 0 || len(sessionConfig.Secret) == 0 {
-				return nil, fmt.Errorf("can not create exchange %s: empty key or secret", exchangeName)
-			}
-		}
+	// the BacktestService field is only set in the backtest mode, it changes the behavior of the session initialization
+	backtestService := environ.BacktestService
+	if backtestService == nil {
+		backtestService = &service.BacktestService{DB: environ.DatabaseService.DB}
+	}
 
-		exchange, err = cmdutil.NewExchangeStandard(exchangeName, sessionConfig.Key, sessionConfig.Secret, sessionConfig.SubAccount)
-	} else {
-		exchange, err = cmdutil.NewExchangeWithEnvVarPrefix(exchangeName, sessionConfig.EnvVarPrefix)
+	return backtestService.ImportKLines(ctx, session.Exchange, symbol, interval, startTime, endTime)
+}
+
+// ReconcileTrades compares the exchange trades of the session symbol traded since the given time with the stored trades,
+// it's a two-way diff: the missing trades are inserted if insertMissing is true, and the stored trades that are not
+// found on the exchange are reported as the unknown trades.
+func (environ *Environment) ReconcileTrades(ctx context.Context, sessionName, symbol string, since time.Time, insertMissing bool) (*service.TradeReconciliation, error) {
+	if environ.DatabaseService == nil {
+		return nil, ErrDatabaseNotConfigured
 	}
 
-	if err != nil {
-		return nil, err
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	// configure exchange
-	if sessionConfig.Margin {
-		marginExchange, ok := exchange.(types.MarginExchange)
-		if !ok {
-			return nil, fmt.Errorf("exchange %s does not support margin", exchangeName)
-		}
+	return environ.TradeService.Reconcile(ctx, session.Exchange, symbol, since, insertMissing)
+}
 
-		if sessionConfig.IsolatedMargin {
-			marginExchange.UseIsolatedMargin(sessionConfig.IsolatedMarginSymbol)
-		} else {
-			marginExchange.UseMargin()
-		}
+// QuerySessionBalances queries the live balances of the session from the exchange
+func (environ *Environment) QuerySessionBalances(ctx context.Context, sessionName string) (types.BalanceMap, error) {
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	session := NewExchangeSession(name, exchange)
-	session.ExchangeName = sessionConfig.ExchangeName
-	session.EnvVarPrefix = sessionConfig.EnvVarPrefix
-	session.Key = sessionConfig.Key
-	session.Secret = sessionConfig.Secret
-	session.SubAccount = sessionConfig.SubAccount
-	session.PublicOnly = sessionConfig.PublicOnly
-	session.Margin = sessionConfig.Margin
-	session.IsolatedMargin = sessionConfig.IsolatedMargin
-	session.IsolatedMarginSymbol = sessionConfig.IsolatedMarginSymbol
-	return session, nil
+	if session.PublicOnly {
+		return nil, ErrPublicOnlySession
+	}
+
+	return session.Exchange.QueryAccountBalances(ctx)
 }
 
-func (environ *Environment) AddExchangesFromSessionConfig(sessions map[string]*ExchangeSession) error {
-	for sessionName, sessionConfig := range sessions {
-		session, err := NewExchangeSessionFromConfig(sessionName, sessionConfig)
-		if err != nil {
-			return err
-		}
+// QueryOrdersByStatus queries the synced orders of the session symbol with the given status since the given time,
+// only the orders of the session account type (spot, cross margin or isolated margin) are returned.
+func (environ *Environment) QueryOrdersByStatus(sessionName, symbol string, status types.OrderStatus, since time.Time) ([]types.Order, error) {
+	if environ.OrderService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
 
-		environ.AddExchangeSession(sessionName, session)
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	return nil
+	return environ.OrderService.QueryByStatus(session.Exchange.Name(), symbol, session.Margin, session.IsolatedMargin, status, since)
 }
 
+// QueryLastOrders queries the last n synced orders of the session symbol, the latest order comes first
+func (environ *Environment) QueryLastOrders(sessionName, symbol string, n int) ([]types.Order, error) {
+	if environ.OrderService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
 
-// Init prepares the data that will be used by the strategies
-func (environ *Environment) Init(ctx context.Context) (err error) {
-	for n := range environ.sessions {
-		var session = environ.sessions[n]
-		if err = session.Init(ctx, environ); err != nil {
-			// we can skip initialized sessions
-			if err != ErrSessionAlreadyInitialized {
-				return err
-			}
-		}
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	return
+	return environ.OrderService.QueryLast(session.Exchange.Name(), symbol, session.Margin, session.IsolatedMargin, n)
 }
 
-func (environ *Environment) Start(ctx context.Context) (err error) {
-	for n := range environ.sessions {
-		var session = environ.sessions[n]
-		if err = session.InitSymbols(ctx, environ); err != nil {
-			return err
-		}
+// ExportTradesCSV writes the synced trades of the session in the time range [since, until) as csv,
+// the empty symbol means all symbols and the zero until means no upper bound
+func (environ *Environment) ExportTradesCSV(w io.Writer, sessionName, symbol string, since, until time.Time) error {
+	if environ.TradeService == nil {
+		return ErrDatabaseNotConfigured
 	}
-	return
-}
 
+	options, err := environ.exportCSVOptions(sessionName, symbol, since, until)
+	if err != nil {
+		return err
+	}
 
-func (environ *Environment) ConfigurePersistence(conf *PersistenceConfig) error {
-	if conf.Redis != nil {
-		if err := env.Set(conf.Redis); err != nil {
-			return err
-		}
+	return environ.TradeService.ExportCSV(w, options)
+}
 
-		environ.PersistenceServiceFacade.Redis = service.NewRedisPersistenceService(conf.Redis)
+// ExportOrdersCSV writes the synced orders of the session created in the time range [since, until) as csv,
+// the empty symbol means all symbols and the zero until means no upper bound
+func (environ *Environment) ExportOrdersCSV(w io.Writer, sessionName, symbol string, since, until time.Time) error {
+	if environ.OrderService == nil {
+		return ErrDatabaseNotConfigured
 	}
 
-	if conf.Json != nil {
-		if _, err := os.Stat(conf.Json.Directory); os.IsNotExist(err) {
-			if err2 := os.MkdirAll(conf.Json.Directory, 0777); err2 != nil {
-				log.WithError(err2).Errorf("can not create directory: %s", conf.Json.Directory)
-				return err2
-			}
-		}
+	options, err := environ.exportCSVOptions(sessionName, symbol, since, until)
+	if err != nil {
+		return err
+	}
 
-		environ.PersistenceServiceFacade.Json = &service.JsonPersistenceService{Directory: conf.Json.Directory}
+	return environ.OrderService.ExportCSV(w, options)
+}
+
+func (environ *Environment) exportCSVOptions(sessionName, symbol string, since, until time.Time) (service.ExportCSVOptions, error) {
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return service.ExportCSVOptions{}, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	return nil
+	return service.ExportCSVOptions{
+		Exchange:   session.Exchange.Name(),
+		Symbol:     symbol,
+		IsMargin:   session.Margin,
+		IsIsolated: session.IsolatedMargin,
+		Since:      since,
+		Until:      until,
+	}, nil
 }
 
-// configure notification rules
-// for symbol-based routes, we should register the same symbol rules for each session.
-// for session-based routes, we should set the fixed callbacks for each session
-func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfig) error {
-	// configure routing here
-	if conf.SymbolChannels != nil {
-		environ.SymbolChannelRouter.AddRoute(conf.SymbolChannels)
+// QueryDeposits queries the synced deposits of the session since the given time, the empty asset means all assets.
+// The deposits are synced by the exchange name, so the deposits of the other sessions on the same exchange, i.e., the other
+// accounts, are included.
+func (environ *Environment) QueryDeposits(sessionName, asset string, since time.Time) ([]types.Deposit, error) {
+	if environ.DepositService == nil {
+		return nil, ErrDatabaseNotConfigured
 	}
-	if conf.SessionChannels != nil {
-		environ.SessionChannelRouter.AddRoute(conf.SessionChannels)
+
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
 	}
 
-	if conf.Routing != nil {
-		// configure passive object notification routing
-		switch conf.Routing.Trade {
-		case "$silent": // silent, do not setup notification
+	return environ.DepositService.QuerySince(session.Exchange.Name(), asset, since)
+}
 
-		case "$session":
-			defaultTradeUpdateHandler := func(trade types.Trade) {
-				text := util.Render(TemplateTradeReport, trade)
-				environ.Notify(text, &trade)
-			}
-			for name := range environ.sessions {
-				session := environ.sessions[name]
-
-				// if we can route session name to channel successfully...
-				channel, ok := environ.SessionChannelRouter.Route(name)
-				if ok {
-					session.Stream.OnTradeUpdate(func(trade types.Trade) {
-						text := util.Render(TemplateTradeReport, trade)
-						environ.NotifyTo(channel, text, &trade)
-					})
-				} else {
-					session.Stream.OnTradeUpdate(defaultTradeUpdateHandler)
-				}
-			}
+// GeneratePnLReport calculates the average cost PnL report of the symbol from the synced trades of the session.
+// The trades before the since time are excluded, and only the trades of the session account type (spot,
+// cross margin or isolated margin) are included. The current price is the last price of the session stream,
+// or the ticker price if the stream has no price of the symbol yet.
+func (environ *Environment) GeneratePnLReport(ctx context.Context, sessionName, symbol string, since time.Time) (*pnl.AverageCostPnlReport, error) {
+	if environ.TradeService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
+
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("exchange session %s not found", sessionName)
+	}
+
+	var trades []types.Trade
+	var err error
+
+	// the trades paying the fee with the platform currency are needed for the fee calculation
+	tradingFeeCurrency := session.Exchange.PlatformFeeCurrency()
+	if strings.HasPrefix(symbol, tradingFeeCurrency) {
+		trades, err = environ.TradeService.QueryForTradingFeeCurrency(session.Exchange.Name(), symbol, tradingFeeCurrency)
+	} else {
+		trades, err = environ.TradeService.Query(service.QueryTradesOptions{
+			Exchange: session.Exchange.Name(),
+			Symbol:   symbol,
+			Since:    since,
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionTrades []types.Trade
+	for _, trade := range trades {
+		if trade.Time.Time().Before(since) {
+			continue
+		}
+
+		if trade.IsMargin != session.Margin || trade.IsIsolated != session.IsolatedMargin {
+			continue
+		}
+
+		sessionTrades = append(sessionTrades, trade)
+	}
+
+	currentPrice, ok := session.LastPrice(symbol)
+	if market, hasMarket := session.Market(symbol); hasMarket && len(environ.priceSources) > 0 {
+		// the unrealized profit is valued by the price sources, e.g., the price oracle of the illiquid asset
+		if price, found := environ.QueryPrice(ctx, session, market.BaseCurrency, market.QuoteCurrency); found {
+			currentPrice, ok = price.Float64(), true
+		}
+	}
+
+	if !ok {
+		ticker, err := session.Exchange.QueryTicker(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		currentPrice = ticker.Last
+	}
+
+	calculator := &pnl.AverageCostCalculator{
+		TradingFeeCurrency: tradingFeeCurrency,
+	}
+
+	report := calculator.Calculate(symbol, sessionTrades, currentPrice)
+	if market, ok := session.Market(symbol); ok {
+		report.Market = market
+	}
+
+	environ.ConvertPnLReport(ctx, session, report)
+	return report, nil
+}
+
+// SetReportingCurrency sets the currency the pnl reports and the balance snapshots are valued in,
+// the profits are reported in the quote currency of the symbols if it's empty
+func (environ *Environment) SetReportingCurrency(currency string) {
+	environ.reportingCurrency = strings.ToUpper(currency)
+}
+
+// ReportingCurrency returns the configured reporting currency
+func (environ *Environment) ReportingCurrency() string {
+	return environ.reportingCurrency
+}
+
+// ConvertPnLReport converts the profits of the report into the reporting currency by the price of the quote currency
+// from the price sources, e.g., the profit of ETHBTC is converted by the BTCUSDT price into USDT. The report is flagged
+// with ConversionMissing if no price source has the price of the quote currency. Nothing is done if the reporting
+// currency is not set.
+func (environ *Environment) ConvertPnLReport(ctx context.Context, session *ExchangeSession, report *pnl.AverageCostPnlReport) {
+	if len(environ.reportingCurrency) == 0 {
+		return
+	}
+
+	quoteCurrency := report.Market.QuoteCurrency
+	if len(quoteCurrency) == 0 {
+		report.SetReportingCurrency(environ.reportingCurrency, 0, false)
+		return
+	}
+
+	price, ok := environ.QueryPrice(ctx, session, quoteCurrency, environ.reportingCurrency)
+	if !ok {
+		log.Warnf("can not convert the %s pnl report of session %s into %s, the %s price of %s is missing",
+			report.Symbol, session.Name, environ.reportingCurrency, environ.reportingCurrency, quoteCurrency)
+	}
+
+	report.SetReportingCurrency(environ.reportingCurrency, price.Float64(), ok)
+}
+
+// AddExchangeSession adds the existing exchange session or pre-created exchange session
+func (environ *Environment) AddExchangeSession(name string, session *ExchangeSession) *ExchangeSession {
+	// update Notifiability from the environment
+	session.Notifiability = environ.Notifiability
+	session.maintenance = environ.maintenance
+
+	// the stream is replaced before any callback is registered, so that the callbacks receive the simulated events
+	if environ.dryRun && !session.PublicOnly {
+		session.enableDryRunStream()
+	}
+
+	environ.sessionsMutex.Lock()
+	environ.sessions[name] = session
+	environ.sessionsMutex.Unlock()
+	return session
+}
+
+// AddExchange adds the given exchange with the session name, this is the default
+func (environ *Environment) AddExchange(name string, exchange types.Exchange) (session *ExchangeSession) {
+	session = NewExchangeSession(name, exchange)
+	return environ.AddExchangeSession(name, session)
+}
+
+func (environ *Environment) ConfigureExchangeSessions(userConfig *Config) error {
+	if len(userConfig.Sessions) == 0 {
+		return environ.AddExchangesByViperKeys()
+	}
+
+	return environ.AddExchangesFromSessionConfig(userConfig.Sessions)
+}
+
+// AddExchangesByViperKeys adds the exchange sessions by the api key flags or env vars, e.g., BINANCE_API_KEY.
+// Multiple accounts of an exchange can be declared by the env var prefixes, e.g., BINANCE_PREFIXES="BINANCE_MAIN,BINANCE_ALT"
+// adds the sessions "binance_main" and "binance_alt" with the keys BINANCE_MAIN_API_KEY and BINANCE_ALT_API_KEY.
+func (environ *Environment) AddExchangesByViperKeys() error {
+	for _, n := range SupportedExchanges {
+		if viper.IsSet(string(n) + "-api-key") {
+			exchange, err := environ.newExchangeWithEnvVarPrefix(n, "")
+			if err != nil {
+				return err
+			}
+
+			environ.AddExchange(n.String(), exchange)
+		}
+
+		for _, prefix := range parseEnvVarPrefixes(viper.GetString(string(n) + "-prefixes")) {
+			name := strings.ToLower(prefix)
+			if _, exists := environ.Session(name); exists {
+				return fmt.Errorf("duplicated exchange session %s of the env var prefix %s", name, prefix)
+			}
+
+			exchange, err := environ.newExchangeWithEnvVarPrefix(n, prefix)
+			if err != nil {
+				return err
+			}
+
+			environ.AddExchange(name, exchange)
+		}
+	}
+
+	return nil
+}
+
+// newExchangeWithEnvVarPrefix creates the exchange with the api keys of the env var prefix,
+// the keys are not required in the validate-only mode
+func (environ *Environment) newExchangeWithEnvVarPrefix(n types.ExchangeName, prefix string) (types.Exchange, error) {
+	if environ.validateOnly {
+		return cmdutil.NewExchangeStandard(n, "", "", "")
+	}
+
+	return cmdutil.NewExchangeWithEnvVarPrefix(n, prefix)
+}
+
+// parseEnvVarPrefixes parses the comma or space separated env var prefixes, the trailing underscores are trimmed,
+// so both "BINANCE_MAIN" and "BINANCE_MAIN_" are accepted
+func parseEnvVarPrefixes(s string) (prefixes []string) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	for _, field := range fields {
+		prefix := strings.ToUpper(strings.TrimRight(field, "_"))
+		if len(prefix) > 0 {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return prefixes
+}
+
+// NewExchangeSessionFromConfig creates the session from the config, the errors carry the session name and the exchange
+// name only, the credential values are never included in the errors or the logs
+func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (*ExchangeSession, error) {
+	return newExchangeSessionFromConfig(name, sessionConfig, false)
+}
+
+// NewStandaloneExchangeSessionFromConfig creates the session from the config and initializes it, the session is not added
+// to the environment, so it can be used outside the environment lifecycle, e.g., in a custom scanner tool.
+// See ExchangeSession.InitStandalone for what's not initialized.
+func NewStandaloneExchangeSessionFromConfig(ctx context.Context, name string, sessionConfig *ExchangeSession) (*ExchangeSession, error) {
+	session, err := NewExchangeSessionFromConfig(name, sessionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.InitStandalone(ctx); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// newExchangeSessionFromConfig creates the session from the config, the exchange is created without the api credentials
+// if validateOnly is set, so that neither the api keys nor the secret resolution is needed for checking the config
+func newExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession, validateOnly bool) (*ExchangeSession, error) {
+	exchangeName, err := types.ValidExchangeName(sessionConfig.ExchangeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchange types.Exchange
+
+	if validateOnly {
+		exchange, err = cmdutil.NewExchangeStandard(exchangeName, "", "", sessionConfig.SubAccount)
+	} else if sessionConfig.PublicOnly {
+		// the public only session streams the market data only, no credential is resolved
+		exchange, err = cmdutil.NewExchangeStandard(exchangeName, "", "", "")
+	} else if sessionConfig.Key != "" && sessionConfig.Secret != "" {
+		// the key and the secret can be encrypted with the master key or stored in Vault,
+		// the plaintext values are still supported
+		key, keyErr := util.ResolveSecret(sessionConfig.Key)
+		if keyErr != nil {
+			return nil, fmt.Errorf("can not resolve the api key of session %s: %w", name, keyErr)
+		}
+
+		secret, secretErr := util.ResolveSecret(sessionConfig.Secret)
+		if secretErr != nil {
+			return nil, fmt.Errorf("can not resolve the api secret of session %s: %w", name, secretErr)
+		}
+
+		exchange, err = cmdutil.NewExchangeStandard(exchangeName, key, secret, sessionConfig.SubAccount)
+	} else {
+		exchange, err = cmdutil.NewExchangeWithEnvVarPrefix(exchangeName, sessionConfig.EnvVarPrefix)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// configure exchange
+	if sessionConfig.Sandbox {
+		sandboxExchange, ok := exchange.(types.SandboxExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support the sandbox endpoints", exchangeName)
+		}
+
+		sandboxExchange.UseSandbox()
+	}
+
+	// the proxy is applied before the rate limit, which wraps the proxied http client
+	if sessionConfig.Proxy != nil {
+		proxyExchange, ok := exchange.(types.ProxyExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support the proxy", exchangeName)
+		}
+
+		if !validateOnly {
+			proxyURL, err := sessionConfig.Proxy.ParseURL()
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy of session %s: %w", name, err)
+			}
+
+			proxyExchange.SetProxy(proxyURL)
+		}
+	}
+
+	if sessionConfig.PublicOnly && sessionConfig.Margin {
+		log.Warnf("session %s is public only, the margin config is ignored", name)
+	} else if sessionConfig.Margin {
+		marginExchange, ok := exchange.(types.MarginExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support margin", exchangeName)
+		}
+
+		if sessionConfig.IsolatedMargin {
+			symbols := sessionConfig.GetIsolatedMarginSymbols()
+			if len(symbols) == 0 {
+				return nil, fmt.Errorf("isolated margin session %s requires isolatedMarginSymbol or isolatedMarginSymbols", name)
+			}
+
+			for _, symbol := range symbols {
+				marginExchange.UseIsolatedMargin(symbol)
+			}
+
+			if settings := marginExchange.GetMarginSettings(); len(settings.IsolatedMarginSymbols) < len(symbols) {
+				return nil, fmt.Errorf("exchange %s does not support multiple isolated margin symbols", exchangeName)
+			}
+		} else {
+			marginExchange.UseMargin()
+		}
+	}
+
+	if sessionConfig.Futures {
+		if sessionConfig.Margin {
+			return nil, fmt.Errorf("session %s can not use futures and margin at the same time", name)
+		}
+
+		futuresExchange, ok := exchange.(types.FuturesExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support futures", exchangeName)
+		}
+
+		futuresExchange.UseFutures()
+	}
+
+	session := NewExchangeSession(name, exchange)
+	session.ExchangeName = sessionConfig.ExchangeName
+	session.EnvVarPrefix = sessionConfig.EnvVarPrefix
+	session.Key = sessionConfig.Key
+	session.Secret = sessionConfig.Secret
+	session.SubAccount = sessionConfig.SubAccount
+	session.PublicOnly = sessionConfig.PublicOnly
+	if !sessionConfig.PublicOnly {
+		session.Margin = sessionConfig.Margin
+		session.IsolatedMargin = sessionConfig.IsolatedMargin
+		session.IsolatedMarginSymbol = sessionConfig.IsolatedMarginSymbol
+		session.IsolatedMarginSymbols = sessionConfig.IsolatedMarginSymbols
+	}
+	session.Futures = sessionConfig.Futures
+	session.Sandbox = sessionConfig.Sandbox
+	session.Proxy = sessionConfig.Proxy
+	session.Reconnect = sessionConfig.Reconnect
+	session.Connection = sessionConfig.Connection
+	session.Tags = sessionConfig.Tags
+	session.DefaultSubscriptions = sessionConfig.DefaultSubscriptions
+
+	if session.PublicOnly {
+		session.Stream.SetPublicOnly()
+	}
+
+	if err := session.subscribeDefaults(sessionConfig.DefaultSubscriptions); err != nil {
+		return nil, fmt.Errorf("invalid subscriptions of session %s: %w", name, err)
+	}
+
+	if sessionConfig.RateLimit != nil {
+		if err := session.SetRateLimit(sessionConfig.RateLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+func (environ *Environment) AddExchangesFromSessionConfig(sessions map[string]*ExchangeSession) error {
+	for sessionName, sessionConfig := range sessions {
+		session, err := newExchangeSessionFromConfig(sessionName, sessionConfig, environ.validateOnly)
+		if err != nil {
+			return err
+		}
+
+		environ.AddExchangeSession(sessionName, session)
+
+		environ.sessionsMutex.Lock()
+		environ.sessionConfigs[sessionName] = sessionConfig
+		environ.sessionsMutex.Unlock()
+	}
+
+	return nil
+}
+
+
+// Init initializes all the exchange sessions. Unlike InitStrict, the failed sessions do not stop the
+// initialization of the other sessions, the errors of the failed sessions are returned in a SessionInitError.
+func (environ *Environment) Init(ctx context.Context) error {
+	if environ.validateOnly {
+		return ErrValidateOnly
+	}
+
+	var initErr = &SessionInitError{Errors: make(map[string]error)}
+	for _, session := range environ.Sessions() {
+		if err := session.Init(ctx, environ); err != nil {
+			// we can skip initialized sessions
+			if err != ErrSessionAlreadyInitialized {
+				session.Logger().WithError(err).Errorf("exchange session %s init error", session.Name)
+				initErr.Errors[session.Name] = err
+			}
+		}
+	}
+
+	if len(initErr.Errors) > 0 {
+		return initErr
+	}
+
+	return nil
+}
+
+// InitStrict initializes the exchange sessions and returns the first session init error
+func (environ *Environment) InitStrict(ctx context.Context) error {
+	if environ.validateOnly {
+		return ErrValidateOnly
+	}
+
+	for _, session := range environ.Sessions() {
+		if err := session.Init(ctx, environ); err != nil {
+			// we can skip initialized sessions
+			if err != ErrSessionAlreadyInitialized {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the api credentials of the exchange sessions by querying the account balances,
+// the public only sessions are skipped. The returned map tells whether the credentials of each session are valid,
+// and the errors of the invalid sessions are returned in a CredentialError.
+// It can be called before Init, or after Init and before Start to fail fast on the bad keys.
+func (environ *Environment) Validate(ctx context.Context) (map[string]bool, error) {
+	var validity = make(map[string]bool)
+	var credentialErr = &CredentialError{Errors: make(map[string]error)}
+
+	for _, session := range environ.Sessions() {
+		if session.PublicOnly {
+			continue
+		}
+
+		var exchange = session.Exchange
+
+		// validate the credentials with the live exchange, the dry run exchange only queries the simulated balances
+		if dryRun, ok := exchange.(*DryRunExchange); ok {
+			exchange = dryRun.Exchange
+		}
+
+		if _, err := exchange.QueryAccountBalances(ctx); err != nil {
+			session.Logger().WithError(err).Errorf("exchange session %s credential validation error", session.Name)
+			credentialErr.Errors[session.Name] = err
+			validity[session.Name] = false
+			continue
+		}
+
+		validity[session.Name] = true
+	}
+
+	if len(credentialErr.Errors) > 0 {
+		return validity, credentialErr
+	}
+
+	return validity, nil
+}
+
+func (environ *Environment) Start(ctx context.Context) (err error) {
+	// warn the clock drift early, before the signed requests fail, the failed check doesn't stop the start
+	if _, err := environ.CheckClockDrift(ctx); err != nil {
+		log.WithError(err).Warn("clock drift check failed")
+	}
+
+	for _, session := range environ.Sessions() {
+		if err = session.InitSymbols(ctx, environ); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+
+// redisPingTimeout is the timeout of checking the redis persistence connection
+const redisPingTimeout = 5 * time.Second
+
+func (environ *Environment) ConfigurePersistence(conf *PersistenceConfig) error {
+	if conf.Redis != nil {
+		if err := env.Set(conf.Redis); err != nil {
+			return err
+		}
+
+		// the invalid TLS config is a config error, it fails the startup even if the persistence is not required
+		redisService, err := service.NewRedisPersistenceService(conf.Redis)
+		if err != nil {
+			return errors.Wrap(err, "invalid redis persistence config")
+		}
+
+		// the redis client connects lazily, so we ping the server here,
+		// otherwise the misconfigured redis is only noticed when the states are saved.
+		ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+		err = redisService.Ping(ctx)
+		cancel()
+
+		if err != nil {
+			addr := net.JoinHostPort(conf.Redis.Host, conf.Redis.Port)
+			if conf.Required {
+				return errors.Wrapf(err, "can not connect to the redis persistence %s", addr)
+			}
+
+			log.WithError(err).Errorf("can not connect to the redis persistence %s, the redis persistence is disabled", addr)
+		} else {
+			environ.PersistenceServiceFacade.Redis = redisService
+		}
+	}
+
+	if conf.DynamoDB != nil {
+		if err := env.Set(conf.DynamoDB); err != nil {
+			return err
+		}
+
+		secretAccessKey, err := util.ResolveSecret(conf.DynamoDB.SecretAccessKey)
+		if err != nil {
+			return fmt.Errorf("can not resolve the aws secret access key: %w", err)
+		}
+		conf.DynamoDB.SecretAccessKey = secretAccessKey
+
+		if len(conf.DynamoDB.Table) == 0 || len(conf.DynamoDB.Region) == 0 {
+			return errors.New("dynamodb persistence requires the table and the region")
+		}
+
+		environ.PersistenceServiceFacade.DynamoDB = service.NewDynamoDBPersistenceService(conf.DynamoDB)
+	}
+
+	if conf.Json != nil {
+		if _, err := os.Stat(conf.Json.Directory); os.IsNotExist(err) {
+			if err2 := os.MkdirAll(conf.Json.Directory, 0777); err2 != nil {
+				log.WithError(err2).Errorf("can not create directory: %s", conf.Json.Directory)
+				return err2
+			}
+		}
+
+		environ.PersistenceServiceFacade.Json = &service.JsonPersistenceService{Directory: conf.Json.Directory}
+	}
+
+	if conf.Bolt != nil {
+		if dir := filepath.Dir(conf.Bolt.File); dir != "." {
+			if err := os.MkdirAll(dir, 0777); err != nil {
+				log.WithError(err).Errorf("can not create directory: %s", dir)
+				return err
+			}
+		}
+
+		boltService, err := service.NewBoltPersistenceService(conf.Bolt)
+		if err != nil {
+			return errors.Wrapf(err, "can not open bolt database: %s", conf.Bolt.File)
+		}
+
+		environ.PersistenceServiceFacade.Bolt = boltService
+	}
+
+	if conf.Primary != "" {
+		if _, err := environ.PersistenceServiceFacade.Select(conf.Primary); err != nil {
+			return errors.Wrap(err, "primary persistence error")
+		}
+
+		environ.PersistenceServiceFacade.Primary = conf.Primary
+	}
+
+	if conf.IsDurableConfigured() && !environ.PersistenceServiceFacade.IsDurable() {
+		log.Warnf("the configured persistence is not available, the states are only kept in memory and will be lost on restart")
+	}
+
+	if err := environ.restoreSyncState(); err != nil {
+		return errors.Wrap(err, "can not restore the sync state")
+	}
+
+	return nil
+}
+
+// configure notification rules
+// for symbol-based routes, we should register the same symbol rules for each session.
+// for session-based routes, we should set the fixed callbacks for each session
+// the routing can be overridden by session via the sessionRouting config.
+func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfig) error {
+	if len(conf.Format) > 0 {
+		outputFormat, err := types.ParseOutputFormat(string(conf.Format))
+		if err != nil {
+			return errors.Wrap(err, "invalid notification format")
+		}
+
+		environ.ObjectChannelRouter.SetFormat(outputFormat)
+	}
+
+	if conf.Templates != nil {
+		// the templates are parsed when the config is loaded, parse them here if the config is built in code
+		if (len(conf.Templates.Trade) > 0 && conf.Templates.TradeTemplate() == nil) ||
+			(len(conf.Templates.Order) > 0 && conf.Templates.OrderTemplate() == nil) ||
+			(len(conf.Templates.SubmitOrder) > 0 && conf.Templates.SubmitOrderTemplate() == nil) {
+			if err := conf.Templates.Parse(); err != nil {
+				return err
+			}
+		}
+
+		environ.tradeReportTemplate = conf.Templates.TradeTemplate()
+		environ.orderReportTemplate = conf.Templates.OrderTemplate()
+		environ.submitOrderReportTemplate = conf.Templates.SubmitOrderTemplate()
+	}
+
+	// the notifications missed by the routing modes are routed by the fallback chain, it's disabled if it's not set
+	environ.SetFallback(conf.Fallback)
+
+	// the symbols are normalized before they're routed, the routed objects keep the exchange symbols
+	environ.SymbolChannelRouter.SetNormalizer(NewSymbolNormalizer(conf.SymbolAliases))
+
+	// configure routing here
+	if conf.SymbolChannels != nil {
+		environ.SymbolChannelRouter.AddRoute(conf.SymbolChannels)
+	}
+	if conf.SessionChannels != nil {
+		environ.SessionChannelRouter.AddRoute(conf.SessionChannels)
+	}
+	if conf.StrategyChannels != nil {
+		environ.StrategyChannelRouter.AddRoute(conf.StrategyChannels)
+	}
+
+	for name := range conf.SessionRoutings {
+		if _, ok := environ.Session(name); !ok {
+			log.Warnf("notification routing is defined for the session %s, but the session is not found", name)
+		}
+	}
+
+	if conf.PnLReport != nil {
+		if conf.PnLReport.Interval <= 0 {
+			return errors.New("pnl report interval must be positive")
+		}
+
+		environ.pnlReportConfig = conf.PnLReport
+	}
+
+	// the object routes are shared by all sessions, so we only need to register them once
+	var tradeObjectRouted, orderObjectRouted, submitOrderObjectRouted, pnlObjectRouted bool
+	var pnlRoutings = make(map[string]string)
+	var submitOrderRoutings = make(map[string]string)
+
+	environ.sessionsMutex.Lock()
+	environ.channelRoutings = make(map[string][]NotificationChannelRoute)
+	environ.sessionsMutex.Unlock()
+
+	for name, session := range environ.Sessions() {
+
+		routing := conf.SessionRouting(name)
+		if routing == nil {
+			continue
+		}
+
+		// the channel routes are set before the notification handlers are configured
+		environ.sessionsMutex.Lock()
+		environ.channelRoutings[name] = routing.Channels
+		environ.sessionsMutex.Unlock()
+
+		// configure passive object notification routing
+		environ.configureTradeNotification(session, routing.Trade, routing.TradeFilter())
+		environ.configureOrderNotification(session, routing.Order, routing.OrderFilter())
+
+		if routing.Trade == "$symbol" && !tradeObjectRouted {
+			tradeObjectRouted = true
 
-		case "$symbol":
 			// configure object routes for Trade
-			environ.ObjectChannelRouter.Route(func(obj interface{}) (channel string, ok bool) {
+			environ.ObjectChannelRouter.AddRoute(func(obj interface{}) (channel string, ok bool) {
 				trade, matched := obj.(*types.Trade)
 				if !matched {
 					return
@@ -371,49 +1412,29 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 				channel, ok = environ.SymbolChannelRouter.Route(trade.Symbol)
 				return
 			})
-
-			// use same handler for each session
-			handler := func(trade types.Trade) {
-				text := util.Render(TemplateTradeReport, trade)
-				channel, ok := environ.RouteObject(&trade)
-				if ok {
-					environ.NotifyTo(channel, text, &trade)
-				} else {
-					environ.Notify(text, &trade)
-				}
-			}
-			for _, session := range environ.sessions {
-				session.Stream.OnTradeUpdate(handler)
-			}
 		}
 
-		switch conf.Routing.Order {
+		// the pnl reports are notified by the pnl report scheduler with the routing of the session
+		pnlRoutings[name] = routing.PnL
 
-		case "$silent": // silent, do not setup notification
+		if routing.PnL == "$symbol" && !pnlObjectRouted {
+			pnlObjectRouted = true
 
-		case "$session":
-			defaultOrderUpdateHandler := func(order types.Order) {
-				text := util.Render(TemplateOrderReport, order)
-				environ.Notify(text, &order)
-			}
-			for name := range environ.sessions {
-				session := environ.sessions[name]
-
-				// if we can route session name to channel successfully...
-				channel, ok := environ.SessionChannelRouter.Route(name)
-				if ok {
-					session.Stream.OnOrderUpdate(func(order types.Order) {
-						text := util.Render(TemplateOrderReport, order)
-						environ.NotifyTo(channel, text, &order)
-					})
-				} else {
-					session.Stream.OnOrderUpdate(defaultOrderUpdateHandler)
+			environ.ObjectChannelRouter.AddRoute(func(obj interface{}) (channel string, ok bool) {
+				report, matched := obj.(*pnl.AverageCostPnlReport)
+				if !matched {
+					return
 				}
-			}
+				channel, ok = environ.SymbolChannelRouter.Route(report.Symbol)
+				return
+			})
+		}
+
+		if routing.Order == "$symbol" && !orderObjectRouted {
+			orderObjectRouted = true
 
-		case "$symbol":
 			// add object route
-			environ.ObjectChannelRouter.Route(func(obj interface{}) (channel string, ok bool) {
+			environ.ObjectChannelRouter.AddRoute(func(obj interface{}) (channel string, ok bool) {
 				order, matched := obj.(*types.Order)
 				if !matched {
 					return
@@ -421,55 +1442,358 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 				channel, ok = environ.SymbolChannelRouter.Route(order.Symbol)
 				return
 			})
-
-			// use same handler for each session
-			handler := func(order types.Order) {
-				text := util.Render(TemplateOrderReport, order)
-				channel, ok := environ.RouteObject(&order)
-				if ok {
-					environ.NotifyTo(channel, text, &order)
-				} else {
-					environ.Notify(text, &order)
-				}
-			}
-			for _, session := range environ.sessions {
-				session.Stream.OnOrderUpdate(handler)
-			}
 		}
 
-		switch conf.Routing.SubmitOrder {
+		// the submit orders are notified by the session order executor before they're sent to the exchange
+		submitOrderRoutings[name] = routing.SubmitOrder
 
-		case "$silent": // silent, do not setup notification
+		if routing.SubmitOrder == "$symbol" && !submitOrderObjectRouted {
+			submitOrderObjectRouted = true
 
-		case "$symbol":
-			// add object route
-			environ.ObjectChannelRouter.Route(func(obj interface{}) (channel string, ok bool) {
+			environ.ObjectChannelRouter.AddRoute(func(obj interface{}) (channel string, ok bool) {
 				order, matched := obj.(*types.SubmitOrder)
 				if !matched {
 					return
 				}
-
 				channel, ok = environ.SymbolChannelRouter.Route(order.Symbol)
 				return
 			})
-
 		}
+	}
 
-		// currently not used
-		switch conf.Routing.PnL {
-		case "$symbol":
-			environ.ObjectChannelRouter.Route(func(obj interface{}) (channel string, ok bool) {
-				report, matched := obj.(*pnl.AverageCostPnlReport)
-				if !matched {
+	environ.sessionsMutex.Lock()
+	environ.pnlRoutings = pnlRoutings
+	environ.submitOrderRoutings = submitOrderRoutings
+	environ.sessionsMutex.Unlock()
+
+	if conf.Routing != nil {
+		// the notifiability injected into the strategies is routed by this mode, see Notifiability.ForStrategy
+		environ.SetStrategyRouting(conf.Routing.Strategy)
+	}
+	return nil
+}
+
+// renderTradeReport renders the trade notification with the configured template or the built-in template
+func (environ *Environment) renderTradeReport(trade types.Trade) string {
+	if environ.tradeReportTemplate != nil {
+		return util.RenderTemplate(environ.tradeReportTemplate, trade)
+	}
+
+	return util.RenderTemplate(defaultTradeReportTemplate, trade)
+}
+
+// renderOrderReport renders the order notification with the configured template or the built-in template
+func (environ *Environment) renderOrderReport(order types.Order) string {
+	if environ.orderReportTemplate != nil {
+		return util.RenderTemplate(environ.orderReportTemplate, order)
+	}
+
+	return util.RenderTemplate(defaultOrderReportTemplate, order)
+}
+
+// renderSubmitOrderReport renders the submit order notification with the configured template or the built-in template
+func (environ *Environment) renderSubmitOrderReport(order types.SubmitOrder) string {
+	if environ.submitOrderReportTemplate != nil {
+		return util.RenderTemplate(environ.submitOrderReportTemplate, order)
+	}
+
+	return util.RenderTemplate(defaultSubmitOrderReportTemplate, order)
+}
+
+// sessionNotifications holds the trade, the order and the submit order notification handlers of a session. The stream
+// callbacks are registered once, and the handlers are replaced when the notification routing is rebuilt.
+type sessionNotifications struct {
+	mu          sync.RWMutex
+	trade       func(trade types.Trade)
+	order       func(order types.Order)
+	submitOrder func(order types.SubmitOrder)
+}
+
+func (n *sessionNotifications) setTradeHandler(handler func(trade types.Trade)) {
+	n.mu.Lock()
+	n.trade = handler
+	n.mu.Unlock()
+}
+
+func (n *sessionNotifications) setOrderHandler(handler func(order types.Order)) {
+	n.mu.Lock()
+	n.order = handler
+	n.mu.Unlock()
+}
+
+func (n *sessionNotifications) setSubmitOrderHandler(handler func(order types.SubmitOrder)) {
+	n.mu.Lock()
+	n.submitOrder = handler
+	n.mu.Unlock()
+}
+
+func (n *sessionNotifications) emitTrade(trade types.Trade) {
+	n.mu.RLock()
+	handler := n.trade
+	n.mu.RUnlock()
+
+	if handler != nil {
+		handler(trade)
+	}
+}
+
+func (n *sessionNotifications) emitOrder(order types.Order) {
+	n.mu.RLock()
+	handler := n.order
+	n.mu.RUnlock()
+
+	if handler != nil {
+		handler(order)
+	}
+}
+
+func (n *sessionNotifications) emitSubmitOrder(order types.SubmitOrder) {
+	n.mu.RLock()
+	handler := n.submitOrder
+	n.mu.RUnlock()
+
+	if handler != nil {
+		handler(order)
+	}
+}
+
+// sessionNotifications returns the notification handlers of the session, the stream callbacks are registered on the
+// first call
+func (environ *Environment) sessionNotifications(session *ExchangeSession) *sessionNotifications {
+	environ.notificationsMutex.Lock()
+	defer environ.notificationsMutex.Unlock()
+
+	notifications, ok := environ.notifications[session.Name]
+	if !ok {
+		notifications = &sessionNotifications{}
+		session.Stream.OnTradeUpdate(notifications.emitTrade)
+		session.Stream.OnOrderUpdate(notifications.emitOrder)
+		environ.notifications[session.Name] = notifications
+	}
+
+	return notifications
+}
+
+// configureTradeNotification sets the trade update notification handler of the session stream
+func (environ *Environment) configureTradeNotification(session *ExchangeSession, mode string, filter *MinNotionalFilter) {
+	logger := session.Logger()
+
+	// the handler replaces the handler of the previous routing, so that the routing can be rebuilt on reload
+	notifications := environ.sessionNotifications(session)
+	notifications.setTradeHandler(nil)
+
+	routes := environ.channelRoutes(session.Name, NotificationEventTrade)
+
+	// the trades below the min notional are filtered before they're rendered,
+	// and the trades are sent to the channel routes after they're notified by the routing mode
+	onTradeUpdate := func(cb func(trade types.Trade)) {
+		notifications.setTradeHandler(func(trade types.Trade) {
+			if !filter.AllowTrade(trade) {
+				return
+			}
+
+			cb(trade)
+
+			for _, route := range routes {
+				logger.WithField("symbol", trade.Symbol).Debugf("notifying trade %d to channel route %s", trade.ID, route.Channel)
+				text := environ.renderTradeReport(trade)
+				environ.NotifyTo(route.Channel, text, &trade)
+			}
+		})
+	}
+
+	// the channel routes are notified even if the routing mode is silent
+	if len(routes) > 0 {
+		onTradeUpdate(func(trade types.Trade) {})
+	}
+
+	switch mode {
+	case "$silent": // silent, do not setup notification
+
+	case "$session":
+		// if we can route session name to channel successfully...
+		channel, ok := environ.SessionChannelRouter.Route(session.Name)
+		if ok {
+			onTradeUpdate(func(trade types.Trade) {
+				logger.WithField("symbol", trade.Symbol).Debugf("notifying trade %d to channel %s", trade.ID, channel)
+				text := environ.renderTradeReport(trade)
+				environ.NotifyTo(channel, text, &trade)
+			})
+		} else {
+			onTradeUpdate(func(trade types.Trade) {
+				text := environ.renderTradeReport(trade)
+				if channel, ok := environ.RouteFallback(session.Name, &trade); ok {
+					logger.WithField("symbol", trade.Symbol).Debugf("notifying trade %d to fallback channel %s", trade.ID, channel)
+					environ.NotifyTo(channel, text, &trade)
 					return
 				}
-				channel, ok = environ.SymbolChannelRouter.Route(report.Symbol)
+
+				logger.WithField("symbol", trade.Symbol).Debugf("notifying trade %d", trade.ID)
+				environ.Notify(text, &trade)
+			})
+		}
+
+	case "$symbol":
+		onTradeUpdate(func(trade types.Trade) {
+			logger := logger.WithField("symbol", trade.Symbol)
+			text := environ.renderTradeReport(trade)
+			channel, ok := environ.RouteSessionObject(session.Name, &trade)
+			if ok {
+				logger.Debugf("notifying trade %d to channel %s", trade.ID, channel)
+				environ.NotifyTo(channel, text, &trade)
+			} else {
+				logger.Debugf("notifying trade %d", trade.ID)
+				environ.Notify(text, &trade)
+			}
+		})
+	}
+}
+
+// configureOrderNotification sets the order update notification handler of the session stream
+func (environ *Environment) configureOrderNotification(session *ExchangeSession, mode string, filter *MinNotionalFilter) {
+	logger := session.Logger()
+
+	notifications := environ.sessionNotifications(session)
+	notifications.setOrderHandler(nil)
+
+	routes := environ.channelRoutes(session.Name, NotificationEventOrder)
+
+	// the orders below the min notional are filtered before they're rendered,
+	// and the orders are sent to the channel routes after they're notified by the routing mode
+	onOrderUpdate := func(cb func(order types.Order)) {
+		notifications.setOrderHandler(func(order types.Order) {
+			if !filter.AllowOrder(order) {
 				return
+			}
+
+			cb(order)
+
+			for _, route := range routes {
+				if !route.allowOrder(order) {
+					continue
+				}
+
+				logger.WithField("symbol", order.Symbol).Debugf("notifying order %d to channel route %s", order.OrderID, route.Channel)
+				text := environ.renderOrderReport(order)
+				environ.NotifyTo(route.Channel, text, &order)
+			}
+		})
+	}
+
+	// the channel routes are notified even if the routing mode is silent
+	if len(routes) > 0 {
+		onOrderUpdate(func(order types.Order) {})
+	}
+
+	switch mode {
+	case "$silent": // silent, do not setup notification
+
+	case "$session":
+		// if we can route session name to channel successfully...
+		channel, ok := environ.SessionChannelRouter.Route(session.Name)
+		if ok {
+			onOrderUpdate(func(order types.Order) {
+				logger.WithField("symbol", order.Symbol).Debugf("notifying order %d to channel %s", order.OrderID, channel)
+				text := environ.renderOrderReport(order)
+				environ.NotifyTo(channel, text, &order)
+			})
+		} else {
+			onOrderUpdate(func(order types.Order) {
+				text := environ.renderOrderReport(order)
+				if channel, ok := environ.RouteFallback(session.Name, &order); ok {
+					logger.WithField("symbol", order.Symbol).Debugf("notifying order %d to fallback channel %s", order.OrderID, channel)
+					environ.NotifyTo(channel, text, &order)
+					return
+				}
+
+				logger.WithField("symbol", order.Symbol).Debugf("notifying order %d", order.OrderID)
+				environ.Notify(text, &order)
 			})
 		}
 
+	case "$symbol":
+		onOrderUpdate(func(order types.Order) {
+			logger := logger.WithField("symbol", order.Symbol)
+			text := environ.renderOrderReport(order)
+			channel, ok := environ.RouteSessionObject(session.Name, &order)
+			if ok {
+				logger.Debugf("notifying order %d to channel %s", order.OrderID, channel)
+				environ.NotifyTo(channel, text, &order)
+			} else {
+				logger.Debugf("notifying order %d", order.OrderID)
+				environ.Notify(text, &order)
+			}
+		})
+	}
+}
+
+// newSubmitOrderNotifier returns the submit order notification handler of the session order executor,
+// the submit orders are notified by the routing mode and then sent to the channel routes of the session
+func (environ *Environment) newSubmitOrderNotifier(session *ExchangeSession) func(order types.SubmitOrder) {
+	notify := environ.newSubmitOrderModeNotifier(session)
+
+	routes := environ.channelRoutes(session.Name, NotificationEventSubmitOrder)
+	if len(routes) == 0 {
+		return notify
+	}
+
+	logger := session.Logger()
+	return func(order types.SubmitOrder) {
+		notify(order)
+
+		for _, route := range routes {
+			logger.WithField("symbol", order.Symbol).Debugf("notifying submit order to channel route %s", route.Channel)
+			text := environ.renderSubmitOrderReport(order)
+			environ.NotifyTo(route.Channel, text, &order)
+		}
+	}
+}
+
+// newSubmitOrderModeNotifier returns the submit order notification handler by the submitOrder routing mode of the
+// session, the submit orders are routed by the object routes if the mode is not set
+func (environ *Environment) newSubmitOrderModeNotifier(session *ExchangeSession) func(order types.SubmitOrder) {
+	logger := session.Logger()
+
+	switch environ.submitOrderRouting(session.Name) {
+	case "$silent": // silent, do not notify
+		return func(order types.SubmitOrder) {}
+
+	case "$session":
+		// if we can route session name to channel successfully...
+		channel, ok := environ.SessionChannelRouter.Route(session.Name)
+		if ok {
+			return func(order types.SubmitOrder) {
+				logger.WithField("symbol", order.Symbol).Debugf("notifying submit order to channel %s", channel)
+				text := environ.renderSubmitOrderReport(order)
+				environ.NotifyTo(channel, text, &order)
+			}
+		}
+
+		return func(order types.SubmitOrder) {
+			text := environ.renderSubmitOrderReport(order)
+			if channel, ok := environ.RouteFallback(session.Name, &order); ok {
+				logger.WithField("symbol", order.Symbol).Debugf("notifying submit order to fallback channel %s", channel)
+				environ.NotifyTo(channel, text, &order)
+				return
+			}
+
+			logger.WithField("symbol", order.Symbol).Debugf("notifying submit order")
+			environ.Notify(text, &order)
+		}
+	}
+
+	return func(order types.SubmitOrder) {
+		logger := logger.WithField("symbol", order.Symbol)
+		text := environ.renderSubmitOrderReport(order)
+		channel, ok := environ.RouteSessionObject(session.Name, &order)
+		if ok {
+			logger.Debugf("notifying submit order to channel %s", channel)
+			environ.NotifyTo(channel, text, &order)
+		} else {
+			logger.Debugf("notifying submit order")
+			environ.Notify(text, &order)
+		}
 	}
-	return nil
 }
 
 func (environ *Environment) SetStartTime(t time.Time) *Environment {
@@ -477,49 +1801,320 @@ func (environ *Environment) SetStartTime(t time.Time) *Environment {
 	return environ
 }
 
+// ConfigureSync applies the sync config, the sync start time is calculated from the current time
+func (environ *Environment) ConfigureSync(conf *SyncConfig) {
+	environ.syncConfig = conf
+
+	now := time.Now()
+	if conf.Since != nil {
+		environ.SetSyncStartTime(conf.Since.StartTime(now))
+	}
+
+	for symbol, since := range conf.Symbols {
+		if since != nil {
+			environ.SetSymbolSyncStartTime(symbol, since.StartTime(now))
+		}
+	}
+
+	for name, window := range conf.Windows {
+		// the exchange names are checked by Config.Validate
+		if exchangeName, err := types.ValidExchangeName(name); err == nil {
+			environ.SetSyncWindow(exchangeName, window.Duration())
+		}
+	}
+}
+
+// SetSyncWindow sets the maximum time range of the order history queries of the exchange, the long sync time range
+// is split into the windows of this size. Zero means the time range is not split.
+func (environ *Environment) SetSyncWindow(exchange types.ExchangeName, window time.Duration) *Environment {
+	if environ.syncWindows == nil {
+		environ.syncWindows = make(map[types.ExchangeName]time.Duration)
+	}
+
+	environ.syncWindows[exchange] = window
+	if environ.SyncService != nil {
+		environ.SyncService.Windows = environ.syncWindows
+	}
+
+	return environ
+}
+
+// SetSymbolSyncStartTime overrides the sync start time of the symbol in all sessions,
+// it applies to the first sync of the symbol and the full sync.
+func (environ *Environment) SetSymbolSyncStartTime(symbol string, t time.Time) *Environment {
+	if environ.symbolSyncStartTimes == nil {
+		environ.symbolSyncStartTimes = make(map[string]time.Time)
+	}
+
+	environ.symbolSyncStartTimes[strings.ToUpper(symbol)] = t
+	return environ
+}
+
+// symbolSyncStartTime returns the sync start time of the symbol, syncStartTime is used if it's not overridden
+func (environ *Environment) symbolSyncStartTime(symbol string) time.Time {
+	if t, ok := environ.symbolSyncStartTimes[symbol]; ok {
+		return t
+	}
+
+	return environ.syncStartTime
+}
+
 // SetSyncStartTime overrides the default trade scan time (-7 days)
 func (environ *Environment) SetSyncStartTime(t time.Time) *Environment {
 	environ.syncStartTime = t
 	return environ
 }
 
+// SetFullSync makes the sync ignore the stored sync cursors and start from the sync start time
+func (environ *Environment) SetFullSync(fullSync bool) *Environment {
+	environ.fullSync = fullSync
+	return environ
+}
+
+// SetSyncConcurrency sets the number of the symbols synced concurrently in a session
+func (environ *Environment) SetSyncConcurrency(concurrency int) *Environment {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	environ.syncConcurrency = concurrency
+	return environ
+}
+
 func (environ *Environment) Connect(ctx context.Context) error {
-	for n := range environ.sessions {
-		// avoid using the placeholder variable for the session because we use that in the callbacks
-		var session = environ.sessions[n]
-		var logger = log.WithField("session", n)
+	if environ.validateOnly {
+		return ErrValidateOnly
+	}
 
-		if len(session.Subscriptions) == 0 {
-			logger.Warnf("exchange session %s has no subscriptions, skipping", session.Name)
-			continue
+	if environ.healthConfig != nil && len(environ.healthConfig.Listen) > 0 {
+		if err := environ.startHealthServer(ctx); err != nil {
+			return fmt.Errorf("can not start the health check server: %w", err)
+		}
+	}
+
+	if environ.metricsConfig != nil && len(environ.metricsConfig.Listen) > 0 {
+		if err := environ.startMetricsServer(ctx); err != nil {
+			return fmt.Errorf("can not start the metrics server: %w", err)
+		}
+	}
+
+	if environ.pnlReportConfig != nil {
+		if environ.TradeService == nil {
+			log.Warn("pnl report is configured, but the database is not configured, the pnl reports are disabled")
 		} else {
-			// add the subscribe requests to the stream
-			for _, s := range session.Subscriptions {
-				logger.Infof("subscribing %s %s %v", s.Symbol, s.Channel, s.Options)
-				session.Stream.Subscribe(s.Channel, s.Symbol, s.Options)
-			}
+			go environ.runPnLReports(ctx, environ.pnlReportConfig.Interval.Duration())
+		}
+	}
+
+	if environ.syncConfig != nil && environ.syncConfig.Scheduled {
+		if environ.SyncService == nil {
+			log.Warn("the scheduled sync is configured, but the database is not configured, the scheduled sync is disabled")
+		} else {
+			environ.StartSyncScheduler(ctx, environ.syncConfig.Interval.Duration())
+		}
+	}
+
+	if environ.balanceSnapshotConfig != nil {
+		if environ.BalanceSnapshotService == nil {
+			log.Warn("the balance snapshot is configured, but the database is not configured, the balance snapshots are disabled")
+		} else {
+			environ.StartBalanceSnapshots(ctx, environ.balanceSnapshotConfig.Interval.Duration())
+		}
+	}
+
+	for _, session := range environ.Sessions() {
+		if err := environ.connectSession(ctx, session); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sessionConnection is the stream connection of a connected session, cancel stops the reconnector and the
+// subscription scheduler of the session
+type sessionConnection struct {
+	reconnector *streamReconnector
+	cancel      context.CancelFunc
+}
+
+// connectSession subscribes the session stream and connects it with the reconnector,
+// the subscription scheduler connects the stream if the session has the subscription schedules
+func (environ *Environment) connectSession(ctx context.Context, session *ExchangeSession) error {
+	var logger = session.Logger()
+
+	if len(session.Subscriptions) == 0 {
+		logger.Warnf("exchange session %s has no subscriptions, skipping", session.Name)
+		return nil
+	} else if len(session.SubscriptionSchedules) == 0 {
+		// add the subscribe requests to the stream, the scheduled subscriptions are added by the scheduler
+		for _, s := range session.Subscriptions {
+			logger.Infof("subscribing %s %s %v", s.Symbol, s.Channel, s.Options)
+			session.Stream.Subscribe(s.Channel, s.Symbol, s.Options)
+		}
+	}
+
+	if err := applyConnectionConfig(session); err != nil {
+		return err
+	}
+
+	environ.metrics.observeStream(session.Name, session.Stream)
+
+	session.Stream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		environ.EmitBalanceUpdate(session.Name, balances)
+	})
+	session.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		environ.EmitBalanceUpdate(session.Name, balances)
+	})
+
+	// the session context is canceled when the session is removed
+	ctx, cancel := context.WithCancel(ctx)
+
+	environ.configureBalanceSnapshotTrigger(ctx, session)
+
+	notify := func(severity types.Severity, format string, args ...interface{}) {
+		channel, _ := environ.RouteSession(session.Name)
+		environ.NotifyToWithSeverity(severity, channel, format, args...)
+	}
+
+	reconnector := newStreamReconnector(session, notify)
+	environ.sessionsMutex.Lock()
+	environ.connections[session.Name] = &sessionConnection{reconnector: reconnector, cancel: cancel}
+	environ.sessionsMutex.Unlock()
+
+	if len(session.SubscriptionSchedules) > 0 {
+		scheduler := newSubscriptionScheduler(session, reconnector, notify)
+		if err := scheduler.start(ctx, time.Now()); err != nil {
+			return err
+		}
+
+		environ.sessionsMutex.Lock()
+		environ.subscriptionSchedulers[session.Name] = scheduler
+		environ.sessionsMutex.Unlock()
+		go scheduler.run(ctx)
+	} else {
+		logger.Infof("connecting session %s...", session.Name)
+		if err := reconnector.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	go reconnector.run(ctx)
+	return nil
+}
+
+func (environ *Environment) IsSyncing() (status SyncStatus) {
+	environ.syncStatusMutex.Lock()
+	status = environ.syncState.Status
+	environ.syncStatusMutex.Unlock()
+	return status
+}
+
+// LastSyncTime returns the finish time of the last successful sync, including the syncs done before the restart
+func (environ *Environment) LastSyncTime() (t time.Time) {
+	environ.syncStatusMutex.Lock()
+	t = environ.syncState.LastSyncTime
+	environ.syncStatusMutex.Unlock()
+	return t
+}
+
+// IsSyncInterrupted returns true if the previous process stopped in the middle of the sync,
+// the flag is cleared after a successful sync.
+func (environ *Environment) IsSyncInterrupted() (interrupted bool) {
+	environ.syncStatusMutex.Lock()
+	interrupted = environ.syncInterrupted
+	environ.syncStatusMutex.Unlock()
+	return interrupted
+}
+
+func (environ *Environment) syncStateStore() service.Store {
+	return environ.PersistenceServiceFacade.Get().NewStore("bbgo", "sync")
+}
+
+// restoreSyncState loads the sync state saved by the previous process
+func (environ *Environment) restoreSyncState() error {
+	var state SyncState
+	if err := environ.syncStateStore().Load(&state); err != nil {
+		if err == service.ErrPersistenceNotExists {
+			return nil
 		}
 
-		logger.Infof("connecting session %s...", session.Name)
-		if err := session.Stream.Connect(ctx); err != nil {
-			return err
+		return err
+	}
+
+	environ.syncStatusMutex.Lock()
+	defer environ.syncStatusMutex.Unlock()
+
+	if state.Status == Syncing {
+		log.Warnf("the previous sync was interrupted, last successful sync: %s", state.LastSyncTime)
+		environ.syncInterrupted = true
+		state.Status = SyncNotStarted
+	}
+
+	environ.syncState = state
+	return nil
+}
+
+func (environ *Environment) setSyncing(status SyncStatus) {
+	environ.updateSyncState(func(state *SyncState) {
+		state.Status = status
+	})
+}
+
+// finishSync marks the sync as done and records the result of the sync
+func (environ *Environment) finishSync(err error) {
+	environ.updateSyncState(func(state *SyncState) {
+		state.Status = SyncDone
+		if err != nil {
+			state.LastError = err.Error()
+			return
 		}
-	}
 
-	return nil
+		state.LastSyncTime = time.Now()
+		state.LastError = ""
+		environ.syncInterrupted = false
+	})
 }
 
-func (environ *Environment) IsSyncing() (status SyncStatus) {
+func (environ *Environment) updateSyncState(update func(state *SyncState)) {
 	environ.syncStatusMutex.Lock()
-	status = environ.syncStatus
+	update(&environ.syncState)
+	state := environ.syncState
 	environ.syncStatusMutex.Unlock()
-	return status
+
+	if err := environ.syncStateStore().Save(state); err != nil {
+		log.WithError(err).Errorf("can not save the sync state")
+	}
 }
 
-func (environ *Environment) setSyncing(status SyncStatus) {
-	environ.syncStatusMutex.Lock()
-	environ.syncStatus = status
-	environ.syncStatusMutex.Unlock()
+// ReportStrategyError sends the strategy error with the critical severity and emits it to the OnStrategyError callbacks
+func (environ *Environment) ReportStrategyError(err *StrategyError) {
+	log.WithError(err.Err).WithFields(err.logFields()).Errorf("strategy %s error", err.StrategyID)
+
+	// the strategy channel is preferred to the session channel
+	channel, ok := environ.RouteStrategy(err.StrategyID)
+	if !ok && len(err.Session) > 0 {
+		channel, _ = environ.RouteSession(err.Session)
+	}
+
+	environ.NotifyToWithSeverity(types.SeverityCritical, channel, "%s", err.Error())
+	environ.EmitStrategyError(err.StrategyID, err)
+}
+
+// TestNotifications sends a test message through every configured notifier to its default channel, so that the
+// notification setup can be verified before trading. The failed notifiers are logged and reported in the results.
+func (environ *Environment) TestNotifications(ctx context.Context) []NotifierTestResult {
+	message := fmt.Sprintf("bbgo test notification, sent at %s", time.Now().Format(time.RFC3339))
+	results := environ.Notifiability.TestNotifications(ctx, message)
+	for _, result := range results {
+		if result.Error != nil {
+			log.WithError(result.Error).Errorf("test notification of %s failed", result.Notifier)
+		} else {
+			log.Infof("test notification: %s", result)
+		}
+	}
+
+	return results
 }
 
 // Sync syncs all registered exchange sessions
@@ -532,65 +2127,250 @@ func (environ *Environment) Sync(ctx context.Context) error {
 	defer environ.syncMutex.Unlock()
 
 	environ.setSyncing(Syncing)
-	defer environ.setSyncing(SyncDone)
 
-	for _, session := range environ.sessions {
-		if err := environ.syncSession(ctx, session); err != nil {
+	for _, session := range environ.Sessions() {
+		if err := environ.syncSession(ctx, session, nil); err != nil {
+			environ.finishSync(err)
 			return err
 		}
 	}
 
+	environ.finishSync(nil)
 	return nil
 }
 
 func (environ *Environment) SyncSession(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) error {
-	if environ.SyncService == nil {
+	_, err := environ.SyncSessionWithSummary(ctx, session, defaultSymbols...)
+	return err
+}
+
+// syncSession syncs the symbols and the account records of the session, the synced records are counted in the summary if it's given
+func (environ *Environment) syncSession(ctx context.Context, session *ExchangeSession, summary *SyncSummary, defaultSymbols ...string) error {
+	// there is no trade, order or account record to sync
+	if session.PublicOnly {
+		session.Logger().Debugf("session %s is public only, skipping sync", session.Name)
 		return nil
 	}
 
-	environ.syncMutex.Lock()
-	defer environ.syncMutex.Unlock()
+	// the trade and order records have no futures flag, the futures records would be mixed up with the spot records
+	if session.Futures {
+		session.Logger().Infof("session %s is a futures session, skipping sync", session.Name)
+		return nil
+	}
 
-	environ.setSyncing(Syncing)
-	defer environ.setSyncing(SyncDone)
+	// the in-process sync mutex does not work for the processes sharing the same database,
+	// the session is skipped if another process is syncing it
+	if lockService := environ.SyncService.LockService; lockService != nil {
+		lock, err := lockService.TryLock(ctx, "bbgo-sync:"+session.Name)
+		if err == service.ErrSyncLocked {
+			session.Logger().Warnf("session %s is being synced by another process, skipping", session.Name)
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "can not acquire the sync lock of session %s", session.Name)
+		}
 
-	return environ.syncSession(ctx, session, defaultSymbols...)
+		defer func() {
+			if err := lock.Unlock(context.Background()); err != nil {
+				session.Logger().WithError(err).Errorf("can not release the sync lock of session %s", session.Name)
+			}
+		}()
+	}
+
+	start := time.Now()
+	err := environ.syncSessionSymbols(ctx, session, summary, defaultSymbols...)
+	environ.metrics.observeSync(session.Name, start, err)
+	return err
+}
+
+// syncSessionSymbols syncs the trading data of the session symbols and the account records
+func (environ *Environment) syncSessionSymbols(ctx context.Context, session *ExchangeSession, summary *SyncSummary, defaultSymbols ...string) error {
+	symbols, err := getSessionSymbols(ctx, session, defaultSymbols...)
+	if err != nil {
+		return err
+	}
+
+	session.Logger().Infof("syncing symbols %v from session %s", symbols, session.Name)
+
+	progress := func(progress service.SyncProgress) {
+		if summary != nil {
+			summary.add(progress)
+		}
+
+		environ.EmitSyncProgress(SyncProgress{Session: session.Name, SyncProgress: progress})
+	}
+
+	// the errors are indexed by the symbol index, so that the combined error keeps the symbol order
+	var symbolErrors = make([]error, len(symbols))
+	var indexC = make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < environ.syncConcurrency && w < len(symbols); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexC {
+				if err := environ.syncSymbol(ctx, session, symbols[i], progress); err != nil {
+					symbolErrors[i] = errors.Wrapf(err, "%s sync error", symbols[i])
+				}
+			}
+		}()
+	}
+
+	for i := range symbols {
+		indexC <- i
+	}
+
+	close(indexC)
+	wg.Wait()
+
+	var syncErr = &SyncError{Session: session.Name}
+	for _, err := range symbolErrors {
+		if err != nil {
+			syncErr.Errors = append(syncErr.Errors, err)
+		}
+	}
+
+	if err := environ.SyncService.SyncAccountRecords(ctx, session.Exchange, progress); err != nil {
+		syncErr.Errors = append(syncErr.Errors, errors.Wrap(err, "account records sync error"))
+	}
+
+	if len(syncErr.Errors) > 0 {
+		return syncErr
+	}
+
+	return nil
 }
 
-func (environ *Environment) syncSession(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) error {
-	symbols, err := getSessionSymbols(session, defaultSymbols...)
+// syncSymbol syncs the symbol from the stored sync cursor and saves the moved cursor
+func (environ *Environment) syncSymbol(ctx context.Context, session *ExchangeSession, symbol string, progress service.SyncProgressHandler) error {
+	cursor, err := environ.loadSyncCursor(session, symbol)
 	if err != nil {
 		return err
 	}
 
-	log.Infof("syncing symbols %v from session %s", symbols, session.Name)
+	if err := environ.SyncService.SyncSymbol(ctx, session.Exchange, cursor, progress); err != nil {
+		return err
+	}
+
+	if err := environ.SyncService.CursorService.Save(*cursor); err != nil {
+		return err
+	}
+
+	environ.updateStoredPosition(ctx, session, symbol)
+	return nil
+}
+
+// loadSyncCursor loads the stored sync cursor of the session symbol,
+// a new cursor starting from the sync start time of the symbol is returned for the first sync or the full sync.
+func (environ *Environment) loadSyncCursor(session *ExchangeSession, symbol string) (*service.SyncCursor, error) {
+	if !environ.fullSync {
+		cursor, err := environ.SyncService.CursorService.Load(session.Name, symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		if cursor != nil {
+			session.Logger().WithField("symbol", symbol).Infof("resuming %s %s sync from %s", session.Name, symbol, cursor.SyncedAt)
+			return cursor, nil
+		}
+	}
 
-	return environ.SyncService.SyncSessionSymbols(ctx, session.Exchange, environ.syncStartTime, symbols...)
+	return &service.SyncCursor{
+		Session:  session.Name,
+		Exchange: session.Exchange.Name(),
+		Symbol:   symbol,
+		SyncedAt: datatype.Time(environ.symbolSyncStartTime(symbol)),
+	}, nil
 }
 
-func getSessionSymbols(session *ExchangeSession, defaultSymbols ...string) ([]string, error) {
+// getSessionSymbols returns the default symbols or the possible symbols of the session, the partial symbols found
+// by FindPossibleSymbols are returned with a warning, so that the known symbols are still covered
+func getSessionSymbols(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) ([]string, error) {
 	if session.IsolatedMargin {
-		return []string{session.IsolatedMarginSymbol}, nil
+		return session.GetIsolatedMarginSymbols(), nil
 	}
 
 	if len(defaultSymbols) > 0 {
 		return defaultSymbols, nil
 	}
 
-	return session.FindPossibleSymbols()
+	symbols, err := session.FindPossibleSymbols(ctx)
+	if partialErr, ok := err.(*PartialSymbolsError); ok && len(symbols) > 0 {
+		session.Logger().WithError(partialErr.Err).Warnf("can not find all the possible symbols of session %s, using the subscribed symbols %v", session.Name, symbols)
+		return symbols, nil
+	}
+
+	return symbols, err
+}
+
+// resolveSecret resolves the encrypted or the vault secret, the secret is returned as it is in the validate-only mode,
+// so that neither the master key nor the vault server is needed for checking the config
+func (environ *Environment) resolveSecret(value string) (string, error) {
+	if environ.validateOnly {
+		return value, nil
+	}
+
+	return util.ResolveSecret(value)
+}
+
+// defaultTelegramPollerTimeout is the default long polling timeout of the telegram bot
+const defaultTelegramPollerTimeout = 10 * time.Second
+
+// telegramBotSettings returns the bot settings, the telegram-api-url and the telegram-poller-timeout flags
+// take precedence over the config. The empty URL means the default "https://api.telegram.org".
+func telegramBotSettings(token string, conf *TelegramNotification) telebot.Settings {
+	var apiURL string
+	var pollerTimeout = defaultTelegramPollerTimeout
+
+	if conf != nil {
+		apiURL = conf.APIURL
+		if conf.PollerTimeout > 0 {
+			pollerTimeout = conf.PollerTimeout.Duration()
+		}
+	}
+
+	if v := viper.GetString("telegram-api-url"); len(v) > 0 {
+		apiURL = v
+	}
+
+	if v := viper.GetDuration("telegram-poller-timeout"); v > 0 {
+		pollerTimeout = v
+	}
+
+	return telebot.Settings{
+		URL:    strings.TrimRight(apiURL, "/"),
+		Token:  token,
+		Poller: &telebot.LongPoller{Timeout: pollerTimeout},
+	}
 }
 
 func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) error {
 	environ.Notifiability = Notifiability{
-		SymbolChannelRouter:  NewPatternChannelRouter(nil),
-		SessionChannelRouter: NewPatternChannelRouter(nil),
-		ObjectChannelRouter:  NewObjectChannelRouter(),
+		SymbolChannelRouter:   NewPatternChannelRouter(nil),
+		SessionChannelRouter:  NewPatternChannelRouter(nil),
+		StrategyChannelRouter: NewPatternChannelRouter(nil),
+		ObjectChannelRouter:   NewObjectChannelRouter(),
+	}
+
+	if userConfig.Notifications != nil && userConfig.Notifications.RateLimit != nil {
+		environ.SetRateLimit(userConfig.Notifications.RateLimit)
+	}
+
+	if userConfig.Notifications != nil && userConfig.Notifications.Digest != nil {
+		environ.SetDigest(userConfig.Notifications.Digest)
+	}
+
+	if userConfig.Notifications != nil {
+		environ.SetDeliveryPolicy(userConfig.Notifications.Delivery)
+	}
+
+	slackToken, err := environ.resolveSecret(viper.GetString("slack-token"))
+	if err != nil {
+		return fmt.Errorf("can not resolve the slack token: %w", err)
 	}
 
-	slackToken := viper.GetString("slack-token")
 	if len(slackToken) > 0 && userConfig.Notifications != nil {
-		if conf := userConfig.Notifications.Slack; conf != nil {
-			if conf.ErrorChannel != "" {
+		if conf := userConfig.Notifications.Slack; conf != nil && notifierEnabled(conf.Enabled) {
+			if conf.ErrorChannel != "" && !environ.validateOnly {
 				log.Debugf("found slack configured, setting up log hook...")
 				log.AddHook(slacklog.NewLogHook(slackToken, conf.ErrorChannel))
 			}
@@ -601,27 +2381,218 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		}
 	}
 
+	discordBotToken, err := environ.resolveSecret(viper.GetString("discord-bot-token"))
+	if err != nil {
+		return fmt.Errorf("can not resolve the discord bot token: %w", err)
+	}
+
+	if len(discordBotToken) > 0 && userConfig.Notifications != nil {
+		if conf := userConfig.Notifications.Discord; conf != nil && notifierEnabled(conf.Enabled) {
+			log.Debugf("adding discord notifier with default channel: %s", conf.DefaultChannel)
+			var options = []discordnotifier.NotifyOption{
+				discordnotifier.WithChannels(conf.Channels),
+			}
+
+			if cb := conf.CircuitBreaker; cb != nil && cb.FailureThreshold != 0 {
+				options = append(options, discordnotifier.WithCircuitBreaker(cb.FailureThreshold, cb.Cooldown.Duration()))
+			}
+
+			var notifier = discordnotifier.New(discordBotToken, conf.DefaultChannel, options...)
+			environ.AddNotifier(notifier)
+		}
+	}
+
+	if userConfig.Notifications != nil {
+		if conf := userConfig.Notifications.Webhook; conf != nil && notifierEnabled(conf.Enabled) {
+			// the webhook url may carry the secret token, log the host only
+			if u, err := url.Parse(conf.URL); err == nil {
+				log.Debugf("adding webhook notifier with host: %s", u.Host)
+			}
+
+			var options = []webhooknotifier.NotifyOption{
+				webhooknotifier.WithSecret(conf.Secret),
+				webhooknotifier.WithChannels(conf.Channels),
+				webhooknotifier.WithTimeout(conf.Timeout.Duration()),
+			}
+
+			if conf.MaxRetries != nil {
+				options = append(options, webhooknotifier.WithMaxRetries(*conf.MaxRetries))
+			}
+
+			if cb := conf.CircuitBreaker; cb != nil && cb.FailureThreshold != 0 {
+				options = append(options, webhooknotifier.WithCircuitBreaker(cb.FailureThreshold, cb.Cooldown.Duration()))
+			}
+
+			environ.AddNotifier(webhooknotifier.New(conf.URL, options...))
+		}
+
+		if conf := userConfig.Notifications.Email; conf != nil && notifierEnabled(conf.Enabled) {
+			if err := env.Set(conf); err != nil {
+				return err
+			}
+
+			log.Debugf("adding email notifier with smtp server: %s:%d", conf.Host, conf.Port)
+			environ.AddNotifier(emailnotifier.New(conf.Host, conf.Port, conf.From, conf.To,
+				emailnotifier.WithAuth(conf.Username, conf.Password),
+				emailnotifier.WithTLSMode(emailnotifier.TLSMode(conf.TLS)),
+				emailnotifier.WithInsecureSkipVerify(conf.InsecureSkipVerify),
+				emailnotifier.WithSubject(conf.Subject),
+				emailnotifier.WithFlushInterval(conf.FlushInterval.Duration()),
+				emailnotifier.WithBatchSize(conf.BatchSize),
+			))
+		}
+
+		if conf := userConfig.Notifications.SMS; conf != nil && notifierEnabled(conf.Enabled) {
+			if err := env.Set(conf); err != nil {
+				return err
+			}
+
+			var options []smsnotifier.NotifyOption
+			if len(conf.MinSeverity) > 0 {
+				severity, err := types.ParseSeverity(conf.MinSeverity)
+				if err != nil {
+					return err
+				}
+
+				options = append(options, smsnotifier.WithMinSeverity(severity))
+			}
+
+			if cb := conf.CircuitBreaker; cb != nil && cb.FailureThreshold != 0 {
+				options = append(options, smsnotifier.WithCircuitBreaker(cb.FailureThreshold, cb.Cooldown.Duration()))
+			}
+
+			authToken, err := environ.resolveSecret(conf.AuthToken)
+			if err != nil {
+				return fmt.Errorf("can not resolve the twilio auth token: %w", err)
+			}
+
+			log.Debugf("adding sms notifier with %d recipients", len(conf.To))
+			environ.AddNotifier(smsnotifier.New(conf.AccountSID, authToken, conf.From, conf.To, options...))
+		}
+
+		if conf := userConfig.Notifications.Matrix; conf != nil && notifierEnabled(conf.Enabled) {
+			if err := env.Set(conf); err != nil {
+				return err
+			}
+
+			accessToken, err := environ.resolveSecret(conf.AccessToken)
+			if err != nil {
+				return fmt.Errorf("can not resolve the matrix access token: %w", err)
+			}
+
+			log.Debugf("adding matrix notifier with homeserver: %s", conf.HomeserverURL)
+			var options = []matrixnotifier.NotifyOption{
+				matrixnotifier.WithRooms(conf.Rooms),
+			}
+
+			if cb := conf.CircuitBreaker; cb != nil && cb.FailureThreshold != 0 {
+				options = append(options, matrixnotifier.WithCircuitBreaker(cb.FailureThreshold, cb.Cooldown.Duration()))
+			}
+
+			environ.AddNotifier(matrixnotifier.New(conf.HomeserverURL, accessToken, conf.DefaultRoom, options...))
+		}
+
+		if conf := userConfig.Notifications.File; conf != nil && notifierEnabled(conf.Enabled) {
+			var options = []filenotifier.NotifyOption{
+				filenotifier.WithChannels(conf.Channels...),
+			}
+
+			if len(conf.RotationSize) > 0 {
+				size, err := filenotifier.ParseSize(conf.RotationSize)
+				if err != nil {
+					return err
+				}
+
+				options = append(options, filenotifier.WithMaxSize(size))
+			}
+
+			// the log file is not created in the validate-only mode
+			if !environ.validateOnly {
+				notifier, err := filenotifier.New(conf.Path, options...)
+				if err != nil {
+					return errors.Wrapf(err, "can not open the notification log file %s", conf.Path)
+				}
+
+				log.Debugf("adding file notifier with path: %s", conf.Path)
+				environ.AddNotifier(notifier)
+			}
+		}
+
+		// the registered notifiers may connect to their services when they're created, they're only looked up
+		// in the validate-only mode
+		if environ.validateOnly {
+			for name := range userConfig.Notifications.Custom {
+				if _, ok := registeredNotifiers[name]; !ok {
+					return fmt.Errorf("notifier %s is not registered", name)
+				}
+			}
+		} else if len(userConfig.Notifications.Custom) > 0 {
+			notifiers, err := newCustomNotifiers(userConfig.Notifications.Custom)
+			if err != nil {
+				return err
+			}
+
+			for _, notifier := range notifiers {
+				environ.AddNotifier(notifier)
+			}
+		}
+	}
+
+	var totpOptions service.TotpKeyOptions
+	var qrcodeOutput otpQRCodeOutput
+	if userConfig.Notifications != nil && userConfig.Notifications.TOTP != nil {
+		totpOptions.Issuer = userConfig.Notifications.TOTP.Issuer
+		totpOptions.AccountName = userConfig.Notifications.TOTP.AccountName
+		qrcodeOutput.Dir = userConfig.Notifications.TOTP.QRCodeDir
+		qrcodeOutput.ASCII = userConfig.Notifications.TOTP.ASCIIQRCode
+	}
+
+	if dir := viper.GetString("otp-qr-dir"); len(dir) > 0 {
+		qrcodeOutput.Dir = dir
+	}
+
+	if viper.GetBool("otp-qr-ascii") {
+		qrcodeOutput.ASCII = true
+	}
+
 	persistence := environ.PersistenceServiceFacade.Get()
-	telegramBotToken := viper.GetString("telegram-bot-token")
-	if len(telegramBotToken) > 0 {
+	telegramBotToken, err := environ.resolveSecret(viper.GetString("telegram-bot-token"))
+	if err != nil {
+		return fmt.Errorf("can not resolve the telegram bot token: %w", err)
+	}
+
+	var telegramConf *TelegramNotification
+	if userConfig.Notifications != nil {
+		telegramConf = userConfig.Notifications.Telegram
+	}
+
+	if telegramConf != nil && !notifierEnabled(telegramConf.Enabled) {
+		log.Debugf("telegram notifier is disabled, the telegram bot is not started")
+		telegramBotToken = ""
+	}
+
+	// the telegram bot connects to the api server when it's created, it's skipped in the validate-only mode
+	if len(telegramBotToken) > 0 && !environ.validateOnly {
 		tt := strings.Split(telegramBotToken, ":")
 		telegramID := tt[0]
 
-		bot, err := telebot.NewBot(telebot.Settings{
-			// You can also set custom API URL.
-			// If field is empty it equals to "https://api.telegram.org".
-			// URL: "http://195.129.111.17:8012",
-			Token:  telegramBotToken,
-			Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
-		})
-
+		bot, err := telebot.NewBot(telegramBotSettings(telegramBotToken, telegramConf))
 		if err != nil {
 			return err
 		}
 
+		if !environ.PersistenceServiceFacade.IsDurable() {
+			log.Warnf("WARNING: the telegram session is only stored in memory, the one-time password key and the " +
+				"authorized users will be lost on restart, please configure the persistence, e.g., redis or json")
+		}
+
 		// allocate a store, so that we can save the chatID for the owner
 		var sessionStore = persistence.NewStore("bbgo", "telegram", telegramID)
 		var interaction = telegramnotifier.NewInteraction(bot, sessionStore)
+		interaction.SetEnvironment(&interactEnvironment{environ: environ})
+		if telegramConf != nil {
+			interaction.SetAdmins(telegramConf.Admins)
+		}
 
 		authToken := viper.GetString("telegram-bot-auth-token")
 		if len(authToken) > 0 {
@@ -633,15 +2604,30 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		}
 
 		var session telegramnotifier.Session
-		if err := sessionStore.Load(&session); err != nil || session.Owner == nil {
+		var sessionErr = sessionStore.Load(&session)
+		if sessionErr == nil && session.Migrate() {
+			// the legacy single owner session is migrated into the subscriber list
+			if err := sessionStore.Save(&session); err != nil {
+				return errors.Wrap(err, "failed to save session")
+			}
+		}
+
+		if sessionErr != nil || (!session.HasSubscribers() && session.OneTimePasswordKey == nil) {
 			log.Warnf("telegram session not found, generating new one-time password key for new telegram session...")
 
-			qrcodeImagePath := fmt.Sprintf("otp-%s.png", telegramID)
-			key, err := setupNewOTPKey(qrcodeImagePath)
+			// tell the keys of the bots apart by the bot username if the account name is not configured
+			botTotpOptions := totpOptions
+			if len(botTotpOptions.AccountName) == 0 && bot.Me != nil {
+				botTotpOptions.AccountName = bot.Me.Username
+			}
+
+			key, qrcodeImagePath, err := setupNewOTPKey(fmt.Sprintf("otp-%s", telegramID), botTotpOptions, qrcodeOutput)
 			if err != nil {
 				return errors.Wrapf(err, "failed to setup totp (time-based one time password) key")
 			}
 
+			printTelegramOtpAuthGuide(qrcodeImagePath)
+
 			session = telegramnotifier.NewSession(key)
 			if err := sessionStore.Save(&session); err != nil {
 				return errors.Wrap(err, "failed to save session")
@@ -649,11 +2635,55 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		}
 
 		go interaction.Start(session)
+		environ.interactions = append(environ.interactions, interaction)
 
 		var notifier = telegramnotifier.New(interaction)
 		environ.Notifiability.AddNotifier(notifier)
 	}
 
+	slackAppToken, err := environ.resolveSecret(viper.GetString("slack-app-token"))
+	if err != nil {
+		return fmt.Errorf("can not resolve the slack app token: %w", err)
+	}
+
+	if userConfig.Notifications != nil && userConfig.Notifications.Slack != nil && !notifierEnabled(userConfig.Notifications.Slack.Enabled) {
+		log.Debugf("slack notifier is disabled, the slack app interaction is not started")
+		slackAppToken = ""
+	}
+
+	if len(slackAppToken) > 0 && !environ.validateOnly {
+		var sessionStore = persistence.NewStore("bbgo", "slack", "interaction")
+		var interaction = slacknotifier.NewInteraction(slackAppToken, sessionStore)
+		interaction.SetEnvironment(&interactEnvironment{environ: environ})
+
+		authToken := viper.GetString("slack-app-auth-token")
+		if len(authToken) > 0 {
+			interaction.SetAuthToken(authToken)
+
+			log.Debugf("slack app auth token is set, using fixed token for authorization...")
+		}
+
+		var session slacknotifier.Session
+		if err := sessionStore.Load(&session); err != nil || (!session.HasAuthorizedUsers() && session.OneTimePasswordKey == nil) {
+			log.Warnf("slack session not found, generating new one-time password key for new slack session...")
+
+			key, qrcodeImagePath, err := setupNewOTPKey("otp-slack", totpOptions, qrcodeOutput)
+			if err != nil {
+				return errors.Wrapf(err, "failed to setup totp (time-based one time password) key")
+			}
+
+			printSlackOtpAuthGuide(qrcodeImagePath)
+
+			session = slacknotifier.NewSession(key)
+			if err := sessionStore.Save(&session); err != nil {
+				return errors.Wrap(err, "failed to save session")
+			}
+		}
+
+		go interaction.Start(context.Background(), session)
+		environ.interactions = append(environ.interactions, interaction)
+	}
+
 	if userConfig.Notifications != nil {
 		if err := environ.ConfigureNotificationRouting(userConfig.Notifications); err != nil {
 			return err
@@ -682,22 +2712,22 @@ func writeOTPKeyAsQRCodePNG(key *otp.Key, imagePath string) error {
 	return nil
 }
 
-// setupNewOTPKey generates a new otp key and save the secret as a qrcode image
-func setupNewOTPKey(qrcodeImagePath string) (*otp.Key, error) {
-	key, err := service.NewDefaultTotpKey()
+// setupNewOTPKey generates a new otp key with the issuer and the account name options and outputs the qrcode,
+// the path of the qrcode image is returned if the qrcode is written as a png image.
+func setupNewOTPKey(qrcodeName string, options service.TotpKeyOptions, output otpQRCodeOutput) (*otp.Key, string, error) {
+	key, err := service.NewTotpKey(options)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to setup totp (time-based one time password) key")
+		return nil, "", errors.Wrapf(err, "failed to setup totp (time-based one time password) key")
 	}
 
 	printOtpKey(key)
 
-	if err := writeOTPKeyAsQRCodePNG(key, qrcodeImagePath); err != nil {
-		return nil, err
+	qrcodeImagePath, err := output.write(key, qrcodeName)
+	if err != nil {
+		return nil, "", err
 	}
 
-	printTelegramOtpAuthGuide(qrcodeImagePath)
-
-	return key, nil
+	return key, qrcodeImagePath, nil
 }
 
 func printOtpKey(key *otp.Key) {
@@ -713,17 +2743,68 @@ func printOtpKey(key *otp.Key) {
 	fmt.Println("")
 }
 
-func printTelegramOtpAuthGuide(qrcodeImagePath string) {
+// printOtpQRCodeGuide prints the command to open the qrcode image, nothing is printed if the qrcode is printed as ascii
+func printOtpQRCodeGuide(qrcodeImagePath string) {
+	if len(qrcodeImagePath) == 0 {
+		return
+	}
+
 	fmt.Printf(`
 To scan your OTP QR code, please run the following command:
 	
 	open %s
+`, qrcodeImagePath)
+}
 
+func printTelegramOtpAuthGuide(qrcodeImagePath string) {
+	printOtpQRCodeGuide(qrcodeImagePath)
+	fmt.Print(`
 send the auth command with the generated one-time password to the bbgo bot you created to enable the notification:
 
 	/auth {code}
 
-`, qrcodeImagePath)
+`)
+}
+
+func printSlackOtpAuthGuide(qrcodeImagePath string) {
+	printOtpQRCodeGuide(qrcodeImagePath)
+	fmt.Print(`
+send the auth command with the generated one-time password in your slack workspace to query bbgo:
+
+	/bbgo auth {code}
+
+`)
+}
+
+// interactEnvironment adapts the environment for the chat interaction commands
+type interactEnvironment struct {
+	environ *Environment
+}
+
+func (e *interactEnvironment) SessionStatuses() (statuses []interact.SessionStatus) {
+	for name, session := range e.environ.Sessions() {
+		streamStatus := session.StreamStatus()
+		statuses = append(statuses, interact.SessionStatus{
+			Name:           name,
+			Exchange:       session.ExchangeName,
+			Connected:      streamStatus.Connected,
+			DisconnectedAt: streamStatus.DisconnectedAt,
+		})
+	}
+
+	return statuses
+}
+
+func (e *interactEnvironment) QuerySessionBalances(ctx context.Context, sessionName string) (types.BalanceMap, error) {
+	return e.environ.QuerySessionBalances(ctx, sessionName)
+}
+
+func (e *interactEnvironment) NetExposure(ctx context.Context, asset string) (*types.NetExposure, error) {
+	return e.environ.NetExposure(ctx, asset)
+}
+
+func (e *interactEnvironment) NotifyPnLReports(ctx context.Context, sessionName, symbol string, since time.Time) (map[string]*pnl.AverageCostPnlReport, time.Time, error) {
+	return e.environ.NotifyPnLReports(ctx, sessionName, symbol, since)
 }
 
 func printTelegramAuthTokenGuide(token string) {
@@ -4,3 +4,13 @@ import "errors"
 
 var ErrSessionAlreadyInitialized = errors.New("session is already initialized")
 
+
+var ErrDatabaseNotConfigured = errors.New("database is not configured")
+
+var ErrValidateOnly = errors.New("the environment is in the validate-only mode, it can not be connected")
+
+// ErrMaintenanceMode is returned when the orders are submitted in the maintenance mode, see Environment.Pause
+var ErrMaintenanceMode = errors.New("the environment is in the maintenance mode, the orders are not submitted")
+
+// ErrPublicOnlySession is returned when the account of a public only session is queried, the session has no credentials
+var ErrPublicOnlySession = errors.New("the session is public only, it has no account")
@@ -0,0 +1,181 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// NetExposure queries the positions of the asset in all the sessions and aggregates them:
+//
+// - the spot sessions count the balances as the long positions
+// - the margin sessions count the held quantity as the long position, and the borrowed quantity with the interest as the short position
+// - the isolated margin sessions count the positions of each isolated margin symbol separately
+//
+// The notional values are calculated with the last prices of the fiat markets of the asset in each session.
+func (environ *Environment) NetExposure(ctx context.Context, asset string) (*types.NetExposure, error) {
+	asset = strings.ToUpper(asset)
+
+	var netExposure = &types.NetExposure{Asset: asset}
+	sessions := environ.Sessions()
+	for _, name := range sortedSessionNames(sessions) {
+		session := sessions[name]
+
+		// the public only session has no position
+		if session.PublicOnly {
+			continue
+		}
+
+		exposures, err := querySessionExposures(ctx, session, asset)
+		if err != nil {
+			return nil, fmt.Errorf("can not query the %s exposure of session %s: %w", asset, name, err)
+		}
+
+		for _, exposure := range exposures {
+			if exposure.Long == 0 && exposure.Short == 0 {
+				continue
+			}
+
+			exposure.Price, exposure.QuoteCurrency = environ.querySessionAssetPrice(ctx, session, asset)
+			netExposure.Add(exposure)
+		}
+	}
+
+	return netExposure, nil
+}
+
+// querySessionExposures queries the positions of the asset by the account type of the session,
+// the balances are used if the exchange doesn't support the margin account query
+func querySessionExposures(ctx context.Context, session *ExchangeSession, asset string) ([]types.SessionExposure, error) {
+	var base = types.SessionExposure{
+		Session:     session.Name,
+		Exchange:    session.Exchange.Name().String(),
+		AccountType: types.AccountTypeSpot,
+	}
+
+	if session.Margin {
+		base.AccountType = types.AccountTypeMargin
+	}
+
+	if session.Margin && session.IsolatedMargin {
+		if service, ok := session.Exchange.(types.IsolatedMarginAccountService); ok {
+			account, err := service.QueryIsolatedMarginAccount(ctx, session.GetIsolatedMarginSymbols()...)
+			if err != nil {
+				return nil, err
+			}
+
+			var exposures []types.SessionExposure
+			for _, marginAsset := range account.Assets {
+				for _, userAsset := range []types.IsolatedUserAsset{marginAsset.BaseAsset, marginAsset.QuoteAsset} {
+					if userAsset.Asset != asset {
+						continue
+					}
+
+					exposure := base
+					exposure.AccountType = types.AccountTypeIsolatedMargin
+					exposure.Symbol = marginAsset.Symbol
+					exposure.Long = userAsset.Free + userAsset.Locked
+					exposure.Short = userAsset.Borrowed + userAsset.Interest
+					exposures = append(exposures, exposure)
+				}
+			}
+
+			return exposures, nil
+		}
+	} else if session.Margin {
+		if service, ok := session.Exchange.(types.MarginAccountService); ok {
+			account, err := service.QueryMarginAccount(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, userAsset := range account.UserAssets {
+				if userAsset.Asset != asset {
+					continue
+				}
+
+				exposure := base
+				exposure.Long = userAsset.Free + userAsset.Locked
+				exposure.Short = userAsset.Borrowed + userAsset.Interest
+				return []types.SessionExposure{exposure}, nil
+			}
+
+			return nil, nil
+		}
+	}
+
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, ok := balances[asset]
+	if !ok {
+		return nil, nil
+	}
+
+	exposure := base
+	exposure.Long = balance.Total()
+	return []types.SessionExposure{exposure}, nil
+}
+
+// querySessionAssetPrice returns the price of the asset in the first fiat currency quoted by the price sources,
+// the zero price is returned if the price is unknown.
+func (environ *Environment) querySessionAssetPrice(ctx context.Context, session *ExchangeSession, asset string) (fixedpoint.Value, string) {
+	if util.StringSliceContains(fiatCurrencies, asset) {
+		return fixedpoint.NewFromFloat(1.0), asset
+	}
+
+	for _, quoteCurrency := range fiatCurrencies {
+		if price, ok := environ.QueryPrice(ctx, session, asset, quoteCurrency); ok {
+			return price, quoteCurrency
+		}
+	}
+
+	return 0, ""
+}
+
+// querySessionPrice returns the price of the asset in the quote currency from the asset market of the session,
+// or the inverse of the price of the inverse market, e.g., the USDT price in BTC is 1 / price of BTCUSDT.
+// false is returned if the session has neither of the markets or the ticker can not be queried.
+func querySessionPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	if asset == quoteCurrency {
+		return fixedpoint.NewFromFloat(1.0), true
+	}
+
+	if price, ok := querySessionSymbolPrice(ctx, session, asset+quoteCurrency); ok {
+		return fixedpoint.NewFromFloat(price), true
+	}
+
+	if price, ok := querySessionSymbolPrice(ctx, session, quoteCurrency+asset); ok {
+		return fixedpoint.NewFromFloat(1.0 / price), true
+	}
+
+	return 0, false
+}
+
+// querySessionSymbolPrice returns the positive last price of the symbol, the ticker is queried if the stream has
+// no price of the symbol yet
+func querySessionSymbolPrice(ctx context.Context, session *ExchangeSession, symbol string) (float64, bool) {
+	if _, ok := session.Market(symbol); !ok {
+		return 0, false
+	}
+
+	if price, ok := session.LastPrice(symbol); ok && price > 0 {
+		return price, true
+	}
+
+	ticker, err := session.Exchange.QueryTicker(ctx, symbol)
+	if err != nil {
+		log.WithError(err).Warnf("can not query the %s ticker of session %s", symbol, session.Name)
+		return 0, false
+	}
+
+	return ticker.Last, ticker.Last > 0
+}
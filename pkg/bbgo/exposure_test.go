@@ -0,0 +1,87 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testExposureExchange struct {
+	types.Exchange
+
+	balances types.BalanceMap
+}
+
+func (e *testExposureExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *testExposureExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.balances, nil
+}
+
+func (e *testExposureExchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return &types.Ticker{Last: 40000.0}, nil
+}
+
+func (e *testExposureExchange) QueryMarginAccount(ctx context.Context) (*types.MarginAccount, error) {
+	return &types.MarginAccount{
+		UserAssets: []types.MarginUserAsset{
+			{Asset: "BTC", Free: fixedpoint.NewFromFloat(0.5), Borrowed: fixedpoint.NewFromFloat(1.5), Interest: fixedpoint.NewFromFloat(0.01)},
+		},
+	}, nil
+}
+
+func TestEnvironment_NetExposure(t *testing.T) {
+	environ := NewEnvironment()
+
+	spot := &ExchangeSession{
+		Name: "binance",
+		Exchange: &testExposureExchange{balances: types.BalanceMap{
+			"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.8), Locked: fixedpoint.NewFromFloat(0.2)},
+		}},
+		markets:    map[string]types.Market{"BTCUSDT": {Symbol: "BTCUSDT"}},
+		lastPrices: map[string]float64{"BTCUSDT": 50000.0},
+	}
+
+	margin := &ExchangeSession{
+		Name:     "binance-margin",
+		Margin:   true,
+		Exchange: &testExposureExchange{},
+		markets:  map[string]types.Market{"BTCUSDT": {Symbol: "BTCUSDT"}},
+	}
+
+	empty := &ExchangeSession{
+		Name:     "max",
+		Exchange: &testExposureExchange{balances: types.BalanceMap{}},
+	}
+
+	environ.AddExchangeSession(spot.Name, spot)
+	environ.AddExchangeSession(margin.Name, margin)
+	environ.AddExchangeSession(empty.Name, empty)
+
+	exposure, err := environ.NetExposure(context.Background(), "btc")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "BTC", exposure.Asset)
+	assert.InDelta(t, 1.5, exposure.Long.Float64(), 1e-8)
+	assert.InDelta(t, 1.51, exposure.Short.Float64(), 1e-8)
+	assert.InDelta(t, -0.01, exposure.Net().Float64(), 1e-8)
+
+	if assert.Len(t, exposure.Sessions, 2) {
+		assert.Equal(t, types.AccountTypeSpot, exposure.Sessions[0].AccountType)
+		assert.InDelta(t, 50000.0, exposure.Sessions[0].Price.Float64(), 1e-8, "the last price is used")
+
+		assert.Equal(t, types.AccountTypeMargin, exposure.Sessions[1].AccountType)
+		assert.InDelta(t, 40000.0, exposure.Sessions[1].Price.Float64(), 1e-8, "the ticker is queried")
+	}
+
+	// 1.0 * 50000 - 1.01 * 40000
+	assert.InDelta(t, 9600.0, exposure.Notionals["USDT"].Float64(), 1e-4)
+}
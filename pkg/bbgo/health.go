@@ -0,0 +1,231 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultHealthDisconnectThreshold is the default duration a stream can stay disconnected before it's reported as unhealthy
+const DefaultHealthDisconnectThreshold = time.Minute
+
+// HealthConfig is the config of the health check server
+type HealthConfig struct {
+	// Listen is the bind address of the health check server, e.g., ":8081"
+	Listen string `json:"listen" yaml:"listen"`
+
+	// DisconnectThreshold is how long a stream can stay disconnected before the health check fails
+	DisconnectThreshold types.Duration `json:"disconnectThreshold,omitempty" yaml:"disconnectThreshold,omitempty"`
+
+	// Sessions are the required sessions, all sessions are required if it's not set
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+}
+
+// StreamStatus is the connectivity status of the session stream
+type StreamStatus struct {
+	Connected bool `json:"connected"`
+
+	// DisconnectedAt is the time the stream was disconnected, or the time we started waiting for the first connect
+	DisconnectedAt *time.Time `json:"disconnectedAt,omitempty"`
+
+	LastMarketDataTime *time.Time `json:"lastMarketDataTime,omitempty"`
+}
+
+// streamHealth tracks the connectivity and the market data events of the session stream
+type streamHealth struct {
+	mu                 sync.Mutex
+	connected          bool
+	disconnectedAt     time.Time
+	lastMarketDataTime time.Time
+}
+
+func newStreamHealth(stream types.Stream) *streamHealth {
+	h := &streamHealth{disconnectedAt: time.Now()}
+
+	stream.OnConnect(func() {
+		h.mu.Lock()
+		h.connected = true
+		h.mu.Unlock()
+	})
+
+	stream.OnDisconnect(func() {
+		h.mu.Lock()
+		if h.connected {
+			h.connected = false
+			h.disconnectedAt = time.Now()
+		}
+		h.mu.Unlock()
+	})
+
+	stream.OnKLine(func(kline types.KLine) { h.touch() })
+	stream.OnKLineClosed(func(kline types.KLine) { h.touch() })
+	stream.OnBookSnapshot(func(book types.OrderBook) { h.touch() })
+	stream.OnBookUpdate(func(book types.OrderBook) { h.touch() })
+	return h
+}
+
+func (h *streamHealth) touch() {
+	h.mu.Lock()
+	h.lastMarketDataTime = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *streamHealth) status() (status StreamStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status.Connected = h.connected
+	if !h.connected {
+		disconnectedAt := h.disconnectedAt
+		status.DisconnectedAt = &disconnectedAt
+	}
+
+	if !h.lastMarketDataTime.IsZero() {
+		lastMarketDataTime := h.lastMarketDataTime
+		status.LastMarketDataTime = &lastMarketDataTime
+	}
+
+	return status
+}
+
+// SessionHealth is the health status of an exchange session
+type SessionHealth struct {
+	StreamStatus
+
+	Required bool `json:"required"`
+	Healthy  bool `json:"healthy"`
+}
+
+// NotifierHealth is the circuit breaker status of a notifier, the notifications are dropped if the breaker is open.
+// The notifiers don't affect the overall health since the trading is not interrupted.
+type NotifierHealth struct {
+	CircuitBreaker string `json:"circuitBreaker"`
+	Dropped        int64  `json:"dropped"`
+}
+
+// HealthReport is the response of the health check endpoint
+type HealthReport struct {
+	Healthy   bool                      `json:"healthy"`
+	Syncing   SyncStatus                `json:"syncing"`
+	Sessions  map[string]SessionHealth  `json:"sessions"`
+	Notifiers map[string]NotifierHealth `json:"notifiers,omitempty"`
+}
+
+// ConfigureHealth sets up the health check config, the server is started when the environment connects the sessions
+func (environ *Environment) ConfigureHealth(conf *HealthConfig) {
+	environ.healthConfig = conf
+}
+
+// HealthReport checks the stream connectivity of the sessions,
+// a required session is unhealthy if its stream is disconnected longer than the threshold.
+func (environ *Environment) HealthReport(now time.Time) HealthReport {
+	threshold := DefaultHealthDisconnectThreshold
+	var required map[string]struct{}
+
+	if conf := environ.healthConfig; conf != nil {
+		if conf.DisconnectThreshold > 0 {
+			threshold = conf.DisconnectThreshold.Duration()
+		}
+
+		if len(conf.Sessions) > 0 {
+			required = make(map[string]struct{}, len(conf.Sessions))
+			for _, name := range conf.Sessions {
+				required[name] = struct{}{}
+			}
+		}
+	}
+
+	report := HealthReport{
+		Healthy:  true,
+		Syncing:  environ.IsSyncing(),
+		Sessions: make(map[string]SessionHealth),
+	}
+
+	for name, session := range environ.Sessions() {
+		health := SessionHealth{
+			StreamStatus: session.StreamStatus(),
+			Required:     true,
+			Healthy:      true,
+		}
+
+		if required != nil {
+			_, health.Required = required[name]
+		}
+
+		if !health.Connected {
+			health.Healthy = health.DisconnectedAt != nil && now.Sub(*health.DisconnectedAt) <= threshold
+		}
+
+		if health.Required && !health.Healthy {
+			report.Healthy = false
+		}
+
+		report.Sessions[name] = health
+	}
+
+	for name, breaker := range environ.circuitBreakers() {
+		if report.Notifiers == nil {
+			report.Notifiers = make(map[string]NotifierHealth)
+		}
+
+		report.Notifiers[name] = NotifierHealth{
+			CircuitBreaker: breaker.State().String(),
+			Dropped:        breaker.Dropped(),
+		}
+	}
+
+	return report
+}
+
+// HealthHandler returns the health check handler, it responds 503 if any of the required sessions is unhealthy
+func (environ *Environment) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := environ.HealthReport(time.Now())
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.WithError(err).Error("health report encode error")
+		}
+	})
+}
+
+func (environ *Environment) startHealthServer(ctx context.Context) error {
+	listener, err := net.Listen("tcp", environ.healthConfig.Listen)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", environ.HealthHandler())
+
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Close(); err != nil {
+			log.WithError(err).Error("health server close error")
+		}
+	}()
+
+	go func() {
+		log.Infof("health check server listening on %s", listener.Addr())
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("health server error")
+		}
+	}()
+
+	return nil
+}
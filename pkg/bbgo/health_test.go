@@ -0,0 +1,69 @@
+package bbgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_HealthReport(t *testing.T) {
+	environ := NewEnvironment()
+	environ.ConfigureHealth(&HealthConfig{
+		DisconnectThreshold: types.Duration(time.Minute),
+		Sessions:            []string{"binance"},
+	})
+
+	binanceStream := &testStream{}
+	maxStream := &testStream{}
+	environ.AddExchangeSession("binance", &ExchangeSession{Name: "binance", Stream: binanceStream, health: newStreamHealth(binanceStream)})
+	environ.AddExchangeSession("max", &ExchangeSession{Name: "max", Stream: maxStream, health: newStreamHealth(maxStream)})
+
+	now := time.Now()
+	report := environ.HealthReport(now)
+	assert.True(t, report.Healthy, "the stream is still waiting for the first connect")
+
+	report = environ.HealthReport(now.Add(2 * time.Minute))
+	assert.False(t, report.Healthy, "the stream is not connected longer than the threshold")
+	assert.False(t, report.Sessions["binance"].Healthy)
+
+	binanceStream.EmitConnect()
+	binanceStream.EmitKLine(types.KLine{Symbol: "BTCUSDT"})
+
+	report = environ.HealthReport(now.Add(2 * time.Minute))
+	assert.True(t, report.Healthy, "the disconnected max session is not required")
+	assert.True(t, report.Sessions["binance"].Connected)
+	assert.NotNil(t, report.Sessions["binance"].LastMarketDataTime)
+	assert.False(t, report.Sessions["max"].Healthy)
+	assert.False(t, report.Sessions["max"].Required)
+
+	binanceStream.EmitDisconnect()
+
+	recorder := httptest.NewRecorder()
+	environ.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, "the stream is just disconnected")
+
+	report = environ.HealthReport(time.Now().Add(2 * time.Minute))
+	assert.False(t, report.Healthy)
+
+	environ.ConfigureHealth(&HealthConfig{DisconnectThreshold: types.Duration(-time.Second)})
+	recorder = httptest.NewRecorder()
+	environ.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, "the default threshold should be used")
+
+	environ.ConfigureHealth(&HealthConfig{DisconnectThreshold: types.Duration(time.Nanosecond)})
+	time.Sleep(time.Millisecond)
+	recorder = httptest.NewRecorder()
+	environ.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var body HealthReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.False(t, body.Healthy)
+	assert.Len(t, body.Sessions, 2)
+}
@@ -0,0 +1,45 @@
+package bbgo
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+// the log formats of the logging config
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LoggingConfig configures the global logger
+type LoggingConfig struct {
+	// Format is "text" or "json", the json format is for the log ingestion, e.g., Loki or ELK
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// NewLogFormatter returns the log formatter of the format, the text formatter is returned for the empty format
+func NewLogFormatter(format string) (log.Formatter, error) {
+	switch format {
+	case "", LogFormatText:
+		return &prefixed.TextFormatter{}, nil
+
+	case LogFormatJSON:
+		return &log.JSONFormatter{}, nil
+
+	}
+
+	return nil, fmt.Errorf("unknown log format %s, valid formats are %s and %s", format, LogFormatText, LogFormatJSON)
+}
+
+// ConfigureLogging sets the formatter of the global logger by the logging config
+func ConfigureLogging(conf *LoggingConfig) error {
+	formatter, err := NewLogFormatter(conf.Format)
+	if err != nil {
+		return err
+	}
+
+	log.SetFormatter(formatter)
+	return nil
+}
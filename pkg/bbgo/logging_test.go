@@ -0,0 +1,29 @@
+package bbgo
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	formatter := log.StandardLogger().Formatter
+	defer log.SetFormatter(formatter)
+
+	if assert.NoError(t, ConfigureLogging(&LoggingConfig{Format: LogFormatJSON})) {
+		assert.IsType(t, &log.JSONFormatter{}, log.StandardLogger().Formatter)
+	}
+
+	assert.Error(t, ConfigureLogging(&LoggingConfig{Format: "xml"}))
+	assert.IsType(t, &log.JSONFormatter{}, log.StandardLogger().Formatter, "the formatter is kept if the format is invalid")
+}
+
+func TestExchangeSession_Logger(t *testing.T) {
+	// the sessions built without NewExchangeSession have no logger
+	session := &ExchangeSession{Name: "binance"}
+	assert.Equal(t, "binance", session.Logger().Data["session"])
+
+	session.logger = log.WithField("session", "max")
+	assert.Equal(t, "max", session.Logger().Data["session"])
+}
@@ -0,0 +1,81 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maintenanceMode is the pause flag shared by the environment and its sessions, the orders submitted through the order
+// executors and the order execution router are rejected with ErrMaintenanceMode while it's paused
+type maintenanceMode struct {
+	mu       sync.RWMutex
+	paused   bool
+	pausedAt time.Time
+}
+
+// pause sets the flag, it returns false if the mode is already paused
+func (m *maintenanceMode) pause() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.paused {
+		return false
+	}
+
+	m.paused = true
+	m.pausedAt = time.Now()
+	return true
+}
+
+// resume clears the flag, it returns the paused duration and false if the mode is not paused
+func (m *maintenanceMode) resume() (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.paused {
+		return 0, false
+	}
+
+	m.paused = false
+	return time.Since(m.pausedAt), true
+}
+
+func (m *maintenanceMode) isPaused() bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.paused
+}
+
+// Pause enters the maintenance mode, the strategy orders are rejected with ErrMaintenanceMode until Resume is called.
+// Unlike Shutdown, the streams stay connected, so the market data and the notifications of the manual trades still
+// work, and unlike the dry run, the orders are not simulated.
+func (environ *Environment) Pause() {
+	if !environ.maintenance.pause() {
+		return
+	}
+
+	log.Warnf("entered the maintenance mode, the strategy orders are blocked")
+	environ.Notify(":construction: bbgo entered the maintenance mode, the strategy orders are blocked")
+}
+
+// Resume leaves the maintenance mode, the strategy orders are submitted again
+func (environ *Environment) Resume() {
+	duration, ok := environ.maintenance.resume()
+	if !ok {
+		return
+	}
+
+	log.Infof("left the maintenance mode after %s", duration.Round(time.Second))
+	environ.Notify(":white_check_mark: bbgo left the maintenance mode after %s, the strategy orders are resumed", duration.Round(time.Second))
+}
+
+// IsPaused returns true if the environment is in the maintenance mode
+func (environ *Environment) IsPaused() bool {
+	return environ.maintenance.isPaused()
+}
@@ -0,0 +1,52 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_Pause(t *testing.T) {
+	environ, notifier := newTestEnvironment()
+	session := environ.AddExchangeSession("binance", &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testSubmitExchange{created: 1},
+		Stream:   &testStream{},
+	})
+	executor := &ExchangeOrderExecutor{Notifiability: environ.Notifiability, Session: session}
+
+	environ.Pause()
+	environ.Pause()
+	assert.True(t, environ.IsPaused())
+	if assert.Len(t, notifier.notifications, 1, "the notification should be sent once") {
+		assert.Contains(t, notifier.notifications[0].text, "entered the maintenance mode")
+	}
+
+	_, err := executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrMaintenanceMode, err)
+
+	_, err = session.submitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrMaintenanceMode, err)
+	assert.Len(t, notifier.notifications, 1, "the blocked orders should not be notified")
+
+	// the streams keep working, e.g., the manual trades are still notified
+	stream := session.Stream.(*testStream)
+	var trades int
+	stream.OnTradeUpdate(func(trade types.Trade) { trades++ })
+	stream.EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	assert.Equal(t, 1, trades)
+
+	environ.Resume()
+	environ.Resume()
+	assert.False(t, environ.IsPaused())
+	if assert.Len(t, notifier.notifications, 2) {
+		assert.Contains(t, notifier.notifications[1].text, "left the maintenance mode")
+	}
+
+	createdOrders, err := session.submitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+}
@@ -0,0 +1,205 @@
+package bbgo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MetricsConfig is the config of the prometheus metrics server
+type MetricsConfig struct {
+	// Listen is the bind address of the metrics server, e.g., ":9090"
+	Listen string `json:"listen" yaml:"listen"`
+}
+
+// Metrics is the prometheus metrics registry of the sessions, the methods are no-op on the nil metrics,
+// so that the callers don't have to check whether the metrics are configured.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	streamConnected     *prometheus.GaugeVec
+	streamMessages      *prometheus.CounterVec
+	orderSubmits        *prometheus.CounterVec
+	orderSubmitDuration *prometheus.HistogramVec
+	syncDuration        *prometheus.HistogramVec
+	syncLastSuccessTime *prometheus.GaugeVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		streamConnected: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bbgo_session_stream_connected",
+			Help: "Whether the session stream is connected, 1 for connected and 0 for disconnected",
+		}, []string{"session"}),
+
+		streamMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bbgo_session_stream_messages_total",
+			Help: "The number of the messages received from the session stream by the message type",
+		}, []string{"session", "type"}),
+
+		orderSubmits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bbgo_order_submits_total",
+			Help: "The number of the submitted orders by the result, success or error",
+		}, []string{"session", "result"}),
+
+		orderSubmitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bbgo_order_submit_duration_seconds",
+			Help:    "The latency of the order submit requests",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"session"}),
+
+		syncDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bbgo_session_sync_duration_seconds",
+			Help:    "The duration of the session trading data sync",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"session"}),
+
+		syncLastSuccessTime: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bbgo_session_sync_last_success_timestamp_seconds",
+			Help: "The unix time of the last successful session sync",
+		}, []string{"session"}),
+	}
+
+	m.registry.MustRegister(
+		m.streamConnected,
+		m.streamMessages,
+		m.orderSubmits,
+		m.orderSubmitDuration,
+		m.syncDuration,
+		m.syncLastSuccessTime,
+	)
+
+	return m
+}
+
+// Handler returns the handler of the metrics in the prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeStream registers the stream callbacks updating the connected gauge and the message counters
+func (m *Metrics) observeStream(session string, stream types.Stream) {
+	if m == nil {
+		return
+	}
+
+	connected := m.streamConnected.WithLabelValues(session)
+	connected.Set(0)
+
+	stream.OnConnect(func() { connected.Set(1) })
+	stream.OnDisconnect(func() { connected.Set(0) })
+
+	messages := func(messageType string) prometheus.Counter {
+		return m.streamMessages.WithLabelValues(session, messageType)
+	}
+
+	klines, books, trades, orders, balances := messages("kline"), messages("book"), messages("trade"), messages("order"), messages("balance")
+	stream.OnKLine(func(types.KLine) { klines.Inc() })
+	stream.OnKLineClosed(func(types.KLine) { klines.Inc() })
+	stream.OnBookSnapshot(func(types.OrderBook) { books.Inc() })
+	stream.OnBookUpdate(func(types.OrderBook) { books.Inc() })
+	stream.OnTradeUpdate(func(types.Trade) { trades.Inc() })
+	stream.OnOrderUpdate(func(types.Order) { orders.Inc() })
+	stream.OnBalanceUpdate(func(types.BalanceMap) { balances.Inc() })
+}
+
+// observeOrderSubmit records the latency of the submit request started at the start time,
+// the orders not created are counted as the errors
+func (m *Metrics) observeOrderSubmit(session string, start time.Time, numOrders, numCreated int) {
+	if m == nil {
+		return
+	}
+
+	m.orderSubmitDuration.WithLabelValues(session).Observe(time.Since(start).Seconds())
+	m.orderSubmits.WithLabelValues(session, "success").Add(float64(numCreated))
+	if numOrders > numCreated {
+		m.orderSubmits.WithLabelValues(session, "error").Add(float64(numOrders - numCreated))
+	}
+}
+
+// observeSync records the duration of the session sync started at the start time
+func (m *Metrics) observeSync(session string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	now := time.Now()
+	m.syncDuration.WithLabelValues(session).Observe(now.Sub(start).Seconds())
+	if err == nil {
+		m.syncLastSuccessTime.WithLabelValues(session).Set(float64(now.Unix()))
+	}
+}
+
+var (
+	notifierCircuitStateDesc = prometheus.NewDesc(
+		"bbgo_notifier_circuit_breaker_state",
+		"The circuit breaker state of the notifier, 0 for closed, 1 for open and 2 for half-open",
+		[]string{"notifier"}, nil)
+
+	notifierDroppedDesc = prometheus.NewDesc(
+		"bbgo_notifier_dropped_notifications_total",
+		"The number of the notifications dropped by the open circuit breaker of the notifier",
+		[]string{"notifier"}, nil)
+)
+
+// notifierCircuitCollector collects the circuit breaker states of the notifiers on scrape,
+// so that it doesn't depend on whether the notifiers are added before the metrics are configured
+type notifierCircuitCollector struct {
+	notifiability *Notifiability
+}
+
+func (c *notifierCircuitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- notifierCircuitStateDesc
+	ch <- notifierDroppedDesc
+}
+
+func (c *notifierCircuitCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, breaker := range c.notifiability.circuitBreakers() {
+		ch <- prometheus.MustNewConstMetric(notifierCircuitStateDesc, prometheus.GaugeValue, float64(breaker.State()), name)
+		ch <- prometheus.MustNewConstMetric(notifierDroppedDesc, prometheus.CounterValue, float64(breaker.Dropped()), name)
+	}
+}
+
+// ConfigureMetrics sets up the metrics registry, the server is started when the environment connects the sessions
+func (environ *Environment) ConfigureMetrics(conf *MetricsConfig) {
+	environ.metricsConfig = conf
+	environ.metrics = NewMetrics()
+	environ.metrics.registry.MustRegister(&notifierCircuitCollector{notifiability: &environ.Notifiability})
+}
+
+func (environ *Environment) startMetricsServer(ctx context.Context) error {
+	listener, err := net.Listen("tcp", environ.metricsConfig.Listen)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", environ.metrics.Handler())
+
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Close(); err != nil {
+			log.WithError(err).Error("metrics server close error")
+		}
+	}()
+
+	go func() {
+		log.Infof("metrics server listening on %s", listener.Addr())
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("metrics server error")
+		}
+	}()
+
+	return nil
+}
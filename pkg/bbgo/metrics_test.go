@@ -0,0 +1,113 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/notifier/webhooknotifier"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+type testSubmitExchange struct {
+	types.Exchange
+
+	created int
+	err     error
+}
+
+func (e *testSubmitExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for i := 0; i < e.created && i < len(orders); i++ {
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: orders[i]})
+	}
+
+	return createdOrders, e.err
+}
+
+func TestMetrics_ObserveStream(t *testing.T) {
+	metrics := NewMetrics()
+	stream := &testStream{}
+	metrics.observeStream("binance", stream)
+
+	connected := metrics.streamConnected.WithLabelValues("binance")
+	assert.Equal(t, 0.0, testutil.ToFloat64(connected))
+
+	stream.EmitConnect()
+	assert.Equal(t, 1.0, testutil.ToFloat64(connected))
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT"})
+	stream.EmitKLine(types.KLine{Symbol: "BTCUSDT"})
+	stream.EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.streamMessages.WithLabelValues("binance", "kline")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.streamMessages.WithLabelValues("binance", "trade")))
+
+	stream.EmitDisconnect()
+	assert.Equal(t, 0.0, testutil.ToFloat64(connected))
+}
+
+func TestMetrics_ObserveOrderSubmit(t *testing.T) {
+	metrics := NewMetrics()
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testSubmitExchange{created: 1, err: errors.New("insufficient balance")},
+		metrics:  metrics,
+	}
+
+	_, err := session.submitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"}, types.SubmitOrder{Symbol: "ETHUSDT"})
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.orderSubmits.WithLabelValues("binance", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.orderSubmits.WithLabelValues("binance", "error")))
+
+	// the nil metrics is no-op
+	session.metrics = nil
+	_, err = session.submitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Error(t, err)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.observeSync("max", time.Now().Add(-time.Second), nil)
+	metrics.observeSync("binance", time.Now(), errors.New("sync error"))
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `bbgo_session_sync_duration_seconds_count{session="binance"} 1`)
+	assert.Contains(t, string(body), `bbgo_session_sync_last_success_timestamp_seconds{session="max"}`)
+	assert.NotContains(t, string(body), `bbgo_session_sync_last_success_timestamp_seconds{session="binance"}`)
+}
+
+func TestMetrics_NotifierCircuitBreaker(t *testing.T) {
+	notifier := webhooknotifier.New("http://127.0.0.1:0", webhooknotifier.WithMaxRetries(0), webhooknotifier.WithCircuitBreaker(1, time.Hour))
+	breaker := notifier.CircuitBreaker()
+	breaker.Report(errors.New("server error"))
+	assert.Equal(t, util.ErrCircuitOpen, breaker.Allow())
+
+	environ := NewEnvironment()
+	environ.ConfigureMetrics(&MetricsConfig{})
+	environ.AddNotifier(notifier)
+	environ.AddNotifier(&testNotifier{})
+
+	recorder := httptest.NewRecorder()
+	environ.metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `bbgo_notifier_circuit_breaker_state{notifier="webhook"} 1`)
+	assert.Contains(t, string(body), `bbgo_notifier_dropped_notifications_total{notifier="webhook"} 1`)
+
+	report := environ.HealthReport(time.Now())
+	assert.True(t, report.Healthy)
+	assert.Equal(t, map[string]NotifierHealth{
+		"webhook": {CircuitBreaker: "open", Dropped: 1},
+	}, report.Notifiers)
+}
@@ -1,10 +1,141 @@
 package bbgo
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
 type Notifier interface {
 	NotifyTo(channel, format string, args ...interface{})
 	Notify(format string, args ...interface{})
 }
 
+// NotifierFactory creates the notifier from the JSON encoded config of the notifier in the custom notifications config
+type NotifierFactory func(config json.RawMessage) (Notifier, error)
+
+var registeredNotifiers = make(map[string]NotifierFactory)
+
+// RegisterNotifier registers the notifier factory by the name like RegisterStrategy, it's usually called in the init
+// function of the notifier package. The notifier is created if its name is configured in the custom notifications config:
+//
+//  notifications:
+//    custom:
+//      mynotifier:
+//        url: https://example.com
+func RegisterNotifier(name string, factory NotifierFactory) {
+	registeredNotifiers[name] = factory
+}
+
+// newCustomNotifiers creates the notifiers of the custom notifications config by the registered factories,
+// the notifiers are created in the order of the names
+func newCustomNotifiers(custom map[string]interface{}) (notifiers []Notifier, err error) {
+	var names []string
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		factory, ok := registeredNotifiers[name]
+		if !ok {
+			return nil, fmt.Errorf("notifier %s is not registered", name)
+		}
+
+		config, err := json.Marshal(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid config of notifier %s: %w", name, err)
+		}
+
+		notifier, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("can not create notifier %s: %w", name, err)
+		}
+
+		notifiers = append(notifiers, notifier)
+	}
+
+	return notifiers, nil
+}
+
+// SeverityNotifier is implemented by the notifiers that filter the notifications by the severity, e.g., the SMS notifier.
+// The notifications sent by Notify and NotifyTo are the routine reports with the info severity.
+type SeverityNotifier interface {
+	NotifyWithSeverity(severity types.Severity, channel, format string, args ...interface{})
+}
+
+// FormatNotifier is implemented by the notifiers that can emit the attached objects in a structured format,
+// e.g., the webhook notifier. The format is the hint of the ObjectChannelRouter.
+type FormatNotifier interface {
+	NotifyToWithFormat(outputFormat types.OutputFormat, channel, format string, args ...interface{})
+}
+
+// TestableNotifier is implemented by the notifiers that can send the test message synchronously and report the
+// delivery error, see Notifiability.TestNotifications
+type TestableNotifier interface {
+	TestNotify(ctx context.Context, message string) error
+}
+
+// CircuitBreakerNotifier is implemented by the HTTP notifiers that drop the notifications while the remote service
+// keeps failing, the breaker state is exposed by the health report and the metrics
+type CircuitBreakerNotifier interface {
+	CircuitBreaker() *util.CircuitBreaker
+}
+
+// NotifierTestResult is the delivery result of the test message of a notifier
+type NotifierTestResult struct {
+	// Notifier is the name of the notifier, e.g., slack, telegram
+	Notifier string
+
+	// Verified is false if the notifier does not implement TestableNotifier, the message is sent by Notify
+	// but the delivery can not be confirmed
+	Verified bool
+
+	Error error
+}
+
+func (r NotifierTestResult) String() string {
+	if r.Error != nil {
+		return fmt.Sprintf("%s: failed: %v", r.Notifier, r.Error)
+	}
+
+	if !r.Verified {
+		return fmt.Sprintf("%s: sent, the delivery is not verified", r.Notifier)
+	}
+
+	return fmt.Sprintf("%s: ok", r.Notifier)
+}
+
+// notifierName returns the name of the notifier from its package name, e.g., "slack" for *slacknotifier.Notifier
+func notifierName(notifier Notifier) string {
+	t := reflect.TypeOf(notifier)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	pkgPath := t.PkgPath()
+	if len(pkgPath) == 0 {
+		return t.String()
+	}
+
+	name := pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+	if name == "bbgo" {
+		return t.Name()
+	}
+
+	if trimmed := strings.TrimSuffix(name, "notifier"); len(trimmed) > 0 {
+		return trimmed
+	}
+
+	return name
+}
+
 type NullNotifier struct{}
 
 func (n *NullNotifier) NotifyTo(channel, format string, args ...interface{}) {}
@@ -12,10 +143,31 @@ func (n *NullNotifier) NotifyTo(channel, format string, args ...interface{}) {}
 func (n *NullNotifier) Notify(format string, args ...interface{}) {}
 
 type Notifiability struct {
-	notifiers            []Notifier
-	SessionChannelRouter *PatternChannelRouter `json:"-"`
-	SymbolChannelRouter  *PatternChannelRouter `json:"-"`
-	ObjectChannelRouter  *ObjectChannelRouter  `json:"-"`
+	notifiers             []Notifier
+	SessionChannelRouter  *PatternChannelRouter `json:"-"`
+	SymbolChannelRouter   *PatternChannelRouter `json:"-"`
+	StrategyChannelRouter *PatternChannelRouter `json:"-"`
+	ObjectChannelRouter   *ObjectChannelRouter  `json:"-"`
+
+	// limiter coalesces the similar notifications, the notifications are not limited if it's not set
+	limiter *notificationLimiter
+
+	// digest accumulates the notifications of the digest channels, all the channels are notified live if it's not set
+	digest *notificationDigest
+
+	// delivery sends the notifications to one of the notifiers by the failover order,
+	// the notifications are broadcast to all the notifiers if it's not set
+	delivery *failoverDelivery
+
+	// fallback routes the notifications missed by the routing modes, the default channel is used if it's not set
+	fallback *notificationFallback
+
+	// strategyRouting is the routing mode of the strategy notifications, see ForStrategy
+	strategyRouting string
+
+	// defaultChannel is the channel of the notifications without the channel, the default channel of the notifiers
+	// is used if it's empty
+	defaultChannel string
 }
 
 // RouteSession routes symbol name to channel
@@ -34,12 +186,100 @@ func (m *Notifiability) RouteSession(session string) (channel string, ok bool) {
 	return "", false
 }
 
-// RouteObject routes object to channel
+// RouteStrategy routes the strategy ID to channel
+func (m *Notifiability) RouteStrategy(strategyID string) (channel string, ok bool) {
+	if m.StrategyChannelRouter != nil {
+		return m.StrategyChannelRouter.Route(strategyID)
+	}
+	return "", false
+}
+
+// SetStrategyRouting sets the routing mode of the notifications sent by the strategies
+func (m *Notifiability) SetStrategyRouting(mode string) {
+	m.strategyRouting = mode
+}
+
+// ForStrategy returns the notifiability injected into the strategy, the notifications of the strategy are routed by
+// the strategy routing mode:
+//
+// - "$strategy" sends the notifications without the channel to the channel of the strategy ID pattern, the default
+//   channel is used if no pattern matches
+// - "$silent" mutes all the notifications of the strategy
+// - the other non-empty value is the channel of the notifications without the channel
+//
+// The notifiability itself is returned if the strategy routing is not configured.
+func (m *Notifiability) ForStrategy(strategyID string) *Notifiability {
+	if len(m.strategyRouting) == 0 {
+		return m
+	}
+
+	// the notifiers, the routers and the limiter are shared
+	n := *m
+
+	switch m.strategyRouting {
+	case "$silent":
+		n.notifiers = nil
+
+	case "$strategy":
+		channel, ok := m.RouteStrategy(strategyID)
+		if !ok {
+			return m
+		}
+		n.defaultChannel = channel
+
+	default:
+		n.defaultChannel = m.strategyRouting
+	}
+
+	return &n
+}
+
+// TestNotifications sends the message to the default channel of every notifier and returns the delivery results
+// in the order of the notifiers. The rate limit is bypassed so that the repeated tests are always sent.
+func (m *Notifiability) TestNotifications(ctx context.Context, message string) []NotifierTestResult {
+	var results []NotifierTestResult
+	for _, n := range m.notifiers {
+		result := NotifierTestResult{Notifier: notifierName(n)}
+		if tn, ok := n.(TestableNotifier); ok {
+			result.Verified = true
+			result.Error = tn.TestNotify(ctx, message)
+		} else {
+			n.Notify("%s", message)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// circuitBreakers returns the circuit breakers of the notifiers by the notifier names
+func (m *Notifiability) circuitBreakers() map[string]*util.CircuitBreaker {
+	breakers := make(map[string]*util.CircuitBreaker)
+	for _, n := range m.notifiers {
+		if cn, ok := n.(CircuitBreakerNotifier); ok {
+			breakers[notifierName(n)] = cn.CircuitBreaker()
+		}
+	}
+
+	return breakers
+}
+
+// RouteObject routes object to channel, the fallback chain is applied if no object route matches
 func (m *Notifiability) RouteObject(obj interface{}) (channel string, ok bool) {
+	return m.RouteSessionObject("", obj)
+}
+
+// RouteSessionObject routes the object of the session to channel by the object routes,
+// and then by the fallback chain, see RouteFallback
+func (m *Notifiability) RouteSessionObject(session string, obj interface{}) (channel string, ok bool) {
 	if m.ObjectChannelRouter != nil {
-		return m.ObjectChannelRouter.Route(obj)
+		if channel, ok = m.ObjectChannelRouter.Route(obj); ok {
+			return channel, ok
+		}
 	}
-	return "", false
+
+	return m.RouteFallback(session, obj)
 }
 
 // AddNotifier adds the notifier that implements the Notifier interface.
@@ -47,14 +287,126 @@ func (m *Notifiability) AddNotifier(notifier Notifier) {
 	m.notifiers = append(m.notifiers, notifier)
 }
 
+// SetRateLimit enables the rate limit of the notifications, the similar notifications of a channel
+// in the rate limit window are coalesced into one summary message.
+func (m *Notifiability) SetRateLimit(conf *NotificationRateLimitConfig) {
+	m.limiter = newNotificationLimiter(conf, func(channel, format string, args ...interface{}) {
+		if len(channel) == 0 {
+			m.notify(format, args...)
+		} else {
+			m.notifyTo(channel, format, args...)
+		}
+	})
+}
+
+// SetDigest switches the channels of the config to the digest mode, the trade, the order and the pnl report
+// notifications of the channels are summarized in a digest message sent on the interval instead of being sent live.
+func (m *Notifiability) SetDigest(conf *NotificationDigestConfig) {
+	m.digest = newNotificationDigest(conf, m.notifyTo)
+}
+
+// SetDeliveryPolicy sets the delivery policy of the notifications. The failover policy sends the notification to the
+// notifiers in the priority order until one succeeds, the critical notifications sent with the severity are always
+// broadcast to all the notifiers.
+func (m *Notifiability) SetDeliveryPolicy(conf *NotificationDeliveryConfig) {
+	if conf == nil || conf.Policy != DeliveryPolicyFailover {
+		m.delivery = nil
+		return
+	}
+
+	m.delivery = newFailoverDelivery(conf)
+}
+
 func (m *Notifiability) Notify(format string, args ...interface{}) {
-	for _, n := range m.notifiers {
-		n.Notify(format, args...)
+	if len(m.defaultChannel) > 0 {
+		m.NotifyTo(m.defaultChannel, format, args...)
+		return
 	}
+
+	if m.limiter != nil && !m.limiter.allow("", format, args...) {
+		return
+	}
+
+	m.notify(format, args...)
 }
 
 func (m *Notifiability) NotifyTo(channel, format string, args ...interface{}) {
+	if m.digest != nil && m.digest.add(channel, args...) {
+		return
+	}
+
+	if m.limiter != nil && !m.limiter.allow(channel, format, args...) {
+		return
+	}
+
+	m.notifyTo(channel, format, args...)
+}
+
+// NotifyCritical sends the critical notification, e.g., the strategy errors and the session disconnects
+func (m *Notifiability) NotifyCritical(format string, args ...interface{}) {
+	m.NotifyToWithSeverity(types.SeverityCritical, m.defaultChannel, format, args...)
+}
+
+// NotifyToWithSeverity sends the notification with the severity, the notifiers that do not support the severity
+// receive it as a normal notification. The default channel is used if the channel is empty.
+func (m *Notifiability) NotifyToWithSeverity(severity types.Severity, channel, format string, args ...interface{}) {
+	if m.limiter != nil && !m.limiter.allow(channel, format, args...) {
+		return
+	}
+
+	for _, n := range m.notifiers {
+		if sn, ok := n.(SeverityNotifier); ok {
+			sn.NotifyWithSeverity(severity, channel, format, args...)
+		} else if len(channel) == 0 {
+			n.Notify(format, args...)
+		} else {
+			n.NotifyTo(channel, format, args...)
+		}
+	}
+}
+
+// OutputFormat returns the format hint of the notified objects, defaults to text
+func (m *Notifiability) OutputFormat() types.OutputFormat {
+	if m.ObjectChannelRouter != nil {
+		return m.ObjectChannelRouter.Format()
+	}
+
+	return types.OutputFormatText
+}
+
+func (m *Notifiability) notify(format string, args ...interface{}) {
+	outputFormat := m.OutputFormat()
+	if m.delivery != nil {
+		m.delivery.deliver(m.notifiers, outputFormat, "", nil, format, args...)
+		return
+	}
+
+	for _, n := range m.notifiers {
+		if fn, ok := n.(FormatNotifier); ok {
+			fn.NotifyToWithFormat(outputFormat, "", format, args...)
+		} else {
+			n.Notify(format, args...)
+		}
+	}
+}
+
+func (m *Notifiability) notifyTo(channel, format string, args ...interface{}) {
+	outputFormat := m.OutputFormat()
+	catchAll := m.catchAllChannels()
+	if m.delivery != nil {
+		m.delivery.deliver(m.notifiers, outputFormat, channel, catchAll, format, args...)
+		return
+	}
+
 	for _, n := range m.notifiers {
-		n.NotifyTo(channel, format, args...)
+		// the catch-all channel is resolved by notifier, the empty channel is the default channel of the notifier
+		ch := notifierChannel(n, channel, catchAll)
+		if fn, ok := n.(FormatNotifier); ok {
+			fn.NotifyToWithFormat(outputFormat, ch, format, args...)
+		} else if len(ch) == 0 {
+			n.Notify(format, args...)
+		} else {
+			n.NotifyTo(ch, format, args...)
+		}
 	}
 }
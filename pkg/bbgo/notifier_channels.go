@@ -0,0 +1,107 @@
+package bbgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The event types of the notification channel routes
+const (
+	NotificationEventTrade       = "trade"
+	NotificationEventOrder       = "order"
+	NotificationEventSubmitOrder = "submitOrder"
+	NotificationEventPnL         = "pnl"
+)
+
+var notificationEvents = []string{NotificationEventTrade, NotificationEventOrder, NotificationEventSubmitOrder, NotificationEventPnL}
+
+// NotificationChannelRoute sends the events of the listed types to the channel in addition to the routing modes,
+// e.g., a personal channel receives all the events while a team channel receives only the order fills
+type NotificationChannelRoute struct {
+	Channel string   `json:"channel" yaml:"channel"`
+	Events  []string `json:"events" yaml:"events"`
+
+	// OrderStatus filters the order events by the order status, all the order updates are sent if it's empty
+	OrderStatus []types.OrderStatus `json:"orderStatus,omitempty" yaml:"orderStatus,omitempty"`
+}
+
+func (route NotificationChannelRoute) hasEvent(event string) bool {
+	for _, e := range route.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowOrder returns true if the order status is one of the order status filter
+func (route NotificationChannelRoute) allowOrder(order types.Order) bool {
+	if len(route.OrderStatus) == 0 {
+		return true
+	}
+
+	for _, status := range route.OrderStatus {
+		if status == order.Status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (route NotificationChannelRoute) validate() (problems []string) {
+	if len(route.Channel) == 0 {
+		problems = append(problems, "channel route has no channel")
+	} else if strings.HasPrefix(route.Channel, "$") {
+		problems = append(problems, fmt.Sprintf("channel route %s can not be a routing mode", route.Channel))
+	}
+
+	if len(route.Events) == 0 && len(route.Channel) > 0 {
+		problems = append(problems, fmt.Sprintf("channel route %s has no event", route.Channel))
+	}
+
+	for _, event := range route.Events {
+		known := false
+		for _, e := range notificationEvents {
+			if e == event {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown event %s of channel route %s, valid events are %s", event,
+				route.Channel, strings.Join(notificationEvents, ", ")))
+		}
+	}
+
+	for _, status := range route.OrderStatus {
+		switch status {
+		case types.OrderStatusNew, types.OrderStatusFilled, types.OrderStatusPartiallyFilled,
+			types.OrderStatusCanceled, types.OrderStatusRejected:
+
+		default:
+			problems = append(problems, fmt.Sprintf("unknown order status %s of channel route %s", status, route.Channel))
+		}
+	}
+
+	return problems
+}
+
+// channelRoutes returns the channel routes of the session that receive the event type
+func (environ *Environment) channelRoutes(session, event string) (routes []NotificationChannelRoute) {
+	environ.sessionsMutex.RLock()
+	sessionRoutes := environ.channelRoutings[session]
+	environ.sessionsMutex.RUnlock()
+
+	for _, route := range sessionRoutes {
+		if route.hasEvent(event) {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes
+}
@@ -0,0 +1,91 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_ConfigureNotificationRouting_Channels(t *testing.T) {
+	environ, notifier := newTestEnvironment("binance", "max")
+
+	var conf NotificationConfig
+	err := yaml.Unmarshal([]byte(`
+sessionChannels:
+  "^binance$": "#binance"
+routing:
+  trade: "$silent"
+  order: "$session"
+  pnL: "$silent"
+  channels:
+  - channel: "#me"
+    events: [trade, order, submitOrder, pnl]
+  - channel: "#team"
+    events: [order]
+    orderStatus: [FILLED]
+sessionRouting:
+  max:
+    channels:
+    - channel: "#max"
+      events: [trade]
+`), &conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, environ.ConfigureNotificationRouting(&conf))
+
+	channels := func() (channels []string) {
+		for _, n := range notifier.notifications {
+			channels = append(channels, n.channel)
+		}
+		notifier.notifications = nil
+		return channels
+	}
+
+	binance := environ.sessions["binance"]
+	stream := binance.Stream.(*testStream)
+
+	stream.EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	assert.Equal(t, []string{"#me"}, channels(), "the channel routes should be notified even if the mode is silent")
+
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, Status: types.OrderStatusNew})
+	assert.Equal(t, []string{"#binance", "#me"}, channels())
+
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, Status: types.OrderStatusFilled})
+	assert.Equal(t, []string{"#binance", "#me", "#team"}, channels())
+
+	environ.newSubmitOrderNotifier(binance)(types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, []string{"", "#me"}, channels())
+
+	environ.notifyPnLReport(binance, environ.pnlRoutings["binance"], &pnl.AverageCostPnlReport{Symbol: "BTCUSDT"})
+	assert.Equal(t, []string{"#me"}, channels())
+
+	// the session routing overrides the channel routes
+	environ.sessions["max"].Stream.(*testStream).EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	environ.sessions["max"].Stream.(*testStream).EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, Status: types.OrderStatusFilled})
+	assert.Equal(t, []string{"#max", ""}, channels())
+}
+
+func TestNotificationChannelRoute_Validate(t *testing.T) {
+	routing := &SlackNotificationRouting{
+		Channels: []NotificationChannelRoute{
+			{Channel: "#me", Events: []string{"trade", "fill"}},
+			{Channel: "$session", Events: []string{"order"}, OrderStatus: []types.OrderStatus{"DONE"}},
+			{Channel: "#team"},
+			{},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"unknown event fill of channel route #me, valid events are trade, order, submitOrder, pnl",
+		"channel route $session can not be a routing mode",
+		"unknown order status DONE of channel route $session",
+		"channel route #team has no event",
+		"channel route has no channel",
+	}, routing.validate())
+}
@@ -0,0 +1,195 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	// DeliveryPolicyBroadcast sends the notifications to all the notifiers, it's the default policy
+	DeliveryPolicyBroadcast = "broadcast"
+
+	// DeliveryPolicyFailover sends the notifications to the notifiers in the priority order until one succeeds
+	DeliveryPolicyFailover = "failover"
+)
+
+const defaultDeliveryCooldown = time.Minute
+
+// NotificationDeliveryConfig is the delivery policy of the notifications
+type NotificationDeliveryConfig struct {
+	// Policy is "broadcast" or "failover", defaults to broadcast
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Priority is the failover order of the notifiers by name, e.g., [slack, webhook]. The notifiers not listed are
+	// tried after the listed ones in the order they are configured.
+	Priority []string `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// FailureThreshold is the number of the consecutive failures to mark a notifier as down, defaults to 1
+	FailureThreshold int `json:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty"`
+
+	// Cooldown is the duration a down notifier is skipped by the failover, defaults to 1m
+	Cooldown types.Duration `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+}
+
+func (c *NotificationDeliveryConfig) validate() (problems []string) {
+	switch c.Policy {
+	case "", DeliveryPolicyBroadcast, DeliveryPolicyFailover:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown delivery policy %s", c.Policy))
+	}
+
+	if c.FailureThreshold < 0 {
+		problems = append(problems, "failureThreshold can not be negative")
+	}
+
+	if c.Cooldown < 0 {
+		problems = append(problems, "cooldown can not be negative")
+	}
+
+	return problems
+}
+
+// ReliableNotifier is implemented by the notifiers that deliver the notification synchronously and return the
+// delivery error, so that the failover delivery can move on to the next notifier when the delivery fails.
+// The output format is the hint of the ObjectChannelRouter like FormatNotifier.
+type ReliableNotifier interface {
+	TryNotifyTo(outputFormat types.OutputFormat, channel, format string, args ...interface{}) error
+}
+
+type notifierHealth struct {
+	failures    int
+	lastFailure time.Time
+}
+
+// failoverDelivery tracks the recent failures of the notifiers, the notifiers failed consecutively are skipped
+// until the cooldown ends
+type failoverDelivery struct {
+	mu sync.Mutex
+
+	priority         []string
+	failureThreshold int
+	cooldown         time.Duration
+
+	// health is the failures of the notifiers by their index in the notifiability, the notifiers are only appended,
+	// and they may not be comparable to be used as the keys
+	health map[int]*notifierHealth
+}
+
+func newFailoverDelivery(conf *NotificationDeliveryConfig) *failoverDelivery {
+	d := &failoverDelivery{
+		priority:         conf.Priority,
+		failureThreshold: conf.FailureThreshold,
+		cooldown:         conf.Cooldown.Duration(),
+		health:           make(map[int]*notifierHealth),
+	}
+
+	if d.failureThreshold <= 0 {
+		d.failureThreshold = 1
+	}
+
+	if d.cooldown <= 0 {
+		d.cooldown = defaultDeliveryCooldown
+	}
+
+	return d
+}
+
+// order returns the indexes of the notifiers sorted by the priority, the notifiers not listed in the priority keep
+// their order
+func (d *failoverDelivery) order(notifiers []Notifier) []int {
+	var ordered []int
+	var listed = make(map[int]struct{})
+	for _, name := range d.priority {
+		for i, n := range notifiers {
+			if _, ok := listed[i]; ok || notifierName(n) != name {
+				continue
+			}
+
+			listed[i] = struct{}{}
+			ordered = append(ordered, i)
+		}
+	}
+
+	for i := range notifiers {
+		if _, ok := listed[i]; !ok {
+			ordered = append(ordered, i)
+		}
+	}
+
+	return ordered
+}
+
+func (d *failoverDelivery) isDown(n int, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.health[n]
+	return ok && h.failures >= d.failureThreshold && now.Sub(h.lastFailure) < d.cooldown
+}
+
+func (d *failoverDelivery) report(n int, err error, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err == nil {
+		delete(d.health, n)
+		return
+	}
+
+	h, ok := d.health[n]
+	if !ok {
+		h = &notifierHealth{}
+		d.health[n] = h
+	}
+
+	h.failures++
+	h.lastFailure = now
+}
+
+// deliver sends the notification to the first notifier that succeeds. The known-down notifiers are tried last,
+// so that the notification is still delivered if all the notifiers were down. The notifiers that do not implement
+// ReliableNotifier can not report the delivery error, the delivery stops at them. The catch-all channels resolve
+// CatchAllChannel by notifier.
+func (d *failoverDelivery) deliver(notifiers []Notifier, outputFormat types.OutputFormat, channel string, catchAll map[string]string, format string, args ...interface{}) {
+	now := time.Now()
+
+	var up, down []int
+	for _, i := range d.order(notifiers) {
+		if d.isDown(i, now) {
+			down = append(down, i)
+		} else {
+			up = append(up, i)
+		}
+	}
+
+	for _, i := range append(up, down...) {
+		n := notifiers[i]
+		ch := notifierChannel(n, channel, catchAll)
+		rn, ok := n.(ReliableNotifier)
+		if !ok {
+			if len(ch) == 0 {
+				n.Notify(format, args...)
+			} else {
+				n.NotifyTo(ch, format, args...)
+			}
+			return
+		}
+
+		err := rn.TryNotifyTo(outputFormat, ch, format, args...)
+		d.report(i, err, time.Now())
+		if err == nil {
+			return
+		}
+
+		log.WithError(err).Warnf("%s notification delivery failed, failing over to the next notifier", notifierName(n))
+	}
+
+	if len(notifiers) > 0 {
+		log.WithField("channel", channel).Errorf("notification delivery failed on all the notifiers, format: %s", format)
+	}
+}
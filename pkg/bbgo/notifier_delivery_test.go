@@ -0,0 +1,106 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testReliableNotifier struct {
+	testNotifier
+	tries int
+	err   error
+}
+
+func (n *testReliableNotifier) TryNotifyTo(outputFormat types.OutputFormat, channel, format string, args ...interface{}) error {
+	n.tries++
+	if n.err != nil {
+		return n.err
+	}
+
+	n.notifications = append(n.notifications, testNotification{channel: channel, text: fmt.Sprintf(format, args...)})
+	return nil
+}
+
+func TestNotifiability_FailoverDelivery(t *testing.T) {
+	primary := &testReliableNotifier{err: errors.New("slack is down")}
+	backup := &testReliableNotifier{}
+	plain := &testNotifier{}
+
+	var m Notifiability
+	m.AddNotifier(primary)
+	m.AddNotifier(backup)
+	m.AddNotifier(plain)
+	m.SetDeliveryPolicy(&NotificationDeliveryConfig{
+		Policy:           DeliveryPolicyFailover,
+		FailureThreshold: 2,
+		Cooldown:         types.Duration(time.Minute),
+	})
+
+	m.Notify("order %s", "submitted")
+	m.NotifyTo("#trades", "trade %s", "BTCUSDT")
+	assert.Equal(t, 2, primary.tries)
+
+	// the primary notifier failed twice, it's skipped in the cooldown
+	m.Notify("order %s", "filled")
+	assert.Equal(t, 2, primary.tries)
+
+	assert.Equal(t, []testNotification{
+		{channel: "", text: "order submitted"},
+		{channel: "#trades", text: "trade BTCUSDT"},
+		{channel: "", text: "order filled"},
+	}, backup.notifications)
+
+	// the notifier that can not report the delivery error is the last resort before the down notifiers
+	backup.err = errors.New("webhook is down")
+	m.Notify("order %s", "canceled")
+	assert.Equal(t, []testNotification{{channel: "", text: "order canceled"}}, plain.notifications)
+	assert.Equal(t, 2, primary.tries)
+
+	// the critical notifications are broadcast
+	m.NotifyCritical("strategy %s error", "grid")
+	assert.Len(t, plain.notifications, 2)
+	assert.Len(t, primary.notifications, 1)
+	assert.Len(t, backup.notifications, 4)
+
+	m.SetDeliveryPolicy(&NotificationDeliveryConfig{Policy: DeliveryPolicyBroadcast})
+	m.Notify("bbgo %s", "stopped")
+	assert.Len(t, plain.notifications, 3)
+	assert.Len(t, primary.notifications, 2)
+}
+
+func TestFailoverDelivery(t *testing.T) {
+	primary := &testReliableNotifier{}
+	plain := &testNotifier{}
+	notifiers := []Notifier{primary, plain}
+
+	d := newFailoverDelivery(&NotificationDeliveryConfig{
+		Policy:   DeliveryPolicyFailover,
+		Priority: []string{"testNotifier"},
+	})
+	assert.Equal(t, []int{1, 0}, d.order(notifiers), "the listed notifiers are tried first")
+
+	d.deliver(notifiers, types.OutputFormatText, "", nil, "hello")
+	assert.Len(t, plain.notifications, 1)
+	assert.Equal(t, 0, primary.tries)
+
+	// the notifier is down until the cooldown ends, a success resets the failures
+	now := time.Now()
+	d.report(0, errors.New("timeout"), now)
+	assert.True(t, d.isDown(0, now.Add(time.Second)))
+	assert.False(t, d.isDown(0, now.Add(defaultDeliveryCooldown)))
+
+	d.report(0, nil, now)
+	assert.False(t, d.isDown(0, now))
+
+	// all the notifiers are down, they are still tried
+	primary.err = errors.New("timeout")
+	d.deliver([]Notifier{primary}, types.OutputFormatText, "", nil, "hello")
+	d.deliver([]Notifier{primary}, types.OutputFormatText, "", nil, "hello")
+	assert.Equal(t, 2, primary.tries)
+}
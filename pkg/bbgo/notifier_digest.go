@@ -0,0 +1,314 @@
+package bbgo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// DefaultNotificationDigestInterval is the digest interval if it's not configured
+const DefaultNotificationDigestInterval = 15 * time.Minute
+
+// NotificationDigestConfig switches the channels from the live mode to the digest mode. The trade, the order and the
+// pnl report notifications sent to a digest channel are accumulated, and a summary of them is sent on the interval.
+type NotificationDigestConfig struct {
+	// Interval is the digest interval, defaults to 15 minutes
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Channels are the channels in the digest mode, the other channels are notified live
+	Channels []string `json:"channels,omitempty" yaml:"channels,omitempty"`
+}
+
+func (c *NotificationDigestConfig) validate() (problems []string) {
+	if c.Interval < 0 {
+		problems = append(problems, "interval can not be negative")
+	}
+
+	if len(c.Channels) == 0 {
+		problems = append(problems, "no digest channel is configured")
+	}
+
+	for _, channel := range c.Channels {
+		if len(channel) == 0 {
+			problems = append(problems, "the digest channel can not be empty")
+		}
+	}
+
+	return problems
+}
+
+type tradeDigest struct {
+	trades        int
+	buyQuantity   float64
+	sellQuantity  float64
+	quoteQuantity float64
+}
+
+type orderDigest struct {
+	submitted int
+	updates   int
+	filled    int
+	canceled  int
+}
+
+type channelDigest struct {
+	start  time.Time
+	trades map[string]*tradeDigest
+	orders map[string]*orderDigest
+
+	// reports are the latest pnl reports of the symbols
+	reports map[string]*pnl.AverageCostPnlReport
+}
+
+func newChannelDigest(start time.Time) *channelDigest {
+	return &channelDigest{
+		start:   start,
+		trades:  make(map[string]*tradeDigest),
+		orders:  make(map[string]*orderDigest),
+		reports: make(map[string]*pnl.AverageCostPnlReport),
+	}
+}
+
+func (d *channelDigest) order(symbol string) *orderDigest {
+	o, ok := d.orders[symbol]
+	if !ok {
+		o = &orderDigest{}
+		d.orders[symbol] = o
+	}
+
+	return o
+}
+
+// add accumulates the object, it returns false if the object is not summarized by the digest
+func (d *channelDigest) add(obj interface{}) bool {
+	switch o := obj.(type) {
+	case types.Trade:
+		return d.add(&o)
+
+	case types.Order:
+		return d.add(&o)
+
+	case types.SubmitOrder:
+		return d.add(&o)
+
+	case *types.Trade:
+		t, ok := d.trades[o.Symbol]
+		if !ok {
+			t = &tradeDigest{}
+			d.trades[o.Symbol] = t
+		}
+
+		t.trades++
+		if o.Side == types.SideTypeBuy {
+			t.buyQuantity += o.Quantity
+		} else {
+			t.sellQuantity += o.Quantity
+		}
+
+		if o.QuoteQuantity > 0 {
+			t.quoteQuantity += o.QuoteQuantity
+		} else {
+			t.quoteQuantity += o.Price * o.Quantity
+		}
+
+	case *types.Order:
+		order := d.order(o.Symbol)
+		order.updates++
+		switch o.Status {
+		case types.OrderStatusFilled:
+			order.filled++
+		case types.OrderStatusCanceled, types.OrderStatusRejected:
+			order.canceled++
+		}
+
+	case *types.SubmitOrder:
+		d.order(o.Symbol).submitted++
+
+	case *pnl.AverageCostPnlReport:
+		d.reports[o.Symbol] = o
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// notificationDigest accumulates the trade, the order and the pnl report notifications of the digest channels,
+// the digest of a channel is sent and reset when the interval since its first notification ends.
+// The other notifications of the digest channels, e.g., the errors, are still sent live.
+type notificationDigest struct {
+	mu       sync.Mutex
+	interval time.Duration
+	channels map[string]struct{}
+	digests  map[string]*channelDigest
+
+	// send sends the digest message without the digest and the rate limit
+	send func(channel, format string, args ...interface{})
+}
+
+func newNotificationDigest(conf *NotificationDigestConfig, send func(channel, format string, args ...interface{})) *notificationDigest {
+	interval := conf.Interval.Duration()
+	if interval <= 0 {
+		interval = DefaultNotificationDigestInterval
+	}
+
+	digest := &notificationDigest{
+		interval: interval,
+		channels: make(map[string]struct{}),
+		digests:  make(map[string]*channelDigest),
+		send:     send,
+	}
+
+	for _, channel := range conf.Channels {
+		digest.channels[channel] = struct{}{}
+	}
+
+	return digest
+}
+
+// add returns true if the notification is accumulated in the digest of the channel instead of being sent
+func (g *notificationDigest) add(channel string, args ...interface{}) bool {
+	if len(args) == 0 || args[0] == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.channels[channel]; !ok {
+		return false
+	}
+
+	d, ok := g.digests[channel]
+	if !ok {
+		d = newChannelDigest(time.Now())
+	}
+
+	if !d.add(args[0]) {
+		return false
+	}
+
+	if !ok {
+		g.digests[channel] = d
+		time.AfterFunc(g.interval, func() {
+			g.flush(channel, d)
+		})
+	}
+
+	return true
+}
+
+func (g *notificationDigest) flush(channel string, d *channelDigest) {
+	g.mu.Lock()
+	if g.digests[channel] != d {
+		// the digest is already flushed
+		g.mu.Unlock()
+		return
+	}
+	delete(g.digests, channel)
+	g.mu.Unlock()
+
+	g.send(channel, "%s", renderDigest(d, time.Now()))
+}
+
+// flushAll sends the digests of all the channels without waiting for the intervals to end
+func (g *notificationDigest) flushAll() {
+	g.mu.Lock()
+	digests := make(map[string]*channelDigest, len(g.digests))
+	for channel, d := range g.digests {
+		digests[channel] = d
+	}
+	g.mu.Unlock()
+
+	for channel, d := range digests {
+		g.flush(channel, d)
+	}
+}
+
+// renderDigest renders the digest as the compact tables of the trades, the orders and the pnl by symbol
+func renderDigest(d *channelDigest, end time.Time) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, ":clipboard: Digest %s - %s\n", d.start.Format("2006-01-02 15:04:05"), end.Format("15:04:05"))
+	buf.WriteString("```\n")
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	sections := 0
+
+	if len(d.trades) > 0 {
+		fmt.Fprintln(w, "TRADES\tCOUNT\tBUY QTY\tSELL QTY\tQUOTE VOLUME")
+		for _, symbol := range sortedDigestSymbols(d.trades) {
+			t := d.trades[symbol]
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", symbol, t.trades,
+				formatDigestFloat(t.buyQuantity), formatDigestFloat(t.sellQuantity), util.FormatFloat(t.quoteQuantity, 2))
+		}
+		sections++
+	}
+
+	if len(d.orders) > 0 {
+		if sections > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, "ORDERS\tSUBMITTED\tUPDATES\tFILLED\tCANCELED")
+		for _, symbol := range sortedDigestSymbols(d.orders) {
+			o := d.orders[symbol]
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", symbol, o.submitted, o.updates, o.filled, o.canceled)
+		}
+		sections++
+	}
+
+	if len(d.reports) > 0 {
+		if sections > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, "PNL\tTRADES\tPROFIT\tUNREALIZED\tCURRENCY")
+		for _, symbol := range sortedDigestSymbols(d.reports) {
+			report := d.reports[symbol]
+			profit, unrealizedProfit, currency := report.Profit, report.UnrealizedProfit, report.Market.QuoteCurrency
+			if len(report.ReportingCurrency) > 0 && !report.ConversionMissing {
+				profit, unrealizedProfit, currency = report.ReportingProfit, report.ReportingUnrealizedProfit, report.ReportingCurrency
+			}
+
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", symbol, report.NumTrades,
+				util.FormatFloat(profit, 2), util.FormatFloat(unrealizedProfit, 2), currency)
+		}
+	}
+
+	_ = w.Flush()
+	buf.WriteString("```")
+	return buf.String()
+}
+
+func formatDigestFloat(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+func sortedDigestSymbols(m interface{}) (symbols []string) {
+	switch m := m.(type) {
+	case map[string]*tradeDigest:
+		for symbol := range m {
+			symbols = append(symbols, symbol)
+		}
+	case map[string]*orderDigest:
+		for symbol := range m {
+			symbols = append(symbols, symbol)
+		}
+	case map[string]*pnl.AverageCostPnlReport:
+		for symbol := range m {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
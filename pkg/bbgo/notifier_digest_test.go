@@ -0,0 +1,67 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestNotifiability_SetDigest(t *testing.T) {
+	notifier := &testRateLimitNotifier{}
+
+	var m Notifiability
+	m.AddNotifier(notifier)
+	m.SetDigest(&NotificationDigestConfig{
+		Interval: types.Duration(100 * time.Millisecond),
+		Channels: []string{"#digest"},
+	})
+
+	m.NotifyTo("#digest", "trade", &types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 20000, Quantity: 0.1})
+	m.NotifyTo("#digest", "trade", types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 21000, Quantity: 0.05, QuoteQuantity: 1050})
+	m.NotifyTo("#digest", "submit order", &types.SubmitOrder{Symbol: "ETHUSDT"})
+	m.NotifyTo("#digest", "order", &types.Order{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT"}, Status: types.OrderStatusFilled})
+	m.NotifyTo("#digest", "order", &types.Order{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT"}, Status: types.OrderStatusCanceled})
+	m.NotifyTo("#digest", "pnl", &pnl.AverageCostPnlReport{Symbol: "BTCUSDT", NumTrades: 2, Profit: 1.5, UnrealizedProfit: -0.25,
+		Market: types.Market{QuoteCurrency: "USDT"}})
+	m.NotifyTo("#digest", "strategy %s error", "grid")
+	m.NotifyTo("#orders", "order", &types.Order{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT"}})
+
+	assert.Equal(t, []string{"strategy grid error"}, notifier.texts("#digest"), "the other notifications should be sent live")
+	assert.Len(t, notifier.texts("#orders"), 1, "the live channel should not be digested")
+
+	time.Sleep(200 * time.Millisecond)
+
+	texts := notifier.texts("#digest")
+	if assert.Len(t, texts, 2) {
+		digest := texts[1]
+		assert.Contains(t, digest, "BTCUSDT  2      0.1      0.05      3050.00")
+		assert.Contains(t, digest, "ETHUSDT  1          2        1       1")
+		assert.Contains(t, digest, "BTCUSDT  2       1.50    -0.25       USDT")
+	}
+
+	// the accumulator is reset after the flush
+	m.NotifyTo("#digest", "trade", &types.Trade{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Price: 1500, Quantity: 1})
+	m.digest.flushAll()
+
+	texts = notifier.texts("#digest")
+	if assert.Len(t, texts, 3) {
+		assert.Contains(t, texts[2], "ETHUSDT  1      1        0         1500.00")
+		assert.NotContains(t, texts[2], "BTCUSDT")
+		assert.NotContains(t, texts[2], "ORDERS")
+	}
+
+	m.digest.flushAll()
+	assert.Len(t, notifier.texts("#digest"), 3, "the empty digest should not be sent")
+}
+
+func TestNotificationDigestConfig_Validate(t *testing.T) {
+	conf := &NotificationDigestConfig{Interval: types.Duration(-time.Minute)}
+	assert.Equal(t, []string{"interval can not be negative", "no digest channel is configured"}, conf.validate())
+
+	conf = &NotificationDigestConfig{Channels: []string{"#digest"}}
+	assert.Empty(t, conf.validate())
+}
@@ -0,0 +1,147 @@
+package bbgo
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The steps of the notification fallback chain
+const (
+	FallbackSymbol   = "$symbol"
+	FallbackSession  = "$session"
+	FallbackCatchAll = "$catchAll"
+)
+
+// CatchAllChannel is the channel resolved by the "$catchAll" fallback step, it's replaced by the catch-all channel of
+// each notifier when the notification is sent, the notifiers without the catch-all channel use their default channel
+const CatchAllChannel = "$catchAll"
+
+var defaultFallbackChain = []string{FallbackSymbol, FallbackSession, FallbackCatchAll}
+
+// NotificationFallbackConfig is the fallback chain of the trade, the order, the submit order and the pnl notifications
+// not routed by their routing modes, the default channel of the notifiers is used at the end of the chain
+type NotificationFallbackConfig struct {
+	// Chain is the order of the fallback steps, "$symbol", "$session" and "$catchAll", defaults to all of them in the order
+	Chain []string `json:"chain,omitempty" yaml:"chain,omitempty"`
+
+	// CatchAll is the catch-all channel of the notifiers by the notifier name, e.g., slack and discord
+	CatchAll map[string]string `json:"catchAll,omitempty" yaml:"catchAll,omitempty"`
+}
+
+func (c *NotificationFallbackConfig) validate() (problems []string) {
+	for _, step := range c.Chain {
+		switch step {
+		case FallbackSymbol, FallbackSession, FallbackCatchAll:
+
+		default:
+			problems = append(problems, fmt.Sprintf("unknown fallback step %s, valid steps are %s, %s and %s", step,
+				FallbackSymbol, FallbackSession, FallbackCatchAll))
+		}
+	}
+
+	for _, name := range sortedKeys(c.CatchAll) {
+		if len(c.CatchAll[name]) == 0 {
+			problems = append(problems, fmt.Sprintf("the catch-all channel of %s is empty", name))
+		}
+	}
+
+	return problems
+}
+
+// notificationFallback is the fallback chain applied by RouteFallback
+type notificationFallback struct {
+	chain    []string
+	catchAll map[string]string
+}
+
+// SetFallback sets the fallback chain of the unrouted notifications, the fallback is disabled if the config is nil
+func (m *Notifiability) SetFallback(conf *NotificationFallbackConfig) {
+	if conf == nil {
+		m.fallback = nil
+		return
+	}
+
+	chain := conf.Chain
+	if len(chain) == 0 {
+		chain = defaultFallbackChain
+	}
+
+	m.fallback = &notificationFallback{chain: chain, catchAll: conf.CatchAll}
+}
+
+// RouteFallback routes the object of the session by the fallback chain: the symbol of the object is routed by the
+// symbol channels, the session name is routed by the session channels, and CatchAllChannel is returned if a notifier
+// has the catch-all channel. The session step is skipped if the session is empty.
+func (m *Notifiability) RouteFallback(session string, obj interface{}) (channel string, ok bool) {
+	if m.fallback == nil {
+		return "", false
+	}
+
+	for _, step := range m.fallback.chain {
+		switch step {
+		case FallbackSymbol:
+			if symbol, found := objectSymbol(obj); found {
+				if channel, ok = m.RouteSymbol(symbol); ok {
+					return channel, ok
+				}
+			}
+
+		case FallbackSession:
+			if len(session) > 0 {
+				if channel, ok = m.RouteSession(session); ok {
+					return channel, ok
+				}
+			}
+
+		case FallbackCatchAll:
+			if len(m.fallback.catchAll) > 0 {
+				return CatchAllChannel, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// catchAllChannels returns the catch-all channels of the notifiers by the notifier name
+func (m *Notifiability) catchAllChannels() map[string]string {
+	if m.fallback == nil {
+		return nil
+	}
+
+	return m.fallback.catchAll
+}
+
+// notifierChannel returns the channel of the notifier, CatchAllChannel is replaced by the catch-all channel of the
+// notifier, or the empty channel, i.e., the default channel, if the notifier has no catch-all channel
+func notifierChannel(n Notifier, channel string, catchAll map[string]string) string {
+	if channel != CatchAllChannel {
+		return channel
+	}
+
+	return catchAll[notifierName(n)]
+}
+
+// objectSymbol returns the symbol of the routed objects
+func objectSymbol(obj interface{}) (string, bool) {
+	switch o := obj.(type) {
+	case *types.Trade:
+		return o.Symbol, true
+	case types.Trade:
+		return o.Symbol, true
+	case *types.Order:
+		return o.Symbol, true
+	case types.Order:
+		return o.Symbol, true
+	case *types.SubmitOrder:
+		return o.Symbol, true
+	case types.SubmitOrder:
+		return o.Symbol, true
+	case *pnl.AverageCostPnlReport:
+		return o.Symbol, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,99 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_ConfigureNotificationRouting_Fallback(t *testing.T) {
+	environ, notifier := newTestEnvironment("binance", "max")
+	other := &testRateLimitNotifier{}
+	environ.AddNotifier(other)
+
+	err := environ.ConfigureNotificationRouting(&NotificationConfig{
+		SymbolChannels:  map[string]string{"^BTC": "#btc"},
+		SessionChannels: map[string]string{"^binance$": "#binance"},
+		Routing: &SlackNotificationRouting{
+			Trade: "$symbol",
+			Order: "$session",
+			PnL:   "$symbol",
+		},
+		Fallback: &NotificationFallbackConfig{
+			CatchAll: map[string]string{"testNotifier": "#unrouted"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	channels := func() (channels []string) {
+		for _, n := range notifier.notifications {
+			channels = append(channels, n.channel)
+		}
+		notifier.notifications = nil
+		return channels
+	}
+
+	binanceStream := environ.sessions["binance"].Stream.(*testStream)
+	maxStream := environ.sessions["max"].Stream.(*testStream)
+
+	// the symbol route misses, the trade falls back to the session route
+	binanceStream.EmitTradeUpdate(types.Trade{Symbol: "ETHUSDT"})
+	assert.Equal(t, []string{"#binance"}, channels())
+
+	// the symbol and the session routes miss, the trade falls back to the catch-all channel of the notifier
+	maxStream.EmitTradeUpdate(types.Trade{Symbol: "ETHUSDT"})
+	assert.Equal(t, []string{"#unrouted"}, channels())
+	assert.Len(t, other.texts(""), 1, "the notifier without the catch-all channel should use its default channel")
+
+	// the session route misses, the order falls back to the symbol route
+	maxStream.EmitOrderUpdate(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}})
+	assert.Equal(t, []string{"#btc"}, channels())
+
+	environ.notifyPnLReport(environ.sessions["binance"], environ.pnlRoutings["binance"], &pnl.AverageCostPnlReport{Symbol: "ETHUSDT"})
+	assert.Equal(t, []string{"#binance"}, channels())
+
+	channel, ok := environ.RouteObject(&types.Trade{Symbol: "ETHUSDT"})
+	assert.True(t, ok)
+	assert.Equal(t, CatchAllChannel, channel, "the session step should be skipped without the session")
+
+	// the fallback is disabled by the reloaded routing without the fallback config
+	assert.NoError(t, environ.reloadNotificationRouting(&NotificationConfig{Routing: &SlackNotificationRouting{Trade: "$symbol"}}))
+	maxStream.EmitTradeUpdate(types.Trade{Symbol: "ETHUSDT"})
+	assert.Equal(t, []string{""}, channels())
+}
+
+func TestNotifiability_RouteFallback_Chain(t *testing.T) {
+	m := Notifiability{
+		SymbolChannelRouter:  NewPatternChannelRouter(map[string]string{"^BTC": "#btc"}),
+		SessionChannelRouter: NewPatternChannelRouter(map[string]string{"^binance$": "#binance"}),
+	}
+
+	channel, ok := m.RouteFallback("binance", &types.Trade{Symbol: "BTCUSDT"})
+	assert.False(t, ok, "the fallback is disabled without the config")
+	assert.Empty(t, channel)
+
+	m.SetFallback(&NotificationFallbackConfig{Chain: []string{FallbackSession, FallbackSymbol}})
+	channel, ok = m.RouteFallback("binance", &types.Trade{Symbol: "BTCUSDT"})
+	assert.True(t, ok)
+	assert.Equal(t, "#binance", channel, "the chain order should be followed")
+
+	channel, ok = m.RouteFallback("max", &types.Trade{Symbol: "ETHUSDT"})
+	assert.False(t, ok, "the catch-all step is not in the chain")
+}
+
+func TestNotificationFallbackConfig_Validate(t *testing.T) {
+	conf := &NotificationFallbackConfig{
+		Chain:    []string{"$symbol", "$default"},
+		CatchAll: map[string]string{"slack": ""},
+	}
+
+	assert.Equal(t, []string{
+		"unknown fallback step $default, valid steps are $symbol, $session and $catchAll",
+		"the catch-all channel of slack is empty",
+	}, conf.validate())
+}
@@ -0,0 +1,180 @@
+package bbgo
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// NotificationRateLimit limits the similar notifications sent to a channel in a time window
+type NotificationRateLimit struct {
+	// Window is the dedup window, the similar notifications in the window are coalesced into one summary
+	Window types.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+
+	// MaxMessages is the number of the similar notifications sent in a window before coalescing, defaults to 1
+	MaxMessages int `json:"maxMessages,omitempty" yaml:"maxMessages,omitempty"`
+}
+
+// NotificationRateLimitConfig is the default rate limit with the per-channel overrides
+type NotificationRateLimitConfig struct {
+	Window      types.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+	MaxMessages int            `json:"maxMessages,omitempty" yaml:"maxMessages,omitempty"`
+
+	// Channels overrides the rate limit by channel name
+	Channels map[string]*NotificationRateLimit `json:"channels,omitempty" yaml:"channels,omitempty"`
+}
+
+type notificationWindow struct {
+	channel    string
+	label      string
+	start      time.Time
+	sent       int
+	suppressed int
+	flushing   bool
+}
+
+// notificationLimiter coalesces the similar notifications of a channel,
+// the notifications are similar if they carry the same type of object, or they have the same format.
+// The first notifications in the window are sent with their objects, so the object routing and the attachments still work,
+// and the suppressed ones are summarized when the window ends.
+type notificationLimiter struct {
+	mu            sync.Mutex
+	defaultLimit  NotificationRateLimit
+	channelLimits map[string]NotificationRateLimit
+	windows       map[string]*notificationWindow
+
+	// send sends the summary message without the rate limit
+	send func(channel, format string, args ...interface{})
+}
+
+func newNotificationLimiter(conf *NotificationRateLimitConfig, send func(channel, format string, args ...interface{})) *notificationLimiter {
+	limiter := &notificationLimiter{
+		defaultLimit: NotificationRateLimit{
+			Window:      conf.Window,
+			MaxMessages: conf.MaxMessages,
+		},
+		channelLimits: make(map[string]NotificationRateLimit),
+		windows:       make(map[string]*notificationWindow),
+		send:          send,
+	}
+
+	for channel, limit := range conf.Channels {
+		if limit != nil {
+			limiter.channelLimits[channel] = *limit
+		}
+	}
+
+	return limiter
+}
+
+func (l *notificationLimiter) limit(channel string) NotificationRateLimit {
+	limit, ok := l.channelLimits[channel]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	if limit.MaxMessages <= 0 {
+		limit.MaxMessages = 1
+	}
+
+	return limit
+}
+
+// allow returns true if the notification should be sent
+func (l *notificationLimiter) allow(channel, format string, args ...interface{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit(channel)
+	if limit.Window <= 0 {
+		return true
+	}
+
+	key := channel + "\x00" + notificationKind(format, args...)
+	now := time.Now()
+
+	w, ok := l.windows[key]
+	if !ok || (!w.flushing && now.Sub(w.start) >= limit.Window.Duration()) {
+		l.windows[key] = &notificationWindow{
+			channel: channel,
+			label:   notificationLabel(args...),
+			start:   now,
+			sent:    1,
+		}
+		return true
+	}
+
+	if w.sent < limit.MaxMessages {
+		w.sent++
+		return true
+	}
+
+	w.suppressed++
+	if !w.flushing {
+		w.flushing = true
+		time.AfterFunc(w.start.Add(limit.Window.Duration()).Sub(now), func() {
+			l.flush(key, w)
+		})
+	}
+
+	return false
+}
+
+func (l *notificationLimiter) flush(key string, w *notificationWindow) {
+	l.mu.Lock()
+	if l.windows[key] == w {
+		delete(l.windows, key)
+	}
+	suppressed := w.suppressed
+	w.suppressed = 0
+	l.mu.Unlock()
+
+	if suppressed > 0 {
+		l.send(w.channel, "+%d more %s", suppressed, w.label)
+	}
+}
+
+// flushAll sends the summaries of all the windows without waiting for the windows to end
+func (l *notificationLimiter) flushAll() {
+	l.mu.Lock()
+	windows := make(map[string]*notificationWindow, len(l.windows))
+	for key, w := range l.windows {
+		windows[key] = w
+	}
+	l.mu.Unlock()
+
+	for key, w := range windows {
+		l.flush(key, w)
+	}
+}
+
+// notificationKind groups the notifications by the object type, the rendered text of an object varies,
+// the notifications without objects are grouped by the format.
+func notificationKind(format string, args ...interface{}) string {
+	if len(args) > 0 && args[0] != nil {
+		switch reflect.TypeOf(args[0]).Kind() {
+		case reflect.Ptr, reflect.Struct:
+			return fmt.Sprintf("%T", args[0])
+		}
+	}
+
+	return format
+}
+
+func notificationLabel(args ...interface{}) string {
+	if len(args) > 0 {
+		switch args[0].(type) {
+		case *types.Order, types.Order:
+			return "order updates"
+		case *types.Trade, types.Trade:
+			return "trade updates"
+		case *types.SubmitOrder, types.SubmitOrder:
+			return "submitted orders"
+		}
+	}
+
+	return "similar notifications"
+}
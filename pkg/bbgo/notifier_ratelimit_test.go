@@ -0,0 +1,86 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testRateLimitNotifier struct {
+	mu            sync.Mutex
+	notifications []testNotification
+	objects       []interface{}
+}
+
+func (n *testRateLimitNotifier) NotifyTo(channel, format string, args ...interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(args) > 0 {
+		if _, ok := args[0].(*types.Order); ok {
+			n.objects = append(n.objects, args[0])
+			args = nil
+		}
+	}
+
+	n.notifications = append(n.notifications, testNotification{channel: channel, text: fmt.Sprintf(format, args...)})
+}
+
+func (n *testRateLimitNotifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo("", format, args...)
+}
+
+func (n *testRateLimitNotifier) texts(channel string) (texts []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, notification := range n.notifications {
+		if notification.channel == channel {
+			texts = append(texts, notification.text)
+		}
+	}
+
+	return texts
+}
+
+func TestNotifiability_SetRateLimit(t *testing.T) {
+	notifier := &testRateLimitNotifier{}
+
+	var m Notifiability
+	m.AddNotifier(notifier)
+	m.SetRateLimit(&NotificationRateLimitConfig{
+		Window: types.Duration(100 * time.Millisecond),
+		Channels: map[string]*NotificationRateLimit{
+			"#orders": {Window: types.Duration(100 * time.Millisecond), MaxMessages: 2},
+			"#pnl":    {},
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		m.NotifyTo("#orders", "order update", &types.Order{OrderID: uint64(i)})
+		m.NotifyTo("#pnl", "pnl report %d", i)
+		m.Notify("trade %d", i)
+	}
+
+	m.Notify("something else")
+
+	assert.Equal(t, []string{"order update", "order update"}, notifier.texts("#orders"),
+		"the first 2 order updates should be sent")
+	assert.Len(t, notifier.objects, 2, "the objects of the representative messages should be kept")
+	assert.Len(t, notifier.texts("#pnl"), 5, "the channel without the window should not be limited")
+	assert.Equal(t, []string{"trade 0", "something else"}, notifier.texts(""))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, []string{"order update", "order update", "+3 more order updates"}, notifier.texts("#orders"))
+	assert.Equal(t, []string{"trade 0", "something else", "+4 more similar notifications"}, notifier.texts(""))
+
+	// a new window starts after the summary
+	m.NotifyTo("#orders", "order update", &types.Order{OrderID: 10})
+	assert.Len(t, notifier.texts("#orders"), 4)
+}
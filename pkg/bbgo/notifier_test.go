@@ -0,0 +1,227 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testSeverityNotifier struct {
+	testNotifier
+	severities []types.Severity
+}
+
+func (n *testSeverityNotifier) NotifyWithSeverity(severity types.Severity, channel, format string, args ...interface{}) {
+	n.severities = append(n.severities, severity)
+	n.notifications = append(n.notifications, testNotification{channel: channel, text: fmt.Sprintf(format, args...)})
+}
+
+func TestNotifiability_NotifyToWithSeverity(t *testing.T) {
+	notifier := &testNotifier{}
+	severityNotifier := &testSeverityNotifier{}
+
+	var m Notifiability
+	m.AddNotifier(notifier)
+	m.AddNotifier(severityNotifier)
+
+	m.NotifyCritical("strategy %s error", "grid")
+	m.NotifyToWithSeverity(types.SeverityWarning, "#alerts", "session %s disconnected", "binance")
+
+	assert.Equal(t, []testNotification{
+		{channel: "", text: "strategy grid error"},
+		{channel: "#alerts", text: "session binance disconnected"},
+	}, notifier.notifications, "the severity should be ignored by the normal notifiers")
+
+	assert.Equal(t, []types.Severity{types.SeverityCritical, types.SeverityWarning}, severityNotifier.severities)
+	assert.Len(t, severityNotifier.notifications, 2)
+}
+
+func TestNotifiability_ForStrategy(t *testing.T) {
+	notifier := &testNotifier{}
+
+	m := Notifiability{
+		StrategyChannelRouter: NewPatternChannelRouter(map[string]string{"^grid": "#grid"}),
+	}
+	m.AddNotifier(notifier)
+
+	assert.True(t, m.ForStrategy("grid") == &m, "the notifiability is shared if the strategy routing is not set")
+
+	m.SetStrategyRouting("$strategy")
+	m.ForStrategy("grid").Notify("grid %s", "started")
+	m.ForStrategy("grid").NotifyTo("#trades", "grid %s", "traded")
+	m.ForStrategy("bollmaker").Notify("bollmaker %s", "started")
+
+	m.SetStrategyRouting("#strategies")
+	m.ForStrategy("bollmaker").NotifyCritical("bollmaker %s", "error")
+
+	m.SetStrategyRouting("$silent")
+	m.ForStrategy("grid").Notify("grid %s", "stopped")
+	m.Notify("bbgo %s", "stopped")
+
+	assert.Equal(t, []testNotification{
+		{channel: "#grid", text: "grid started"},
+		{channel: "#trades", text: "grid traded"},
+		{channel: "", text: "bollmaker started"},
+		{channel: "#strategies", text: "bollmaker error"},
+		{channel: "", text: "bbgo stopped"},
+	}, notifier.notifications)
+}
+
+type testFormatNotifier struct {
+	testNotifier
+	formats []types.OutputFormat
+}
+
+func (n *testFormatNotifier) NotifyToWithFormat(outputFormat types.OutputFormat, channel, format string, args ...interface{}) {
+	n.formats = append(n.formats, outputFormat)
+	n.notifications = append(n.notifications, testNotification{channel: channel, text: fmt.Sprintf(format, args...)})
+}
+
+func TestNotifiability_OutputFormat(t *testing.T) {
+	notifier := &testNotifier{}
+	formatNotifier := &testFormatNotifier{}
+
+	m := Notifiability{ObjectChannelRouter: NewObjectChannelRouter()}
+	m.AddNotifier(notifier)
+	m.AddNotifier(formatNotifier)
+
+	m.Notify("trade %s", "BTCUSDT")
+	m.ObjectChannelRouter.SetFormat(types.OutputFormatJSON)
+	m.NotifyTo("#trades", "trade %s", "ETHUSDT")
+
+	assert.Len(t, notifier.notifications, 2)
+	assert.Equal(t, []types.OutputFormat{types.OutputFormatText, types.OutputFormatJSON}, formatNotifier.formats)
+	assert.Equal(t, "#trades", formatNotifier.notifications[1].channel)
+}
+
+type testTestableNotifier struct {
+	testNotifier
+	messages []string
+	err      error
+}
+
+func (n *testTestableNotifier) TestNotify(ctx context.Context, message string) error {
+	n.messages = append(n.messages, message)
+	return n.err
+}
+
+func TestNotifiability_TestNotifications(t *testing.T) {
+	notifier := &testNotifier{}
+	testable := &testTestableNotifier{}
+	failing := &testTestableNotifier{err: errors.New("channel not found")}
+
+	var m Notifiability
+	m.AddNotifier(notifier)
+	m.AddNotifier(testable)
+	m.AddNotifier(failing)
+	m.SetRateLimit(&NotificationRateLimitConfig{Window: types.Duration(time.Minute), MaxMessages: 1})
+
+	m.TestNotifications(context.Background(), "test 100%")
+	results := m.TestNotifications(context.Background(), "test 100%")
+
+	assert.Equal(t, []NotifierTestResult{
+		{Notifier: "testNotifier"},
+		{Notifier: "testTestableNotifier", Verified: true},
+		{Notifier: "testTestableNotifier", Verified: true, Error: failing.err},
+	}, results)
+
+	assert.Equal(t, []testNotification{
+		{channel: "", text: "test 100%"},
+		{channel: "", text: "test 100%"},
+	}, notifier.notifications, "the test notifications are not rate limited")
+	assert.Equal(t, []string{"test 100%", "test 100%"}, testable.messages)
+	assert.Empty(t, testable.notifications)
+
+	assert.Equal(t, "testNotifier: sent, the delivery is not verified", results[0].String())
+	assert.Equal(t, "testTestableNotifier: ok", results[1].String())
+	assert.Equal(t, "testTestableNotifier: failed: channel not found", results[2].String())
+}
+
+func TestRegisterNotifier(t *testing.T) {
+	var created *testNotifier
+	RegisterNotifier("test", func(config json.RawMessage) (Notifier, error) {
+		var conf struct {
+			Channel string `json:"channel"`
+		}
+
+		if err := json.Unmarshal(config, &conf); err != nil {
+			return nil, err
+		}
+
+		if len(conf.Channel) == 0 {
+			return nil, errors.New("channel is required")
+		}
+
+		created = &testNotifier{}
+		return created, nil
+	})
+	defer delete(registeredNotifiers, "test")
+
+	var config Config
+	err := yaml.Unmarshal([]byte(`
+notifications:
+  custom:
+    test:
+      channel: "#bbgo"
+`), &config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	environ := NewEnvironment()
+	if assert.NoError(t, environ.ConfigureNotificationSystem(&config)) && assert.NotNil(t, created) {
+		environ.NotifyTo("#bbgo", "session %s connected", "binance")
+		assert.Equal(t, []testNotification{{channel: "#bbgo", text: "session binance connected"}}, created.notifications)
+	}
+
+	config.Notifications.Custom["test"] = map[string]interface{}{}
+	assert.EqualError(t, NewEnvironment().ConfigureNotificationSystem(&config), "can not create notifier test: channel is required")
+
+	config.Notifications.Custom = map[string]interface{}{"unknown": nil}
+	assert.EqualError(t, NewEnvironment().ConfigureNotificationSystem(&config), "notifier unknown is not registered")
+}
+
+func TestEnvironment_ConfigureNotificationSystem_Disabled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-notifier")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	viper.Set("telegram-bot-token", "123456:invalid")
+	defer viper.Set("telegram-bot-token", "")
+
+	var config Config
+	err = yaml.Unmarshal([]byte(`
+notifications:
+  webhook:
+    url: "http://localhost:8080/bbgo"
+    enabled: false
+  file:
+    path: "`+filepath.Join(dir, "notifications.log")+`"
+  telegram:
+    enabled: false
+`), &config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the telegram bot is not created, otherwise the invalid token fails the configuration
+	environ := NewEnvironment()
+	if assert.NoError(t, environ.ConfigureNotificationSystem(&config)) {
+		assert.Len(t, environ.notifiers, 1, "only the file notifier is enabled")
+		assert.Empty(t, environ.interactions)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 type OrderExecutor interface {
@@ -41,7 +42,7 @@ func (e *ExchangeOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessi
 		return nil, err
 	}
 
-	return es.Exchange.SubmitOrders(ctx, formattedOrders...)
+	return es.submitOrders(ctx, formattedOrders...)
 }
 
 // ExchangeOrderExecutor is an order executor wrapper for single exchange instance.
@@ -51,6 +52,10 @@ type ExchangeOrderExecutor struct {
 
 	Session *ExchangeSession
 
+	// submitOrderNotifier notifies the submit orders by the submitOrder routing of the session,
+	// the submit orders are routed by the object routes if it's not set
+	submitOrderNotifier func(order types.SubmitOrder)
+
 	// private trade update callbacks
 	tradeUpdateCallbacks []func(trade types.Trade)
 
@@ -60,37 +65,40 @@ type ExchangeOrderExecutor struct {
 
 func (e *ExchangeOrderExecutor) notifySubmitOrders(orders ...types.SubmitOrder) {
 	for _, order := range orders {
+		if e.submitOrderNotifier != nil {
+			e.submitOrderNotifier(order)
+			continue
+		}
+
 		// pass submit order as an interface object.
-		channel, ok := e.RouteObject(&order)
+		text := util.RenderTemplate(defaultSubmitOrderReportTemplate, order)
+		channel, ok := e.RouteSessionObject(e.Session.Name, &order)
 		if ok {
-			e.NotifyTo(channel, ":memo: Submitting %s %s %s order with quantity: %s at price: %s", order.Symbol, order.Type, order.Side, order.QuantityString, order.PriceString, &order)
+			e.NotifyTo(channel, text, &order)
 		} else {
-			e.Notify(":memo: Submitting %s %s %s order with quantity: %s at price: %s", order.Symbol, order.Type, order.Side, order.QuantityString, order.PriceString, &order)
+			e.Notify(text, &order)
 		}
 	}
 }
 
 func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	// reject the orders before they're notified
+	if e.Session.maintenance.isPaused() {
+		return nil, ErrMaintenanceMode
+	}
+
 	formattedOrders, err := formatOrders(e.Session, orders)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, order := range formattedOrders {
-		// pass submit order as an interface object.
-		channel, ok := e.RouteObject(&order)
-		if ok {
-			e.NotifyTo(channel, ":memo: Submitting %s %s %s order with quantity: %s", order.Symbol, order.Type, order.Side, order.QuantityString, order)
-		} else {
-			e.Notify(":memo: Submitting %s %s %s order with quantity: %s", order.Symbol, order.Type, order.Side, order.QuantityString, order)
-		}
-
 		log.Infof("submitting order: %s", order.String())
 	}
 
 	e.notifySubmitOrders(formattedOrders...)
 
-	return e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	return e.Session.submitOrders(ctx, formattedOrders...)
 }
 
 type BasicRiskController struct {
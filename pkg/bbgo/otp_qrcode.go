@@ -0,0 +1,94 @@
+package bbgo
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/pquerna/otp"
+)
+
+// qrCodeQuietZone is the width of the blank border around the ascii qrcode, the scanners need it to locate the code
+const qrCodeQuietZone = 2
+
+// otpQRCodeOutput is where the qrcode of the generated one-time password key goes
+type otpQRCodeOutput struct {
+	// Dir is the directory of the qrcode png image, defaults to the temp directory
+	Dir string
+
+	// ASCII prints the qrcode to stdout instead of writing the png image, for the headless environments
+	ASCII bool
+}
+
+// write writes the qrcode of the key, it returns the path of the png image, or an empty path if the qrcode is printed
+func (o otpQRCodeOutput) write(key *otp.Key, name string) (string, error) {
+	if o.ASCII {
+		code, err := renderQRCodeASCII(key.URL())
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Println(code)
+		return "", nil
+	}
+
+	dir := o.Dir
+	if len(dir) == 0 {
+		dir = os.TempDir()
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	imagePath := filepath.Join(dir, name+".png")
+	if err := writeOTPKeyAsQRCodePNG(key, imagePath); err != nil {
+		return "", err
+	}
+
+	return imagePath, nil
+}
+
+// renderQRCodeASCII renders the qrcode with the half block characters, two modules a character vertically.
+// The light modules are drawn, so that the code can be scanned from a terminal with the dark background.
+func renderQRCodeASCII(content string) (string, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := code.Bounds()
+	size := bounds.Dx()
+
+	isLight := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= size || y >= size {
+			return true
+		}
+
+		r, g, b, _ := code.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return color.GrayModel.Convert(color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: 0xffff}).(color.Gray).Y > 0x7f
+	}
+
+	var sb strings.Builder
+	for y := -qrCodeQuietZone; y < size+qrCodeQuietZone; y += 2 {
+		for x := -qrCodeQuietZone; x < size+qrCodeQuietZone; x++ {
+			top, bottom := isLight(x, y), isLight(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
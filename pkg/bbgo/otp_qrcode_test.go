@@ -0,0 +1,48 @@
+package bbgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTPQRCodeOutput_Write(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "bbgo", AccountName: "test"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "bbgo-otp")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	imagePath, err := otpQRCodeOutput{Dir: filepath.Join(dir, "qrcode")}.write(key, "otp-123")
+	if assert.NoError(t, err) {
+		assert.Equal(t, filepath.Join(dir, "qrcode", "otp-123.png"), imagePath)
+		assert.FileExists(t, imagePath)
+	}
+}
+
+func TestRenderQRCodeASCII(t *testing.T) {
+	code, err := renderQRCodeASCII("otpauth://totp/bbgo:test?secret=JBSWY3DPEHPK3PXP")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	width := len([]rune(lines[0]))
+	for _, line := range lines {
+		assert.Equal(t, width, len([]rune(line)))
+	}
+
+	// two modules a line, the quiet zone is drawn on both sides
+	assert.Equal(t, (width+1)/2, len(lines))
+	assert.Equal(t, strings.Repeat("█", width), lines[0], "the quiet zone should be light")
+}
@@ -1,8 +1,6 @@
 package bbgo
 
 import (
-	"fmt"
-
 	"github.com/c9s/bbgo/pkg/service"
 )
 
@@ -21,20 +19,9 @@ type Persistence struct {
 	Facade *service.PersistenceServiceFacade `json:"-" yaml:"-"`
 }
 
+// backendService returns the persistence service of the type, an error is returned if it's not configured
 func (p *Persistence) backendService(t string) (service.PersistenceService, error) {
-	switch t {
-	case "json":
-		return p.Facade.Json, nil
-
-	case "redis":
-		return p.Facade.Redis, nil
-
-	case "memory":
-		return p.Facade.Memory, nil
-
-	}
-
-	return nil, fmt.Errorf("unsupported persistent type %s", t)
+	return p.Facade.Select(t)
 }
 
 func (p *Persistence) Load(val interface{}, subIDs ...string) error {
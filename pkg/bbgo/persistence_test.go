@@ -0,0 +1,25 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func TestPersistence_NotConfigured(t *testing.T) {
+	p := &Persistence{
+		PersistenceSelector: &PersistenceSelector{StoreID: "state", Type: "bolt"},
+		Facade: &service.PersistenceServiceFacade{
+			Memory: service.NewMemoryService(),
+		},
+	}
+
+	var val int
+	assert.EqualError(t, p.Load(&val), "persistence type bolt is not configured")
+	assert.EqualError(t, p.Save(1), "persistence type bolt is not configured")
+
+	p.PersistenceSelector.Type = "memory"
+	assert.NoError(t, p.Save(1))
+}
@@ -0,0 +1,177 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PnLReportConfig schedules the average cost PnL reports of the synced trades,
+// the reports are notified through the pnL routing of the session
+type PnLReportConfig struct {
+	// Interval is the interval between the reports, e.g., "1h"
+	Interval types.Duration `json:"interval" yaml:"interval"`
+
+	// Sessions are the sessions to report, all sessions are reported if it's not set
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Symbols are the symbols to report, the possible symbols of the session are reported if it's not set
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+}
+
+// runPnLReports notifies the PnL reports on every interval until the context is done
+func (environ *Environment) runPnLReports(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			environ.notifyPnLReports(ctx)
+		}
+	}
+}
+
+// notifyPnLReports generates the PnL reports of the configured sessions and symbols and notifies them
+func (environ *Environment) notifyPnLReports(ctx context.Context) {
+	conf := environ.pnlReportConfig
+
+	var sessionNames = conf.Sessions
+	if len(sessionNames) == 0 {
+		for name := range environ.Sessions() {
+			sessionNames = append(sessionNames, name)
+		}
+		sort.Strings(sessionNames)
+	}
+
+	for _, sessionName := range sessionNames {
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			log.Warnf("pnl report session %s not found", sessionName)
+			continue
+		}
+
+		mode := environ.pnlRouting(sessionName)
+		if mode == "$silent" && len(environ.channelRoutes(sessionName, NotificationEventPnL)) == 0 {
+			continue
+		}
+
+		symbols, err := getSessionSymbols(ctx, session, conf.Symbols...)
+		if err != nil {
+			log.WithError(err).Errorf("can not get the symbols of session %s for the pnl report", sessionName)
+			continue
+		}
+
+		for _, symbol := range symbols {
+			report, err := environ.GeneratePnLReport(ctx, sessionName, symbol, time.Time{})
+			if err != nil {
+				log.WithError(err).Errorf("can not generate the %s pnl report of session %s", symbol, sessionName)
+				continue
+			}
+
+			environ.notifyPnLReport(session, mode, report)
+		}
+	}
+}
+
+// MaxPnLReportRange is the max range of the on-demand PnL reports, the earlier start time is capped so that a mistyped
+// start time doesn't scan the whole trade history
+const MaxPnLReportRange = 90 * 24 * time.Hour
+
+// NotifyPnLReports generates the PnL reports of the symbol since the given time on demand, e.g., by the chat command,
+// and notifies them through the pnL routing of the sessions like the scheduled reports. All the sessions that have the
+// market of the symbol are reported if the session name is empty, and the sessions without the trades in the range
+// are skipped. The start time is capped by MaxPnLReportRange, the capped start time is returned with the reports of
+// the sessions.
+func (environ *Environment) NotifyPnLReports(ctx context.Context, sessionName, symbol string, since time.Time) (map[string]*pnl.AverageCostPnlReport, time.Time, error) {
+	if earliest := time.Now().Add(-MaxPnLReportRange); since.Before(earliest) {
+		log.Warnf("the %s pnl report since %s is capped to %s", symbol, since.Format(time.RFC3339), earliest.Format(time.RFC3339))
+		since = earliest
+	}
+
+	var sessions = environ.Sessions()
+	var sessionNames []string
+	if len(sessionName) > 0 {
+		if _, ok := sessions[sessionName]; !ok {
+			return nil, since, fmt.Errorf("exchange session %s not found", sessionName)
+		}
+
+		sessionNames = []string{sessionName}
+	} else {
+		for name, session := range sessions {
+			if _, ok := session.Market(symbol); ok {
+				sessionNames = append(sessionNames, name)
+			}
+		}
+		sort.Strings(sessionNames)
+	}
+
+	if len(sessionNames) == 0 {
+		return nil, since, fmt.Errorf("market %s not found in any session", symbol)
+	}
+
+	var reports = make(map[string]*pnl.AverageCostPnlReport)
+	for _, name := range sessionNames {
+		report, err := environ.GeneratePnLReport(ctx, name, symbol, since)
+		if err != nil {
+			return nil, since, err
+		}
+
+		if report.NumTrades == 0 {
+			log.Infof("no %s trades of session %s since %s, skipping the pnl report", symbol, name, since.Format(time.RFC3339))
+			continue
+		}
+
+		environ.notifyPnLReport(sessions[name], environ.pnlRouting(name), report)
+
+		reports[name] = report
+	}
+
+	return reports, since, nil
+}
+
+// notifyPnLReport notifies the report by the routing mode like the trade notifications, and then sends it to the
+// channel routes of the session. The mode other than "$silent", "$session" and "$symbol" is the channel name.
+func (environ *Environment) notifyPnLReport(session *ExchangeSession, mode string, report *pnl.AverageCostPnlReport) {
+	if mode != "$silent" {
+		environ.notifyPnLReportByMode(session, mode, report)
+	}
+
+	for _, route := range environ.channelRoutes(session.Name, NotificationEventPnL) {
+		environ.NotifyTo(route.Channel, ":moneybag: %s PnL report of session %s", report.Symbol, session.Name, report)
+	}
+}
+
+func (environ *Environment) notifyPnLReportByMode(session *ExchangeSession, mode string, report *pnl.AverageCostPnlReport) {
+	var channel string
+	var ok bool
+
+	switch mode {
+	case "$session":
+		channel, ok = environ.SessionChannelRouter.Route(session.Name)
+		if !ok {
+			channel, ok = environ.RouteFallback(session.Name, report)
+		}
+
+	case "$symbol":
+		channel, ok = environ.RouteSessionObject(session.Name, report)
+
+	default:
+		channel, ok = mode, len(mode) > 0
+	}
+
+	if ok {
+		environ.NotifyTo(channel, ":moneybag: %s PnL report of session %s", report.Symbol, session.Name, report)
+	} else {
+		environ.Notify(":moneybag: %s PnL report of session %s", report.Symbol, session.Name, report)
+	}
+}
@@ -0,0 +1,156 @@
+package bbgo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_NotifyPnLReports(t *testing.T) {
+	ctx := context.Background()
+	environ, notifier := newTestEnvironment("binance", "max")
+	environ.sessions["binance"].Exchange = &testPnLExchange{}
+	environ.sessions["max"].Exchange = &testPnLExchange{}
+
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, environ.TradeService.Insert(types.Trade{
+		ID: 1, OrderID: 1, Exchange: types.ExchangeBinance.String(), Symbol: "BTCUSDT",
+		Price: 100.5, Quantity: 1.5, QuoteQuantity: 150.75, Side: types.SideTypeBuy, IsBuyer: true,
+		Time: datatype.Time(time.Now().Add(-time.Hour)),
+	}))
+
+	err := environ.ConfigureNotificationRouting(&NotificationConfig{
+		SymbolChannels: map[string]string{"^BTC": "#btc"},
+		Routing:        &SlackNotificationRouting{PnL: "$symbol"},
+		SessionRoutings: map[string]*SlackNotificationRouting{
+			"max": {PnL: "$silent"},
+		},
+		PnLReport: &PnLReportConfig{
+			Interval: types.Duration(time.Hour),
+			Symbols:  []string{"BTCUSDT"},
+		},
+	})
+	assert.NoError(t, err)
+
+	environ.notifyPnLReports(ctx)
+
+	if assert.Len(t, notifier.notifications, 1, "the max session is silent") {
+		assert.Equal(t, "#btc", notifier.notifications[0].channel)
+		assert.True(t, strings.HasPrefix(notifier.notifications[0].text, ":moneybag: BTCUSDT PnL report of session binance"))
+	}
+
+	err = environ.ConfigureNotificationRouting(&NotificationConfig{
+		PnLReport: &PnLReportConfig{},
+	})
+	assert.Error(t, err, "the interval is required")
+}
+
+func TestEnvironment_NotifyPnLReports_OnDemand(t *testing.T) {
+	ctx := context.Background()
+	environ, notifier := newTestEnvironment("binance", "max")
+	for _, session := range environ.sessions {
+		session.Exchange = &testPnLExchange{}
+		session.markets = map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		}
+	}
+
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i, tradeTime := range []time.Time{now.Add(-time.Hour), now.AddDate(0, 0, -10)} {
+		assert.NoError(t, environ.TradeService.Insert(types.Trade{
+			ID: int64(i + 1), OrderID: 1, Exchange: types.ExchangeBinance.String(), Symbol: "BTCUSDT",
+			Price: 100.5, Quantity: 1.5, QuoteQuantity: 150.75, Side: types.SideTypeBuy, IsBuyer: true,
+			Time: datatype.Time(tradeTime),
+		}))
+	}
+
+	assert.NoError(t, environ.ConfigureNotificationRouting(&NotificationConfig{
+		Routing: &SlackNotificationRouting{PnL: "#pnl"},
+		SessionRoutings: map[string]*SlackNotificationRouting{
+			"max": {PnL: "$silent"},
+		},
+	}))
+
+	reports, since, err := environ.NotifyPnLReports(ctx, "", "BTCUSDT", now.AddDate(0, 0, -2))
+	if assert.NoError(t, err) && assert.Len(t, reports, 2) {
+		assert.Equal(t, 1, reports["binance"].NumTrades, "the trades before the start time are excluded")
+		assert.Equal(t, 1, reports["max"].NumTrades)
+	}
+	assert.Equal(t, now.AddDate(0, 0, -2), since)
+
+	if assert.Len(t, notifier.notifications, 1, "the max session is silent") {
+		assert.Equal(t, "#pnl", notifier.notifications[0].channel)
+	}
+
+	reports, since, err = environ.NotifyPnLReports(ctx, "binance", "BTCUSDT", time.Time{})
+	if assert.NoError(t, err) && assert.Len(t, reports, 1) {
+		assert.Equal(t, 2, reports["binance"].NumTrades)
+	}
+	assert.True(t, since.After(now.Add(-MaxPnLReportRange-time.Minute)), "the range is capped")
+
+	reports, _, err = environ.NotifyPnLReports(ctx, "binance", "BTCUSDT", now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, reports, "no trades in the range")
+
+	_, _, err = environ.NotifyPnLReports(ctx, "", "ETHUSDT", now.AddDate(0, 0, -2))
+	assert.Error(t, err)
+}
+
+func TestEnvironment_ConvertPnLReport(t *testing.T) {
+	ctx := context.Background()
+	environ := NewEnvironment()
+	environ.SetReportingCurrency("usdt")
+	assert.Equal(t, "USDT", environ.ReportingCurrency())
+
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testExposureExchange{},
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+			"USDTTWD": {Symbol: "USDTTWD", BaseCurrency: "USDT", QuoteCurrency: "TWD"},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 50000.0, "USDTTWD": 25.0},
+	}
+
+	report := &pnl.AverageCostPnlReport{
+		Symbol:           "ETHBTC",
+		Market:           types.Market{Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+		Profit:           0.01,
+		UnrealizedProfit: 0.02,
+	}
+	environ.ConvertPnLReport(ctx, session, report)
+	assert.Equal(t, "USDT", report.ReportingCurrency)
+	assert.Equal(t, 500.0, report.ReportingProfit)
+	assert.Equal(t, 1000.0, report.ReportingUnrealizedProfit)
+	assert.False(t, report.ConversionMissing)
+
+	report = &pnl.AverageCostPnlReport{
+		Symbol: "BTCTWD",
+		Market: types.Market{Symbol: "BTCTWD", BaseCurrency: "BTC", QuoteCurrency: "TWD"},
+		Profit: 2500.0,
+	}
+	environ.ConvertPnLReport(ctx, session, report)
+	assert.Equal(t, 100.0, report.ReportingProfit, "converted by the inverse market")
+
+	report = &pnl.AverageCostPnlReport{
+		Symbol: "ETHBUSD",
+		Market: types.Market{Symbol: "ETHBUSD", BaseCurrency: "ETH", QuoteCurrency: "BUSD"},
+		Profit: 10.0,
+	}
+	environ.ConvertPnLReport(ctx, session, report)
+	assert.True(t, report.ConversionMissing)
+}
@@ -0,0 +1,104 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// PriceSource quotes the price of an asset in a quote currency, the prices are used to value the pnl reports, the balance
+// snapshots and the exposures, e.g., an external price oracle for the illiquid assets that have no reliable ticker.
+type PriceSource interface {
+	// QueryPrice returns the price of the asset in the quote currency for the session being valued,
+	// false is returned if the source has no price of the pair
+	QueryPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool)
+}
+
+// PriceSourceFunc is the function adapter of the PriceSource interface
+type PriceSourceFunc func(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool)
+
+func (f PriceSourceFunc) QueryPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	return f(ctx, session, asset, quoteCurrency)
+}
+
+// ExchangeTickerPriceSource is the default price source, it quotes the last price of the asset market in the session
+// markets, or the inverse of the price of the inverse market. The ticker is queried if the stream has no price yet.
+type ExchangeTickerPriceSource struct{}
+
+func (s *ExchangeTickerPriceSource) QueryPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	return querySessionPrice(ctx, session, asset, quoteCurrency)
+}
+
+var defaultPriceSource PriceSource = &ExchangeTickerPriceSource{}
+
+// PriceSourceFactory creates the price source from the JSON encoded options of the price source config
+type PriceSourceFactory func(options json.RawMessage) (PriceSource, error)
+
+var registeredPriceSources = make(map[string]PriceSourceFactory)
+
+// RegisterPriceSource registers the price source factory by the name like RegisterNotifier, it's usually called in the
+// init function of the price source package. The price source is created if its name is configured in priceSources:
+//
+//  priceSources:
+//  - name: coingecko
+//    options:
+//      apiKey: ${COINGECKO_API_KEY}
+func RegisterPriceSource(name string, factory PriceSourceFactory) {
+	registeredPriceSources[name] = factory
+}
+
+// PriceSourceConfig is the config of a price source registered by RegisterPriceSource
+type PriceSourceConfig struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Options are passed to the factory of the price source
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ConfigurePriceSources creates the configured price sources by the registered factories, the sources are consulted in
+// the config order
+func (environ *Environment) ConfigurePriceSources(configs []PriceSourceConfig) error {
+	for _, conf := range configs {
+		factory, ok := registeredPriceSources[conf.Name]
+		if !ok {
+			return fmt.Errorf("price source %s is not registered", conf.Name)
+		}
+
+		options, err := json.Marshal(conf.Options)
+		if err != nil {
+			return fmt.Errorf("invalid options of price source %s: %w", conf.Name, err)
+		}
+
+		source, err := factory(options)
+		if err != nil {
+			return fmt.Errorf("can not create price source %s: %w", conf.Name, err)
+		}
+
+		environ.AddPriceSource(source)
+	}
+
+	return nil
+}
+
+// AddPriceSource adds the price source, the sources are consulted in the order they're added before the exchange ticker
+func (environ *Environment) AddPriceSource(source PriceSource) {
+	environ.priceSources = append(environ.priceSources, source)
+}
+
+// QueryPrice returns the price of the asset in the quote currency from the first price source that has a positive
+// price, the exchange ticker of the session is the fallback of the added sources
+func (environ *Environment) QueryPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	if asset == quoteCurrency {
+		return fixedpoint.NewFromFloat(1.0), true
+	}
+
+	for _, source := range environ.priceSources {
+		if price, ok := source.QueryPrice(ctx, session, asset, quoteCurrency); ok && price > 0 {
+			return price, true
+		}
+	}
+
+	return defaultPriceSource.QueryPrice(ctx, session, asset, quoteCurrency)
+}
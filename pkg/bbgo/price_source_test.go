@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testOraclePriceSource quotes the fixed prices in USDT
+type testOraclePriceSource struct {
+	Prices map[string]float64 `json:"prices"`
+}
+
+func (s *testOraclePriceSource) QueryPrice(ctx context.Context, session *ExchangeSession, asset, quoteCurrency string) (fixedpoint.Value, bool) {
+	price, ok := s.Prices[asset]
+	if !ok || quoteCurrency != "USDT" {
+		return 0, false
+	}
+
+	return fixedpoint.NewFromFloat(price), true
+}
+
+func TestEnvironment_QueryPrice(t *testing.T) {
+	RegisterPriceSource("test-oracle", func(options json.RawMessage) (PriceSource, error) {
+		var source testOraclePriceSource
+		return &source, json.Unmarshal(options, &source)
+	})
+
+	ctx := context.Background()
+	environ := NewEnvironment()
+	environ.SetReportingCurrency("USDT")
+
+	err := environ.ConfigurePriceSources([]PriceSourceConfig{{Name: "unknown"}})
+	assert.EqualError(t, err, "price source unknown is not registered")
+
+	err = environ.ConfigurePriceSources([]PriceSourceConfig{
+		{Name: "test-oracle", Options: map[string]interface{}{"prices": map[string]interface{}{"XYZ": 2.5, "BTC": 0}}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testExposureExchange{},
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 50000.0},
+	}
+
+	price, ok := environ.QueryPrice(ctx, session, "XYZ", "USDT")
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, price.Float64())
+
+	price, ok = environ.QueryPrice(ctx, session, "BTC", "USDT")
+	assert.True(t, ok)
+	assert.Equal(t, 50000.0, price.Float64(), "the zero price falls back to the exchange ticker")
+
+	_, ok = environ.QueryPrice(ctx, session, "XYZ", "TWD")
+	assert.False(t, ok)
+
+	// the reporting currency conversion consults the price sources
+	report := &pnl.AverageCostPnlReport{
+		Symbol: "ABCXYZ",
+		Market: types.Market{Symbol: "ABCXYZ", BaseCurrency: "ABC", QuoteCurrency: "XYZ"},
+		Profit: 10.0,
+	}
+	environ.ConvertPnLReport(ctx, session, report)
+	assert.False(t, report.ConversionMissing)
+	assert.Equal(t, 25.0, report.ReportingProfit)
+}
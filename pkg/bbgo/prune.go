@@ -0,0 +1,92 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// PruneResult is the number of the records deleted from a session symbol
+type PruneResult struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+	Trades  int64  `json:"trades"`
+	Orders  int64  `json:"orders"`
+}
+
+// PruneSession deletes the trades and the orders of the session symbols before the given time, all the synced symbols
+// of the session are pruned if no symbol is given. The time can not be later than the sync cursor of any symbol,
+// otherwise the deleted records would be synced again, and nothing is deleted in that case.
+func (environ *Environment) PruneSession(ctx context.Context, session *ExchangeSession, until time.Time, symbols ...string) ([]PruneResult, error) {
+	if environ.SyncService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
+
+	cursors, err := environ.pruneCursors(session, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cursor := range cursors {
+		if syncedAt := cursor.SyncedAt.Time(); until.After(syncedAt) {
+			return nil, fmt.Errorf("can not prune %s %s records before %s, the symbol is only synced until %s",
+				session.Name, cursor.Symbol, until.Format(time.RFC3339), syncedAt.Format(time.RFC3339))
+		}
+	}
+
+	var results []PruneResult
+	for _, cursor := range cursors {
+		result := PruneResult{Session: session.Name, Symbol: cursor.Symbol}
+
+		result.Trades, err = environ.TradeService.DeleteBefore(ctx, session.Exchange, cursor.Symbol, until)
+		if err != nil {
+			return results, err
+		}
+
+		result.Orders, err = environ.OrderService.DeleteBefore(ctx, session.Exchange, cursor.Symbol, until)
+		if err != nil {
+			return results, err
+		}
+
+		log.Infof("pruned %s %s records before %s: %d trades, %d orders", session.Name, cursor.Symbol, until, result.Trades, result.Orders)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// pruneCursors loads the sync cursors of the symbols to prune, the symbols never synced can not be pruned
+func (environ *Environment) pruneCursors(session *ExchangeSession, symbols []string) ([]service.SyncCursor, error) {
+	if len(symbols) == 0 {
+		return environ.SyncService.CursorService.QueryBySession(session.Name)
+	}
+
+	var cursors []service.SyncCursor
+	for _, symbol := range symbols {
+		cursor, err := environ.SyncService.CursorService.Load(session.Name, symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		if cursor == nil {
+			return nil, fmt.Errorf("can not prune %s %s records, the symbol is never synced", session.Name, symbol)
+		}
+
+		cursors = append(cursors, *cursor)
+	}
+
+	return cursors, nil
+}
+
+// OptimizeDatabase reclaims the space of the deleted records after the sessions are pruned
+func (environ *Environment) OptimizeDatabase(ctx context.Context) error {
+	if environ.DatabaseService == nil {
+		return ErrDatabaseNotConfigured
+	}
+
+	return environ.DatabaseService.Optimize(ctx, "trades", "orders")
+}
@@ -0,0 +1,65 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_PruneSession(t *testing.T) {
+	ctx := context.Background()
+
+	environ, _ := newTestEnvironment("binance")
+	session := environ.sessions["binance"]
+	session.Exchange = &testBalanceSnapshotExchange{}
+
+	_, err := environ.PruneSession(ctx, session, time.Now())
+	assert.Equal(t, ErrDatabaseNotConfigured, err)
+
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	for i, tradeTime := range []time.Time{now.Add(-3 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Minute)} {
+		assert.NoError(t, environ.TradeService.Insert(types.Trade{
+			ID:       int64(i + 1),
+			Exchange: types.ExchangeBinance.String(),
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Time:     datatype.Time(tradeTime),
+		}))
+	}
+
+	assert.NoError(t, environ.SyncService.CursorService.Save(service.SyncCursor{
+		Session:     "binance",
+		Exchange:    types.ExchangeBinance,
+		Symbol:      "BTCUSDT",
+		LastTradeID: 3,
+		SyncedAt:    datatype.Time(now.Add(-time.Hour)),
+	}))
+
+	_, err = environ.PruneSession(ctx, session, now, "BTCUSDT")
+	assert.Error(t, err, "the records after the sync cursor should not be deleted")
+
+	_, err = environ.PruneSession(ctx, session, now.Add(-2*time.Hour), "ETHUSDT")
+	assert.Error(t, err, "the symbol is never synced")
+
+	results, err := environ.PruneSession(ctx, session, now.Add(-90*time.Minute))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []PruneResult{{Session: "binance", Symbol: "BTCUSDT", Trades: 2}}, results)
+	}
+
+	assert.NoError(t, environ.OptimizeDatabase(ctx))
+
+	trades, err := environ.TradeService.QueryLast(types.ExchangeBinance, "BTCUSDT", false, false, 10)
+	if assert.NoError(t, err) && assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(3), trades[0].ID)
+	}
+}
@@ -0,0 +1,286 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	// defaultReconnectBackoff is the first wait after a disconnect,
+	// it also gives the streams that reconnect by themselves a chance to recover.
+	defaultReconnectBackoff = 10 * time.Second
+
+	defaultMaxReconnectBackoff = 5 * time.Minute
+)
+
+// ReconnectConfig is the stream reconnect config of the exchange session
+type ReconnectConfig struct {
+	// MaxRetries is the max number of the connect attempts in a row, 0 means retrying forever
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// MaxBackoff is the ceiling of the exponential backoff between the connect attempts
+	MaxBackoff types.Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+}
+
+// StreamConnectionConfig tunes the websocket connection of the session stream, the stream defaults are kept if the
+// fields are not set
+type StreamConnectionConfig struct {
+	// ConnectTimeout is the deadline of each connect attempt, so that a stuck handshake is retried instead of
+	// hanging the startup
+	ConnectTimeout types.Duration `json:"connectTimeout,omitempty" yaml:"connectTimeout,omitempty"`
+
+	// KeepaliveInterval is the interval of the websocket ping messages
+	KeepaliveInterval types.Duration `json:"keepaliveInterval,omitempty" yaml:"keepaliveInterval,omitempty"`
+}
+
+func (c *StreamConnectionConfig) validate() (problems []string) {
+	if c.ConnectTimeout < 0 {
+		problems = append(problems, "connectTimeout can not be negative")
+	}
+
+	if c.KeepaliveInterval < 0 {
+		problems = append(problems, "keepaliveInterval can not be negative")
+	}
+
+	return problems
+}
+
+// applyConnectionConfig applies the connection config to the session stream before it's connected
+func applyConnectionConfig(session *ExchangeSession) error {
+	conf := session.Connection
+	if conf == nil {
+		return nil
+	}
+
+	stream, ok := baseStream(session.Stream).(types.TunableStream)
+	if !ok {
+		return fmt.Errorf("the stream of session %s does not support the connection config", session.Name)
+	}
+
+	stream.SetConnectionOptions(types.StreamConnectionOptions{
+		ConnectTimeout:    conf.ConnectTimeout.Duration(),
+		KeepaliveInterval: conf.KeepaliveInterval.Duration(),
+	})
+	return nil
+}
+
+// streamReconnector connects the session stream with retries,
+// and reconnects the stream when the stream is disconnected and does not recover by itself.
+type streamReconnector struct {
+	session *ExchangeSession
+	notify  func(severity types.Severity, format string, args ...interface{})
+	logger  *log.Entry
+
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu           sync.Mutex
+	connected    bool
+	disconnected bool
+
+	// reconnecting is set when the reconnector is closing and connecting the stream,
+	// the disconnect events emitted by the close are ignored.
+	reconnecting bool
+
+	// suspended is set when the stream is closed by the subscription schedule,
+	// the stream is not reconnected until it's resumed.
+	suspended bool
+
+	disconnectC chan struct{}
+}
+
+func newStreamReconnector(session *ExchangeSession, notify func(severity types.Severity, format string, args ...interface{})) *streamReconnector {
+	r := &streamReconnector{
+		session:     session,
+		notify:      notify,
+		logger:      log.WithField("session", session.Name),
+		backoff:     defaultReconnectBackoff,
+		maxBackoff:  defaultMaxReconnectBackoff,
+		disconnectC: make(chan struct{}, 1),
+	}
+
+	if conf := session.Reconnect; conf != nil {
+		r.maxRetries = conf.MaxRetries
+		if conf.MaxBackoff > 0 {
+			r.maxBackoff = conf.MaxBackoff.Duration()
+		}
+	}
+
+	if r.backoff > r.maxBackoff {
+		r.backoff = r.maxBackoff
+	}
+
+	session.Stream.OnConnect(r.handleConnect)
+	session.Stream.OnDisconnect(r.handleDisconnect)
+	return r
+}
+
+func (r *streamReconnector) handleConnect() {
+	r.mu.Lock()
+	reconnected := r.disconnected
+	r.connected = true
+	r.disconnected = false
+	r.mu.Unlock()
+
+	if reconnected {
+		r.notify(types.SeverityInfo, "exchange session %s stream is reconnected", r.session.Name)
+	}
+}
+
+func (r *streamReconnector) handleDisconnect() {
+	r.mu.Lock()
+	if r.reconnecting || r.suspended {
+		r.mu.Unlock()
+		return
+	}
+
+	r.connected = false
+	r.disconnected = true
+	r.mu.Unlock()
+
+	r.notify(types.SeverityCritical, "exchange session %s stream is disconnected", r.session.Name)
+
+	select {
+	case r.disconnectC <- struct{}{}:
+	default:
+	}
+}
+
+func (r *streamReconnector) isConnected() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connected
+}
+
+func (r *streamReconnector) setReconnecting(reconnecting bool) {
+	r.mu.Lock()
+	r.reconnecting = reconnecting
+	r.mu.Unlock()
+}
+
+// connect connects the stream, the connect is retried with the exponential backoff until the max retries is reached
+func (r *streamReconnector) connect(ctx context.Context) (err error) {
+	backoff := r.backoff
+	for attempt := 1; ; attempt++ {
+		if err = r.session.Stream.Connect(ctx); err == nil {
+			r.handleConnect()
+			return nil
+		}
+
+		if r.maxRetries > 0 && attempt >= r.maxRetries {
+			return err
+		}
+
+		r.logger.WithError(err).Warnf("stream connect error, retrying in %s (attempt %d)", backoff, attempt)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > r.maxBackoff {
+			backoff = r.maxBackoff
+		}
+	}
+}
+
+// run waits for the disconnect events and reconnects the stream if the stream is still disconnected after the backoff
+func (r *streamReconnector) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-r.disconnectC:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.backoff):
+			}
+
+			if r.isConnected() || r.isSuspended() {
+				continue
+			}
+
+			r.logger.Warnf("stream is not recovered, reconnecting...")
+			r.resubscribe(time.Now())
+			if err := r.reconnect(ctx); err != nil {
+				r.logger.WithError(err).Errorf("stream reconnect error")
+				r.notify(types.SeverityCritical, "exchange session %s stream reconnect failed: %v", r.session.Name, err)
+			}
+		}
+	}
+}
+
+// reconnect closes and connects the stream, e.g., to apply the changed subscriptions
+func (r *streamReconnector) reconnect(ctx context.Context) error {
+	r.setReconnecting(true)
+	defer r.setReconnecting(false)
+
+	if err := r.session.Stream.Close(); err != nil {
+		r.logger.WithError(err).Warn("stream close error")
+	}
+
+	return r.connect(ctx)
+}
+
+// resubscribe replaces the subscriptions of the stream with the session subscriptions in their schedule windows, the
+// subscriptions are sent when the stream connects. The stream keeps its subscriptions if it can't unsubscribe.
+func (r *streamReconnector) resubscribe(now time.Time) {
+	stream := baseStream(r.session.Stream)
+	unsubscriber, ok := stream.(types.Unsubscriber)
+	if !ok {
+		r.logger.Debugf("the stream of session %s can not unsubscribe, keeping the stream subscriptions", r.session.Name)
+		return
+	}
+
+	for _, sub := range r.session.Subscriptions {
+		if !r.session.isSubscriptionScheduled(sub, now) {
+			continue
+		}
+
+		// unsubscribe first, so that the subscription is not duplicated
+		unsubscriber.Unsubscribe(sub.Channel, sub.Symbol, sub.Options)
+		stream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+	}
+}
+
+func (r *streamReconnector) isSuspended() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.suspended
+}
+
+// suspend closes the stream, and the stream is not reconnected until it's resumed
+func (r *streamReconnector) suspend() {
+	r.mu.Lock()
+	wasConnected := r.connected
+	r.suspended = true
+	r.connected = false
+	r.mu.Unlock()
+
+	if !wasConnected {
+		return
+	}
+
+	if err := r.session.Stream.Close(); err != nil {
+		r.logger.WithError(err).Warn("stream close error")
+	}
+}
+
+// resume connects the suspended stream
+func (r *streamReconnector) resume(ctx context.Context) error {
+	r.mu.Lock()
+	r.suspended = false
+	r.mu.Unlock()
+
+	return r.connect(ctx)
+}
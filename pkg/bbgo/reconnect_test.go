@@ -0,0 +1,203 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testReconnectStream fails the first failures connects
+type testReconnectStream struct {
+	testStream
+
+	mu       sync.Mutex
+	failures int
+	connects int
+	closes   int
+}
+
+func (s *testReconnectStream) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	s.connects++
+	fail := s.connects <= s.failures
+	s.mu.Unlock()
+
+	if fail {
+		return errors.New("connect error")
+	}
+
+	s.EmitConnect()
+	return nil
+}
+
+func (s *testReconnectStream) Close() error {
+	s.mu.Lock()
+	s.closes++
+	s.mu.Unlock()
+
+	s.EmitDisconnect()
+	return nil
+}
+
+func (s *testReconnectStream) counts() (connects, closes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connects, s.closes
+}
+
+type testNotifications struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (n *testNotifications) notify(severity types.Severity, format string, args ...interface{}) {
+	n.mu.Lock()
+	n.messages = append(n.messages, fmt.Sprintf(format, args...))
+	n.mu.Unlock()
+}
+
+func (n *testNotifications) get() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.messages...)
+}
+
+func newTestReconnector(stream *testReconnectStream, conf *ReconnectConfig) (*streamReconnector, *testNotifications) {
+	notifications := &testNotifications{}
+	session := &ExchangeSession{Name: "binance", Stream: stream, Reconnect: conf}
+	reconnector := newStreamReconnector(session, notifications.notify)
+	reconnector.backoff = time.Millisecond
+	return reconnector, notifications
+}
+
+func TestStreamReconnector_Connect(t *testing.T) {
+	stream := &testReconnectStream{failures: 2}
+	reconnector, notifications := newTestReconnector(stream, &ReconnectConfig{MaxRetries: 3})
+
+	assert.NoError(t, reconnector.connect(context.Background()))
+	connects, _ := stream.counts()
+	assert.Equal(t, 3, connects)
+	assert.True(t, reconnector.isConnected())
+	assert.Empty(t, notifications.get(), "the first connect should not be notified")
+
+	stream = &testReconnectStream{failures: 5}
+	reconnector, _ = newTestReconnector(stream, &ReconnectConfig{MaxRetries: 2})
+	assert.EqualError(t, reconnector.connect(context.Background()), "connect error")
+	connects, _ = stream.counts()
+	assert.Equal(t, 2, connects, "should stop at the max retries")
+}
+
+func TestStreamReconnector_Backoff(t *testing.T) {
+	reconnector, _ := newTestReconnector(&testReconnectStream{}, &ReconnectConfig{MaxBackoff: types.Duration(time.Second)})
+	assert.Equal(t, time.Second, reconnector.maxBackoff)
+	assert.Equal(t, time.Second, newStreamReconnector(reconnector.session, nil).backoff, "the first backoff should not exceed the ceiling")
+}
+
+func TestStreamReconnector_Reconnect(t *testing.T) {
+	stream := &testReconnectStream{}
+	reconnector, notifications := newTestReconnector(stream, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, reconnector.connect(ctx))
+	go reconnector.run(ctx)
+
+	// the stream does not reconnect by itself, so the reconnector should close and connect the stream
+	stream.EmitDisconnect()
+
+	assert.Eventually(t, func() bool {
+		connects, closes := stream.counts()
+		return connects == 2 && closes == 1 && reconnector.isConnected()
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, []string{
+		"exchange session binance stream is disconnected",
+		"exchange session binance stream is reconnected",
+	}, notifications.get())
+}
+
+func TestStreamReconnector_Resubscribe(t *testing.T) {
+	stream := &testReconnectStream{}
+	reconnector, _ := newTestReconnector(stream, nil)
+
+	sub := types.Subscription{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: "1m"}}
+	reconnector.session.Subscriptions = map[types.Subscription]types.Subscription{sub: sub}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, reconnector.connect(ctx))
+	go reconnector.run(ctx)
+
+	// the stream lost its subscriptions
+	stream.mu.Lock()
+	stream.Subscriptions = nil
+	stream.mu.Unlock()
+
+	for i := 1; i <= 2; i++ {
+		stream.EmitDisconnect()
+
+		assert.Eventually(t, func() bool {
+			connects, _ := stream.counts()
+			return connects == i+1 && reconnector.isConnected()
+		}, time.Second, time.Millisecond)
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	assert.Equal(t, []types.Subscription{sub}, stream.Subscriptions, "the subscriptions should be subscribed once")
+}
+
+type testTunableStream struct {
+	testReconnectStream
+	options []types.StreamConnectionOptions
+}
+
+func (s *testTunableStream) SetConnectionOptions(options types.StreamConnectionOptions) {
+	s.options = append(s.options, options)
+}
+
+func TestEnvironment_ConnectSession_Connection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	environ, _ := newTestEnvironment()
+	subscription := types.Subscription{Symbol: "BTCUSDT", Channel: types.BookChannel}
+
+	stream := &testTunableStream{}
+	session := &ExchangeSession{
+		Name:          "binance",
+		Stream:        stream,
+		Subscriptions: map[types.Subscription]types.Subscription{subscription: subscription},
+		Connection: &StreamConnectionConfig{
+			ConnectTimeout:    types.Duration(10 * time.Second),
+			KeepaliveInterval: types.Duration(30 * time.Second),
+		},
+	}
+	environ.AddExchangeSession("binance", session)
+
+	assert.NoError(t, environ.connectSession(ctx, session))
+	assert.Equal(t, []types.StreamConnectionOptions{
+		{ConnectTimeout: 10 * time.Second, KeepaliveInterval: 30 * time.Second},
+	}, stream.options, "the options should be applied before the stream is connected")
+
+	connects, _ := stream.counts()
+	assert.Equal(t, 1, connects)
+
+	untunable := &ExchangeSession{
+		Name:          "max",
+		Stream:        &testReconnectStream{},
+		Subscriptions: map[types.Subscription]types.Subscription{subscription: subscription},
+		Connection:    &StreamConnectionConfig{ConnectTimeout: types.Duration(time.Second)},
+	}
+	environ.AddExchangeSession("max", untunable)
+	assert.EqualError(t, environ.connectSession(ctx, untunable), "the stream of session max does not support the connection config")
+}
@@ -0,0 +1,258 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReloadResult is the summary of the changes applied by Reload
+type ReloadResult struct {
+	// AddedSessions are the new sessions initialized and connected by the reload
+	AddedSessions []string
+
+	// RemovedSessions are the sessions disconnected and removed by the reload
+	RemovedSessions []string
+
+	// ChangedSessions are the sessions with the changed config, they keep running with the previous config until
+	// the process is restarted
+	ChangedSessions []string
+}
+
+func (r *ReloadResult) String() string {
+	var changes []string
+	if len(r.AddedSessions) > 0 {
+		changes = append(changes, "added sessions: "+strings.Join(r.AddedSessions, ", "))
+	}
+
+	if len(r.RemovedSessions) > 0 {
+		changes = append(changes, "removed sessions: "+strings.Join(r.RemovedSessions, ", "))
+	}
+
+	if len(r.ChangedSessions) > 0 {
+		changes = append(changes, "changed sessions (restart required): "+strings.Join(r.ChangedSessions, ", "))
+	}
+
+	if len(changes) == 0 {
+		return "config reloaded, the notification routing is rebuilt"
+	}
+
+	return "config reloaded, the notification routing is rebuilt, " + strings.Join(changes, "; ")
+}
+
+// Reload applies the additive changes of the reloaded config to the running environment without dropping the
+// existing connections. The sessions are compared with the session configs of the previous config by the name:
+// the new sessions are initialized, synced and connected, the removed sessions are disconnected, and the notification
+// routing is rebuilt for all the sessions. The changed sessions keep running with the previous config, and the
+// strategies, the database and the notifiers are not reloaded, they're applied on restart.
+//
+// The new sessions failed to initialize are not added, their errors are returned in a SessionInitError,
+// and the other changes are still applied. The session registry is changed under the sessions mutex, so the
+// sessions can be read by the health check, the sync and the api routes during the reload.
+func (environ *Environment) Reload(ctx context.Context, userConfig *Config) (*ReloadResult, error) {
+	if environ.validateOnly {
+		return nil, ErrValidateOnly
+	}
+
+	var result = &ReloadResult{}
+	var added = make(map[string]*ExchangeSession)
+	var sessionConfigs = environ.sessionConfigsSnapshot()
+
+	if len(userConfig.Sessions) == 0 {
+		// the sessions are added by the api key env vars, there is nothing to compare
+		log.Warn("the reloaded config defines no sessions, the sessions are not reloaded")
+	} else {
+		for _, name := range sortedSessionNames(userConfig.Sessions) {
+			sessionConfig := userConfig.Sessions[name]
+
+			previous, ok := sessionConfigs[name]
+			if !ok {
+				// the session is added by the api key env vars
+				if _, exists := environ.Session(name); exists {
+					result.ChangedSessions = append(result.ChangedSessions, name)
+					continue
+				}
+
+				session, err := newExchangeSessionFromConfig(name, sessionConfig, false)
+				if err != nil {
+					return nil, err
+				}
+
+				added[name] = session
+				result.AddedSessions = append(result.AddedSessions, name)
+				continue
+			}
+
+			if !sameSessionConfig(previous, sessionConfig) {
+				log.Warnf("the config of session %s is changed, the changes are applied on restart", name)
+				result.ChangedSessions = append(result.ChangedSessions, name)
+			}
+		}
+
+		for _, name := range sortedSessionNames(sessionConfigs) {
+			if _, ok := userConfig.Sessions[name]; !ok {
+				result.RemovedSessions = append(result.RemovedSessions, name)
+			}
+		}
+	}
+
+	for _, name := range result.RemovedSessions {
+		log.Infof("removing session %s...", name)
+		environ.removeSession(name)
+	}
+
+	for _, name := range result.AddedSessions {
+		environ.AddExchangeSession(name, added[name])
+
+		environ.sessionsMutex.Lock()
+		environ.sessionConfigs[name] = userConfig.Sessions[name]
+		environ.sessionsMutex.Unlock()
+	}
+
+	// the routing is rebuilt before the new sessions are initialized,
+	// so that the order executors of the new sessions get the submit order routing
+	if err := environ.reloadNotificationRouting(userConfig.Notifications); err != nil {
+		return nil, err
+	}
+
+	var initErr = &SessionInitError{Errors: make(map[string]error)}
+	var connected []string
+	for _, name := range result.AddedSessions {
+		session := added[name]
+		if err := environ.addReloadedSession(ctx, session); err != nil {
+			session.Logger().WithError(err).Errorf("can not add the reloaded session %s", name)
+			initErr.Errors[name] = err
+			environ.removeSession(name)
+			continue
+		}
+
+		connected = append(connected, name)
+	}
+
+	result.AddedSessions = connected
+
+	if len(initErr.Errors) > 0 {
+		return result, initErr
+	}
+
+	return result, nil
+}
+
+// addReloadedSession initializes, syncs and connects the session added by the reload
+func (environ *Environment) addReloadedSession(ctx context.Context, session *ExchangeSession) error {
+	if err := session.Init(ctx, environ); err != nil && err != ErrSessionAlreadyInitialized {
+		return err
+	}
+
+	if err := environ.SyncSession(ctx, session); err != nil {
+		return fmt.Errorf("sync error: %w", err)
+	}
+
+	return environ.connectSession(ctx, session)
+}
+
+// sessionConfigsSnapshot returns a copy of the session configs
+func (environ *Environment) sessionConfigsSnapshot() map[string]*ExchangeSession {
+	environ.sessionsMutex.RLock()
+	defer environ.sessionsMutex.RUnlock()
+
+	sessionConfigs := make(map[string]*ExchangeSession, len(environ.sessionConfigs))
+	for name, sessionConfig := range environ.sessionConfigs {
+		sessionConfigs[name] = sessionConfig
+	}
+
+	return sessionConfigs
+}
+
+// removeSession stops the stream connection of the session and removes the session,
+// the stream is suspended before it's closed, so that the disconnect is neither notified nor reconnected
+func (environ *Environment) removeSession(name string) {
+	environ.sessionsMutex.Lock()
+	connection, ok := environ.connections[name]
+	delete(environ.connections, name)
+	delete(environ.subscriptionSchedulers, name)
+	delete(environ.sessionConfigs, name)
+	delete(environ.sessions, name)
+	environ.sessionsMutex.Unlock()
+
+	if ok {
+		connection.reconnector.suspend()
+		connection.cancel()
+	}
+
+	environ.notificationsMutex.Lock()
+	if notifications, ok := environ.notifications[name]; ok {
+		notifications.setTradeHandler(nil)
+		notifications.setOrderHandler(nil)
+		delete(environ.notifications, name)
+	}
+	environ.notificationsMutex.Unlock()
+}
+
+// reloadNotificationRouting resets the routes, the templates and the routing modes, and configures the routing of the
+// reloaded config. The routers are reset in place because the sessions and the order executors share them.
+func (environ *Environment) reloadNotificationRouting(conf *NotificationConfig) error {
+	for _, router := range []*PatternChannelRouter{environ.SymbolChannelRouter, environ.SessionChannelRouter, environ.StrategyChannelRouter} {
+		if router != nil {
+			router.reset()
+		}
+	}
+
+	if environ.ObjectChannelRouter != nil {
+		environ.ObjectChannelRouter.reset()
+	}
+
+	environ.tradeReportTemplate = nil
+	environ.orderReportTemplate = nil
+	environ.submitOrderReportTemplate = nil
+
+	environ.sessionsMutex.Lock()
+	environ.pnlRoutings = make(map[string]string)
+	environ.submitOrderRoutings = make(map[string]string)
+	environ.channelRoutings = make(map[string][]NotificationChannelRoute)
+	environ.sessionsMutex.Unlock()
+
+	environ.SetStrategyRouting("")
+
+	environ.notificationsMutex.Lock()
+	for _, notifications := range environ.notifications {
+		notifications.setTradeHandler(nil)
+		notifications.setOrderHandler(nil)
+	}
+	environ.notificationsMutex.Unlock()
+
+	if conf != nil {
+		if err := environ.ConfigureNotificationRouting(conf); err != nil {
+			return err
+		}
+	}
+
+	// the order executors of the initialized sessions notify the submit orders with the rebuilt routing
+	for _, session := range environ.Sessions() {
+		if session.orderExecutor != nil {
+			environ.sessionNotifications(session).setSubmitOrderHandler(environ.newSubmitOrderNotifier(session))
+		}
+	}
+
+	return nil
+}
+
+// sameSessionConfig compares the config fields of the sessions, the runtime fields are not encoded,
+// and the credentials are compared without the redaction of MarshalJSON
+func sameSessionConfig(a, b *ExchangeSession) bool {
+	aa, err := json.Marshal((*exchangeSessionJSON)(a))
+	if err != nil {
+		return false
+	}
+
+	bb, err := json.Marshal((*exchangeSessionJSON)(b))
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(aa, bb)
+}
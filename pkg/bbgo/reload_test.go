@@ -0,0 +1,165 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_Reload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	environ, notifier := newTestEnvironment()
+
+	streams := make(map[string]*testReconnectStream)
+	for _, name := range []string{"binance", "max"} {
+		stream := &testReconnectStream{}
+		streams[name] = stream
+
+		subscription := types.Subscription{Symbol: "BTCUSDT", Channel: types.BookChannel}
+		environ.AddExchangeSession(name, &ExchangeSession{
+			Name:          name,
+			Stream:        stream,
+			Subscriptions: map[types.Subscription]types.Subscription{subscription: subscription},
+		})
+		environ.sessionConfigs[name] = &ExchangeSession{ExchangeName: name, Tags: []string{"maker"}}
+
+		assert.NoError(t, environ.connectSession(ctx, environ.sessions[name]))
+	}
+
+	assert.NoError(t, environ.ConfigureNotificationRouting(&NotificationConfig{
+		SessionChannels: map[string]string{"^binance$": "#binance"},
+		Routing:         &SlackNotificationRouting{Trade: "$session"},
+	}))
+
+	result, err := environ.Reload(ctx, &Config{
+		Sessions: map[string]*ExchangeSession{
+			"binance": {ExchangeName: "binance", Tags: []string{"taker"}},
+		},
+		Notifications: &NotificationConfig{
+			SessionChannels: map[string]string{"^binance$": "#trades"},
+			Routing:         &SlackNotificationRouting{Trade: "$session"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Empty(t, result.AddedSessions)
+	assert.Equal(t, []string{"max"}, result.RemovedSessions)
+	assert.Equal(t, []string{"binance"}, result.ChangedSessions, "the changed session keeps running with the previous config")
+
+	// the removed session is disconnected silently, and the existing connection is kept
+	_, ok := environ.Session("max")
+	assert.False(t, ok)
+	connects, closes := streams["max"].counts()
+	assert.Equal(t, 1, connects)
+	assert.Equal(t, 1, closes)
+	connects, closes = streams["binance"].counts()
+	assert.Equal(t, 1, connects)
+	assert.Equal(t, 0, closes)
+
+	// the notification routing is replaced instead of being registered twice
+	streams["binance"].EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	streams["max"].EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Equal(t, "#trades", notifier.notifications[0].channel)
+	}
+
+	// the routing is removed if the reloaded config has no notification config
+	_, err = environ.Reload(ctx, &Config{
+		Sessions: map[string]*ExchangeSession{
+			"binance": {ExchangeName: "binance", Tags: []string{"maker"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	streams["binance"].EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT"})
+	assert.Len(t, notifier.notifications, 1)
+}
+
+// the sessions are read by the health check, the sync and the notification routing while Reload removes them,
+// the test is meant to be run with -race
+func TestEnvironment_Reload_ConcurrentReaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	environ, _ := newTestEnvironment()
+	environ.SyncService = &service.SyncService{}
+
+	var names []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("session%d", i)
+		names = append(names, name)
+
+		session := environ.AddExchangeSession(name, &ExchangeSession{Name: name, PublicOnly: true, Stream: &testReconnectStream{}})
+		environ.sessionConfigs[name] = &ExchangeSession{ExchangeName: "binance", PublicOnly: true}
+		assert.NoError(t, environ.connectSession(ctx, session))
+	}
+
+	notifications := &NotificationConfig{
+		Routing: &SlackNotificationRouting{PnL: "$session", SubmitOrder: "$session"},
+	}
+	assert.NoError(t, environ.ConfigureNotificationRouting(notifications))
+
+	readers := []func(){
+		func() { environ.HealthReport(time.Now()) },
+		func() { assert.NoError(t, environ.Sync(ctx)) },
+		func() { environ.SelectSessionsByTag("maker") },
+		func() { environ.ActiveSubscriptions("session49") },
+		func() { environ.channelRoutes("session49", NotificationEventPnL) },
+	}
+
+	done := make(chan struct{})
+	var started, wg sync.WaitGroup
+	for _, read := range readers {
+		started.Add(1)
+		wg.Add(1)
+		go func(read func()) {
+			defer wg.Done()
+
+			read()
+			started.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					read()
+				}
+			}
+		}(read)
+	}
+
+	started.Wait()
+
+	// remove the sessions one by one, the last session is kept
+	for len(names) > 1 {
+		names = names[1:]
+
+		sessions := make(map[string]*ExchangeSession)
+		for _, name := range names {
+			sessions[name] = &ExchangeSession{ExchangeName: "binance", PublicOnly: true}
+		}
+
+		result, err := environ.Reload(ctx, &Config{Sessions: sessions, Notifications: notifications})
+		if assert.NoError(t, err) {
+			assert.Len(t, result.RemovedSessions, 1)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+
+	assert.Len(t, environ.Sessions(), 1)
+	assert.Len(t, environ.HealthReport(time.Now()).Sessions, 1)
+}
@@ -2,10 +2,13 @@ package bbgo
 
 import (
 	"regexp"
+	"sync"
+	"text/template"
 
 	"github.com/robfig/cron/v3"
 
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 type PnLReporter interface {
@@ -81,7 +84,12 @@ func (reporter *AverageCostPnLReporter) Run() {
 }
 
 type PatternChannelRouter struct {
+	mu     sync.RWMutex
 	routes map[*regexp.Regexp]string
+
+	// normalizer converts the routed text before the patterns are matched, e.g., the exchange symbol to the
+	// canonical symbol, the original text is matched if the normalized text matches no pattern
+	normalizer *SymbolNormalizer
 }
 
 func NewPatternChannelRouter(routes map[string]string) *PatternChannelRouter {
@@ -99,6 +107,9 @@ func (router *PatternChannelRouter) AddRoute(routes map[string]string) {
 		return
 	}
 
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
 	if router.routes == nil {
 		router.routes = make(map[*regexp.Regexp]string)
 	}
@@ -108,7 +119,38 @@ func (router *PatternChannelRouter) AddRoute(routes map[string]string) {
 	}
 }
 
+// SetNormalizer sets the symbol normalizer applied to the routed text, it's used by the symbol channel router
+func (router *PatternChannelRouter) SetNormalizer(normalizer *SymbolNormalizer) {
+	router.mu.Lock()
+	router.normalizer = normalizer
+	router.mu.Unlock()
+}
+
+// reset removes all the routes and the normalizer, the router is reset in place because the notifiability of the
+// sessions and the order executors are copies that share the same routers
+func (router *PatternChannelRouter) reset() {
+	router.mu.Lock()
+	router.routes = make(map[*regexp.Regexp]string)
+	router.normalizer = nil
+	router.mu.Unlock()
+}
+
 func (router *PatternChannelRouter) Route(text string) (channel string, ok bool) {
+	router.mu.RLock()
+	defer router.mu.RUnlock()
+
+	if router.normalizer != nil {
+		if normalized := router.normalizer.Normalize(text); normalized != text {
+			if channel, ok = router.match(normalized); ok {
+				return channel, ok
+			}
+		}
+	}
+
+	return router.match(text)
+}
+
+func (router *PatternChannelRouter) match(text string) (channel string, ok bool) {
 	for pattern, channel := range router.routes {
 		if pattern.MatchString(text) {
 			ok = true
@@ -122,7 +164,12 @@ func (router *PatternChannelRouter) Route(text string) (channel string, ok bool)
 type ObjectChannelHandler func(obj interface{}) (channel string, ok bool)
 
 type ObjectChannelRouter struct {
+	mu     sync.RWMutex
 	routes []ObjectChannelHandler
+
+	// format is the format hint of the routed objects, the notifiers implementing FormatNotifier
+	// emit the objects in this format, the other notifiers always emit the rendered text.
+	format types.OutputFormat
 }
 
 func NewObjectChannelRouter() *ObjectChannelRouter {
@@ -130,11 +177,44 @@ func NewObjectChannelRouter() *ObjectChannelRouter {
 }
 
 func (router *ObjectChannelRouter) AddRoute(f ObjectChannelHandler) {
+	router.mu.Lock()
 	router.routes = append(router.routes, f)
+	router.mu.Unlock()
+}
+
+// SetFormat sets the format hint of the routed objects
+func (router *ObjectChannelRouter) SetFormat(format types.OutputFormat) {
+	router.mu.Lock()
+	router.format = format
+	router.mu.Unlock()
+}
+
+// reset removes all the routes and the format hint, see PatternChannelRouter.reset
+func (router *ObjectChannelRouter) reset() {
+	router.mu.Lock()
+	router.routes = nil
+	router.format = ""
+	router.mu.Unlock()
+}
+
+// Format returns the format hint of the routed objects, defaults to text
+func (router *ObjectChannelRouter) Format() types.OutputFormat {
+	router.mu.RLock()
+	defer router.mu.RUnlock()
+
+	if len(router.format) == 0 {
+		return types.OutputFormatText
+	}
+
+	return router.format
 }
 
 func (router *ObjectChannelRouter) Route(obj interface{}) (channel string, ok bool) {
-	for _, f := range router.routes {
+	router.mu.RLock()
+	routes := router.routes
+	router.mu.RUnlock()
+
+	for _, f := range routes {
 		channel, ok = f(obj)
 		if ok {
 			return
@@ -150,3 +230,11 @@ type TradeReporter struct {
 const TemplateTradeReport = `:handshake: {{ .Symbol }} {{ .Side }} Trade Execution @ {{ .Price  }}`
 
 const TemplateOrderReport = `:handshake: {{ .Symbol }} {{ .Side }} Order Update @ {{ .Price  }}`
+
+const TemplateSubmitOrderReport = `:memo: Submitting {{ .Symbol }} {{ .Type }} {{ .Side }} order with quantity: {{ .QuantityString }} at price: {{ .PriceString }}`
+
+var defaultTradeReportTemplate = template.Must(template.New("trade").Parse(TemplateTradeReport))
+
+var defaultOrderReportTemplate = template.Must(template.New("order").Parse(TemplateOrderReport))
+
+var defaultSubmitOrderReportTemplate = template.Must(template.New("submitOrder").Parse(TemplateSubmitOrderReport))
@@ -2,12 +2,17 @@ package bbgo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/service"
@@ -15,6 +20,60 @@ import (
 	"github.com/c9s/bbgo/pkg/util"
 )
 
+// RateLimitConfig is the request rate limit of the exchange API
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond" yaml:"requestsPerSecond"`
+
+	// Burst is the max number of the requests sent at once, defaults to 1
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+// NewLimiter creates the limiter from the config
+func (c *RateLimitConfig) NewLimiter() (*rate.Limiter, error) {
+	burst := c.Burst
+	if burst == 0 {
+		burst = 1
+	}
+
+	return util.NewValidLimiter(rate.Limit(c.RequestsPerSecond), burst)
+}
+
+// ProxyConfig is the proxy of the exchange REST API client and the streams of a session
+type ProxyConfig struct {
+	// URL is the proxy URL, the supported schemes are socks5, http and https, e.g., socks5://127.0.0.1:1080
+	URL string `json:"url" yaml:"url"`
+
+	// Username and Password authenticate with the proxy, the password can be encrypted or stored in Vault like the
+	// api secret
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// ParseURL resolves the password and parses the proxy URL with the credentials
+func (c *ProxyConfig) ParseURL() (*url.URL, error) {
+	password, err := util.ResolveSecret(c.Password)
+	if err != nil {
+		return nil, fmt.Errorf("can not resolve the proxy password: %w", err)
+	}
+
+	return util.ParseProxyURL(c.URL, c.Username, password)
+}
+
+// redacted returns the copy of the config with the passwords redacted, including the password in the URL
+func (c *ProxyConfig) redacted() *ProxyConfig {
+	redacted := *c
+	redacted.Password = redactSecret(c.Password)
+
+	if u, err := url.Parse(c.URL); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactSecret(password))
+			redacted.URL = u.String()
+		}
+	}
+
+	return &redacted
+}
+
 var fiatCurrencies = []string{"USDC", "USDT", "USD", "TWD", "EUR", "GBP"}
 
 type StandardIndicatorSet struct {
@@ -120,6 +179,39 @@ type ExchangeSession struct {
 	IsolatedMargin       bool   `json:"isolatedMargin,omitempty" yaml:"isolatedMargin,omitempty"`
 	IsolatedMarginSymbol string `json:"isolatedMarginSymbol,omitempty" yaml:"isolatedMarginSymbol,omitempty"`
 
+	// IsolatedMarginSymbols lets an isolated margin session cover multiple isolated margin pairs of the account
+	IsolatedMarginSymbols []string `json:"isolatedMarginSymbols,omitempty" yaml:"isolatedMarginSymbols,omitempty"`
+
+	// Futures connects the session to the futures (perpetual) account, it can not be used with margin
+	Futures bool `json:"futures,omitempty" yaml:"futures,omitempty"`
+
+	// Sandbox connects the session to the testnet endpoints of the exchange, the other sessions are not affected
+	Sandbox bool `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+
+	// Reconnect is the stream reconnect config, the stream is reconnected with the default config if it's not set
+	Reconnect *ReconnectConfig `json:"reconnect,omitempty" yaml:"reconnect,omitempty"`
+
+	// Connection tunes the connect timeout and the keepalive of the stream connection
+	Connection *StreamConnectionConfig `json:"connection,omitempty" yaml:"connection,omitempty"`
+
+	// Tags labels the session by its purpose, e.g., "maker" or "taker", so that the sessions can be selected by group
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// RateLimit limits the request rate of the exchange REST API client of this session, including the sync requests
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+
+	// Proxy connects the exchange REST API client and the streams of this session through the proxy,
+	// the session connects directly if it's not set
+	Proxy *ProxyConfig `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// DefaultSubscriptions are subscribed when the session is created from the config, so that the session streams
+	// the market data even if no strategy is attached, the same subscriptions of the strategies are merged
+	DefaultSubscriptions []types.Subscription `json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+
+	// SubscriptionSchedules limits the subscriptions of the symbols to the windows, the stream is reconnected when the
+	// subscriptions enter or leave their windows, and it's closed when none of the subscriptions is in its window
+	SubscriptionSchedules []SubscriptionSchedule `json:"subscriptionSchedules,omitempty" yaml:"subscriptionSchedules,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -161,6 +253,18 @@ type ExchangeSession struct {
 
 	orderExecutor *ExchangeOrderExecutor
 
+	// health tracks the stream connectivity for the health check
+	health *streamHealth
+
+	// metrics records the order submits, it's nil if the metrics are not configured
+	metrics *Metrics
+
+	// rateLimiter is the limiter of the exchange API requests, it's nil if the rate limit is not configured
+	rateLimiter *rate.Limiter
+
+	// maintenance is the pause flag of the environment, it's set when the session is added to the environment
+	maintenance *maintenanceMode
+
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
@@ -170,9 +274,10 @@ type ExchangeSession struct {
 func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 	return &ExchangeSession{
 		Notifiability: Notifiability{
-			SymbolChannelRouter:  NewPatternChannelRouter(nil),
-			SessionChannelRouter: NewPatternChannelRouter(nil),
-			ObjectChannelRouter:  NewObjectChannelRouter(),
+			SymbolChannelRouter:   NewPatternChannelRouter(nil),
+			SessionChannelRouter:  NewPatternChannelRouter(nil),
+			StrategyChannelRouter: NewPatternChannelRouter(nil),
+			ObjectChannelRouter:   NewObjectChannelRouter(),
 		},
 
 		Name:          name,
@@ -202,7 +307,7 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		return ErrSessionAlreadyInitialized
 	}
 
-	var log = log.WithField("session", session.Name)
+	var log = session.Logger()
 
 	if !viper.GetBool("bbgo-markets-cache") {
 		markets, err := session.Exchange.QueryMarkets(ctx)
@@ -224,22 +329,34 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		session.markets = markets
 	}
 
-	// query and initialize the balances
-	log.Infof("querying balances from session %s...", session.Name)
-	balances, err := session.Exchange.QueryAccountBalances(ctx)
-	if err != nil {
+	if session.Margin && session.IsolatedMargin {
+		for _, symbol := range session.GetIsolatedMarginSymbols() {
+			if _, ok := session.markets[symbol]; !ok {
+				return fmt.Errorf("isolated margin symbol %s is not found in the markets of session %s", symbol, session.Name)
+			}
+		}
+	}
+
+	if session.PublicOnly {
+		// the public only session has no account, only the market data is streamed
+		log.Infof("session %s is public only, skipping the account setup", session.Name)
+	} else if err := session.initAccount(ctx, environ); err != nil {
 		return err
 	}
 
-	log.Infof("%s account", session.Name)
-	balances.Print()
+	session.health = newStreamHealth(session.Stream)
+	session.metrics = environ.metrics
 
-	session.Account.UpdateBalances(balances)
+	// the submit order handler is replaced when the notification routing is rebuilt
+	var notifications = environ.sessionNotifications(session)
+	notifications.setSubmitOrderHandler(environ.newSubmitOrderNotifier(session))
 
 	var orderExecutor = &ExchangeOrderExecutor{
 		// copy the notification system so that we can route
 		Notifiability: session.Notifiability,
 		Session:       session,
+
+		submitOrderNotifier: notifications.emitSubmitOrder,
 	}
 
 	// forward trade updates and order updates to the order executor
@@ -252,11 +369,14 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 	// insert trade into db right before everything
 	// TODO: we should insert the backtest trades into the database,
 	// 		 however we should clean up the trades before we start the next backtesting
-	if environ.TradeService != nil && environ.BacktestService == nil {
+	if environ.TradeService != nil && environ.BacktestService == nil && !environ.IsDryRun() {
 		session.Stream.OnTradeUpdate(func(trade types.Trade) {
 			if err := environ.TradeService.Insert(trade); err != nil {
 				log.WithError(err).Errorf("trade insert error: %+v", trade)
+				return
 			}
+
+			environ.updateStoredPosition(ctx, session, trade.Symbol)
 		})
 	}
 
@@ -277,6 +397,262 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 	return nil
 }
 
+// initAccount queries and initializes the balances, the dry run exchange is enabled with the balances in the dry run mode
+func (session *ExchangeSession) initAccount(ctx context.Context, environ *Environment) error {
+	var log = session.Logger()
+
+	log.Infof("querying balances from session %s...", session.Name)
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("%s account", session.Name)
+	balances.Print()
+
+	session.Account.UpdateBalances(balances)
+
+	if environ.IsDryRun() {
+		log.Warnf("session %s is running in the dry run mode, the orders will not be sent to the exchange", session.Name)
+		session.enableDryRun(balances)
+	}
+
+	return nil
+}
+
+// InitStandalone initializes the session that is not added to an environment, the markets and the balances are loaded
+// like Init, but the trades are not saved into the database, nothing is notified and the dry run mode is not used.
+// The stream is not connected, call session.Stream.Connect after the subscriptions are added.
+func (session *ExchangeSession) InitStandalone(ctx context.Context) error {
+	return session.Init(ctx, NewEnvironment())
+}
+
+// Clone creates an independent session of the same exchange account with the new name, e.g., to run the variants of a
+// strategy with different configs side by side. The clone shares the exchange API client, so the credentials and the
+// rate limiter are shared, but it has its own stream, subscriptions, account, positions and order stores. Add the clone
+// to the environment by AddExchangeSession before the environment is initialized.
+//
+// Note that the private streams of the clones receive the order and trade updates of the whole account, the strategies
+// should tell their own orders by the order stores.
+func (session *ExchangeSession) Clone(newName string) (*ExchangeSession, error) {
+	if session.IsInitialized {
+		return nil, ErrSessionAlreadyInitialized
+	}
+
+	clone := NewExchangeSession(newName, session.Exchange)
+	clone.Notifiability = session.Notifiability
+	clone.ExchangeName = session.ExchangeName
+	clone.EnvVarPrefix = session.EnvVarPrefix
+	clone.Key = session.Key
+	clone.Secret = session.Secret
+	clone.SubAccount = session.SubAccount
+	clone.PublicOnly = session.PublicOnly
+	clone.Margin = session.Margin
+	clone.IsolatedMargin = session.IsolatedMargin
+	clone.IsolatedMarginSymbol = session.IsolatedMarginSymbol
+	clone.IsolatedMarginSymbols = append([]string(nil), session.IsolatedMarginSymbols...)
+	clone.Futures = session.Futures
+	clone.Sandbox = session.Sandbox
+	clone.Reconnect = session.Reconnect
+	clone.Connection = session.Connection
+	clone.Tags = append([]string(nil), session.Tags...)
+	clone.RateLimit = session.RateLimit
+	clone.rateLimiter = session.rateLimiter
+	clone.maintenance = session.maintenance
+	clone.Proxy = session.Proxy
+	clone.DefaultSubscriptions = append([]types.Subscription(nil), session.DefaultSubscriptions...)
+	clone.SubscriptionSchedules = append([]SubscriptionSchedule(nil), session.SubscriptionSchedules...)
+
+	// the default subscriptions were validated when the session was created
+	if err := clone.subscribeDefaults(clone.DefaultSubscriptions); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// enableDryRunStream replaces the stream of the session with the dry run stream, the simulated orders and trades are
+// emitted on it. It's called when the session is added to the dry run environment, before the notifications and the
+// strategies register their callbacks, so that the callbacks receive the simulated events.
+func (session *ExchangeSession) enableDryRunStream() *DryRunStream {
+	if stream, ok := session.Stream.(*DryRunStream); ok {
+		return stream
+	}
+
+	stream := NewDryRunStream(session.Stream)
+
+	// the private events are simulated, so we only need the public market data
+	stream.SetPublicOnly()
+
+	session.Stream = stream
+	return stream
+}
+
+// enableDryRun replaces the exchange of the session with the dry run exchange,
+// the market data still comes from the live stream, but the orders are matched locally.
+func (session *ExchangeSession) enableDryRun(balances types.BalanceMap) {
+	stream := session.enableDryRunStream()
+	session.Exchange = NewDryRunExchange(session.Exchange, stream, session.markets, balances)
+}
+
+// redactedSecret replaces the credentials of the session in the logs and the JSON output
+const redactedSecret = "******"
+
+// redactSecret redacts the non-empty credential unless the --unsafe-log-secrets flag is set,
+// the empty value is kept, so that the missing credentials can still be told
+func redactSecret(value string) string {
+	if len(value) == 0 || viper.GetBool("unsafe-log-secrets") {
+		return value
+	}
+
+	return redactedSecret
+}
+
+// Logger returns the logger with the session field, the logs of the session should be written with it,
+// so that the logs of the different sessions can be told apart
+func (session *ExchangeSession) Logger() *log.Entry {
+	if session.logger == nil {
+		return log.WithField("session", session.Name)
+	}
+
+	return session.logger
+}
+
+// String describes the session config with the credentials redacted, it's used when the session is formatted by %v or %s
+func (session *ExchangeSession) String() string {
+	return fmt.Sprintf("ExchangeSession{name: %s, exchange: %s, envVarPrefix: %s, key: %s, secret: %s, subAccount: %s, publicOnly: %t, margin: %t, isolatedMargin: %t, futures: %t, sandbox: %t}",
+		session.Name,
+		session.ExchangeName,
+		session.EnvVarPrefix,
+		redactSecret(session.Key),
+		redactSecret(session.Secret),
+		redactSecret(session.SubAccount),
+		session.PublicOnly,
+		session.Margin,
+		session.IsolatedMargin,
+		session.Futures,
+		session.Sandbox)
+}
+
+// exchangeSessionJSON is the ExchangeSession without the MarshalJSON method
+type exchangeSessionJSON ExchangeSession
+
+// MarshalJSON marshals the session with the credentials redacted, so that the session API and the dumped config
+// never carry the api keys
+func (session *ExchangeSession) MarshalJSON() ([]byte, error) {
+	var proxy *ProxyConfig
+	if session.Proxy != nil {
+		proxy = session.Proxy.redacted()
+	}
+
+	return json.Marshal(struct {
+		*exchangeSessionJSON
+
+		// the fields shadow the credential fields of the embedded session
+		Key        string       `json:"key,omitempty"`
+		Secret     string       `json:"secret,omitempty"`
+		SubAccount string       `json:"subAccount,omitempty"`
+		Proxy      *ProxyConfig `json:"proxy,omitempty"`
+	}{
+		exchangeSessionJSON: (*exchangeSessionJSON)(session),
+		Key:                 redactSecret(session.Key),
+		Secret:              redactSecret(session.Secret),
+		SubAccount:          redactSecret(session.SubAccount),
+		Proxy:               proxy,
+	})
+}
+
+// subscribeDefaults subscribes the default subscriptions of the config, the symbols are upper-cased like the symbols
+// subscribed by the strategies, so that the same subscriptions are merged
+func (session *ExchangeSession) subscribeDefaults(subscriptions []types.Subscription) error {
+	for _, sub := range subscriptions {
+		if len(sub.Symbol) == 0 {
+			return fmt.Errorf("the %s subscription requires a symbol", sub.Channel)
+		}
+
+		symbol := strings.ToUpper(sub.Symbol)
+
+		switch sub.Channel {
+		case types.BookChannel:
+
+		case types.KLineChannel:
+			if _, ok := types.SupportedIntervals[types.Interval(sub.Options.Interval)]; !ok {
+				return fmt.Errorf("invalid kline interval %q of the %s subscription", sub.Options.Interval, symbol)
+			}
+
+		default:
+			return fmt.Errorf("unsupported channel %q of the %s subscription", sub.Channel, symbol)
+		}
+
+		session.Subscribe(sub.Channel, symbol, sub.Options)
+	}
+
+	return nil
+}
+
+// HasTag returns true if the session is labeled with any of the given tags
+func (session *ExchangeSession) HasTag(tags ...string) bool {
+	for _, tag := range tags {
+		if util.StringSliceContains(session.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StreamStatus returns the connectivity status of the session stream
+func (session *ExchangeSession) StreamStatus() StreamStatus {
+	if session.health == nil {
+		return StreamStatus{}
+	}
+
+	return session.health.status()
+}
+
+// RateLimiter returns the limiter of the exchange API requests, it returns nil if the rate limit is not configured
+func (session *ExchangeSession) RateLimiter() *rate.Limiter {
+	return session.rateLimiter
+}
+
+// SetRateLimit applies the rate limit to the exchange API client of the session
+func (session *ExchangeSession) SetRateLimit(conf *RateLimitConfig) error {
+	limitedExchange, ok := session.Exchange.(types.RateLimitedExchange)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support the rate limit", session.Exchange.Name())
+	}
+
+	limiter, err := conf.NewLimiter()
+	if err != nil {
+		return fmt.Errorf("invalid rate limit of session %s: %w", session.Name, err)
+	}
+
+	limitedExchange.SetRateLimiter(limiter)
+	session.RateLimit = conf
+	session.rateLimiter = limiter
+	return nil
+}
+
+// GetIsolatedMarginSymbols returns the isolated margin symbols of the session,
+// including the symbol from the isolatedMarginSymbol option.
+func (session *ExchangeSession) GetIsolatedMarginSymbols() (symbols []string) {
+	var seen = make(map[string]struct{})
+	for _, symbol := range append([]string{session.IsolatedMarginSymbol}, session.IsolatedMarginSymbols...) {
+		if len(symbol) == 0 {
+			continue
+		}
+
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+
+		seen[symbol] = struct{}{}
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
 func (session *ExchangeSession) InitSymbols(ctx context.Context, environ *Environment) error {
 	if err := session.initUsedSymbols(ctx, environ); err != nil {
 		return err
@@ -555,15 +931,45 @@ func (session *ExchangeSession) UpdatePrices(ctx context.Context) (err error) {
 	return err
 }
 
-func (session *ExchangeSession) FindPossibleSymbols() (symbols []string, err error) {
-	// If the session is an isolated margin session, there will be only the isolated margin symbol
+// findSymbolsRetryAttempts and findSymbolsRetryDelay are the retry settings of the market and the balance queries
+// sent by FindPossibleSymbols
+var findSymbolsRetryAttempts = 3
+var findSymbolsRetryDelay = 3 * time.Second
+
+// PartialSymbolsError is returned by FindPossibleSymbols with the symbols that could be determined,
+// i.e., the subscribed symbols, when the markets or the balances of the session can not be queried
+type PartialSymbolsError struct {
+	Session string
+	Err     error
+}
+
+func (e *PartialSymbolsError) Error() string {
+	return fmt.Sprintf("only the partial symbols of session %s are found: %v", e.Session, e.Err)
+}
+
+func (e *PartialSymbolsError) Unwrap() error {
+	return e.Err
+}
+
+// FindPossibleSymbols finds the fiat markets of the assets in the balance sheet. The markets and the balances are queried
+// from the exchange with retries if the session is not initialized. If the queries still fail, the subscribed symbols
+// are returned with a PartialSymbolsError, so that the caller can still cover the known symbols.
+func (session *ExchangeSession) FindPossibleSymbols(ctx context.Context) (symbols []string, err error) {
+	// If the session is an isolated margin session, there will be only the isolated margin symbols
 	if session.Margin && session.IsolatedMargin {
-		return []string{
-			session.IsolatedMarginSymbol,
-		}, nil
+		return session.GetIsolatedMarginSymbols(), nil
+	}
+
+	markets, err := session.queryMarketsWithRetry(ctx)
+	if err != nil {
+		return session.subscribedSymbols(), &PartialSymbolsError{Session: session.Name, Err: errors.Wrap(err, "market query error")}
+	}
+
+	balances, err := session.queryBalancesWithRetry(ctx)
+	if err != nil {
+		return session.subscribedSymbols(), &PartialSymbolsError{Session: session.Name, Err: errors.Wrap(err, "balance query error")}
 	}
 
-	var balances = session.Account.Balances()
 	var fiatAssets []string
 
 	for _, currency := range fiatCurrencies {
@@ -574,7 +980,7 @@ func (session *ExchangeSession) FindPossibleSymbols() (symbols []string, err err
 
 	var symbolMap = map[string]struct{}{}
 
-	for _, market := range session.Markets() {
+	for _, market := range markets {
 		// ignore the markets that are not fiat currency markets
 		if !util.StringSliceContains(fiatAssets, market.QuoteCurrency) {
 			continue
@@ -595,3 +1001,65 @@ func (session *ExchangeSession) FindPossibleSymbols() (symbols []string, err err
 
 	return symbols, nil
 }
+
+// queryMarketsWithRetry returns the loaded markets, the markets are queried and cached if they're not loaded yet
+func (session *ExchangeSession) queryMarketsWithRetry(ctx context.Context) (map[string]types.Market, error) {
+	if len(session.markets) > 0 {
+		return session.markets, nil
+	}
+
+	var markets map[string]types.Market
+	err := util.Retry(ctx, findSymbolsRetryAttempts, findSymbolsRetryDelay, func() (err error) {
+		markets, err = session.Exchange.QueryMarkets(ctx)
+		return err
+	}, func(err error) {
+		log.WithError(err).Warnf("can not query the markets of session %s, retrying...", session.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session.markets = markets
+	return markets, nil
+}
+
+// queryBalancesWithRetry returns the account balances of the initialized session, the balances are queried
+// if the session is not initialized yet
+func (session *ExchangeSession) queryBalancesWithRetry(ctx context.Context) (types.BalanceMap, error) {
+	if session.IsInitialized {
+		return session.Account.Balances(), nil
+	}
+
+	var balances types.BalanceMap
+	err := util.Retry(ctx, findSymbolsRetryAttempts, findSymbolsRetryDelay, func() (err error) {
+		balances, err = session.Exchange.QueryAccountBalances(ctx)
+		return err
+	}, func(err error) {
+		log.WithError(err).Warnf("can not query the balances of session %s, retrying...", session.Name)
+	})
+
+	return balances, err
+}
+
+// subscribedSymbols returns the sorted symbols subscribed by the config and the strategies
+func (session *ExchangeSession) subscribedSymbols() (symbols []string) {
+	for symbol := range session.usedSymbols {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// submitOrders submits the orders to the exchange and records the submit latency and the results in the metrics,
+// the orders are rejected in the maintenance mode
+func (session *ExchangeSession) submitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if session.maintenance.isPaused() {
+		return nil, ErrMaintenanceMode
+	}
+
+	start := time.Now()
+	createdOrders, err := session.Exchange.SubmitOrders(ctx, orders...)
+	session.metrics.observeOrderSubmit(session.Name, start, len(orders), len(createdOrders))
+	return createdOrders, err
+}
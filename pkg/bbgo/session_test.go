@@ -0,0 +1,365 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeSession_RedactSecrets(t *testing.T) {
+	session := &ExchangeSession{
+		Name:         "binance",
+		ExchangeName: "binance",
+		EnvVarPrefix: "BINANCE",
+		Key:          "my-api-key",
+		Secret:       "my-api-secret",
+		SubAccount:   "my-sub-account",
+	}
+
+	text := fmt.Sprintf("%v", session)
+	assert.NotContains(t, text, "my-api-key")
+	assert.NotContains(t, text, "my-api-secret")
+	assert.NotContains(t, text, "my-sub-account")
+	assert.Contains(t, text, "name: binance")
+
+	out, err := json.Marshal(session)
+	if assert.NoError(t, err) {
+		var data map[string]interface{}
+		assert.NoError(t, json.Unmarshal(out, &data))
+		assert.Equal(t, redactedSecret, data["key"])
+		assert.Equal(t, redactedSecret, data["secret"])
+		assert.Equal(t, redactedSecret, data["subAccount"])
+		assert.Equal(t, "BINANCE", data["envVarPrefix"])
+	}
+
+	// the sessions of the config are redacted as well
+	config := &Config{Sessions: map[string]*ExchangeSession{"binance": session}}
+	data, err := config.Map()
+	if assert.NoError(t, err) {
+		sessions := data["sessions"].(map[string]interface{})
+		assert.Equal(t, redactedSecret, sessions["binance"].(map[string]interface{})["key"])
+	}
+
+	// the empty credentials are kept empty
+	out, err = json.Marshal(&ExchangeSession{Name: "max", ExchangeName: "max"})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(out), "key")
+	}
+
+	viper.Set("unsafe-log-secrets", true)
+	defer viper.Set("unsafe-log-secrets", false)
+
+	assert.Contains(t, session.String(), "my-api-key")
+	out, err = json.Marshal(session)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(out), "my-api-secret")
+	}
+}
+
+func TestNewExchangeSessionFromConfig_Subscriptions(t *testing.T) {
+	var config Config
+	err := yaml.Unmarshal([]byte(`
+sessions:
+  binance:
+    exchange: binance
+    subscriptions:
+    - channel: book
+      symbol: btcusdt
+    - channel: kline
+      symbol: BTCUSDT
+      options:
+        interval: 1m
+`), &config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session, err := newExchangeSessionFromConfig("binance", config.Sessions["binance"], true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, session.Subscriptions, 2)
+	assert.Contains(t, session.usedSymbols, "BTCUSDT")
+
+	// the same subscription of the strategy is merged
+	session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1m"})
+	session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "5m"})
+	assert.Len(t, session.Subscriptions, 3)
+
+	_, err = newExchangeSessionFromConfig("binance", &ExchangeSession{
+		ExchangeName:         "binance",
+		DefaultSubscriptions: []types.Subscription{{Channel: types.KLineChannel, Symbol: "BTCUSDT"}},
+	}, true)
+	assert.Error(t, err, "the kline subscription requires the interval")
+
+	_, err = newExchangeSessionFromConfig("binance", &ExchangeSession{
+		ExchangeName:         "binance",
+		DefaultSubscriptions: []types.Subscription{{Channel: "trade", Symbol: "BTCUSDT"}},
+	}, true)
+	assert.Error(t, err, "unsupported channel")
+}
+
+func TestNewExchangeSessionFromConfig_Sandbox(t *testing.T) {
+	testnet, err := newExchangeSessionFromConfig("binance-testnet", &ExchangeSession{
+		ExchangeName: "binance",
+		Sandbox:      true,
+	}, true)
+	if assert.NoError(t, err) {
+		assert.True(t, testnet.Sandbox)
+		assert.Equal(t, binance.TestnetAPIURL, testnet.Exchange.(*binance.Exchange).Client.BaseURL)
+	}
+
+	// the live session is not affected by the testnet session
+	live, err := newExchangeSessionFromConfig("binance", &ExchangeSession{ExchangeName: "binance"}, true)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, binance.TestnetAPIURL, live.Exchange.(*binance.Exchange).Client.BaseURL)
+	}
+
+	_, err = newExchangeSessionFromConfig("ftx", &ExchangeSession{ExchangeName: "ftx", Sandbox: true}, true)
+	assert.Error(t, err, "ftx does not provide the testnet")
+}
+
+func TestNewExchangeSessionFromConfig_Futures(t *testing.T) {
+	session, err := newExchangeSessionFromConfig("binance-futures", &ExchangeSession{
+		ExchangeName: "binance",
+		Futures:      true,
+	}, true)
+	if assert.NoError(t, err) {
+		assert.True(t, session.Futures)
+		futuresExchange, ok := session.Exchange.(types.FuturesExchange)
+		if assert.True(t, ok) {
+			assert.True(t, futuresExchange.GetFuturesSettings().IsFutures)
+		}
+	}
+
+	_, err = newExchangeSessionFromConfig("ftx-futures", &ExchangeSession{
+		ExchangeName: "ftx",
+		Futures:      true,
+	}, true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "exchange ftx does not support futures", err.Error())
+	}
+
+	_, err = newExchangeSessionFromConfig("binance-futures", &ExchangeSession{
+		ExchangeName: "binance",
+		Futures:      true,
+		Margin:       true,
+	}, true)
+	assert.Error(t, err, "futures can not be used with margin")
+}
+
+func TestNewExchangeSessionFromConfig_PublicOnly(t *testing.T) {
+	// the credentials are not resolved, the encrypted values can not be decrypted without the master key
+	session, err := NewExchangeSessionFromConfig("binance-public", &ExchangeSession{
+		ExchangeName:   "binance",
+		Key:            "enc:invalid",
+		Secret:         "enc:invalid",
+		PublicOnly:     true,
+		Margin:         true,
+		IsolatedMargin: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, session.PublicOnly)
+	assert.False(t, session.Margin, "the margin config is ignored")
+	assert.False(t, session.IsolatedMargin)
+	assert.False(t, session.Exchange.(types.MarginExchange).GetMarginSettings().IsMargin)
+	assert.Empty(t, session.Exchange.(*binance.Exchange).Client.APIKey)
+}
+
+// testPublicExchange serves the public market data only, the account queries fail
+type testPublicExchange struct {
+	types.Exchange
+}
+
+func (e *testPublicExchange) NewStream() types.Stream {
+	return &testStream{}
+}
+
+func (e *testPublicExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return types.MarketMap{"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}}, nil
+}
+
+func (e *testPublicExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return nil, errors.New("api key is required")
+}
+
+func TestExchangeSession_Init_PublicOnly(t *testing.T) {
+	ctx := context.Background()
+
+	environ := NewEnvironment()
+	session := environ.AddExchange("public", &testPublicExchange{})
+	session.PublicOnly = true
+
+	assert.NoError(t, environ.Init(ctx))
+	assert.True(t, session.IsInitialized)
+
+	validity, err := environ.Validate(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, validity)
+
+	// the sync does not query the account
+	environ.SyncService = &service.SyncService{}
+	assert.NoError(t, environ.SyncSession(ctx, session))
+
+	_, err = environ.QuerySessionBalances(ctx, "public")
+	assert.Equal(t, ErrPublicOnlySession, err)
+}
+
+type testSymbolsExchange struct {
+	types.Exchange
+
+	marketErrors  int
+	balanceErrors int
+}
+
+func (e *testSymbolsExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if e.marketErrors > 0 {
+		e.marketErrors--
+		return nil, errors.New("market query timeout")
+	}
+
+	return types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		"ETHUSDT": {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT"},
+		"ETHBTC":  {Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+	}, nil
+}
+
+func (e *testSymbolsExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if e.balanceErrors > 0 {
+		e.balanceErrors--
+		return nil, errors.New("balance query timeout")
+	}
+
+	return types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+	}, nil
+}
+
+type testStreamExchange struct {
+	testSymbolsExchange
+}
+
+func (e *testStreamExchange) NewStream() types.Stream {
+	return &testStream{}
+}
+
+func TestExchangeSession_InitStandalone(t *testing.T) {
+	ctx := context.Background()
+
+	session := NewExchangeSession("scanner", &testStreamExchange{})
+	if !assert.NoError(t, session.InitStandalone(ctx)) {
+		return
+	}
+
+	assert.True(t, session.IsInitialized)
+	_, ok := session.Market("ETHBTC")
+	assert.True(t, ok)
+
+	balance, ok := session.Account.Balance("USDT")
+	if assert.True(t, ok) {
+		assert.Equal(t, 100.0, balance.Available.Float64())
+	}
+
+	assert.Equal(t, ErrSessionAlreadyInitialized, session.InitStandalone(ctx))
+
+	_, err := NewStandaloneExchangeSessionFromConfig(ctx, "scanner", &ExchangeSession{ExchangeName: "kucoin"})
+	assert.Error(t, err)
+}
+
+func TestExchangeSession_Clone(t *testing.T) {
+	ctx := context.Background()
+
+	exchange := &testStreamExchange{}
+	session := NewExchangeSession("binance", exchange)
+	session.Key = "key"
+	session.Tags = []string{"maker"}
+	session.DefaultSubscriptions = []types.Subscription{
+		{Symbol: "btcusdt", Channel: types.KLineChannel, Options: types.SubscribeOptions{Interval: "1m"}},
+	}
+	assert.NoError(t, session.subscribeDefaults(session.DefaultSubscriptions))
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession(session.Name, session)
+
+	clone, err := session.Clone("binance_b")
+	if !assert.NoError(t, err) {
+		return
+	}
+	environ.AddExchangeSession(clone.Name, clone)
+
+	assert.Equal(t, "binance_b", clone.Name)
+	assert.Equal(t, "key", clone.Key)
+	assert.True(t, clone.Exchange == session.Exchange, "the exchange client is shared")
+	assert.True(t, clone.Stream != session.Stream, "the clone has its own stream")
+	assert.True(t, clone.Account != session.Account, "the clone has its own account")
+	assert.Equal(t, session.Subscriptions, clone.Subscriptions)
+
+	// the subscriptions of the clone do not affect the original session
+	clone.Subscribe(types.BookChannel, "ETHUSDT", types.SubscribeOptions{})
+	clone.Tags[0] = "taker"
+	assert.Len(t, session.Subscriptions, 1)
+	assert.Equal(t, []string{"maker"}, session.Tags)
+
+	assert.NoError(t, environ.Init(ctx))
+	assert.True(t, clone.IsInitialized)
+
+	_, err = session.Clone("binance_c")
+	assert.Equal(t, ErrSessionAlreadyInitialized, err)
+}
+
+func TestExchangeSession_FindPossibleSymbols(t *testing.T) {
+	defer func(delay time.Duration) { findSymbolsRetryDelay = delay }(findSymbolsRetryDelay)
+	findSymbolsRetryDelay = time.Millisecond
+
+	ctx := context.Background()
+
+	// the transient errors are retried
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testSymbolsExchange{marketErrors: 1, balanceErrors: 2},
+	}
+
+	symbols, err := session.FindPossibleSymbols(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT"}, symbols)
+
+	// the subscribed symbols are returned if the balances can not be queried
+	session = &ExchangeSession{
+		Name:        "binance",
+		Exchange:    &testSymbolsExchange{balanceErrors: findSymbolsRetryAttempts},
+		usedSymbols: map[string]struct{}{"ETHUSDT": {}, "BTCUSDT": {}},
+	}
+
+	symbols, err = session.FindPossibleSymbols(ctx)
+	assert.IsType(t, &PartialSymbolsError{}, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+
+	session.Exchange = &testSymbolsExchange{balanceErrors: findSymbolsRetryAttempts}
+	symbols, err = getSessionSymbols(ctx, session)
+	assert.NoError(t, err, "the partial symbols are used with a warning")
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+
+	// nothing is known without the subscriptions
+	session.usedSymbols = nil
+	session.Exchange = &testSymbolsExchange{balanceErrors: findSymbolsRetryAttempts}
+	_, err = getSessionSymbols(ctx, session)
+	assert.Error(t, err)
+}
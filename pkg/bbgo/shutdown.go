@@ -0,0 +1,127 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ShutdownError is the combined error of the failed shutdown steps
+type ShutdownError struct {
+	Errors []error
+}
+
+func (e *ShutdownError) Error() string {
+	var messages = make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("environment shutdown failed: %s", strings.Join(messages, "; "))
+}
+
+// interactionStopper is the chat interaction which runs a poller or a connection, e.g., the telegram bot
+type interactionStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Shutdown stops the chat interactions, disconnects the session streams, sends the buffered notifications
+// and closes the database connection pool. The steps not done before the context is done are abandoned,
+// the errors of the steps are returned in a ShutdownError.
+func (environ *Environment) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	for _, interaction := range environ.interactions {
+		if err := interaction.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("can not stop the chat interaction: %w", err))
+		}
+	}
+
+	for name, session := range environ.Sessions() {
+		if session.Stream == nil {
+			continue
+		}
+
+		stream := session.Stream
+		if err := runWithContext(ctx, stream.Close); err != nil {
+			errs = append(errs, fmt.Errorf("can not close the stream of the session %s: %w", name, err))
+		}
+	}
+
+	errs = append(errs, environ.flushNotifications(ctx)...)
+
+	if environ.DatabaseService != nil {
+		if err := environ.DatabaseService.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("can not close the database: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ShutdownError{Errors: errs}
+	}
+
+	return nil
+}
+
+// FlushNotifications sends the coalesced, the digested and the queued notifications, it's called before the one-shot commands exit,
+// the first flush error is returned
+func (environ *Environment) FlushNotifications(ctx context.Context) error {
+	if errs := environ.flushNotifications(ctx); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+func (environ *Environment) flushNotifications(ctx context.Context) (errs []error) {
+	if environ.digest != nil {
+		environ.digest.flushAll()
+	}
+
+	if environ.limiter != nil {
+		environ.limiter.flushAll()
+	}
+
+	for _, notifier := range environ.notifiers {
+		notifier := notifier
+		if err := runWithContext(ctx, func() error { return flushNotifier(notifier) }); err != nil {
+			errs = append(errs, fmt.Errorf("can not flush the notifier %T: %w", notifier, err))
+		}
+	}
+
+	return errs
+}
+
+// flushNotifier sends the buffered notifications of the notifier, the notifiers implementing io.Closer,
+// e.g., the email notifier and the file notifier, are closed since closing flushes their buffers.
+func flushNotifier(notifier Notifier) error {
+	switch n := notifier.(type) {
+	case io.Closer:
+		return n.Close()
+
+	case interface{ Flush() error }:
+		return n.Flush()
+
+	case interface{ Flush() }:
+		n.Flush()
+	}
+
+	return nil
+}
+
+// runWithContext runs the function and returns the context error if the context is done before the function returns
+func runWithContext(ctx context.Context, f func() error) error {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- f()
+	}()
+
+	select {
+	case err := <-errC:
+		return err
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,69 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testCloseErrorStream struct {
+	testStream
+}
+
+func (s *testCloseErrorStream) Close() error {
+	return errors.New("already closed")
+}
+
+type testClosingNotifier struct {
+	testNotifier
+	closed bool
+}
+
+func (n *testClosingNotifier) Close() error {
+	n.closed = true
+	return nil
+}
+
+type testBlockingNotifier struct {
+	testNotifier
+}
+
+func (n *testBlockingNotifier) Flush() {
+	select {}
+}
+
+func TestEnvironment_Shutdown(t *testing.T) {
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", &ExchangeSession{Name: "binance", Stream: &testStream{}})
+	environ.AddExchangeSession("max", &ExchangeSession{Name: "max", Stream: &testCloseErrorStream{}})
+
+	notifier := &testClosingNotifier{}
+	environ.AddNotifier(notifier)
+	environ.AddNotifier(&testBlockingNotifier{})
+
+	environ.SetRateLimit(&NotificationRateLimitConfig{Window: types.Duration(time.Hour)})
+	environ.NotifyTo("#alerts", "session %s disconnected", "binance")
+	environ.NotifyTo("#alerts", "session %s disconnected", "max")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := environ.Shutdown(ctx)
+
+	var shutdownErr *ShutdownError
+	if assert.True(t, errors.As(err, &shutdownErr)) && assert.Len(t, shutdownErr.Errors, 2) {
+		assert.EqualError(t, shutdownErr.Errors[0], "can not close the stream of the session max: already closed")
+		assert.True(t, errors.Is(shutdownErr.Errors[1], context.DeadlineExceeded), "the blocking notifier should be abandoned")
+	}
+
+	assert.True(t, notifier.closed)
+	assert.Equal(t, []testNotification{
+		{channel: "#alerts", text: "session binance disconnected"},
+		{channel: "#alerts", text: "+1 more similar notifications"},
+	}, notifier.notifications, "the suppressed notifications should be summarized on shutdown")
+}
@@ -0,0 +1,107 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// storedPositionBatchSize is the number of the stored trades aggregated in a batch
+const storedPositionBatchSize = 500
+
+// UpdateStoredPosition aggregates the trades stored since the last update into the stored average cost position of
+// the session symbol, so that the position is recovered by PositionService.Query without replaying all the trades.
+// The trades are aggregated in the storing order, the trades back-filled by the sync are aggregated after the newer
+// trades received from the stream.
+func (environ *Environment) UpdateStoredPosition(ctx context.Context, session *ExchangeSession, symbol string) (*service.Position, error) {
+	if environ.PositionService == nil || environ.TradeService == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
+
+	// the stream and the sync update the same position, the position is loaded and saved under the lock,
+	// so that no trade is aggregated twice
+	environ.storedPositionMutex.Lock()
+	defer environ.storedPositionMutex.Unlock()
+
+	stored, err := environ.PositionService.Query(ctx, session.Name, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		market, ok := session.Market(symbol)
+		if !ok {
+			return nil, fmt.Errorf("market %s not found in session %s", symbol, session.Name)
+		}
+
+		stored = &service.Position{
+			Session:       session.Name,
+			Exchange:      session.Exchange.Name(),
+			Symbol:        symbol,
+			BaseCurrency:  market.BaseCurrency,
+			QuoteCurrency: market.QuoteCurrency,
+		}
+	}
+
+	position := NewPositionFromStored(stored)
+
+	var aggregated = 0
+	for {
+		trades, err := environ.TradeService.QueryAfterGID(ctx, session.Exchange.Name(), symbol, session.Margin, session.IsolatedMargin, stored.LastTradeGID, storedPositionBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trade := range trades {
+			position.AddTrade(trade)
+			stored.LastTradeGID = trade.GID
+		}
+
+		aggregated += len(trades)
+		if len(trades) < storedPositionBatchSize {
+			break
+		}
+	}
+
+	if aggregated == 0 && stored.GID > 0 {
+		return stored, nil
+	}
+
+	stored.Base = position.Base
+	stored.Quote = position.Quote
+	stored.AverageCost = position.AverageCost
+	stored.UpdatedAt = datatype.Time(time.Now())
+
+	if err := environ.PositionService.Save(ctx, *stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// updateStoredPosition updates the stored position and logs the error, it's skipped if the database is not configured
+func (environ *Environment) updateStoredPosition(ctx context.Context, session *ExchangeSession, symbol string) {
+	if environ.PositionService == nil {
+		return
+	}
+
+	if _, err := environ.UpdateStoredPosition(ctx, session, symbol); err != nil {
+		session.Logger().WithError(err).Errorf("can not update the stored %s position of session %s", symbol, session.Name)
+	}
+}
+
+// NewPositionFromStored creates the position from the stored position, e.g., to recover the position of the strategy
+// on restart
+func NewPositionFromStored(stored *service.Position) *Position {
+	return &Position{
+		Symbol:        stored.Symbol,
+		BaseCurrency:  stored.BaseCurrency,
+		QuoteCurrency: stored.QuoteCurrency,
+		Base:          stored.Base,
+		Quote:         stored.Quote,
+		AverageCost:   stored.AverageCost,
+	}
+}
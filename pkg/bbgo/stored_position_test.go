@@ -0,0 +1,70 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_UpdateStoredPosition(t *testing.T) {
+	ctx := context.Background()
+	environ := NewEnvironment()
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: &testExposureExchange{},
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+	}
+
+	_, err := environ.UpdateStoredPosition(ctx, session, "BTCUSDT")
+	assert.Equal(t, ErrDatabaseNotConfigured, err)
+
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	insertTrade := func(id int64, side types.SideType, price, quantity float64) {
+		assert.NoError(t, environ.TradeService.Insert(types.Trade{
+			ID: id, OrderID: uint64(id), Exchange: types.ExchangeBinance.String(), Symbol: "BTCUSDT",
+			Price: price, Quantity: quantity, QuoteQuantity: price * quantity, Side: side, IsBuyer: side == types.SideTypeBuy,
+			Time: datatype.Time(now.Add(time.Duration(id) * time.Minute)),
+		}))
+	}
+
+	insertTrade(1, types.SideTypeBuy, 30000.0, 1.0)
+	insertTrade(2, types.SideTypeBuy, 40000.0, 1.0)
+
+	stored, err := environ.UpdateStoredPosition(ctx, session, "BTCUSDT")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2.0, stored.Base.Float64())
+		assert.Equal(t, 35000.0, stored.AverageCost.Float64())
+	}
+
+	// the aggregated trades are not aggregated again
+	insertTrade(3, types.SideTypeSell, 50000.0, 0.5)
+	_, err = environ.UpdateStoredPosition(ctx, session, "BTCUSDT")
+	assert.NoError(t, err)
+	_, err = environ.UpdateStoredPosition(ctx, session, "BTCUSDT")
+	assert.NoError(t, err)
+
+	stored, err = environ.PositionService.Query(ctx, "binance", "BTCUSDT")
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.Equal(t, 1.5, stored.Base.Float64())
+		assert.Equal(t, 35000.0, stored.AverageCost.Float64())
+		assert.Equal(t, -45000.0, stored.Quote.Float64())
+
+		position := NewPositionFromStored(stored)
+		assert.Equal(t, "BTC", position.BaseCurrency)
+		assert.Equal(t, 1.5, position.Base.Float64())
+	}
+
+	_, err = environ.UpdateStoredPosition(ctx, session, "ETHUSDT")
+	assert.Error(t, err, "the market is not found")
+}
@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// strategyStream wraps the session stream to recover the panics of the callbacks, so that a panic in a strategy
+// callback neither crashes the process nor stops the read loop of the stream. The callbacks registered while a strategy
+// runs are owned by the strategy, their panics are reported as the strategy errors.
+type strategyStream struct {
+	types.Stream
+
+	session string
+
+	// owner returns the id of the running strategy, it's empty if no strategy is running
+	owner func() string
+
+	report func(err *StrategyError)
+}
+
+func newStrategyStream(session string, stream types.Stream, owner func() string, report func(err *StrategyError)) *strategyStream {
+	return &strategyStream{
+		Stream:  stream,
+		session: session,
+		owner:   owner,
+		report:  report,
+	}
+}
+
+// baseStream returns the stream wrapped by strategyStream and DryRunStream, the optional interfaces of the stream,
+// e.g., types.TunableStream, should be checked against it
+func baseStream(stream types.Stream) types.Stream {
+	for {
+		switch s := stream.(type) {
+		case *strategyStream:
+			stream = s.Stream
+		case *DryRunStream:
+			stream = s.stream
+		default:
+			return stream
+		}
+	}
+}
+
+// recover recovers the panic of the callback of the event, it must be deferred by the callbacks
+func (s *strategyStream) recover(owner, event string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if len(owner) == 0 {
+		log.WithField("session", s.session).Errorf("%s callback panic: %v\n%s", event, r, debug.Stack())
+		return
+	}
+
+	err := &StrategyError{StrategyID: owner, Session: s.session, Err: fmt.Errorf("%s callback: %v", event, r), Panic: true}
+	log.WithFields(err.logFields()).Errorf("strategy %s %s callback panic: %v\n%s", owner, event, r, debug.Stack())
+	s.report(err)
+}
+
+func (s *strategyStream) OnStart(cb func()) {
+	owner := s.owner()
+	s.Stream.OnStart(func() {
+		defer s.recover(owner, "start")
+		cb()
+	})
+}
+
+func (s *strategyStream) OnConnect(cb func()) {
+	owner := s.owner()
+	s.Stream.OnConnect(func() {
+		defer s.recover(owner, "connect")
+		cb()
+	})
+}
+
+func (s *strategyStream) OnDisconnect(cb func()) {
+	owner := s.owner()
+	s.Stream.OnDisconnect(func() {
+		defer s.recover(owner, "disconnect")
+		cb()
+	})
+}
+
+func (s *strategyStream) OnTradeUpdate(cb func(trade types.Trade)) {
+	owner := s.owner()
+	s.Stream.OnTradeUpdate(func(trade types.Trade) {
+		defer s.recover(owner, "trade update")
+		cb(trade)
+	})
+}
+
+func (s *strategyStream) OnOrderUpdate(cb func(order types.Order)) {
+	owner := s.owner()
+	s.Stream.OnOrderUpdate(func(order types.Order) {
+		defer s.recover(owner, "order update")
+		cb(order)
+	})
+}
+
+func (s *strategyStream) OnBalanceSnapshot(cb func(balances types.BalanceMap)) {
+	owner := s.owner()
+	s.Stream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		defer s.recover(owner, "balance snapshot")
+		cb(balances)
+	})
+}
+
+func (s *strategyStream) OnBalanceUpdate(cb func(balances types.BalanceMap)) {
+	owner := s.owner()
+	s.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		defer s.recover(owner, "balance update")
+		cb(balances)
+	})
+}
+
+func (s *strategyStream) OnKLineClosed(cb func(kline types.KLine)) {
+	owner := s.owner()
+	s.Stream.OnKLineClosed(func(kline types.KLine) {
+		defer s.recover(owner, "kline closed")
+		cb(kline)
+	})
+}
+
+func (s *strategyStream) OnKLine(cb func(kline types.KLine)) {
+	owner := s.owner()
+	s.Stream.OnKLine(func(kline types.KLine) {
+		defer s.recover(owner, "kline")
+		cb(kline)
+	})
+}
+
+func (s *strategyStream) OnBookUpdate(cb func(book types.OrderBook)) {
+	owner := s.owner()
+	s.Stream.OnBookUpdate(func(book types.OrderBook) {
+		defer s.recover(owner, "book update")
+		cb(book)
+	})
+}
+
+func (s *strategyStream) OnBookSnapshot(cb func(book types.OrderBook)) {
+	owner := s.owner()
+	s.Stream.OnBookSnapshot(func(book types.OrderBook) {
+		defer s.recover(owner, "book snapshot")
+		cb(book)
+	})
+}
@@ -0,0 +1,350 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// subscriptionScheduleCheckInterval is the interval the subscription schedules are checked
+const subscriptionScheduleCheckInterval = time.Minute
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// SubscriptionSchedule limits the subscriptions of the symbol to the windows, e.g., the market hours of the tokenized
+// stocks, so that the stream is not kept open when the market is not liquid
+type SubscriptionSchedule struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+
+	// Channel is the channel of the subscriptions the schedule applies to, all the channels of the symbol if it's empty
+	Channel types.Channel `json:"channel,omitempty" yaml:"channel,omitempty"`
+
+	// TimeZone is the IANA time zone of the windows, e.g., America/New_York, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+
+	Windows []SubscriptionWindow `json:"windows" yaml:"windows"`
+}
+
+// SubscriptionWindow is the daily window of the subscriptions
+type SubscriptionWindow struct {
+	// Weekdays are the days the window opens, e.g., [mon, tue, wed, thu, fri], the window opens every day if it's empty
+	Weekdays []string `json:"weekdays,omitempty" yaml:"weekdays,omitempty"`
+
+	// Start and End are the time of the day in HH:MM, the window crosses the midnight if End is before Start,
+	// and it lasts the whole day if they are the same
+	Start string `json:"start" yaml:"start"`
+	End   string `json:"end" yaml:"end"`
+}
+
+func (s *SubscriptionSchedule) validate() (problems []string) {
+	if len(s.Symbol) == 0 {
+		problems = append(problems, "symbol is required")
+	}
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid time zone %s", s.TimeZone))
+	}
+
+	if len(s.Windows) == 0 {
+		problems = append(problems, "windows are required")
+	}
+
+	for _, w := range s.Windows {
+		for _, day := range w.Weekdays {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				problems = append(problems, fmt.Sprintf("unknown weekday %s", day))
+			}
+		}
+
+		for _, t := range []string{w.Start, w.End} {
+			if _, err := parseTimeOfDay(t); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	return problems
+}
+
+// matches returns true if the schedule applies to the subscription
+func (s *SubscriptionSchedule) matches(sub types.Subscription) bool {
+	return strings.EqualFold(s.Symbol, sub.Symbol) && (len(s.Channel) == 0 || s.Channel == sub.Channel)
+}
+
+// isOpen returns true if the time is in any of the windows
+func (s *SubscriptionSchedule) isOpen(now time.Time) bool {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now = now.In(loc)
+	for _, w := range s.Windows {
+		if w.isOpen(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *SubscriptionWindow) isOpen(now time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	switch {
+	case start == end:
+		return w.opensOn(now.Weekday())
+
+	case start < end:
+		return w.opensOn(now.Weekday()) && minutes >= start && minutes < end
+
+	default:
+		// the window crosses the midnight, the early hours belong to the window opened on the day before
+		if minutes >= start {
+			return w.opensOn(now.Weekday())
+		}
+
+		return minutes < end && w.opensOn((now.Weekday()+6)%7)
+	}
+}
+
+func (w *SubscriptionWindow) opensOn(day time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+
+	for _, d := range w.Weekdays {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTimeOfDay parses HH:MM into the minutes of the day
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expecting HH:MM", s)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// isSubscriptionScheduled returns true if the subscription is not limited by the schedules or it's in any window of
+// the schedules that apply to it
+func (session *ExchangeSession) isSubscriptionScheduled(sub types.Subscription, now time.Time) bool {
+	matched := false
+	for i := range session.SubscriptionSchedules {
+		schedule := &session.SubscriptionSchedules[i]
+		if !schedule.matches(sub) {
+			continue
+		}
+
+		if schedule.isOpen(now) {
+			return true
+		}
+
+		matched = true
+	}
+
+	return !matched
+}
+
+// subscriptionScheduler subscribes the stream to the subscriptions in their windows, and unsubscribes the others.
+// The stream is reconnected to apply the changed subscriptions, and it's suspended if none of the subscriptions is
+// in its window.
+type subscriptionScheduler struct {
+	session     *ExchangeSession
+	reconnector *streamReconnector
+	notify      func(severity types.Severity, format string, args ...interface{})
+	logger      *log.Entry
+
+	mu sync.Mutex
+
+	// active is the subscriptions in their windows
+	active map[types.Subscription]struct{}
+
+	// subscribed is the subscriptions added to the stream, the subscriptions out of their windows are kept if the
+	// stream can not unsubscribe
+	subscribed map[types.Subscription]struct{}
+}
+
+func newSubscriptionScheduler(session *ExchangeSession, reconnector *streamReconnector, notify func(severity types.Severity, format string, args ...interface{})) *subscriptionScheduler {
+	s := &subscriptionScheduler{
+		session:     session,
+		reconnector: reconnector,
+		notify:      notify,
+		logger:      log.WithField("session", session.Name),
+		subscribed:  make(map[types.Subscription]struct{}),
+	}
+
+	if _, ok := baseStream(session.Stream).(types.Unsubscriber); !ok {
+		s.logger.Warnf("the stream of session %s can not unsubscribe, the scheduled subscriptions are only stopped when all of them are out of their windows", session.Name)
+	}
+
+	return s
+}
+
+// update subscribes the stream to the subscriptions that enter their windows and unsubscribes the subscriptions that
+// leave, true is returned if the active subscriptions are changed
+func (s *subscriptionScheduler) update(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := make(map[types.Subscription]struct{})
+	for _, sub := range s.session.Subscriptions {
+		if s.session.isSubscriptionScheduled(sub, now) {
+			active[sub] = struct{}{}
+		}
+	}
+
+	changed := s.active == nil || len(active) != len(s.active)
+	for sub := range active {
+		if _, ok := s.active[sub]; !ok {
+			changed = true
+		}
+
+		if _, ok := s.subscribed[sub]; ok {
+			continue
+		}
+
+		s.logger.Infof("subscribing %s %s %v", sub.Symbol, sub.Channel, sub.Options)
+		s.session.Stream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+		s.subscribed[sub] = struct{}{}
+	}
+
+	if unsubscriber, ok := baseStream(s.session.Stream).(types.Unsubscriber); ok {
+		for sub := range s.subscribed {
+			if _, ok := active[sub]; ok {
+				continue
+			}
+
+			s.logger.Infof("unsubscribing %s %s %v", sub.Symbol, sub.Channel, sub.Options)
+			unsubscriber.Unsubscribe(sub.Channel, sub.Symbol, sub.Options)
+			delete(s.subscribed, sub)
+		}
+	}
+
+	s.active = active
+	return changed
+}
+
+func (s *subscriptionScheduler) activeSubscriptions() (subscriptions []types.Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.active {
+		subscriptions = append(subscriptions, sub)
+	}
+
+	return subscriptions
+}
+
+// start subscribes the stream to the subscriptions in their windows and connects the stream,
+// the stream is suspended if none of the subscriptions is in its window
+func (s *subscriptionScheduler) start(ctx context.Context, now time.Time) error {
+	s.update(now)
+
+	if len(s.activeSubscriptions()) == 0 {
+		s.logger.Infof("none of the subscriptions of session %s is in its window, the stream is suspended", s.session.Name)
+		s.reconnector.suspend()
+		return nil
+	}
+
+	s.logger.Infof("connecting session %s...", s.session.Name)
+	return s.reconnector.connect(ctx)
+}
+
+// check applies the changed subscriptions to the stream
+func (s *subscriptionScheduler) check(ctx context.Context, now time.Time) {
+	if !s.update(now) {
+		return
+	}
+
+	var err error
+	switch {
+	case len(s.activeSubscriptions()) == 0:
+		s.reconnector.suspend()
+		s.notify(types.SeverityInfo, "exchange session %s stream is suspended by the subscription schedule", s.session.Name)
+		return
+
+	case s.reconnector.isSuspended():
+		if err = s.reconnector.resume(ctx); err == nil {
+			s.notify(types.SeverityInfo, "exchange session %s stream is resumed by the subscription schedule", s.session.Name)
+			return
+		}
+
+	default:
+		s.logger.Infof("the scheduled subscriptions are changed, reconnecting...")
+		err = s.reconnector.reconnect(ctx)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		s.logger.WithError(err).Error("stream connect error")
+		s.notify(types.SeverityCritical, "exchange session %s stream connect failed: %v", s.session.Name, err)
+	}
+}
+
+func (s *subscriptionScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(subscriptionScheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			s.check(ctx, now)
+		}
+	}
+}
+
+// ActiveSubscriptions returns the subscriptions of the session that are in their schedule windows, all the
+// subscriptions are returned if the session has no subscription schedule or it's not connected yet
+func (environ *Environment) ActiveSubscriptions(sessionName string) []types.Subscription {
+	environ.sessionsMutex.RLock()
+	scheduler, ok := environ.subscriptionSchedulers[sessionName]
+	environ.sessionsMutex.RUnlock()
+
+	if ok {
+		return scheduler.activeSubscriptions()
+	}
+
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil
+	}
+
+	var subscriptions []types.Subscription
+	for _, sub := range session.Subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+
+	return subscriptions
+}
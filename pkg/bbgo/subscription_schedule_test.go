@@ -0,0 +1,139 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSubscriptionSchedule_IsOpen(t *testing.T) {
+	schedule := SubscriptionSchedule{
+		Symbol:   "AAPLUSD",
+		TimeZone: "America/New_York",
+		Windows: []SubscriptionWindow{
+			{Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:30", End: "16:00"},
+		},
+	}
+	assert.Empty(t, schedule.validate())
+
+	ny, err := time.LoadLocation("America/New_York")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 2021-03-01 is a Monday
+	assert.True(t, schedule.isOpen(time.Date(2021, 3, 1, 9, 30, 0, 0, ny)))
+	assert.True(t, schedule.isOpen(time.Date(2021, 3, 1, 20, 0, 0, 0, time.UTC)), "15:00 in New York")
+	assert.False(t, schedule.isOpen(time.Date(2021, 3, 1, 16, 0, 0, 0, ny)))
+	assert.False(t, schedule.isOpen(time.Date(2021, 3, 6, 12, 0, 0, 0, ny)), "saturday")
+
+	overnight := SubscriptionWindow{Weekdays: []string{"Fri"}, Start: "22:00", End: "02:00"}
+	assert.True(t, overnight.isOpen(time.Date(2021, 3, 5, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.isOpen(time.Date(2021, 3, 6, 1, 0, 0, 0, time.UTC)), "the early hours of saturday")
+	assert.False(t, overnight.isOpen(time.Date(2021, 3, 5, 1, 0, 0, 0, time.UTC)), "the early hours of friday")
+
+	invalid := SubscriptionSchedule{
+		TimeZone: "Mars/Olympus",
+		Windows:  []SubscriptionWindow{{Weekdays: []string{"someday"}, Start: "9am", End: "16:00"}},
+	}
+	assert.Equal(t, []string{
+		"symbol is required",
+		"invalid time zone Mars/Olympus",
+		"unknown weekday someday",
+		`invalid time of day "9am", expecting HH:MM`,
+	}, invalid.validate())
+}
+
+func TestSubscriptionScheduler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &testReconnectStream{}
+	reconnector, notifications := newTestReconnector(stream, nil)
+
+	session := reconnector.session
+	session.Subscriptions = make(map[types.Subscription]types.Subscription)
+	session.SubscriptionSchedules = []SubscriptionSchedule{
+		{Symbol: "AAPLUSD", Windows: []SubscriptionWindow{{Start: "14:30", End: "21:00"}}},
+	}
+
+	aapl := types.Subscription{Symbol: "AAPLUSD", Channel: types.BookChannel}
+	btc := types.Subscription{Symbol: "BTCUSDT", Channel: types.KLineChannel, Options: types.SubscribeOptions{Interval: "1m"}}
+	session.Subscriptions[aapl] = aapl
+	session.Subscriptions[btc] = btc
+
+	scheduler := newSubscriptionScheduler(session, reconnector, notifications.notify)
+
+	// the unscheduled subscription keeps the stream connected
+	closed := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	assert.NoError(t, scheduler.start(ctx, closed))
+	assert.Equal(t, []types.Subscription{btc}, stream.Subscriptions)
+	connects, closes := stream.counts()
+	assert.Equal(t, 1, connects)
+	assert.Equal(t, 0, closes)
+
+	// the market opens
+	scheduler.check(ctx, time.Date(2021, 3, 1, 15, 0, 0, 0, time.UTC))
+	assert.ElementsMatch(t, []types.Subscription{aapl, btc}, stream.Subscriptions)
+	connects, closes = stream.counts()
+	assert.Equal(t, 2, connects)
+	assert.Equal(t, 1, closes)
+	assert.True(t, reconnector.isConnected())
+
+	// nothing is changed
+	scheduler.check(ctx, time.Date(2021, 3, 1, 15, 1, 0, 0, time.UTC))
+	connects, _ = stream.counts()
+	assert.Equal(t, 2, connects)
+
+	// the market closes
+	scheduler.check(ctx, time.Date(2021, 3, 1, 21, 0, 0, 0, time.UTC))
+	assert.Equal(t, []types.Subscription{btc}, stream.Subscriptions)
+	assert.ElementsMatch(t, []types.Subscription{btc}, scheduler.activeSubscriptions())
+	assert.Empty(t, notifications.get(), "the reconnects by the schedule are not notified as the disconnects")
+}
+
+func TestSubscriptionScheduler_Suspend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &testReconnectStream{}
+	reconnector, notifications := newTestReconnector(stream, nil)
+
+	session := reconnector.session
+	session.Subscriptions = make(map[types.Subscription]types.Subscription)
+	session.SubscriptionSchedules = []SubscriptionSchedule{
+		{Symbol: "AAPLUSD", Windows: []SubscriptionWindow{{Weekdays: []string{"mon"}, Start: "14:30", End: "21:00"}}},
+	}
+
+	aapl := types.Subscription{Symbol: "AAPLUSD", Channel: types.BookChannel}
+	session.Subscriptions[aapl] = aapl
+
+	scheduler := newSubscriptionScheduler(session, reconnector, notifications.notify)
+
+	// the stream is not connected out of the window
+	assert.NoError(t, scheduler.start(ctx, time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)))
+	connects, _ := stream.counts()
+	assert.Equal(t, 0, connects)
+	assert.True(t, reconnector.isSuspended())
+
+	scheduler.check(ctx, time.Date(2021, 3, 1, 15, 0, 0, 0, time.UTC))
+	connects, _ = stream.counts()
+	assert.Equal(t, 1, connects)
+	assert.False(t, reconnector.isSuspended())
+
+	scheduler.check(ctx, time.Date(2021, 3, 2, 15, 0, 0, 0, time.UTC))
+	connects, closes := stream.counts()
+	assert.Equal(t, 1, connects)
+	assert.Equal(t, 1, closes)
+	assert.True(t, reconnector.isSuspended())
+	assert.Empty(t, stream.Subscriptions)
+
+	assert.Equal(t, []string{
+		"exchange session binance stream is resumed by the subscription schedule",
+		"exchange session binance stream is suspended by the subscription schedule",
+	}, notifications.get())
+}
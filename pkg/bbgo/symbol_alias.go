@@ -0,0 +1,69 @@
+package bbgo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// symbolSeparators are removed from the symbols by CanonicalSymbol, e.g., BTC/USDT, BTC-USDT and btc_usdt are BTCUSDT
+var symbolSeparators = strings.NewReplacer("/", "", "-", "", "_", "", ":", "")
+
+// CanonicalSymbol returns the canonical form of the symbol, which is the upper case symbol without the separators
+func CanonicalSymbol(symbol string) string {
+	return strings.ToUpper(symbolSeparators.Replace(symbol))
+}
+
+// SymbolNormalizer maps the exchange specific symbols to the canonical symbols, e.g., XBTUSD to BTCUSDT,
+// so the symbol channel routes match the same market on all the exchanges.
+type SymbolNormalizer struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+func NewSymbolNormalizer(aliases map[string]string) *SymbolNormalizer {
+	normalizer := &SymbolNormalizer{}
+	normalizer.SetAliases(aliases)
+	return normalizer
+}
+
+// SetAliases replaces the alias map, the keys and the values are converted to the canonical form
+func (n *SymbolNormalizer) SetAliases(aliases map[string]string) {
+	canonical := make(map[string]string, len(aliases))
+	for alias, symbol := range aliases {
+		canonical[CanonicalSymbol(alias)] = CanonicalSymbol(symbol)
+	}
+
+	n.mu.Lock()
+	n.aliases = canonical
+	n.mu.Unlock()
+}
+
+// Normalize returns the canonical symbol of the exchange symbol, the aliases are resolved once, they're not chained
+func (n *SymbolNormalizer) Normalize(symbol string) string {
+	canonical := CanonicalSymbol(symbol)
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if aliased, ok := n.aliases[canonical]; ok {
+		return aliased
+	}
+
+	return canonical
+}
+
+func validateSymbolAliases(aliases map[string]string) (problems []string) {
+	for _, alias := range sortedKeys(aliases) {
+		if len(CanonicalSymbol(alias)) == 0 {
+			problems = append(problems, "empty alias symbol")
+			continue
+		}
+
+		if len(CanonicalSymbol(aliases[alias])) == 0 {
+			problems = append(problems, fmt.Sprintf("alias %s is mapped to an empty symbol", alias))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,59 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSymbolNormalizer_Normalize(t *testing.T) {
+	normalizer := NewSymbolNormalizer(map[string]string{"xbtusd": "BTC/USDT", "XBT-USDT": "BTCUSDT"})
+
+	assert.Equal(t, "BTCUSDT", normalizer.Normalize("XBTUSD"))
+	assert.Equal(t, "BTCUSDT", normalizer.Normalize("XBT_USDT"))
+	assert.Equal(t, "BTCUSDT", normalizer.Normalize("btc/usdt"))
+	assert.Equal(t, "ETHUSDT", normalizer.Normalize("ETH-USDT"))
+}
+
+func TestEnvironment_ConfigureNotificationRouting_SymbolAliases(t *testing.T) {
+	environ, notifier := newTestEnvironment("ftx")
+
+	err := environ.ConfigureNotificationRouting(&NotificationConfig{
+		SymbolChannels: map[string]string{"^BTCUSDT$": "#btc", "^ETH-PERP$": "#eth-perp"},
+		SymbolAliases:  map[string]string{"XBTUSD": "BTCUSDT"},
+		Routing:        &SlackNotificationRouting{Trade: "$symbol"},
+	})
+	assert.NoError(t, err)
+
+	channel, ok := environ.RouteSymbol("BTC/USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "#btc", channel)
+
+	channel, ok = environ.RouteSymbol("ETH-PERP")
+	assert.True(t, ok)
+	assert.Equal(t, "#eth-perp", channel, "the original symbol should be matched if the canonical symbol matches no route")
+
+	trade := &types.Trade{Symbol: "XBTUSD"}
+	channel, ok = environ.RouteObject(trade)
+	assert.True(t, ok)
+	assert.Equal(t, "#btc", channel)
+	assert.Equal(t, "XBTUSD", trade.Symbol, "the routed object should keep the exchange symbol")
+
+	environ.sessions["ftx"].Stream.(*testStream).EmitTradeUpdate(types.Trade{Symbol: "XBTUSD"})
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Equal(t, "#btc", notifier.notifications[0].channel)
+	}
+
+	// the normalizer is reset with the routes
+	assert.NoError(t, environ.reloadNotificationRouting(&NotificationConfig{SymbolChannels: map[string]string{"^BTCUSDT$": "#btc"}}))
+	_, ok = environ.RouteSymbol("XBTUSD")
+	assert.False(t, ok)
+}
+
+func TestValidateSymbolAliases(t *testing.T) {
+	assert.Equal(t, []string{"empty alias symbol", "alias XBTUSD is mapped to an empty symbol"},
+		validateSymbolAliases(map[string]string{"/": "BTCUSDT", "XBTUSD": ""}))
+	assert.Empty(t, validateSymbolAliases(map[string]string{"XBTUSD": "BTCUSDT"}))
+}
@@ -0,0 +1,58 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultSyncInterval is the interval of the scheduled sync if the interval is not configured
+const DefaultSyncInterval = time.Hour
+
+// StartSyncScheduler runs Sync on every interval in the background until the context is done,
+// the scheduled sync is skipped if the previous sync is still in progress.
+func (environ *Environment) StartSyncScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+
+	log.Infof("starting the sync scheduler, interval: %s", interval)
+	go environ.runSyncScheduler(ctx, interval)
+}
+
+func (environ *Environment) runSyncScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			environ.scheduledSync(ctx)
+		}
+	}
+}
+
+// scheduledSync runs Sync and notifies the sync error, the sync started by the other callers, e.g., the sync api,
+// is not waited, so that the scheduled syncs don't pile up on the sync mutex.
+func (environ *Environment) scheduledSync(ctx context.Context) {
+	if environ.IsSyncing() == Syncing {
+		log.Warn("the previous sync is still in progress, skipping the scheduled sync")
+		return
+	}
+
+	if err := environ.Sync(ctx); err != nil {
+		// the sync is interrupted by the shutdown
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.WithError(err).Error("scheduled sync error")
+		environ.NotifyToWithSeverity(types.SeverityWarning, "", "scheduled sync error: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_StartSyncScheduler(t *testing.T) {
+	defer func(delay time.Duration) { findSymbolsRetryDelay = delay }(findSymbolsRetryDelay)
+	findSymbolsRetryDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	environ, notifier := newTestEnvironment()
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the symbols of the session can not be found, the sync fails
+	environ.sessions["binance"] = &ExchangeSession{Name: "binance", Exchange: &testInitExchange{}}
+
+	// the scheduled sync is skipped while the other sync is in progress
+	environ.syncMutex.Lock()
+	environ.setSyncing(Syncing)
+	environ.scheduledSync(ctx)
+	environ.finishSync(nil)
+	environ.syncMutex.Unlock()
+	assert.Len(t, notifier.notifications, 0)
+
+	environ.scheduledSync(ctx)
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Contains(t, notifier.notifications[0].text, "scheduled sync error")
+	}
+
+	assert.Equal(t, SyncDone, environ.IsSyncing())
+	assert.False(t, environ.LastSyncTime().IsZero(), "the last successful sync time is kept")
+
+	// the scheduler stops when the context is cancelled
+	done := make(chan struct{})
+	go func() {
+		environ.runSyncScheduler(ctx, time.Hour)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the sync scheduler should stop when the context is cancelled")
+	}
+}
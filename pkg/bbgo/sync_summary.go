@@ -0,0 +1,90 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// SyncRecordSummary is the number of the records of a type synced for a symbol,
+// the symbol is empty for the account records, e.g., deposits, withdraws and rewards
+type SyncRecordSummary struct {
+	Symbol  string                 `json:"symbol,omitempty"`
+	Type    service.SyncRecordType `json:"type"`
+	Records int                    `json:"records"`
+}
+
+// SyncSummary is the summary of a session sync
+type SyncSummary struct {
+	Session   string              `json:"session"`
+	StartTime time.Time           `json:"startTime"`
+	EndTime   time.Time           `json:"endTime"`
+	Records   []SyncRecordSummary `json:"records"`
+	Error     string              `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+type syncRecordKey struct {
+	symbol     string
+	recordType service.SyncRecordType
+}
+
+// add updates the number of the synced records from the progress event, the symbols are synced concurrently
+func (s *SyncSummary) add(progress service.SyncProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, record := range s.Records {
+		if record.Symbol == progress.Symbol && record.Type == progress.Type {
+			if progress.Records > record.Records {
+				s.Records[i].Records = progress.Records
+			}
+			return
+		}
+	}
+
+	s.Records = append(s.Records, SyncRecordSummary{
+		Symbol:  progress.Symbol,
+		Type:    progress.Type,
+		Records: progress.Records,
+	})
+}
+
+// SyncSessionWithSummary syncs the session like SyncSession, and returns the summary of the synced records.
+// The summary is returned even if the sync fails, the error is also recorded in the summary.
+func (environ *Environment) SyncSessionWithSummary(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) (*SyncSummary, error) {
+	summary := &SyncSummary{Session: session.Name, StartTime: time.Now()}
+	if environ.SyncService == nil {
+		summary.EndTime = summary.StartTime
+		return summary, nil
+	}
+
+	environ.syncMutex.Lock()
+	defer environ.syncMutex.Unlock()
+
+	environ.setSyncing(Syncing)
+
+	err := environ.syncSession(ctx, session, summary, defaultSymbols...)
+	environ.finishSync(err)
+
+	summary.EndTime = time.Now()
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	// the account records go last
+	sort.SliceStable(summary.Records, func(i, j int) bool {
+		a, b := summary.Records[i], summary.Records[j]
+		if a.Symbol != b.Symbol {
+			return len(b.Symbol) == 0 || (len(a.Symbol) > 0 && a.Symbol < b.Symbol)
+		}
+
+		return false
+	})
+
+	return summary, err
+}
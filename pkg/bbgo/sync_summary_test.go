@@ -0,0 +1,48 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func TestEnvironment_SyncSessionWithSummary(t *testing.T) {
+	ctx := context.Background()
+	environ := NewEnvironment()
+	if err := environ.ConfigureDatabaseDriver(ctx, "sqlite3", ":memory:"); err != nil {
+		t.Fatal(err)
+	}
+
+	exchange := &testSyncExchange{
+		orderIDs: map[string]uint64{"BTCUSDT": 201, "ETHUSDT": 202, "LTCUSDT": 203},
+		queries:  make(map[string]int),
+	}
+	session := &ExchangeSession{Name: "binance", Exchange: exchange}
+
+	summary, err := environ.SyncSessionWithSummary(ctx, session, "LTCUSDT", "ETHUSDT", "BTCUSDT")
+	assert.Error(t, err)
+
+	if assert.NotNil(t, summary) {
+		assert.Equal(t, "binance", summary.Session)
+		assert.Contains(t, summary.Error, "ETHUSDT")
+		assert.False(t, summary.EndTime.Before(summary.StartTime))
+
+		var records = make(map[string]int)
+		var symbols []string
+		for _, record := range summary.Records {
+			records[record.Symbol+"-"+string(record.Type)] = record.Records
+			if len(symbols) == 0 || symbols[len(symbols)-1] != record.Symbol {
+				symbols = append(symbols, record.Symbol)
+			}
+		}
+
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "LTCUSDT", ""}, symbols, "the records should be sorted by symbol, the account records go last")
+		assert.Equal(t, 1, records["BTCUSDT-"+string(service.SyncRecordTrade)])
+		assert.Equal(t, 1, records["BTCUSDT-"+string(service.SyncRecordOrder)])
+		assert.Equal(t, 1, records["LTCUSDT-"+string(service.SyncRecordTrade)])
+		assert.Equal(t, 0, records["ETHUSDT-"+string(service.SyncRecordTrade)])
+	}
+}
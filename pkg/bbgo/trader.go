@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -16,6 +17,46 @@ import (
 
 var SupportedExchanges = []types.ExchangeName{"binance", "max", "ftx"}
 
+// StrategyError is the run error or the recovered panic of a strategy
+type StrategyError struct {
+	StrategyID string
+
+	// Session is the session of the single exchange strategy, it's empty for the cross exchange strategy
+	Session string
+
+	Err error
+
+	// Panic is true if the error is recovered from a panic of the strategy
+	Panic bool
+}
+
+func (e *StrategyError) Error() string {
+	var what = "run error"
+	if e.Panic {
+		what = "panic"
+	}
+
+	if len(e.Session) == 0 {
+		return fmt.Sprintf("cross exchange strategy %s %s: %v", e.StrategyID, what, e.Err)
+	}
+
+	return fmt.Sprintf("strategy %s on session %s %s: %v", e.StrategyID, e.Session, what, e.Err)
+}
+
+// logFields returns the strategy and the session fields of the error logs
+func (e *StrategyError) logFields() log.Fields {
+	fields := log.Fields{"strategy": e.StrategyID}
+	if len(e.Session) > 0 {
+		fields["session"] = e.Session
+	}
+
+	return fields
+}
+
+func (e *StrategyError) Unwrap() error {
+	return e.Err
+}
+
 // SingleExchangeStrategy represents the single Exchange strategy
 type SingleExchangeStrategy interface {
 	ID() string
@@ -80,6 +121,10 @@ type Trader struct {
 
 	logger Logger
 
+	// runningStrategy is the id of the strategy being run, the stream callbacks registered by the strategy are owned by it
+	mu              sync.Mutex
+	runningStrategy string
+
 	Graceful Graceful
 }
 
@@ -203,7 +248,7 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 		return errors.New("strategy object is not a struct")
 	}
 
-	if err := trader.injectCommonServices(rs); err != nil {
+	if err := trader.injectCommonServices(rs, strategy.ID()); err != nil {
 		return err
 	}
 
@@ -277,13 +322,20 @@ func (trader *Trader) getSessionOrderExecutor(sessionName string) OrderExecutor
 	return orderExecutor
 }
 
+// RunAllSingleExchangeStrategy runs the single exchange strategies, the strategy panics are reported and the other
+// strategies keep running, the run errors stop the trader
 func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
+	trader.wrapSessionStreams()
+
 	// load and run Session strategies
 	for sessionName, strategies := range trader.exchangeStrategies {
 		var session = trader.environment.sessions[sessionName]
 		var orderExecutor = trader.getSessionOrderExecutor(sessionName)
 		for _, strategy := range strategies {
-			if err := trader.RunSingleExchangeStrategy(ctx, strategy, session, orderExecutor); err != nil {
+			err := trader.runStrategy(strategy.ID(), sessionName, func() error {
+				return trader.RunSingleExchangeStrategy(ctx, strategy, session, orderExecutor)
+			})
+			if err != nil && !isStrategyPanic(err) {
 				return err
 			}
 		}
@@ -292,6 +344,30 @@ func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
 	return nil
 }
 
+// wrapSessionStreams wraps the session streams with strategyStream, so that the panics of the strategy callbacks are
+// recovered, the streams already wrapped are kept
+func (trader *Trader) wrapSessionStreams() {
+	for name, session := range trader.environment.sessions {
+		if _, ok := session.Stream.(*strategyStream); ok {
+			continue
+		}
+
+		session.Stream = newStrategyStream(name, session.Stream, trader.currentStrategy, trader.environment.ReportStrategyError)
+	}
+}
+
+func (trader *Trader) currentStrategy() string {
+	trader.mu.Lock()
+	defer trader.mu.Unlock()
+	return trader.runningStrategy
+}
+
+func (trader *Trader) setRunningStrategy(strategyID string) {
+	trader.mu.Lock()
+	trader.runningStrategy = strategyID
+	trader.mu.Unlock()
+}
+
 func (trader *Trader) Run(ctx context.Context) error {
 	trader.Subscribe()
 
@@ -318,11 +394,14 @@ func (trader *Trader) Run(ctx context.Context) error {
 			continue
 		}
 
-		if err := trader.injectCommonServices(rs); err != nil {
+		if err := trader.injectCommonServices(rs, strategy.ID()); err != nil {
 			return err
 		}
 
-		if err := strategy.CrossRun(ctx, router, trader.environment.sessions); err != nil {
+		err := trader.runStrategy(strategy.ID(), "", func() error {
+			return strategy.CrossRun(ctx, router, trader.environment.sessions)
+		})
+		if err != nil && !isStrategyPanic(err) {
 			return err
 		}
 	}
@@ -330,7 +409,38 @@ func (trader *Trader) Run(ctx context.Context) error {
 	return trader.environment.Connect(ctx)
 }
 
-func (trader *Trader) injectCommonServices(rs reflect.Value) error {
+// runStrategy runs the strategy and recovers the panic of the strategy, so that the panic doesn't crash the process.
+// The run error or the panic is returned as a *StrategyError, which is reported to the notifiers and the strategy error callbacks.
+// The stream callbacks registered during the run are owned by the strategy.
+func (trader *Trader) runStrategy(strategyID, session string, run func() error) (err error) {
+	trader.setRunningStrategy(strategyID)
+
+	defer func() {
+		trader.setRunningStrategy("")
+
+		if r := recover(); r != nil {
+			strategyErr := &StrategyError{StrategyID: strategyID, Session: session, Err: fmt.Errorf("%v", r), Panic: true}
+			log.WithFields(strategyErr.logFields()).Errorf("strategy %s panic: %v\n%s", strategyID, r, debug.Stack())
+			err = strategyErr
+		} else if err != nil {
+			err = &StrategyError{StrategyID: strategyID, Session: session, Err: err}
+		}
+
+		if err != nil {
+			trader.environment.ReportStrategyError(err.(*StrategyError))
+		}
+	}()
+
+	return run()
+}
+
+// isStrategyPanic returns true if the error is a recovered panic of the strategy
+func isStrategyPanic(err error) bool {
+	strategyErr, ok := err.(*StrategyError)
+	return ok && strategyErr.Panic
+}
+
+func (trader *Trader) injectCommonServices(rs reflect.Value, strategyID string) error {
 	if err := injectField(rs, "Graceful", &trader.Graceful, true); err != nil {
 		return errors.Wrap(err, "failed to inject Graceful")
 	}
@@ -339,7 +449,8 @@ func (trader *Trader) injectCommonServices(rs reflect.Value) error {
 		return errors.Wrap(err, "failed to inject Logger")
 	}
 
-	if err := injectField(rs, "Notifiability", &trader.environment.Notifiability, false); err != nil {
+	// the notifications of the strategy are routed by the strategy routing
+	if err := injectField(rs, "Notifiability", trader.environment.Notifiability.ForStrategy(strategyID), false); err != nil {
 		return errors.Wrap(err, "failed to inject Notifiability")
 	}
 
@@ -0,0 +1,111 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTrader_runStrategy(t *testing.T) {
+	environ, notifier := newTestEnvironment("binance")
+	trader := NewTrader(environ)
+
+	var reported []error
+	environ.OnStrategyError(func(strategyID string, err error) {
+		assert.Equal(t, "grid", strategyID)
+		reported = append(reported, err)
+	})
+
+	err := trader.runStrategy("grid", "binance", func() error {
+		var prices map[string]float64
+		prices["BTCUSDT"] = 1000.0
+		return nil
+	})
+
+	var strategyErr *StrategyError
+	if assert.True(t, errors.As(err, &strategyErr), "the panic should be recovered") {
+		assert.True(t, strategyErr.Panic)
+		assert.Equal(t, "binance", strategyErr.Session)
+	}
+
+	runErr := errors.New("insufficient balance")
+	err = trader.runStrategy("grid", "", func() error {
+		return runErr
+	})
+	assert.True(t, errors.Is(err, runErr))
+	assert.EqualError(t, err, "cross exchange strategy grid run error: insufficient balance")
+
+	assert.Len(t, reported, 2)
+	if assert.Len(t, notifier.notifications, 2) {
+		assert.Contains(t, notifier.notifications[0].text, "strategy grid on session binance panic")
+	}
+
+	assert.NoError(t, trader.runStrategy("grid", "binance", func() error { return nil }))
+	assert.Len(t, reported, 2)
+}
+
+type testCallbackStrategy struct {
+	id       string
+	panicRun bool
+	onKLine  func(kline types.KLine)
+}
+
+func (s *testCallbackStrategy) ID() string {
+	return s.id
+}
+
+func (s *testCallbackStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	if s.onKLine != nil {
+		session.Stream.OnKLineClosed(s.onKLine)
+	}
+
+	if s.panicRun {
+		panic("run panic")
+	}
+
+	return nil
+}
+
+func TestTrader_RunAllSingleExchangeStrategy_Panic(t *testing.T) {
+	environ, _ := newTestEnvironment("binance")
+	stream := environ.sessions["binance"].Stream.(*testStream)
+	trader := NewTrader(environ)
+
+	var reported []*StrategyError
+	environ.OnStrategyError(func(strategyID string, err error) {
+		reported = append(reported, err.(*StrategyError))
+	})
+
+	var closed []types.KLine
+	assert.NoError(t, trader.AttachStrategyOn("binance",
+		&testCallbackStrategy{id: "panic-run", panicRun: true},
+		&testCallbackStrategy{id: "panic-callback", onKLine: func(kline types.KLine) {
+			panic("callback panic")
+		}},
+		&testCallbackStrategy{id: "grid", onKLine: func(kline types.KLine) {
+			closed = append(closed, kline)
+		}},
+	))
+
+	assert.NoError(t, trader.RunAllSingleExchangeStrategy(context.Background()), "the panic should not stop the trader")
+	if assert.Len(t, reported, 1) {
+		assert.Equal(t, "panic-run", reported[0].StrategyID)
+		assert.True(t, reported[0].Panic)
+	}
+
+	assert.NotPanics(t, func() {
+		stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT"})
+	})
+
+	assert.Len(t, closed, 1, "the callbacks of the other strategies should be called")
+	if assert.Len(t, reported, 2) {
+		assert.Equal(t, "panic-callback", reported[1].StrategyID)
+		assert.Equal(t, "binance", reported[1].Session)
+		assert.True(t, reported[1].Panic)
+		assert.EqualError(t, reported[1], "strategy panic-callback on session binance panic: kline closed callback: callback panic")
+	}
+}
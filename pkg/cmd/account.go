@@ -39,7 +39,7 @@ var accountCmd = &cobra.Command{
 		}
 
 		var userConfig *bbgo.Config
-		if _, err := os.Stat(configFile); err == nil {
+		if _, err := statConfigFile(configFile); err == nil {
 			// load successfully
 			userConfig, err = bbgo.Load(configFile, false)
 			if err != nil {
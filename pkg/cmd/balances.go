@@ -43,7 +43,7 @@ var balancesCmd = &cobra.Command{
 		// if config file exists, use the config loaded from the config file.
 		// otherwise, use a empty config object
 		var userConfig *bbgo.Config
-		if _, err := os.Stat(configFile); err == nil {
+		if _, err := statConfigFile(configFile); err == nil {
 			// load successfully
 			userConfig, err = bbgo.Load(configFile, false)
 			if err != nil {
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	RootCmd.AddCommand(CheckCmd)
+}
+
+// CheckCmd checks the config without connecting the exchanges, the database and the chat bots, it's useful in CI.
+// go run ./cmd/bbgo check config/bbgo.yaml
+var CheckCmd = &cobra.Command{
+	Use:          "check [config file]",
+	Short:        "check the config without connecting to the exchanges",
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(args) > 0 {
+			configFile = args[0]
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("config file is required")
+		}
+
+		userConfig, err := bbgo.Load(configFile, true)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.CheckConfig(userConfig); err != nil {
+			return err
+		}
+
+		log.Infof("config %s is valid: %d sessions, %d exchange strategies, %d cross exchange strategies",
+			configFile, len(environ.Sessions()), len(userConfig.ExchangeStrategies), len(userConfig.CrossExchangeStrategies))
+		return nil
+	},
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	RootCmd.AddCommand(clockDriftCmd)
+}
+
+// clockDriftCmd checks the drift against the server time of the session exchanges, it fails if the drift exceeds the threshold:
+// go run ./cmd/bbgo clock-drift --config config/bbgo.yaml
+var clockDriftCmd = &cobra.Command{
+	Use:          "clock-drift",
+	Short:        "check the drift of the local clock against the exchange server time",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		if userConfig.ClockDrift != nil {
+			environ.ConfigureClockDrift(userConfig.ClockDrift)
+		}
+
+		drifts, err := environ.CheckClockDrift(ctx)
+
+		exceeded := false
+		for _, drift := range drifts {
+			status := "ok"
+			if drift.Exceeded {
+				status = "exceeded"
+				exceeded = true
+			}
+
+			fmt.Printf("%-16s drift: %-14s round trip: %-14s %s\n", drift.Session, drift.Drift, drift.RoundTrip, status)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// exit with the error, so that the check can be used in the deployment scripts
+		if exceeded {
+			return errors.New("the clock drift exceeds the threshold")
+		}
+
+		return nil
+	},
+}
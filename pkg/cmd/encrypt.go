@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+func init() {
+	EncryptCmd.Flags().Bool("generate-key", false, "generate a new master key")
+	RootCmd.AddCommand(EncryptCmd)
+}
+
+// EncryptCmd encrypts the secret values for the session config with the master key from the BBGO_MASTER_KEY env var
+var EncryptCmd = &cobra.Command{
+	Use:          "encrypt [value]",
+	Short:        "encrypt the api key or secret for the session config",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		generateKey, err := cmd.Flags().GetBool("generate-key")
+		if err != nil {
+			return err
+		}
+
+		if generateKey {
+			key, err := util.GenerateMasterKey()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(key)
+			return nil
+		}
+
+		key, err := util.MasterKeyFromEnv()
+		if err != nil {
+			return err
+		}
+
+		var value string
+		if len(args) > 0 {
+			value = args[0]
+		} else {
+			// read the value from stdin so that it does not stay in the shell history
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && len(line) == 0 {
+				return err
+			}
+
+			value = strings.TrimSpace(line)
+		}
+
+		if len(value) == 0 {
+			return fmt.Errorf("empty value")
+		}
+
+		encrypted, err := util.EncryptSecret(value, key)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(encrypted)
+		return nil
+	},
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ExportCmd.Flags().String("session", "", "the exchange session name to export, all sessions are exported if it's not set")
+	ExportCmd.Flags().String("symbol", "", "the symbol to export, all symbols are exported if it's not set")
+	ExportCmd.Flags().String("since", "", "export the records since the date, e.g., 2021-01-01")
+	ExportCmd.Flags().String("until", "", "export the records before the date, e.g., 2022-01-01")
+	ExportCmd.Flags().String("output-dir", ".", "the directory of the exported csv files")
+	RootCmd.AddCommand(ExportCmd)
+}
+
+// ExportCmd exports the synced trades and orders as csv files, one trades file and one orders file per session
+// go run ./cmd/bbgo export --config config/bbgo.yaml --session binance --since 2021-01-01 --until 2022-01-01 --output-dir exports
+var ExportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "export the synced trades and orders as csv files",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		since, err := parseDateFlag(cmd, "since")
+		if err != nil {
+			return err
+		}
+
+		until, err := parseDateFlag(cmd, "until")
+		if err != nil {
+			return err
+		}
+
+		outputDir, err := cmd.Flags().GetString("output-dir")
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return bbgo.ErrDatabaseNotConfigured
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		var sessionNames []string
+		if len(sessionName) > 0 {
+			sessionNames = []string{sessionName}
+		} else {
+			for name := range environ.Sessions() {
+				sessionNames = append(sessionNames, name)
+			}
+			sort.Strings(sessionNames)
+		}
+
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return err
+		}
+
+		for _, name := range sessionNames {
+			tradesFile := filepath.Join(outputDir, name+"_trades.csv")
+			if err := exportCSVFile(tradesFile, func(w io.Writer) error {
+				return environ.ExportTradesCSV(w, name, symbol, since, until)
+			}); err != nil {
+				return err
+			}
+
+			ordersFile := filepath.Join(outputDir, name+"_orders.csv")
+			if err := exportCSVFile(ordersFile, func(w io.Writer) error {
+				return environ.ExportOrdersCSV(w, name, symbol, since, until)
+			}); err != nil {
+				return err
+			}
+
+			log.Infof("exported the trades and the orders of session %s to %s and %s", name, tradesFile, ordersFile)
+		}
+
+		return nil
+	},
+}
+
+// parseDateFlag parses the date flag in the types.DateFormat, the zero time is returned if the flag is not set
+func parseDateFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(types.DateFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s date %q: %w", name, value, err)
+	}
+
+	return t, nil
+}
+
+func exportCSVFile(path string, export func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := export(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ImportCmd.Flags().String("session", "", "the exchange session name to import the klines from")
+	ImportCmd.Flags().String("symbol", "", "the symbol to import")
+	ImportCmd.Flags().StringSlice("interval", []string{"1m"}, "the kline intervals to import")
+	ImportCmd.Flags().String("since", "", "import the klines since the date, e.g., 2021-01-01")
+	ImportCmd.Flags().String("until", "", "import the klines until the date, defaults to now")
+	RootCmd.AddCommand(ImportCmd)
+}
+
+// ImportCmd imports the historical klines into the database for backtesting
+// go run ./cmd/bbgo import --session binance --symbol BTCUSDT --interval 1m --interval 1h --since 2021-01-01
+var ImportCmd = &cobra.Command{
+	Use:          "import",
+	Short:        "import the historical klines for backtesting",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(sessionName) == 0 || len(symbol) == 0 {
+			return errors.New("--session and --symbol options are required")
+		}
+
+		intervals, err := cmd.Flags().GetStringSlice("interval")
+		if err != nil {
+			return err
+		}
+
+		sinceStr, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		since, err := time.Parse(types.DateFormat, sinceStr)
+		if err != nil {
+			return fmt.Errorf("invalid --since date %q: %w", sinceStr, err)
+		}
+
+		until := time.Now()
+		untilStr, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if len(untilStr) > 0 {
+			until, err = time.Parse(types.DateFormat, untilStr)
+			if err != nil {
+				return fmt.Errorf("invalid --until date %q: %w", untilStr, err)
+			}
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		for _, interval := range intervals {
+			if _, ok := types.SupportedIntervals[types.Interval(interval)]; !ok {
+				return fmt.Errorf("unsupported interval %s", interval)
+			}
+
+			imported, err := environ.ImportBacktestData(ctx, sessionName, symbol, types.Interval(interval), since, until)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("imported %d %s %s klines from session %s", imported, symbol, interval, sessionName)
+		}
+
+		return nil
+	},
+}
@@ -33,7 +33,7 @@ var marketCmd = &cobra.Command{
 			return errors.New("--config option is required")
 		}
 
-		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if _, err := statConfigFile(configFile); os.IsNotExist(err) {
 			return err
 		}
 
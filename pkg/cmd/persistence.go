@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func init() {
+	persistenceExportCmd.Flags().String("type", "", "the persistence type to export, e.g., json, redis, bolt or dynamodb, defaults to the primary persistence")
+	persistenceExportCmd.Flags().String("output", "", "the output file of the snapshot, defaults to stdout")
+	persistenceImportCmd.Flags().String("type", "", "the persistence type to import into, defaults to the primary persistence")
+	persistenceImportCmd.Flags().String("input", "", "the snapshot file to import, defaults to stdin")
+	PersistenceCmd.AddCommand(persistenceExportCmd)
+	PersistenceCmd.AddCommand(persistenceImportCmd)
+	RootCmd.AddCommand(PersistenceCmd)
+}
+
+// PersistenceCmd backs up and restores the persisted strategy states, e.g., to move the states from json to redis:
+// go run ./cmd/bbgo persistence export --config config/bbgo.yaml --type json --output states.json
+// go run ./cmd/bbgo persistence import --config config/bbgo.yaml --type redis --input states.json
+var PersistenceCmd = &cobra.Command{
+	Use:   "persistence",
+	Short: "export and import the persisted states",
+}
+
+var persistenceExportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "export all the persisted states as a json document",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		persistence, err := selectPersistence(cmd)
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if len(output) > 0 {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if err := service.ExportPersistence(persistence, w); err != nil {
+			return err
+		}
+
+		if len(output) > 0 {
+			log.Infof("the persisted states are exported to %s", output)
+		}
+
+		return nil
+	},
+}
+
+var persistenceImportCmd = &cobra.Command{
+	Use:          "import",
+	Short:        "import the persisted states from the exported json document",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		persistence, err := selectPersistence(cmd)
+		if err != nil {
+			return err
+		}
+
+		input, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = os.Stdin
+		if len(input) > 0 {
+			f, err := os.Open(input)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		if err := service.ImportPersistence(persistence, r); err != nil {
+			return err
+		}
+
+		log.Infof("the persisted states are imported into %T", persistence)
+		return nil
+	},
+}
+
+// selectPersistence configures the persistence of the config and returns the persistence service of the --type flag
+func selectPersistence(cmd *cobra.Command) (service.PersistenceService, error) {
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configFile) == 0 {
+		return nil, errors.New("--config option is required")
+	}
+
+	persistenceType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return nil, err
+	}
+
+	userConfig, err := bbgo.Load(configFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if userConfig.Persistence == nil {
+		return nil, fmt.Errorf("persistence is not configured in %s", configFile)
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
+		return nil, err
+	}
+
+	if len(persistenceType) == 0 {
+		return environ.PersistenceServiceFacade.Get(), nil
+	}
+
+	return environ.PersistenceServiceFacade.Select(persistenceType)
+}
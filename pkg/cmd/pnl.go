@@ -14,6 +14,7 @@ import (
 	"github.com/c9s/bbgo/pkg/accounting"
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -23,6 +24,9 @@ func init() {
 	PnLCmd.Flags().String("symbol", "", "trading symbol")
 	PnLCmd.Flags().Bool("include-transfer", false, "convert transfer records into trades")
 	PnLCmd.Flags().Int("limit", 500, "number of trades")
+	PnLCmd.Flags().String("format", "text", "the output format of the report, text or json")
+	PnLCmd.Flags().String("since", "30d", "the start time of the notified report, a duration like 7d or a date like 2021-06-01, it's used with --notify")
+	PnLCmd.Flags().Bool("notify", false, "send the report of the synced trades since the start time to the pnl channels of the sessions, all the sessions of the symbol are reported if --session is not given")
 	RootCmd.AddCommand(PnLCmd)
 }
 
@@ -42,7 +46,7 @@ var PnLCmd = &cobra.Command{
 			return errors.New("--config option is required")
 		}
 
-		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if _, err := statConfigFile(configFile); os.IsNotExist(err) {
 			return err
 		}
 
@@ -70,6 +74,16 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		formatStr, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		outputFormat, err := types.ParseOutputFormat(formatStr)
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 
 		if err := environ.ConfigureDatabase(ctx); err != nil {
@@ -80,6 +94,25 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		notify, err := cmd.Flags().GetBool("notify")
+		if err != nil {
+			return err
+		}
+
+		if notify {
+			sinceStr, err := cmd.Flags().GetString("since")
+			if err != nil {
+				return err
+			}
+
+			since, err := interact.ParseSince(sinceStr, time.Now())
+			if err != nil {
+				return err
+			}
+
+			return notifyPnLReports(ctx, environ, userConfig, sessionName, symbol, since, outputFormat)
+		}
+
 		session, ok := environ.Session(sessionName)
 		if !ok {
 			return fmt.Errorf("session %s not found", sessionName)
@@ -180,7 +213,81 @@ var PnLCmd = &cobra.Command{
 		}
 
 		report := calculator.Calculate(symbol, trades, currentPrice)
+		report.Market = market
+
+		environ.SetReportingCurrency(userConfig.ReportingCurrency)
+		if err := environ.ConfigurePriceSources(userConfig.PriceSources); err != nil {
+			return err
+		}
+
+		environ.ConvertPnLReport(ctx, session, report)
+
+		if outputFormat == types.OutputFormatJSON {
+			return printJSON(report)
+		}
+
 		report.Print()
 		return nil
 	},
 }
+
+// notifyPnLReports syncs the trades and notifies the PnL reports of the symbol since the start time like the pnl chat
+// command, the reports are also printed
+func notifyPnLReports(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config, sessionName, symbol string, since time.Time, outputFormat types.OutputFormat) error {
+	// the telegram subscribers are loaded from the persistence
+	if userConfig.Persistence != nil {
+		if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
+			return err
+		}
+	}
+
+	if err := environ.ConfigureNotificationSystem(userConfig); err != nil {
+		return err
+	}
+
+	environ.SetReportingCurrency(userConfig.ReportingCurrency)
+	if err := environ.ConfigurePriceSources(userConfig.PriceSources); err != nil {
+		return err
+	}
+
+	if len(sessionName) > 0 {
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		if err := environ.SyncSession(ctx, session, symbol); err != nil {
+			return err
+		}
+	} else if err := environ.Sync(ctx); err != nil {
+		return err
+	}
+
+	reports, since, err := environ.NotifyPnLReports(ctx, sessionName, symbol, since)
+	if err != nil {
+		return err
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(ctx, 30*time.Second)
+	defer cancelFlush()
+
+	if err := environ.FlushNotifications(flushCtx); err != nil {
+		log.WithError(err).Error("can not flush the notifications")
+	}
+
+	if len(reports) == 0 {
+		log.Infof("no %s trades since %s", symbol, since.Format(time.RFC3339))
+		return nil
+	}
+
+	if outputFormat == types.OutputFormatJSON {
+		return printJSON(reports)
+	}
+
+	for name, report := range reports {
+		fmt.Printf("session %s:\n", name)
+		report.Print()
+	}
+
+	return nil
+}
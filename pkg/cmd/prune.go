@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	PruneCmd.Flags().String("session", "", "the exchange session name to prune, all sessions are pruned if it's not set")
+	PruneCmd.Flags().String("symbol", "", "the symbol to prune, all the synced symbols of the session are pruned if it's not set")
+	PruneCmd.Flags().String("until", "", "delete the trades and the orders before the date, e.g., 2021-01-01")
+	PruneCmd.Flags().Bool("skip-optimize", false, "do not vacuum (sqlite3) or optimize the tables after the records are deleted")
+	PruneCmd.Flags().String("format", "text", "the output format of the prune result, text or json")
+	RootCmd.AddCommand(PruneCmd)
+}
+
+var PruneCmd = &cobra.Command{
+	Use:          "prune",
+	Short:        "delete the old trades and orders from the database",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		if _, err := statConfigFile(configFile); os.IsNotExist(err) {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		untilStr, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if len(untilStr) == 0 {
+			return errors.New("--until option is required")
+		}
+
+		loc, err := time.LoadLocation("Local")
+		if err != nil {
+			return err
+		}
+
+		until, err := time.ParseInLocation("2006-01-02", untilStr, loc)
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		skipOptimize, err := cmd.Flags().GetBool("skip-optimize")
+		if err != nil {
+			return err
+		}
+
+		formatStr, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		outputFormat, err := types.ParseOutputFormat(formatStr)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		var symbols []string
+		if len(symbol) > 0 {
+			symbols = []string{symbol}
+		}
+
+		var selectedSessions []string
+		if len(sessionName) > 0 {
+			selectedSessions = []string{sessionName}
+		}
+
+		var results []bbgo.PruneResult
+		for _, session := range environ.SelectSessions(selectedSessions...) {
+			sessionResults, err := environ.PruneSession(ctx, session, until, symbols...)
+			results = append(results, sessionResults...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !skipOptimize {
+			log.Infof("optimizing the database...")
+			if err := environ.OptimizeDatabase(ctx); err != nil {
+				return errors.Wrap(err, "can not optimize the database")
+			}
+		}
+
+		if outputFormat == types.OutputFormatJSON {
+			return printJSON(results)
+		}
+
+		var trades, orders int64
+		for _, result := range results {
+			log.Infof("%s %s: %d trades, %d orders deleted", result.Session, result.Symbol, result.Trades, result.Orders)
+			trades += result.Trades
+			orders += result.Orders
+		}
+
+		log.Infof("%d trades, %d orders deleted in total", trades, orders)
+		return nil
+	},
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ReconcileCmd.Flags().String("session", "", "the exchange session name to reconcile")
+	ReconcileCmd.Flags().String("symbol", "", "the symbol to reconcile")
+	ReconcileCmd.Flags().String("since", "", "reconcile the trades since the date, e.g., 2021-01-01, defaults to 7 days ago")
+	ReconcileCmd.Flags().Bool("insert-missing", false, "insert the exchange trades that are missing in the database")
+	ReconcileCmd.Flags().String("format", "text", "the output format of the reconciliation report, text or json")
+	RootCmd.AddCommand(ReconcileCmd)
+}
+
+// ReconcileCmd compares the stored trades with the exchange trades
+// go run ./cmd/bbgo reconcile --session binance --symbol BTCUSDT --since 2021-01-01 --insert-missing
+var ReconcileCmd = &cobra.Command{
+	Use:          "reconcile",
+	Short:        "reconcile the stored trades against the exchange trades",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(sessionName) == 0 || len(symbol) == 0 {
+			return errors.New("--session and --symbol options are required")
+		}
+
+		since := time.Now().AddDate(0, 0, -7)
+		sinceStr, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		if len(sinceStr) > 0 {
+			since, err = time.ParseInLocation(types.DateFormat, sinceStr, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --since date %q: %w", sinceStr, err)
+			}
+		}
+
+		insertMissing, err := cmd.Flags().GetBool("insert-missing")
+		if err != nil {
+			return err
+		}
+
+		formatStr, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		outputFormat, err := types.ParseOutputFormat(formatStr)
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		reconciliation, err := environ.ReconcileTrades(ctx, sessionName, symbol, since, insertMissing)
+		if err != nil {
+			return err
+		}
+
+		if outputFormat == types.OutputFormatJSON {
+			return printJSON(reconciliation)
+		}
+
+		for _, trade := range reconciliation.Missing {
+			log.Warnf("missing trade: %d %s %s price: %f volume: %f %s", trade.ID, trade.Symbol, trade.Side, trade.Price, trade.Quantity, trade.Time.String())
+		}
+
+		for _, trade := range reconciliation.Unknown {
+			log.Warnf("unknown trade, not found on the exchange: %d %s %s price: %f volume: %f %s", trade.ID, trade.Symbol, trade.Side, trade.Price, trade.Quantity, trade.Time.String())
+		}
+
+		log.Infof("%s %s trades since %s: %d matched, %d missing, %d unknown, %d inserted",
+			sessionName, reconciliation.Symbol, since, reconciliation.Matched,
+			len(reconciliation.Missing), len(reconciliation.Unknown), reconciliation.Inserted)
+		return nil
+	},
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/x-cray/logrus-prefixed-formatter"
 
 	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
 )
 
 var RootCmd = &cobra.Command{
@@ -44,6 +46,22 @@ var RootCmd = &cobra.Command{
 			}
 		}
 
+		unsafeLogSecrets, err := cmd.Flags().GetBool("unsafe-log-secrets")
+		if err != nil {
+			return err
+		}
+
+		// the log format flag overrides the logging config
+		if logFormat := viper.GetString("log-format"); len(logFormat) > 0 {
+			if err := bbgo.ConfigureLogging(&bbgo.LoggingConfig{Format: logFormat}); err != nil {
+				return err
+			}
+		}
+
+		if unsafeLogSecrets {
+			log.Warn("--unsafe-log-secrets is enabled, the api keys and secrets of the sessions are no longer redacted in the logs")
+		}
+
 		return nil
 	},
 
@@ -54,10 +72,13 @@ var RootCmd = &cobra.Command{
 
 func init() {
 	RootCmd.PersistentFlags().Bool("debug", false, "debug flag")
-	RootCmd.PersistentFlags().String("config", "bbgo.yaml", "config file")
+	RootCmd.PersistentFlags().String("config", "bbgo.yaml", "config file, multiple config files separated by commas are merged in order")
 
 	RootCmd.PersistentFlags().Bool("no-dotenv", false, "disable built-in dotenv")
 	RootCmd.PersistentFlags().String("dotenv", ".env.local", "the dotenv file you want to load")
+	RootCmd.PersistentFlags().Bool("unsafe-log-secrets", false, "do not redact the api keys and secrets of the sessions in the logs, for debugging only")
+	RootCmd.PersistentFlags().Bool("db-skip-upgrade", false, "do not run the database migrations, verify the schema version instead")
+	RootCmd.PersistentFlags().String("log-format", "", "the log format, text or json, it overrides the logging config")
 
 	// A flag can be 'persistent' meaning that this flag will be available to
 	// the command it's assigned to as well as every command under that command.
@@ -65,9 +86,15 @@ func init() {
 	RootCmd.PersistentFlags().String("slack-token", "", "slack token")
 	RootCmd.PersistentFlags().String("slack-channel", "dev-bbgo", "slack trading channel")
 	RootCmd.PersistentFlags().String("slack-error-channel", "bbgo-error", "slack error channel")
+	RootCmd.PersistentFlags().String("slack-app-token", "", "slack app-level token for the socket mode slash commands")
+	RootCmd.PersistentFlags().String("slack-app-auth-token", "", "slack slash command auth token")
+
+	RootCmd.PersistentFlags().String("discord-bot-token", "", "discord bot token")
 
 	RootCmd.PersistentFlags().String("telegram-bot-token", "", "telegram bot token from bot father")
 	RootCmd.PersistentFlags().String("telegram-bot-auth-token", "", "telegram auth token")
+	RootCmd.PersistentFlags().String("telegram-api-url", "", "telegram bot API URL, e.g., a mirror or a proxy, defaults to https://api.telegram.org")
+	RootCmd.PersistentFlags().Duration("telegram-poller-timeout", 0, "telegram long polling timeout, defaults to 10s")
 
 	RootCmd.PersistentFlags().String("binance-api-key", "", "binance api key")
 	RootCmd.PersistentFlags().String("binance-api-secret", "", "binance api secret")
@@ -22,12 +22,15 @@ import (
 func init() {
 	RunCmd.Flags().Bool("no-compile", false, "do not compile wrapper binary")
 	RunCmd.Flags().String("totp-key-url", "", "time-based one-time password key URL, if defined, it will be used for restoring the otp key")
-	RunCmd.Flags().String("totp-issuer", "", "")
-	RunCmd.Flags().String("totp-account-name", "", "")
+	RunCmd.Flags().String("totp-issuer", "", "the issuer of the generated one-time password key, overrides the notifications.totp.issuer config")
+	RunCmd.Flags().String("totp-account-name", "", "the account name of the generated one-time password key, overrides the notifications.totp.accountName config")
+	RunCmd.Flags().String("otp-qr-dir", "", "the directory to write the one-time password qrcode image, defaults to the temp directory")
+	RunCmd.Flags().Bool("otp-qr-ascii", false, "print the one-time password qrcode to stdout instead of writing the png image")
 	RunCmd.Flags().Bool("enable-webserver", false, "enable webserver")
 	RunCmd.Flags().Bool("enable-web-server", false, "legacy option, this is renamed to --enable-webserver")
 	RunCmd.Flags().String("webserver-bind", ":8080", "webserver binding")
 	RunCmd.Flags().Bool("setup", false, "use setup mode")
+	RunCmd.Flags().Bool("dry-run", false, "simulate the orders with the live market data instead of sending them to the exchanges")
 	RootCmd.AddCommand(RunCmd)
 }
 
@@ -75,6 +78,9 @@ func runSetup(baseCtx context.Context, userConfig *bbgo.Config, enableApiServer
 
 	log.Infof("shutting down...")
 	trader.Graceful.Shutdown(shutdownCtx)
+	if err := environ.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Errorf("environment shutdown error")
+	}
 	cancelShutdown()
 	return nil
 }
@@ -96,6 +102,10 @@ func BootstrapBacktestEnvironment(ctx context.Context, environ *bbgo.Environment
 
 
 func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config) error {
+	if err := configureLogging(userConfig); err != nil {
+		return err
+	}
+
 	if err := environ.ConfigureDatabase(ctx); err != nil {
 		return err
 	}
@@ -104,6 +114,32 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 		return errors.Wrap(err, "exchange session configure error")
 	}
 
+	if userConfig.Sync != nil {
+		environ.ConfigureSync(userConfig.Sync)
+	}
+
+	if userConfig.Health != nil {
+		environ.ConfigureHealth(userConfig.Health)
+	}
+
+	if userConfig.ClockDrift != nil {
+		environ.ConfigureClockDrift(userConfig.ClockDrift)
+	}
+
+	if userConfig.Metrics != nil {
+		environ.ConfigureMetrics(userConfig.Metrics)
+	}
+
+	if userConfig.BalanceSnapshot != nil {
+		environ.ConfigureBalanceSnapshot(userConfig.BalanceSnapshot)
+	}
+
+	environ.SetReportingCurrency(userConfig.ReportingCurrency)
+
+	if err := environ.ConfigurePriceSources(userConfig.PriceSources); err != nil {
+		return errors.Wrap(err, "price source configure error")
+	}
+
 	if userConfig.Persistence != nil {
 		if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
 			return errors.Wrap(err, "persistence configure error")
@@ -117,11 +153,18 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 	return nil
 }
 
-func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer bool, webServerBind string) error {
+func runConfig(basectx context.Context, configFile string, userConfig *bbgo.Config, enableWebServer bool, webServerBind string, dryRun bool) error {
 	ctx, cancelTrading := context.WithCancel(basectx)
 	defer cancelTrading()
 
 	environ := bbgo.NewEnvironment()
+	environ.SetDryRun(dryRun)
+
+	// validate the config before connecting anything, so that the typos in the config fail fast
+	if err := userConfig.Validate(environ); err != nil {
+		return err
+	}
+
 	if err := BootstrapEnvironment(ctx, environ, userConfig); err != nil {
 		return err
 	}
@@ -130,6 +173,10 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer
 		return err
 	}
 
+	if _, err := environ.Validate(ctx); err != nil {
+		return err
+	}
+
 	if err := environ.Sync(ctx); err != nil {
 		return err
 	}
@@ -157,7 +204,10 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer
 		}()
 	}
 
-	cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	// SIGHUP reloads the config, the other signals shut down the process
+	for cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP) == syscall.SIGHUP {
+		reloadConfig(ctx, environ, configFile)
+	}
 
 	cancelTrading()
 
@@ -165,10 +215,46 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer
 
 	log.Infof("shutting down...")
 	trader.Graceful.Shutdown(shutdownCtx)
+	if err := environ.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Errorf("environment shutdown error")
+	}
 	cancelShutdown()
 	return nil
 }
 
+// reloadConfig reloads the config files and applies the additive changes to the environment,
+// the running config is kept if the reloaded config can not be loaded or it's invalid
+func reloadConfig(ctx context.Context, environ *bbgo.Environment, configFile string) {
+	if len(configFile) == 0 {
+		log.Warn("no config file is specified, skipping the config reload")
+		return
+	}
+
+	log.Infof("reloading config %s...", configFile)
+
+	userConfig, err := bbgo.Load(configFile, false)
+	if err != nil {
+		log.WithError(err).Errorf("can not reload the config, the running config is kept")
+		return
+	}
+
+	if err := userConfig.Validate(environ); err != nil {
+		log.WithError(err).Errorf("the reloaded config is invalid, the running config is kept")
+		return
+	}
+
+	result, err := environ.Reload(ctx, userConfig)
+	if err != nil {
+		log.WithError(err).Errorf("config reload error")
+		environ.Notify("config reload error: %v", err)
+	}
+
+	if result != nil {
+		log.Info(result.String())
+		environ.Notify(result.String())
+	}
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	setup, err := cmd.Flags().GetBool("setup")
 	if err != nil {
@@ -199,6 +285,11 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
 	configFile, err := cmd.Flags().GetString("config")
 	if err != nil {
 		return err
@@ -212,7 +303,7 @@ func run(cmd *cobra.Command, args []string) error {
 			return errors.New("--config option is required")
 		}
 
-		if _, err := os.Stat(configFile); err != nil {
+		if _, err := statConfigFile(configFile); err != nil {
 			return err
 		}
 
@@ -240,7 +331,7 @@ func run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return runConfig(ctx, userConfig, enableWebServer, webServerBind)
+		return runConfig(ctx, configFile, userConfig, enableWebServer, webServerBind, dryRun)
 	}
 
 	return runWrapperBinary(ctx, userConfig, cmd, args)
@@ -258,18 +349,24 @@ func runWrapperBinary(ctx context.Context, userConfig *bbgo.Config, cmd *cobra.C
 		return err
 	}
 
-	if sig := cmdutil.WaitForSignal(ctx, syscall.SIGTERM, syscall.SIGINT); sig != nil {
+	for {
+		sig := cmdutil.WaitForSignal(ctx, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+		if sig == nil {
+			return nil
+		}
+
 		log.Infof("sending signal to the child process...")
 		if err := runCmd.Process.Signal(sig); err != nil {
 			return err
 		}
 
-		if err := runCmd.Wait(); err != nil {
-			return err
+		// the child process reloads the config on SIGHUP
+		if sig == syscall.SIGHUP {
+			continue
 		}
-	}
 
-	return nil
+		return runCmd.Wait()
+	}
 }
 
 // buildAndRun builds the package natively and run the binary with the given args
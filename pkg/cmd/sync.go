@@ -10,12 +10,17 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
 	SyncCmd.Flags().String("session", "", "the exchange session name for sync")
+	SyncCmd.Flags().StringSlice("tag", nil, "sync the exchange sessions labeled with the tags instead of the --session option")
 	SyncCmd.Flags().String("symbol", "", "symbol of market for syncing")
 	SyncCmd.Flags().String("since", "", "sync from time")
+	SyncCmd.Flags().Bool("full-sync", false, "ignore the stored sync cursors and sync from the --since time")
+	SyncCmd.Flags().Int("concurrency", bbgo.DefaultSyncConcurrency, "the number of the symbols synced concurrently")
+	SyncCmd.Flags().String("format", "text", "the output format of the sync summary, text or json")
 	RootCmd.AddCommand(SyncCmd)
 }
 
@@ -35,7 +40,7 @@ var SyncCmd = &cobra.Command{
 			return errors.New("--config option is required")
 		}
 
-		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if _, err := statConfigFile(configFile); os.IsNotExist(err) {
 			return err
 		}
 
@@ -44,11 +49,35 @@ var SyncCmd = &cobra.Command{
 			return err
 		}
 
+		if err := configureLogging(userConfig); err != nil {
+			return err
+		}
+
 		since, err := cmd.Flags().GetString("since")
 		if err != nil {
 			return err
 		}
 
+		fullSync, err := cmd.Flags().GetBool("full-sync")
+		if err != nil {
+			return err
+		}
+
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			return err
+		}
+
+		formatStr, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		outputFormat, err := types.ParseOutputFormat(formatStr)
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 		if err := environ.ConfigureDatabase(ctx); err != nil {
 			return err
@@ -58,10 +87,10 @@ var SyncCmd = &cobra.Command{
 			return err
 		}
 
-		var (
-			// default sync start time
-			startTime = time.Now().AddDate(-1, 0, 0)
-		)
+		// the --since option overrides the sync config
+		if userConfig.Sync != nil {
+			environ.ConfigureSync(userConfig.Sync)
+		}
 
 		if len(since) > 0 {
 			loc, err := time.LoadLocation("Local")
@@ -69,10 +98,12 @@ var SyncCmd = &cobra.Command{
 				return err
 			}
 
-			startTime, err = time.ParseInLocation("2006-01-02", since, loc)
+			startTime, err := time.ParseInLocation("2006-01-02", since, loc)
 			if err != nil {
 				return err
 			}
+
+			environ.SetSyncStartTime(startTime)
 		}
 
 		sessionName, err := cmd.Flags().GetString("session")
@@ -85,7 +116,19 @@ var SyncCmd = &cobra.Command{
 			return err
 		}
 
-		environ.SetSyncStartTime(startTime)
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			return err
+		}
+
+		environ.SetFullSync(fullSync)
+		environ.SetSyncConcurrency(concurrency)
+		environ.OnSyncProgress(func(progress bbgo.SyncProgress) {
+			// log the start of each record type and every 100 records fetched
+			if progress.Records%100 == 0 {
+				log.Infof("syncing %s %s %ss: %d records fetched", progress.Session, progress.Symbol, progress.Type, progress.Records)
+			}
+		})
 
 		var defaultSymbols []string
 		if len(symbol) > 0 {
@@ -98,15 +141,42 @@ var SyncCmd = &cobra.Command{
 			selectedSessions = []string{sessionName}
 		}
 
-		sessions := environ.SelectSessions(selectedSessions...)
+		var sessions map[string]*bbgo.ExchangeSession
+		if len(tags) > 0 {
+			sessions = environ.SelectSessionsByTag(tags...)
+		} else {
+			sessions = environ.SelectSessions(selectedSessions...)
+		}
+
+		var summaries []*bbgo.SyncSummary
 		for _, session := range sessions {
-			if err := environ.SyncSession(ctx, session, defaultSymbols...); err != nil {
+			summary, err := environ.SyncSessionWithSummary(ctx, session, defaultSymbols...)
+			summaries = append(summaries, summary)
+			if err != nil {
+				if outputFormat == types.OutputFormatJSON {
+					_ = printJSON(summaries)
+				}
+
 				return err
 			}
 
 			log.Infof("exchange session %s synchronization done", session.Name)
 		}
 
+		if outputFormat == types.OutputFormatJSON {
+			return printJSON(summaries)
+		}
+
+		for _, summary := range summaries {
+			for _, record := range summary.Records {
+				if len(record.Symbol) > 0 {
+					log.Infof("%s %s: %d %ss synced", summary.Session, record.Symbol, record.Records, record.Type)
+				} else {
+					log.Infof("%s: %d %ss synced", summary.Session, record.Records, record.Type)
+				}
+			}
+		}
+
 		return nil
 	},
 }
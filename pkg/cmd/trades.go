@@ -35,7 +35,7 @@ var tradesCmd = &cobra.Command{
 		// if config file exists, use the config loaded from the config file.
 		// otherwise, use a empty config object
 		var userConfig *bbgo.Config
-		if _, err := os.Stat(configFile); err == nil {
+		if _, err := statConfigFile(configFile); err == nil {
 			// load successfully
 			userConfig, err = bbgo.Load(configFile, false)
 			if err != nil {
@@ -111,7 +111,7 @@ var tradeUpdateCmd = &cobra.Command{
 		// if config file exists, use the config loaded from the config file.
 		// otherwise, use a empty config object
 		var userConfig *bbgo.Config
-		if _, err := os.Stat(configFile); err == nil {
+		if _, err := statConfigFile(configFile); err == nil {
 			// load successfully
 			userConfig, err = bbgo.Load(configFile, false)
 			if err != nil {
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/viper"
 
+	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/exchange/ftx"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -27,3 +30,35 @@ func newExchange(session string) (types.Exchange, error) {
 	}
 	return nil, fmt.Errorf("unsupported session %s", session)
 }
+
+// printJSON prints the object as indented JSON to stdout, the logs go to stderr so the output can be piped
+func printJSON(obj interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(obj)
+}
+
+// configureLogging applies the logging config, the config is skipped if the log format is set by the --log-format flag
+func configureLogging(userConfig *bbgo.Config) error {
+	if userConfig.Logging == nil || len(viper.GetString("log-format")) > 0 {
+		return nil
+	}
+
+	return bbgo.ConfigureLogging(userConfig.Logging)
+}
+
+// statConfigFile checks the local config files, the remote config, e.g., https:// or s3://, is checked when it's
+// loaded. Multiple config files are separated by commas, the file info of the last local file is returned.
+func statConfigFile(configFile string) (info os.FileInfo, err error) {
+	for _, f := range bbgo.ConfigFiles(configFile) {
+		if bbgo.IsRemoteConfig(f) {
+			continue
+		}
+
+		if info, err = os.Stat(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
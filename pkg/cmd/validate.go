@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	RootCmd.AddCommand(ValidateCmd)
+}
+
+// ValidateCmd checks the api credentials of the sessions, it exits with an error if any of the credentials is invalid.
+// go run ./cmd/bbgo validate --config config/bbgo.yaml
+var ValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "validate the api credentials of the exchange sessions",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		var userConfig = &bbgo.Config{}
+		if _, err := statConfigFile(configFile); err == nil {
+			userConfig, err = bbgo.Load(configFile, false)
+			if err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		validity, err := environ.Validate(ctx)
+
+		var names []string
+		for name := range validity {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if validity[name] {
+				log.Infof("session %s: credentials are valid", name)
+			} else {
+				log.Errorf("session %s: credentials are invalid", name)
+			}
+		}
+
+		return err
+	},
+}
@@ -12,6 +12,10 @@ import (
 
 type ClosedOrderBatchQuery struct {
 	types.Exchange
+
+	// MaxWindow is the maximum time range of a query, the time range of the batch query is split into the windows of
+	// this size for the exchanges that reject or truncate the long time range. Zero means the time range is not split.
+	MaxWindow time.Duration
 }
 
 func (e ClosedOrderBatchQuery) Query(ctx context.Context, symbol string, startTime, endTime time.Time, lastOrderID uint64) (c chan types.Order, errC chan error) {
@@ -34,16 +38,27 @@ func (e ClosedOrderBatchQuery) Query(ctx context.Context, symbol string, startTi
 				logrus.WithError(err).Error("rate limit error")
 			}
 
-			logrus.Infof("batch querying %s closed orders %s <=> %s", symbol, startTime, endTime)
+			windowEndTime := endTime
+			if e.MaxWindow > 0 && startTime.Add(e.MaxWindow).Before(endTime) {
+				windowEndTime = startTime.Add(e.MaxWindow)
+			}
+
+			logrus.Infof("batch querying %s closed orders %s <=> %s", symbol, startTime, windowEndTime)
 
-			orders, err := e.QueryClosedOrders(ctx, symbol, startTime, endTime, lastOrderID)
+			orders, err := e.QueryClosedOrders(ctx, symbol, startTime, windowEndTime, lastOrderID)
 			if err != nil {
 				errC <- err
 				return
 			}
 
 			if len(orders) == 0 || (len(orders) == 1 && orders[0].OrderID == lastOrderID) {
-				return
+				if windowEndTime.Equal(endTime) {
+					return
+				}
+
+				// no more orders in this window, move to the next window
+				startTime = windowEndTime
+				continue
 			}
 
 			for _, o := range orders {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 )
 
 //go:generate callbackgen -type DepthFrame
@@ -14,6 +15,9 @@ type DepthFrame struct {
 	client  *binance.Client
 	context context.Context
 
+	// futuresClient fetches the snapshots of the futures depth, the spot client is used if it's nil
+	futuresClient *futures.Client
+
 	mu            sync.Mutex
 	once          sync.Once
 	SnapshotDepth *DepthEvent
@@ -131,8 +135,11 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 			return
 		}
 
+		// the futures events are chained by the previous update ID, their first update IDs are not continuous
+		chained := e.PreviousUpdateID > 0 && e.PreviousUpdateID == f.SnapshotDepth.FinalUpdateID
+
 		// if the first update ID > final update ID + 1, it means something is missing, we need to reload.
-		if e.FirstUpdateID > f.SnapshotDepth.FinalUpdateID+1 {
+		if !chained && e.FirstUpdateID > f.SnapshotDepth.FinalUpdateID+1 {
 			if debugBinanceDepth {
 				log.Warnf("event first update id %d > final update id + 1 (%d), resetting snapshot", e.FirstUpdateID, f.SnapshotDepth.FirstUpdateID+1)
 			}
@@ -156,6 +163,10 @@ func (f *DepthFrame) fetch(ctx context.Context) (*DepthEvent, error) {
 		log.Infof("fetching %s depth snapshot", f.Symbol)
 	}
 
+	if f.futuresClient != nil {
+		return f.fetchFutures(ctx)
+	}
+
 	response, err := f.client.NewDepthService().Symbol(f.Symbol).Do(ctx)
 	if err != nil {
 		return nil, err
@@ -176,3 +187,25 @@ func (f *DepthFrame) fetch(ctx context.Context) (*DepthEvent, error) {
 
 	return &event, nil
 }
+
+func (f *DepthFrame) fetchFutures(ctx context.Context) (*DepthEvent, error) {
+	response, err := f.futuresClient.NewDepthService().Symbol(f.Symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event := DepthEvent{
+		FirstUpdateID: 0,
+		FinalUpdateID: response.LastUpdateID,
+	}
+
+	for _, entry := range response.Bids {
+		event.Bids = append(event.Bids, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+	}
+
+	for _, entry := range response.Asks {
+		event.Asks = append(event.Asks, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+	}
+
+	return &event, nil
+}
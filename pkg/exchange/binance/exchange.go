@@ -3,16 +3,19 @@ package binance
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/datatype"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -27,29 +30,81 @@ var log = logrus.WithFields(logrus.Fields{
 func init() {
 	_ = types.Exchange(&Exchange{})
 	_ = types.MarginExchange(&Exchange{})
+	_ = types.FuturesExchange(&Exchange{})
+	_ = types.RateLimitedExchange(&Exchange{})
+	_ = types.SandboxExchange(&Exchange{})
+	_ = types.ProxyExchange(&Exchange{})
 
 	if ok, _ := strconv.ParseBool(os.Getenv("DEBUG_BINANCE_STREAM")); ok {
 		log.Level = logrus.DebugLevel
 	}
 }
 
+// TestnetAPIURL is the REST endpoint of the spot testnet, the testnet api keys are created at https://testnet.binance.vision
+const TestnetAPIURL = "https://testnet.binance.vision"
+
 type Exchange struct {
 	types.MarginSettings
 
+	// FuturesSettings switches the account, the orders, the trades, the markets and the klines to the USDⓈ-M futures api,
+	// the tickers and the deposit and withdraw history are still queried from the spot api
+	types.FuturesSettings
+
 	Client *binance.Client
+
+	// futuresClient is the REST API client of the USDⓈ-M futures account
+	futuresClient *futures.Client
+
+	// sandbox makes the streams connect to the testnet
+	sandbox bool
+
+	// proxyURL is the proxy of the streams, the streams connect directly if it's nil
+	proxyURL *url.URL
 }
 
 func New(key, secret string) *Exchange {
 	var client = binance.NewClient(key, secret)
 	return &Exchange{
-		Client: client,
+		Client:        client,
+		futuresClient: binance.NewFuturesClient(key, secret),
 	}
 }
 
+// SetRateLimiter limits the request rate of the REST API client
+func (e *Exchange) SetRateLimiter(limiter *rate.Limiter) {
+	e.Client.HTTPClient = util.NewRateLimitedHTTPClient(e.Client.HTTPClient, limiter)
+	e.futuresClient.HTTPClient = util.NewRateLimitedHTTPClient(e.futuresClient.HTTPClient, limiter)
+}
+
+// UseSandbox switches the exchange to the testnet endpoints, it only affects this exchange instance,
+// unlike binance.UseTestnet of the go-binance package, so that the testnet session can run alongside the live sessions.
+func (e *Exchange) UseSandbox() {
+	e.sandbox = true
+	e.Client.BaseURL = TestnetAPIURL
+	e.futuresClient.BaseURL = FuturesTestnetAPIURL
+}
+
+// SetProxy sends the REST API requests and connects the streams through the proxy
+func (e *Exchange) SetProxy(proxyURL *url.URL) {
+	e.proxyURL = proxyURL
+	e.Client.HTTPClient = util.NewProxyHTTPClient(e.Client.HTTPClient, proxyURL)
+	e.futuresClient.HTTPClient = util.NewProxyHTTPClient(e.futuresClient.HTTPClient, proxyURL)
+}
+
 func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBinance
 }
 
+// QueryServerTime queries the server time of the exchange, the server time is in milliseconds
+func (e *Exchange) QueryServerTime(ctx context.Context) (time.Time, error) {
+	millis, err := e.Client.NewServerTimeService().Do(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, millis*int64(time.Millisecond)), nil
+}
+
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
 	req := e.Client.NewListPriceChangeStatsService()
 	req.Symbol(strings.ToUpper(symbol))
@@ -113,6 +168,10 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	log.Info("querying market info...")
 
+	if e.IsFutures {
+		return e.queryFuturesMarkets(ctx)
+	}
+
 	exchangeInfo, err := e.Client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -168,6 +227,18 @@ func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float6
 func (e *Exchange) NewStream() types.Stream {
 	stream := NewStream(e.Client)
 	stream.MarginSettings = e.MarginSettings
+	if e.sandbox {
+		stream.webSocketURL = TestnetWebSocketURL
+	}
+
+	if e.IsFutures {
+		stream.useFutures(e.futuresClient, e.sandbox)
+	}
+
+	if e.proxyURL != nil {
+		stream.dialer = util.NewProxyWebsocketDialer(e.proxyURL)
+	}
+
 	return stream
 }
 
@@ -204,6 +275,25 @@ func (e *Exchange) getLaunchDate() (time.Time, error) {
 	return time.Date(2017, time.July, 14, 0, 0, 0, 0, loc), nil
 }
 
+// QueryDepositAddress queries the deposit address of the default network of the asset
+func (e *Exchange) QueryDepositAddress(ctx context.Context, asset string) (*types.DepositAddress, error) {
+	resp, err := e.Client.NewGetDepositAddressService().Asset(asset).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Address) == 0 {
+		return nil, types.ErrDepositAddressNotFound
+	}
+
+	return &types.DepositAddress{
+		Exchange:   types.ExchangeBinance,
+		Asset:      asset,
+		Address:    resp.Address,
+		AddressTag: resp.AddressTag,
+	}, nil
+}
+
 func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
 	startTime := since
 
@@ -371,6 +461,10 @@ func (e *Exchange) PlatformFeeCurrency() string {
 }
 
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsFutures {
+		return e.queryFuturesAccount(ctx)
+	}
+
 	account, err := e.Client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -395,6 +489,10 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 }
 
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if e.IsFutures {
+		return e.queryFuturesOpenOrders(ctx, symbol)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOpenOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -422,6 +520,10 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 	log.Infof("querying closed orders %s from %s <=> %s ...", symbol, since, until)
 
+	if e.IsFutures {
+		return e.queryFuturesClosedOrders(ctx, symbol, since, until, lastOrderID)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -461,6 +563,14 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
 	for _, o := range orders {
+		if e.IsFutures {
+			if err := e.cancelFuturesOrder(ctx, o); err != nil {
+				log.WithError(err).Errorf("order cancel error")
+				err2 = err
+			}
+			continue
+		}
+
 		var req = e.Client.NewCancelOrderService()
 
 		// Mandatory
@@ -691,7 +801,9 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 	for _, order := range orders {
 		var createdOrder *types.Order
 
-		if e.IsMargin {
+		if e.IsFutures {
+			createdOrder, err = e.submitFuturesOrder(ctx, order)
+		} else if e.IsMargin {
 			createdOrder, err = e.submitMarginOrder(ctx, order)
 		} else {
 			createdOrder, err = e.submitSpotOrder(ctx, order)
@@ -731,6 +843,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 
 	log.Infof("querying kline %s %s %v", symbol, interval, options)
 
+	if e.IsFutures {
+		return e.queryFuturesKLines(ctx, symbol, interval, limit, options)
+	}
+
 	req := e.Client.NewKlinesService().
 		Symbol(symbol).
 		Interval(string(interval)).
@@ -774,6 +890,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
 	var remoteTrades []*binance.TradeV3
 
+	if e.IsFutures {
+		return e.queryFuturesTrades(ctx, symbol, options)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginTradesService().
 			IsIsolated(e.IsIsolatedMargin).
@@ -0,0 +1,387 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// the endpoints of the USDⓈ-M futures testnet, the testnet api keys are created at https://testnet.binancefuture.com
+const (
+	FuturesTestnetAPIURL       = "https://testnet.binancefuture.com"
+	FuturesWebSocketURL        = "wss://fstream.binance.com/ws"
+	FuturesTestnetWebSocketURL = "wss://stream.binancefuture.com/ws"
+)
+
+func (e *Exchange) queryFuturesMarkets(ctx context.Context) (types.MarketMap, error) {
+	exchangeInfo, err := e.futuresClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, symbol := range exchangeInfo.Symbols {
+		market := types.Market{
+			Symbol:          symbol.Symbol,
+			PricePrecision:  symbol.PricePrecision,
+			VolumePrecision: symbol.QuantityPrecision,
+			QuoteCurrency:   symbol.QuoteAsset,
+			BaseCurrency:    symbol.BaseAsset,
+		}
+
+		if f := symbol.LotSizeFilter(); f != nil {
+			market.MinQuantity = util.MustParseFloat(f.MinQuantity)
+			market.MaxQuantity = util.MustParseFloat(f.MaxQuantity)
+			market.StepSize = util.MustParseFloat(f.StepSize)
+		}
+
+		if f := symbol.PriceFilter(); f != nil {
+			market.MaxPrice = util.MustParseFloat(f.MaxPrice)
+			market.MinPrice = util.MustParseFloat(f.MinPrice)
+			market.TickSize = util.MustParseFloat(f.TickSize)
+		}
+
+		markets[symbol.Symbol] = market
+	}
+
+	return markets, nil
+}
+
+// queryFuturesAccount queries the futures account, the balances are the wallet balances of the assets,
+// the margin of the positions and the open orders is locked
+func (e *Exchange) queryFuturesAccount(ctx context.Context) (*types.Account, error) {
+	account, err := e.futuresClient.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = map[string]types.Balance{}
+	for _, asset := range account.Assets {
+		walletBalance := fixedpoint.Must(fixedpoint.NewFromString(asset.WalletBalance))
+		initialMargin := fixedpoint.Must(fixedpoint.NewFromString(asset.InitialMargin))
+		balances[asset.Asset] = types.Balance{
+			Currency:  asset.Asset,
+			Available: walletBalance.Sub(initialMargin),
+			Locked:    initialMargin,
+		}
+	}
+
+	a := &types.Account{}
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+func (e *Exchange) queryFuturesOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	binanceOrders, err := e.futuresClient.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	return toGlobalFuturesOrders(binanceOrders)
+}
+
+func (e *Exchange) queryFuturesClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	req := e.futuresClient.NewListOrdersService().Symbol(symbol)
+
+	if lastOrderID > 0 {
+		req.OrderID(int64(lastOrderID))
+	} else {
+		req.StartTime(since.UnixNano() / int64(time.Millisecond)).
+			EndTime(until.UnixNano() / int64(time.Millisecond))
+	}
+
+	binanceOrders, err := req.Do(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	return toGlobalFuturesOrders(binanceOrders)
+}
+
+func (e *Exchange) cancelFuturesOrder(ctx context.Context, o types.Order) error {
+	req := e.futuresClient.NewCancelOrderService().Symbol(o.Symbol)
+
+	if o.OrderID > 0 {
+		req.OrderID(int64(o.OrderID))
+	} else if len(o.ClientOrderID) > 0 {
+		req.OrigClientOrderID(o.ClientOrderID)
+	}
+
+	_, err := req.Do(ctx)
+	return err
+}
+
+func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	orderType, err := toLocalFuturesOrderType(order.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	req := e.futuresClient.NewCreateOrderService().
+		Symbol(order.Symbol).
+		Side(futures.SideType(order.Side)).
+		Type(orderType)
+
+	if len(order.ClientOrderID) > 0 {
+		req.NewClientOrderID(order.ClientOrderID)
+	}
+
+	if len(order.QuantityString) > 0 {
+		req.Quantity(order.QuantityString)
+	} else if order.Market.Symbol != "" {
+		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+	} else {
+		req.Quantity(strconv.FormatFloat(order.Quantity, 'f', 8, 64))
+	}
+
+	// set price field for limit orders
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeLimit:
+		if len(order.PriceString) > 0 {
+			req.Price(order.PriceString)
+		} else if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price))
+		}
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if len(order.StopPriceString) == 0 {
+			return nil, fmt.Errorf("stop price string can not be empty")
+		}
+
+		req.StopPrice(order.StopPriceString)
+	}
+
+	if len(order.TimeInForce) > 0 {
+		req.TimeInForce(futures.TimeInForceType(order.TimeInForce))
+	} else {
+		switch order.Type {
+		case types.OrderTypeLimit, types.OrderTypeStopLimit:
+			req.TimeInForce(futures.TimeInForceTypeGTC)
+		}
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("futures order creation response: %+v", response)
+
+	return toGlobalFuturesOrder(&futures.Order{
+		Symbol:           response.Symbol,
+		OrderID:          response.OrderID,
+		ClientOrderID:    response.ClientOrderID,
+		Price:            response.Price,
+		OrigQuantity:     response.OrigQuantity,
+		ExecutedQuantity: response.ExecutedQuantity,
+		Status:           response.Status,
+		TimeInForce:      response.TimeInForce,
+		Type:             response.Type,
+		Side:             response.Side,
+		StopPrice:        response.StopPrice,
+		UpdateTime:       response.UpdateTime,
+		Time:             response.UpdateTime,
+	})
+}
+
+func (e *Exchange) queryFuturesKLines(ctx context.Context, symbol string, interval types.Interval, limit int, options types.KLineQueryOptions) ([]types.KLine, error) {
+	req := e.futuresClient.NewKlinesService().
+		Symbol(symbol).
+		Interval(string(interval)).
+		Limit(limit)
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, k := range resp {
+		kLines = append(kLines, types.KLine{
+			Exchange:       types.ExchangeBinance.String(),
+			Symbol:         symbol,
+			Interval:       interval,
+			StartTime:      time.Unix(0, k.OpenTime*int64(time.Millisecond)),
+			EndTime:        time.Unix(0, k.CloseTime*int64(time.Millisecond)),
+			Open:           util.MustParseFloat(k.Open),
+			Close:          util.MustParseFloat(k.Close),
+			High:           util.MustParseFloat(k.High),
+			Low:            util.MustParseFloat(k.Low),
+			Volume:         util.MustParseFloat(k.Volume),
+			QuoteVolume:    util.MustParseFloat(k.QuoteAssetVolume),
+			NumberOfTrades: uint64(k.TradeNum),
+			Closed:         true,
+		})
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) queryFuturesTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	req := e.futuresClient.NewListAccountTradeService().
+		Symbol(symbol)
+
+	if options.Limit > 0 {
+		req.Limit(int(options.Limit))
+	} else {
+		req.Limit(1000)
+	}
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	// BINANCE uses inclusive last trade ID
+	if options.LastTradeID > 0 {
+		req.FromID(options.LastTradeID)
+	}
+
+	remoteTrades, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range remoteTrades {
+		localTrade, err := toGlobalFuturesTrade(*t)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert binance futures trade: %+v", t)
+			continue
+		}
+
+		trades = append(trades, *localTrade)
+	}
+
+	return trades, nil
+}
+
+func toLocalFuturesOrderType(orderType types.OrderType) (futures.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		return futures.OrderTypeLimit, nil
+
+	case types.OrderTypeStopLimit:
+		return futures.OrderTypeStop, nil
+
+	case types.OrderTypeStopMarket:
+		return futures.OrderTypeStopMarket, nil
+
+	case types.OrderTypeMarket:
+		return futures.OrderTypeMarket, nil
+	}
+
+	return "", fmt.Errorf("order type %s not supported", orderType)
+}
+
+func toGlobalFuturesOrderType(orderType futures.OrderType) types.OrderType {
+	switch orderType {
+
+	case futures.OrderTypeLimit, futures.OrderTypeTakeProfit:
+		return types.OrderTypeLimit
+
+	case futures.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case futures.OrderTypeStop:
+		return types.OrderTypeStopLimit
+
+	case futures.OrderTypeStopMarket:
+		return types.OrderTypeStopMarket
+
+	default:
+		log.Errorf("unsupported futures order type: %v", orderType)
+		return ""
+	}
+}
+
+func toGlobalFuturesOrders(binanceOrders []*futures.Order) (orders []types.Order, err error) {
+	for _, binanceOrder := range binanceOrders {
+		order, err := toGlobalFuturesOrder(binanceOrder)
+		if err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, *order)
+	}
+
+	return orders, err
+}
+
+func toGlobalFuturesOrder(binanceOrder *futures.Order) (*types.Order, error) {
+	status := toGlobalOrderStatus(binance.OrderStatusType(binanceOrder.Status))
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: binanceOrder.ClientOrderID,
+			Symbol:        binanceOrder.Symbol,
+			Side:          toGlobalSideType(binance.SideType(binanceOrder.Side)),
+			Type:          toGlobalFuturesOrderType(binanceOrder.Type),
+			Quantity:      util.MustParseFloat(binanceOrder.OrigQuantity),
+			Price:         util.MustParseFloat(binanceOrder.Price),
+			StopPrice:     util.MustParseFloat(binanceOrder.StopPrice),
+			TimeInForce:   string(binanceOrder.TimeInForce),
+		},
+		Exchange:         types.ExchangeBinance.String(),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		OrderID:          uint64(binanceOrder.OrderID),
+		Status:           status,
+		ExecutedQuantity: util.MustParseFloat(binanceOrder.ExecutedQuantity),
+		CreationTime:     datatype.Time(millisecondTime(binanceOrder.Time)),
+		UpdateTime:       datatype.Time(millisecondTime(binanceOrder.UpdateTime)),
+	}, nil
+}
+
+func toGlobalFuturesTrade(t futures.AccountTrade) (*types.Trade, error) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
+	}
+
+	quantity, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
+	}
+
+	quoteQuantity := price * quantity
+	if len(t.QuoteQuantity) > 0 {
+		quoteQuantity = util.MustParseFloat(t.QuoteQuantity)
+	}
+
+	return &types.Trade{
+		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
+		Price:         price,
+		Symbol:        t.Symbol,
+		Exchange:      types.ExchangeBinance.String(),
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Side:          toGlobalSideType(binance.SideType(t.Side)),
+		IsBuyer:       t.Buyer,
+		IsMaker:       t.Maker,
+		Fee:           util.MustParseFloat(t.Commission),
+		FeeCurrency:   t.CommissionAsset,
+		Time:          datatype.Time(millisecondTime(t.Time)),
+	}, nil
+}
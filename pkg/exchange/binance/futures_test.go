@@ -0,0 +1,119 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestFuturesServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/fapi/v1/account":
+			_, _ = w.Write([]byte(`{"assets": [{"asset": "USDT", "walletBalance": "1000.5", "initialMargin": "200.5"}]}`))
+
+		case r.Method == http.MethodPost && r.URL.Path == "/fapi/v1/order":
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "BTCUSDT", r.Form.Get("symbol"))
+			assert.Equal(t, "LIMIT", r.Form.Get("type"))
+			assert.Equal(t, "GTC", r.Form.Get("timeInForce"))
+			_, _ = w.Write([]byte(`{"symbol": "BTCUSDT", "orderId": 22542179, "clientOrderId": "test", "price": "30000", "origQty": "0.01", "executedQty": "0", "status": "NEW", "timeInForce": "GTC", "type": "LIMIT", "side": "BUY", "updateTime": 1566818724722}`))
+
+		case r.Method == http.MethodPost && r.URL.Path == "/fapi/v1/listenKey":
+			_, _ = w.Write([]byte(`{"listenKey": "futureslistenkey"}`))
+
+		case r.URL.Path == "/fapi/v1/listenKey":
+			_, _ = w.Write([]byte(`{}`))
+
+		case r.URL.Path == "/ws/futureslistenkey":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"e": "ORDER_TRADE_UPDATE", "E": 1568879465651, "T": 1568879465650, "o": {"s": "BTCUSDT", "c": "test", "S": "BUY", "o": "LIMIT", "f": "GTC", "q": "0.01", "p": "30000", "ap": "0", "sp": "0", "x": "NEW", "X": "NEW", "i": 22542179, "l": "0", "z": "0", "L": "0", "T": 1568879465651, "t": 0, "m": false, "R": false, "rp": "0"}}`))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExchange_Futures(t *testing.T) {
+	server := newTestFuturesServer(t)
+	defer server.Close()
+
+	exchange := New("key", "secret")
+	exchange.UseFutures()
+	exchange.futuresClient.BaseURL = server.URL
+
+	ctx := context.Background()
+
+	balances, err := exchange.QueryAccountBalances(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fixedpoint.NewFromFloat(800.0), balances["USDT"].Available)
+		assert.Equal(t, fixedpoint.NewFromFloat(200.5), balances["USDT"].Locked)
+	}
+
+	createdOrders, err := exchange.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:      "BTCUSDT",
+		Side:        types.SideTypeBuy,
+		Type:        types.OrderTypeLimit,
+		Quantity:    0.01,
+		PriceString: "30000",
+	})
+	if assert.NoError(t, err) && assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, uint64(22542179), createdOrders[0].OrderID)
+		assert.Equal(t, types.OrderStatusNew, createdOrders[0].Status)
+		assert.True(t, createdOrders[0].IsWorking)
+	}
+}
+
+func TestStream_Futures(t *testing.T) {
+	server := newTestFuturesServer(t)
+	defer server.Close()
+
+	exchange := New("key", "secret")
+	exchange.UseFutures()
+	exchange.futuresClient.BaseURL = server.URL
+
+	stream := exchange.NewStream().(*Stream)
+	stream.webSocketURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	orderC := make(chan types.Order, 1)
+	stream.OnOrderUpdate(func(order types.Order) {
+		orderC <- order
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	select {
+	case order := <-orderC:
+		assert.Equal(t, uint64(22542179), order.OrderID)
+		assert.Equal(t, types.OrderTypeLimit, order.Type)
+		assert.Equal(t, types.OrderStatusNew, order.Status)
+	case <-time.After(3 * time.Second):
+		t.Error("the order update of the futures stream is not emitted")
+	}
+}
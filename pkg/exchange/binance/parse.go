@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/valyala/fastjson"
 
 	"github.com/c9s/bbgo/pkg/datatype"
@@ -239,6 +240,172 @@ type OutboundAccountInfoEvent struct {
 	Permissions []string  `json:"P,omitempty"`
 }
 
+/*
+
+ORDER_TRADE_UPDATE of the futures user data stream
+
+{
+  "e": "ORDER_TRADE_UPDATE",     // Event Type
+  "E": 1568879465651,            // Event Time
+  "T": 1568879465650,            // Transaction Time
+  "o": {
+    "s": "BTCUSDT",              // Symbol
+    "c": "TEST",                 // Client Order Id
+    "S": "SELL",                 // Side
+    "o": "LIMIT",                // Order Type
+    "f": "GTC",                  // Time in Force
+    "q": "0.001",                // Original Quantity
+    "p": "9000",                 // Original Price
+    "ap": "0",                   // Average Price
+    "sp": "0",                   // Stop Price
+    "x": "NEW",                  // Execution Type
+    "X": "NEW",                  // Order Status
+    "i": 8886774,                // Order Id
+    "l": "0",                    // Order Last Filled Quantity
+    "z": "0",                    // Order Filled Accumulated Quantity
+    "L": "0",                    // Last Filled Price
+    "N": "USDT",                 // Commission Asset, will not push if no commission
+    "n": "0",                    // Commission, will not push if no commission
+    "T": 1568879465651,          // Order Trade Time
+    "t": 0,                      // Trade Id
+    "m": false,                  // Is this trade the maker side?
+    "R": false,                  // Is this reduce only
+    "rp": "0"                    // Realized Profit of the trade
+  }
+}
+*/
+type OrderTrade struct {
+	Symbol        string `json:"s"`
+	ClientOrderID string `json:"c"`
+	Side          string `json:"S"`
+	OrderType     string `json:"o"`
+	TimeInForce   string `json:"f"`
+
+	OriginalQuantity string `json:"q"`
+	OriginalPrice    string `json:"p"`
+	AveragePrice     string `json:"ap"`
+	StopPrice        string `json:"sp"`
+
+	CurrentExecutionType string `json:"x"`
+	CurrentOrderStatus   string `json:"X"`
+
+	OrderID int64 `json:"i"`
+
+	LastFilledQuantity       string `json:"l"`
+	CumulativeFilledQuantity string `json:"z"`
+	LastFilledPrice          string `json:"L"`
+
+	CommissionAsset  string `json:"N"`
+	CommissionAmount string `json:"n"`
+
+	OrderTradeTime int64 `json:"T"`
+	TradeID        int64 `json:"t"`
+
+	IsMaker      bool `json:"m"`
+	IsReduceOnly bool `json:"R"`
+
+	RealizedProfit string `json:"rp"`
+}
+
+type OrderTradeUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64      `json:"T"`
+	OrderTrade      OrderTrade `json:"o"`
+}
+
+func (e *OrderTradeUpdateEvent) Order() (*types.Order, error) {
+	switch e.OrderTrade.CurrentExecutionType {
+	case "NEW", "CANCELED", "EXPIRED":
+	default:
+		return nil, errors.New("order trade update is not for order")
+	}
+
+	o := e.OrderTrade
+	orderTime := time.Unix(0, o.OrderTradeTime*int64(time.Millisecond))
+	return &types.Order{
+		Exchange: string(types.ExchangeBinance),
+		SubmitOrder: types.SubmitOrder{
+			Symbol:        o.Symbol,
+			ClientOrderID: o.ClientOrderID,
+			Side:          toGlobalSideType(binance.SideType(o.Side)),
+			Type:          toGlobalFuturesOrderType(futures.OrderType(o.OrderType)),
+			Quantity:      util.MustParseFloat(o.OriginalQuantity),
+			Price:         util.MustParseFloat(o.OriginalPrice),
+			StopPrice:     util.MustParseFloat(o.StopPrice),
+			TimeInForce:   o.TimeInForce,
+		},
+		OrderID:          uint64(o.OrderID),
+		Status:           toGlobalOrderStatus(binance.OrderStatusType(o.CurrentOrderStatus)),
+		ExecutedQuantity: util.MustParseFloat(o.CumulativeFilledQuantity),
+		CreationTime:     datatype.Time(orderTime),
+		UpdateTime:       datatype.Time(orderTime),
+	}, nil
+}
+
+func (e *OrderTradeUpdateEvent) Trade() (*types.Trade, error) {
+	if e.OrderTrade.CurrentExecutionType != "TRADE" {
+		return nil, errors.New("order trade update is not a trade")
+	}
+
+	o := e.OrderTrade
+	price := util.MustParseFloat(o.LastFilledPrice)
+	quantity := util.MustParseFloat(o.LastFilledQuantity)
+	return &types.Trade{
+		ID:            o.TradeID,
+		Exchange:      string(types.ExchangeBinance),
+		Symbol:        o.Symbol,
+		OrderID:       uint64(o.OrderID),
+		Side:          toGlobalSideType(binance.SideType(o.Side)),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		IsBuyer:       o.Side == "BUY",
+		IsMaker:       o.IsMaker,
+		Time:          datatype.Time(time.Unix(0, o.OrderTradeTime*int64(time.Millisecond))),
+		Fee:           util.MustParseFloat(o.CommissionAmount),
+		FeeCurrency:   o.CommissionAsset,
+	}, nil
+}
+
+/*
+
+ACCOUNT_UPDATE of the futures user data stream, only the updated assets are pushed
+
+{
+  "e": "ACCOUNT_UPDATE",         // Event Type
+  "E": 1564745798939,            // Event Time
+  "T": 1564745798938,            // Transaction
+  "a": {
+    "m": "ORDER",                // Event reason type
+    "B": [                       // Balances
+      {
+        "a": "USDT",             // Asset
+        "wb": "122624.12345678", // Wallet Balance
+        "cw": "100.12345678"     // Cross Wallet Balance
+      }
+    ]
+  }
+}
+*/
+type FuturesBalance struct {
+	Asset              string `json:"a"`
+	WalletBalance      string `json:"wb"`
+	CrossWalletBalance string `json:"cw"`
+}
+
+type AccountUpdate struct {
+	EventReasonType string           `json:"m"`
+	Balances        []FuturesBalance `json:"B,omitempty"`
+}
+
+type AccountUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64         `json:"T"`
+	AccountUpdate   AccountUpdate `json:"a"`
+}
+
 type ResultEvent struct {
 	Result interface{} `json:"result,omitempty"`
 	ID     int         `json:"id"`
@@ -278,6 +445,16 @@ func ParseEvent(message string) (interface{}, error) {
 		err := json.Unmarshal([]byte(message), &event)
 		return &event, err
 
+	case "ORDER_TRADE_UPDATE":
+		var event OrderTradeUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
+	case "ACCOUNT_UPDATE":
+		var event AccountUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	case "depthUpdate":
 		return parseDepthEvent(val)
 
@@ -303,6 +480,9 @@ type DepthEvent struct {
 	FirstUpdateID int64  `json:"U"`
 	FinalUpdateID int64  `json:"u"`
 
+	// PreviousUpdateID is the final update ID of the previous event, it's only sent by the futures stream
+	PreviousUpdateID int64 `json:"pu"`
+
 	Bids []DepthEntry
 	Asks []DepthEntry
 }
@@ -375,8 +555,9 @@ func parseDepthEvent(val *fastjson.Value) (*DepthEvent, error) {
 			Time:  val.GetInt64("E"),
 		},
 		Symbol:        string(val.GetStringBytes("s")),
-		FirstUpdateID: val.GetInt64("U"),
-		FinalUpdateID: val.GetInt64("u"),
+		FirstUpdateID:    val.GetInt64("U"),
+		FinalUpdateID:    val.GetInt64("u"),
+		PreviousUpdateID: val.GetInt64("pu"),
 	}
 
 	for _, ev := range val.GetArray("b") {
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var jsCommentTrimmer = regexp.MustCompile("(?m)//.*$")
@@ -183,3 +185,86 @@ func TestParseOrderUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
 }
+
+func TestParseFuturesAccountUpdate(t *testing.T) {
+	payload := `{
+  "e": "ACCOUNT_UPDATE",
+  "E": 1564745798939,
+  "T": 1564745798938,
+  "a": {
+    "m": "ORDER",
+    "B": [
+      {
+        "a": "USDT",
+        "wb": "122624.12345678",
+        "cw": "100.12345678"
+      }
+    ]
+  }
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	accountUpdate, ok := event.(*AccountUpdateEvent)
+	if assert.True(t, ok) && assert.Len(t, accountUpdate.AccountUpdate.Balances, 1) {
+		assert.Equal(t, "USDT", accountUpdate.AccountUpdate.Balances[0].Asset)
+		assert.Equal(t, "122624.12345678", accountUpdate.AccountUpdate.Balances[0].WalletBalance)
+	}
+}
+
+func TestParseFuturesOrderTradeUpdate(t *testing.T) {
+	payload := `{
+  "e": "ORDER_TRADE_UPDATE",
+  "E": 1568879465651,
+  "T": 1568879465650,
+  "o": {
+    "s": "BTCUSDT",
+    "c": "TEST",
+    "S": "SELL",
+    "o": "LIMIT",
+    "f": "GTC",
+    "q": "0.002",
+    "p": "9000",
+    "ap": "9000",
+    "sp": "0",
+    "x": "TRADE",
+    "X": "PARTIALLY_FILLED",
+    "i": 8886774,
+    "l": "0.001",
+    "z": "0.001",
+    "L": "9000",
+    "N": "USDT",
+    "n": "0.0036",
+    "T": 1568879465651,
+    "t": 1234,
+    "m": true,
+    "R": false,
+    "rp": "0"
+  }
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	orderTradeUpdate, ok := event.(*OrderTradeUpdateEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	_, err = orderTradeUpdate.Order()
+	assert.Error(t, err)
+
+	trade, err := orderTradeUpdate.Trade()
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1234), trade.ID)
+		assert.Equal(t, uint64(8886774), trade.OrderID)
+		assert.Equal(t, types.SideTypeSell, trade.Side)
+		assert.Equal(t, 9000.0, trade.Price)
+		assert.Equal(t, 0.001, trade.Quantity)
+		assert.Equal(t, 9.0, trade.QuoteQuantity)
+		assert.Equal(t, 0.0036, trade.Fee)
+		assert.Equal(t, "USDT", trade.FeeCurrency)
+		assert.True(t, trade.IsMaker)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gorilla/websocket"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -37,6 +38,15 @@ func init() {
 	}
 }
 
+// the websocket endpoints of the production spot api and the spot testnet
+const (
+	WebSocketURL        = "wss://stream.binance.com:9443/ws"
+	TestnetWebSocketURL = "wss://testnet.binance.vision/ws"
+)
+
+// defaultPingInterval is the interval of the ping messages if the keepalive interval is not set
+const defaultPingInterval = 10 * time.Second
+
 type StreamRequest struct {
 	// request ID is required
 	ID     int      `json:"id"`
@@ -47,6 +57,7 @@ type StreamRequest struct {
 //go:generate callbackgen -type Stream -interface
 type Stream struct {
 	types.MarginSettings
+	types.FuturesSettings
 
 	types.StandardStream
 
@@ -55,8 +66,28 @@ type Stream struct {
 	Conn      *websocket.Conn
 	connLock  sync.Mutex
 
+	// futuresClient requests the listen key of the futures user data stream and the futures depth snapshots
+	futuresClient *futures.Client
+
+	// readCancel cancels the read loop of the connection, and readDone is closed when the read loop exits,
+	// the read loop is stopped before the stream is connected again, so that only one goroutine reads the connection
+	readCancel context.CancelFunc
+	readDone   chan struct{}
+
 	publicOnly bool
 
+	// webSocketURL is the base url of the websocket endpoint, it's the testnet endpoint for the sandbox exchange
+	webSocketURL string
+
+	// dialer is the websocket dialer, it connects through the proxy of the exchange
+	dialer *websocket.Dialer
+
+	// connectTimeout is the deadline of the listen key request and the handshake, no deadline is set if it's zero
+	connectTimeout time.Duration
+
+	// pingInterval is the interval of the ping messages
+	pingInterval time.Duration
+
 	// custom callbacks
 	depthEventCallbacks       []func(e *DepthEvent)
 	kLineEventCallbacks       []func(e *KLineEvent)
@@ -66,23 +97,29 @@ type Stream struct {
 	outboundAccountInfoEventCallbacks     []func(event *OutboundAccountInfoEvent)
 	outboundAccountPositionEventCallbacks []func(event *OutboundAccountPositionEvent)
 	executionReportEventCallbacks         []func(event *ExecutionReportEvent)
+	orderTradeUpdateEventCallbacks        []func(event *OrderTradeUpdateEvent)
+	accountUpdateEventCallbacks           []func(event *AccountUpdateEvent)
 
 	depthFrames map[string]*DepthFrame
 }
 
 func NewStream(client *binance.Client) *Stream {
 	stream := &Stream{
-		Client:      client,
-		depthFrames: make(map[string]*DepthFrame),
+		Client:       client,
+		webSocketURL: WebSocketURL,
+		dialer:       websocket.DefaultDialer,
+		pingInterval: defaultPingInterval,
+		depthFrames:  make(map[string]*DepthFrame),
 	}
 
 	stream.OnDepthEvent(func(e *DepthEvent) {
 		f, ok := stream.depthFrames[e.Symbol]
 		if !ok {
 			f = &DepthFrame{
-				client:  client,
-				context: context.Background(),
-				Symbol:  e.Symbol,
+				client:        client,
+				futuresClient: stream.futuresClient,
+				context:       context.Background(),
+				Symbol:        e.Symbol,
 			}
 
 			stream.depthFrames[e.Symbol] = f
@@ -172,6 +209,40 @@ func NewStream(client *binance.Client) *Stream {
 		}
 	})
 
+	stream.OnOrderTradeUpdateEvent(func(e *OrderTradeUpdateEvent) {
+		switch e.OrderTrade.CurrentExecutionType {
+
+		case "NEW", "CANCELED", "EXPIRED":
+			order, err := e.Order()
+			if err != nil {
+				log.WithError(err).Error("order convert error")
+				return
+			}
+
+			stream.EmitOrderUpdate(*order)
+
+		case "TRADE":
+			trade, err := e.Trade()
+			if err != nil {
+				log.WithError(err).Error("trade convert error")
+				return
+			}
+
+			stream.EmitTradeUpdate(*trade)
+		}
+	})
+
+	stream.OnAccountUpdateEvent(func(e *AccountUpdateEvent) {
+		balances := types.BalanceMap{}
+		for _, balance := range e.AccountUpdate.Balances {
+			balances[balance.Asset] = types.Balance{
+				Currency:  balance.Asset,
+				Available: fixedpoint.Must(fixedpoint.NewFromString(balance.WalletBalance)),
+			}
+		}
+		stream.EmitBalanceUpdate(balances)
+	})
+
 	stream.OnConnect(func() {
 		// reset the previous frames
 		for _, f := range stream.depthFrames {
@@ -203,19 +274,38 @@ func NewStream(client *binance.Client) *Stream {
 	return stream
 }
 
+// useFutures connects the stream to the futures endpoint, the listen key is requested by the futures client
+func (s *Stream) useFutures(client *futures.Client, sandbox bool) {
+	s.UseFutures()
+	s.futuresClient = client
+	if sandbox {
+		s.webSocketURL = FuturesTestnetWebSocketURL
+	} else {
+		s.webSocketURL = FuturesWebSocketURL
+	}
+}
+
 func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
-func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
+// SetConnectionOptions sets the connect timeout and the ping interval of the websocket connection
+func (s *Stream) SetConnectionOptions(options types.StreamConnectionOptions) {
+	s.connectTimeout = options.ConnectTimeout
+	if options.KeepaliveInterval > 0 {
+		s.pingInterval = options.KeepaliveInterval
+	}
+}
+
+func (s *Stream) dial(ctx context.Context, listenKey string) (*websocket.Conn, error) {
 	var url string
 	if s.publicOnly {
-		url = "wss://stream.binance.com:9443/ws"
+		url = s.webSocketURL
 	} else {
-		url = "wss://stream.binance.com:9443/ws/" + listenKey
+		url = s.webSocketURL + "/" + listenKey
 	}
 
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	conn, _, err := s.dialer.DialContext(ctx, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +317,11 @@ func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
 }
 
 func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
+	if s.IsFutures {
+		log.Infof("futures mode is enabled, requesting futures user stream listen key...")
+		return s.futuresClient.NewStartUserStreamService().Do(ctx)
+	}
+
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			log.Infof("isolated margin %s is enabled, requesting margin user stream listen key...", s.IsolatedMarginSymbol)
@@ -244,6 +339,10 @@ func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
 }
 
 func (s *Stream) keepaliveListenKey(ctx context.Context, listenKey string) error {
+	if s.IsFutures {
+		return s.futuresClient.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+	}
+
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewKeepaliveIsolatedMarginUserStreamService().ListenKey(listenKey)
@@ -259,12 +358,20 @@ func (s *Stream) keepaliveListenKey(ctx context.Context, listenKey string) error
 }
 
 func (s *Stream) connect(ctx context.Context) error {
+	// the deadline only applies to the connect attempt, the connection lives with the stream context
+	connectCtx := ctx
+	if s.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		connectCtx, cancel = context.WithTimeout(ctx, s.connectTimeout)
+		defer cancel()
+	}
+
 	if s.publicOnly {
 		log.Infof("stream is set to public only mode")
 	} else {
 		log.Infof("request listen key for creating user data stream...")
 
-		listenKey, err := s.fetchListenKey(ctx)
+		listenKey, err := s.fetchListenKey(connectCtx)
 		if err != nil {
 			return err
 		}
@@ -273,7 +380,7 @@ func (s *Stream) connect(ctx context.Context) error {
 		log.Infof("user data stream created. listenKey: %s", maskListenKey(s.ListenKey))
 	}
 
-	conn, err := s.dial(s.ListenKey)
+	conn, err := s.dial(connectCtx, s.ListenKey)
 	if err != nil {
 		return err
 	}
@@ -304,20 +411,66 @@ func convertSubscription(s types.Subscription) string {
 }
 
 func (s *Stream) Connect(ctx context.Context) error {
+	// the stream may be connected again without being closed, e.g., by the reconnector of the session
+	if err := s.stopReading(); err != nil {
+		log.WithError(err).Debug("previous connection close error")
+	}
+
 	err := s.connect(ctx)
 	if err != nil {
 		return err
 	}
 
-	go s.read(ctx)
+	readCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	s.connLock.Lock()
+	s.readCancel = cancel
+	s.readDone = done
+	s.connLock.Unlock()
+
+	go func() {
+		defer close(done)
+		s.read(readCtx)
+	}()
 
 	s.EmitStart()
 	return nil
 }
 
+// stopReading cancels the read loop, closes the connection and waits until the read loop exits,
+// nothing is done if the stream is not connected
+func (s *Stream) stopReading() error {
+	s.connLock.Lock()
+	cancel, done, conn := s.readCancel, s.readDone, s.Conn
+	s.readCancel, s.readDone = nil, nil
+	s.connLock.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	// unblock the read of the connection
+	err := conn.Close()
+	<-done
+
+	// the read loop may have reconnected by itself before it's canceled
+	s.connLock.Lock()
+	if s.Conn != conn {
+		if closeErr := s.Conn.Close(); closeErr != nil {
+			log.WithError(closeErr).Debug("connection close error")
+		}
+	}
+	s.connLock.Unlock()
+
+	return err
+}
+
 func (s *Stream) read(ctx context.Context) {
 
-	pingTicker := time.NewTicker(10 * time.Second)
+	pingTicker := time.NewTicker(s.pingInterval)
 	defer pingTicker.Stop()
 
 	keepAliveTicker := time.NewTicker(5 * time.Minute)
@@ -362,6 +515,11 @@ func (s *Stream) read(ctx context.Context) {
 
 			mt, message, err := s.Conn.ReadMessage()
 			if err != nil {
+				// the connection is closed by Close or by the next Connect
+				if ctx.Err() != nil {
+					return
+				}
+
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
 					log.WithError(err).Errorf("read error: %s", err.Error())
 				} else {
@@ -383,8 +541,13 @@ func (s *Stream) read(ctx context.Context) {
 							}
 						}
 
-						err = s.connect(ctx)
-						time.Sleep(5 * time.Second)
+						if err = s.connect(ctx); err != nil {
+							select {
+							case <-ctx.Done():
+								return
+							case <-time.After(5 * time.Second):
+							}
+						}
 					}
 				}
 
@@ -428,6 +591,14 @@ func (s *Stream) read(ctx context.Context) {
 			case *ExecutionReportEvent:
 				log.Info(e.Event, " ", e)
 				s.EmitExecutionReportEvent(e)
+
+			case *OrderTradeUpdateEvent:
+				log.Info(e.Event, " ", e.OrderTrade)
+				s.EmitOrderTradeUpdateEvent(e)
+
+			case *AccountUpdateEvent:
+				log.Info(e.Event, " ", e.AccountUpdate.Balances)
+				s.EmitAccountUpdateEvent(e)
 			}
 		}
 	}
@@ -437,7 +608,9 @@ func (s *Stream) invalidateListenKey(ctx context.Context, listenKey string) (err
 	// should use background context to invalidate the user stream
 	log.Info("closing listen key")
 
-	if s.IsMargin {
+	if s.IsFutures {
+		err = s.futuresClient.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx)
+	} else if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewCloseIsolatedMarginUserStreamService().ListenKey(listenKey)
 			req.Symbol(s.IsolatedMarginSymbol)
@@ -469,10 +642,7 @@ func (s *Stream) Close() error {
 		log.Infof("user data stream closed")
 	}
 
-	s.connLock.Lock()
-	defer s.connLock.Unlock()
-
-	return s.Conn.Close()
+	return s.stopReading()
 }
 
 func maskListenKey(listenKey string) string {
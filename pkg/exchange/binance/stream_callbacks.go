@@ -74,6 +74,26 @@ func (s *Stream) EmitExecutionReportEvent(event *ExecutionReportEvent) {
 	}
 }
 
+func (s *Stream) OnOrderTradeUpdateEvent(cb func(event *OrderTradeUpdateEvent)) {
+	s.orderTradeUpdateEventCallbacks = append(s.orderTradeUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitOrderTradeUpdateEvent(event *OrderTradeUpdateEvent) {
+	for _, cb := range s.orderTradeUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
+func (s *Stream) OnAccountUpdateEvent(cb func(event *AccountUpdateEvent)) {
+	s.accountUpdateEventCallbacks = append(s.accountUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitAccountUpdateEvent(event *AccountUpdateEvent) {
+	for _, cb := range s.accountUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
 type StreamEventHub interface {
 	OnDepthEvent(cb func(e *DepthEvent))
 
@@ -88,4 +108,8 @@ type StreamEventHub interface {
 	OnOutboundAccountPositionEvent(cb func(event *OutboundAccountPositionEvent))
 
 	OnExecutionReportEvent(cb func(event *ExecutionReportEvent))
+
+	OnOrderTradeUpdateEvent(cb func(event *OrderTradeUpdateEvent))
+
+	OnAccountUpdateEvent(cb func(event *AccountUpdateEvent))
 }
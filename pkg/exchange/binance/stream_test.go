@@ -0,0 +1,63 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream_Reconnect(t *testing.T) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		atomic.AddInt32(&connections, 1)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	stream := NewStream(nil)
+	stream.SetPublicOnly()
+	stream.webSocketURL = "ws" + strings.TrimPrefix(server.URL, "http")
+
+	var disconnects int32
+	stream.OnDisconnect(func() {
+		atomic.AddInt32(&disconnects, 1)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the stream is closed and connected again like the session reconnector does, and connected again without the close
+	assert.NoError(t, stream.Connect(ctx))
+	assert.NoError(t, stream.Close())
+	assert.NoError(t, stream.Connect(ctx))
+	assert.NoError(t, stream.Connect(ctx))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&connections) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, stream.Close())
+
+	// the closed connections are not reconnected by the read loops
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&connections))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&disconnects))
+}
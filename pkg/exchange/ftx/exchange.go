@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 const (
@@ -26,6 +28,12 @@ type Exchange struct {
 	key, secret  string
 	subAccount   string
 	restEndpoint *url.URL
+
+	// limiter limits the request rate of the REST API, the requests are not limited if it's nil
+	limiter *rate.Limiter
+
+	// proxyURL is the proxy of the REST API and the streams, they connect directly if it's nil
+	proxyURL *url.URL
 }
 
 func NewExchange(key, secret string, subAccount string) *Exchange {
@@ -41,8 +49,27 @@ func NewExchange(key, secret string, subAccount string) *Exchange {
 	}
 }
 
+// SetRateLimiter limits the request rate of the REST API
+func (e *Exchange) SetRateLimiter(limiter *rate.Limiter) {
+	e.limiter = limiter
+}
+
+// SetProxy sends the REST API requests and connects the streams through the proxy
+func (e *Exchange) SetProxy(proxyURL *url.URL) {
+	e.proxyURL = proxyURL
+}
+
 func (e *Exchange) newRest() *restRequest {
-	r := newRestRequest(&http.Client{Timeout: defaultHTTPTimeout}, e.restEndpoint).Auth(e.key, e.secret)
+	var client = &http.Client{Timeout: defaultHTTPTimeout}
+	if e.proxyURL != nil {
+		client = util.NewProxyHTTPClient(client, e.proxyURL)
+	}
+
+	if e.limiter != nil {
+		client = util.NewRateLimitedHTTPClient(client, e.limiter)
+	}
+
+	r := newRestRequest(client, e.restEndpoint).Auth(e.key, e.secret)
 	if len(e.subAccount) > 0 {
 		r.SubAccount(e.subAccount)
 	}
@@ -58,7 +85,26 @@ func (e *Exchange) PlatformFeeCurrency() string {
 }
 
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.key, e.secret)
+	stream := NewStream(e.key, e.secret)
+	if e.proxyURL != nil {
+		stream.ws.SetDialer(util.NewProxyWebsocketDialer(e.proxyURL))
+	}
+
+	return stream
+}
+
+// QueryServerTime queries the server time of the exchange
+func (e *Exchange) QueryServerTime(ctx context.Context) (time.Time, error) {
+	resp, err := e.newRest().ServerTime(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !resp.Success {
+		return time.Time{}, fmt.Errorf("ftx returns querying server time failure")
+	}
+
+	return resp.Result, nil
 }
 
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
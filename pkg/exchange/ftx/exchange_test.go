@@ -427,6 +427,23 @@ func TestExchange_QueryMarkets(t *testing.T) {
 	}, resp["BTCUSD"])
 }
 
+func TestExchange_QueryServerTime(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/time", r.URL.Path)
+		fmt.Fprintln(w, `{"success": true, "result": "2021-08-16T12:34:56.789012+00:00"}`)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	serverTime, err := ex.QueryServerTime(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 8, 16, 12, 34, 56, 789012000, time.UTC), serverTime.UTC())
+}
+
 func TestExchange_QueryDepositHistory(t *testing.T) {
 	respJSON := `
 {
@@ -32,6 +32,25 @@ func (r *marketRequest) Markets(ctx context.Context) (marketsResponse, error) {
 	return m, nil
 }
 
+// ServerTime queries the server time, doc: https://docs.ftx.com/#rest-api
+func (r *marketRequest) ServerTime(ctx context.Context) (serverTimeResponse, error) {
+	resp, err := r.
+		Method("GET").
+		ReferenceURL("api/time").
+		DoAuthenticatedRequest(ctx)
+
+	if err != nil {
+		return serverTimeResponse{}, err
+	}
+
+	var t serverTimeResponse
+	if err := json.Unmarshal(resp.Body, &t); err != nil {
+		return serverTimeResponse{}, fmt.Errorf("failed to unmarshal server time response body to json: %w", err)
+	}
+
+	return t, nil
+}
+
 /*
 supported resolutions: window length in seconds. options: 15, 60, 300, 900, 3600, 14400, 86400
 doc: https://docs.ftx.com/?javascript#get-historical-prices
@@ -169,6 +169,17 @@ type balances struct {
   }
 ]
 */
+/*
+{
+  "success": true,
+  "result": "2021-08-16T12:34:56.789012+00:00"
+}
+*/
+type serverTimeResponse struct {
+	Success bool      `json:"success"`
+	Result  time.Time `json:"result"`
+}
+
 type marketsResponse struct {
 	Success bool     `json:"success"`
 	Result  []market `json:"result"`
@@ -27,14 +27,21 @@ type Stream struct {
 
 	// subscriptions are only accessed in single goroutine environment, so I don't use mutex to protect them
 	subscriptions []websocketRequest
+
+	// pingInterval is the interval of the ping requests
+	pingInterval time.Duration
 }
 
+// defaultPingInterval is the ping interval of the ftx websocket api, https://docs.ftx.com/?javascript#request-process
+const defaultPingInterval = 15 * time.Second
+
 func NewStream(key, secret string) *Stream {
 	s := &Stream{
 		key:            key,
 		secret:         secret,
 		StandardStream: &types.StandardStream{},
 		ws:             service.NewWebsocketClientBase(endpoint, 3*time.Second),
+		pingInterval:   defaultPingInterval,
 	}
 
 	s.ws.OnMessage((&messageHandler{StandardStream: s.StandardStream}).handleMessage)
@@ -51,6 +58,14 @@ func NewStream(key, secret string) *Stream {
 	return s
 }
 
+// SetConnectionOptions sets the connect timeout and the ping interval of the websocket connection
+func (s *Stream) SetConnectionOptions(options types.StreamConnectionOptions) {
+	s.ws.SetConnectTimeout(options.ConnectTimeout)
+	if options.KeepaliveInterval > 0 {
+		s.pingInterval = options.KeepaliveInterval
+	}
+}
+
 func (s *Stream) Connect(ctx context.Context) error {
 	// If it's not public only, let's do the authentication.
 	if atomic.LoadInt32(&s.publicOnly) == 0 {
@@ -62,8 +77,7 @@ func (s *Stream) Connect(ctx context.Context) error {
 	}
 
 	go func() {
-		// https://docs.ftx.com/?javascript#request-process
-		tk := time.NewTicker(15 * time.Second)
+		tk := time.NewTicker(s.pingInterval)
 		defer tk.Stop()
 		for {
 			select {
@@ -71,6 +85,7 @@ func (s *Stream) Connect(ctx context.Context) error {
 				if err := ctx.Err(); err != nil {
 					logger.WithError(err).Errorf("websocket ping goroutine is terminated")
 				}
+				return
 			case <-tk.C:
 				if err := s.ws.Conn().WriteJSON(websocketRequest{
 					Operation: ping,
@@ -113,6 +128,23 @@ func (s *Stream) Subscribe(channel types.Channel, symbol string, _ types.Subscri
 	})
 }
 
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string, _ types.SubscribeOptions) {
+	if channel != types.BookChannel {
+		return
+	}
+
+	var subscriptions []websocketRequest
+	for _, sub := range s.subscriptions {
+		if sub.Channel == orderBookChannel && sub.Market == TrimUpperString(symbol) {
+			continue
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	s.subscriptions = subscriptions
+}
+
 func (s *Stream) Close() error {
 	s.subscriptions = nil
 	if s.ws != nil {
@@ -247,6 +247,24 @@ func toGlobalTrade(t max.Trade) (*types.Trade, error) {
 	}, nil
 }
 
+// toGlobalDepositAddress returns the first generated deposit address of the asset
+func toGlobalDepositAddress(asset string, addresses []max.DepositAddress) (*types.DepositAddress, error) {
+	for _, a := range addresses {
+		if len(a.Address) == 0 {
+			continue
+		}
+
+		return &types.DepositAddress{
+			Exchange: types.ExchangeMax,
+			Asset:    asset,
+			Network:  strings.ToUpper(a.CurrencyVersion),
+			Address:  a.Address,
+		}, nil
+	}
+
+	return nil, types.ErrDepositAddressNotFound
+}
+
 func toGlobalDepositStatus(a string) types.DepositStatus {
 	switch a {
 	case "submitting", "submitted", "checking":
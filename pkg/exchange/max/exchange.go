@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
 	"sort"
 	"strconv"
@@ -31,6 +32,9 @@ var log = logrus.WithField("exchange", "max")
 type Exchange struct {
 	client      *maxapi.RestClient
 	key, secret string
+
+	// proxyURL is the proxy of the streams, the streams connect directly if it's nil
+	proxyURL *url.URL
 }
 
 func New(key, secret string) *Exchange {
@@ -52,6 +56,18 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeMax
 }
 
+// SetRateLimiter limits the request rate of the REST API client,
+// the query specific limiters of this package are still applied.
+func (e *Exchange) SetRateLimiter(limiter *rate.Limiter) {
+	e.client.SetRateLimiter(limiter)
+}
+
+// SetProxy sends the REST API requests and connects the streams through the proxy
+func (e *Exchange) SetProxy(proxyURL *url.URL) {
+	e.proxyURL = proxyURL
+	e.client.SetProxy(proxyURL)
+}
+
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
 	ticker, err := e.client.PublicService.Ticker(toLocalSymbol(symbol))
 	if err != nil {
@@ -116,6 +132,16 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 	return tickers, nil
 }
 
+// QueryServerTime queries the server time of the exchange, the server time is in seconds
+func (e *Exchange) QueryServerTime(ctx context.Context) (time.Time, error) {
+	timestamp, err := e.client.PublicService.Timestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(timestamp, 0), nil
+}
+
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	log.Info("querying market info...")
 
@@ -153,7 +179,12 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 }
 
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.key, e.secret)
+	stream := NewStream(e.key, e.secret)
+	if e.proxyURL != nil {
+		stream.websocketService.SetDialer(util.NewProxyWebsocketDialer(e.proxyURL))
+	}
+
+	return stream
 }
 
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
@@ -500,6 +531,40 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 	return a, nil
 }
 
+// QueryDepositAddress queries the deposit address of the asset, the address must be created by CreateDepositAddress
+// before it's used
+func (e *Exchange) QueryDepositAddress(ctx context.Context, asset string) (*types.DepositAddress, error) {
+	if err := accountQueryLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	addresses, err := e.client.AccountService.NewGetDepositAddressesRequest().
+		Currency(toLocalCurrency(asset)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalDepositAddress(asset, addresses)
+}
+
+// CreateDepositAddress creates the deposit address of the asset, the address is generated asynchronously,
+// types.ErrDepositAddressNotFound is returned if the generation is not done yet
+func (e *Exchange) CreateDepositAddress(ctx context.Context, asset string) (*types.DepositAddress, error) {
+	if err := accountQueryLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	addresses, err := e.client.AccountService.NewCreateDepositAddressesRequest().
+		Currency(toLocalCurrency(asset)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalDepositAddress(asset, addresses)
+}
+
 func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
 	startTime := since
 	limit := 1000
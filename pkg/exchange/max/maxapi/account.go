@@ -221,6 +221,95 @@ func (s *AccountService) NewGetDepositHistoryRequest() *GetDepositHistoryRequest
 	}
 }
 
+type DepositAddress struct {
+	Currency        string `json:"currency"`
+	CurrencyVersion string `json:"currency_version"` // "eth", "erc20"
+	Address         string `json:"address"`
+	Type            string `json:"type"`
+}
+
+type GetDepositAddressesRequestParams struct {
+	*PrivateRequestParams
+
+	Currency string `json:"currency,omitempty"`
+}
+
+type GetDepositAddressesRequest struct {
+	client *RestClient
+	params GetDepositAddressesRequestParams
+}
+
+func (r *GetDepositAddressesRequest) Currency(currency string) *GetDepositAddressesRequest {
+	r.params.Currency = currency
+	return r
+}
+
+func (r *GetDepositAddressesRequest) Do(ctx context.Context) (addresses []DepositAddress, err error) {
+	req, err := r.client.newAuthenticatedRequest("GET", "v2/deposit_addresses", &r.params)
+	if err != nil {
+		return addresses, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return addresses, err
+	}
+
+	if err := response.DecodeJSON(&addresses); err != nil {
+		return addresses, err
+	}
+
+	return addresses, err
+}
+
+func (s *AccountService) NewGetDepositAddressesRequest() *GetDepositAddressesRequest {
+	return &GetDepositAddressesRequest{
+		client: s.client,
+	}
+}
+
+type CreateDepositAddressesRequestParams struct {
+	*PrivateRequestParams
+
+	Currency string `json:"currency"`
+}
+
+// CreateDepositAddressesRequest creates the deposit addresses of the currency, the addresses are generated
+// asynchronously, so the returned address could be empty until the generation is done
+type CreateDepositAddressesRequest struct {
+	client *RestClient
+	params CreateDepositAddressesRequestParams
+}
+
+func (r *CreateDepositAddressesRequest) Currency(currency string) *CreateDepositAddressesRequest {
+	r.params.Currency = currency
+	return r
+}
+
+func (r *CreateDepositAddressesRequest) Do(ctx context.Context) (addresses []DepositAddress, err error) {
+	req, err := r.client.newAuthenticatedRequest("POST", "v2/deposit_addresses", &r.params)
+	if err != nil {
+		return addresses, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return addresses, err
+	}
+
+	if err := response.DecodeJSON(&addresses); err != nil {
+		return addresses, err
+	}
+
+	return addresses, err
+}
+
+func (s *AccountService) NewCreateDepositAddressesRequest() *CreateDepositAddressesRequest {
+	return &CreateDepositAddressesRequest{
+		client: s.client,
+	}
+}
+
 type Withdraw struct {
 	UUID            string `json:"uuid"`
 	Currency        string `json:"currency"`
@@ -15,12 +15,14 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/util"
 	"github.com/c9s/bbgo/pkg/version"
@@ -73,6 +75,12 @@ type RestClient struct {
 	TradeService   *TradeService
 	OrderService   *OrderService
 	RewardService  *RewardService
+
+	// the server timestamp is loaded by the first authenticated request instead of the constructor,
+	// so that creating the client doesn't send any request
+	nonceMutex  sync.Mutex
+	nonceSynced bool
+
 	// OrderBookService *OrderBookService
 	// MaxTokenService  *MaxTokenService
 	// MaxKLineService  *KLineService
@@ -97,7 +105,6 @@ func NewRestClientWithHttpClient(baseURL string, httpClient *http.Client) *RestC
 	client.RewardService = &RewardService{client}
 
 	// client.MaxTokenService = &MaxTokenService{client}
-	client.initNonce()
 	return client
 }
 
@@ -107,6 +114,16 @@ func NewRestClient(baseURL string) *RestClient {
 	})
 }
 
+// SetRateLimiter limits the request rate of the client, the requests wait for the limiter before being sent.
+func (c *RestClient) SetRateLimiter(limiter *rate.Limiter) {
+	c.client = util.NewRateLimitedHTTPClient(c.client, limiter)
+}
+
+// SetProxy sends the requests of the client through the proxy, it should be called before SetRateLimiter
+func (c *RestClient) SetProxy(proxyURL *url.URL) {
+	c.client = util.NewProxyHTTPClient(c.client, proxyURL)
+}
+
 // Auth sets api key and secret for usage is requests that requires authentication.
 func (c *RestClient) Auth(key string, secret string) *RestClient {
 	c.APIKey = key
@@ -114,21 +131,42 @@ func (c *RestClient) Auth(key string, secret string) *RestClient {
 	return c
 }
 
-func (c *RestClient) initNonce() {
+func (c *RestClient) initNonce() error {
 	var clientTime = time.Now()
-	var err error
-	serverTimestamp, err = c.PublicService.Timestamp()
+	timestamp, err := c.PublicService.Timestamp()
 	if err != nil {
-		logger.WithError(err).Panic("failed to sync timestamp with Max")
+		return err
 	}
 
+	serverTimestamp = timestamp
+
 	// 1 is for the request count mod 0.000 to 0.999
 	timeOffset = serverTimestamp - clientTime.Unix() - 1
 
 	logger.Infof("loaded max server timestamp: %d offset=%d", serverTimestamp, timeOffset)
+	return nil
+}
+
+// syncNonce loads the server timestamp once, the failed sync is retried by the next authenticated request
+func (c *RestClient) syncNonce() {
+	c.nonceMutex.Lock()
+	defer c.nonceMutex.Unlock()
+
+	if c.nonceSynced {
+		return
+	}
+
+	if err := c.initNonce(); err != nil {
+		logger.WithError(err).Error("failed to sync timestamp with Max, using the local time for the nonce")
+		return
+	}
+
+	c.nonceSynced = true
 }
 
 func (c *RestClient) getNonce() int64 {
+	c.syncNonce()
+
 	var seconds = time.Now().Unix()
 	var rc = atomic.AddInt64(&reqCount, 1)
 	return (seconds+timeOffset)*1000 + int64(math.Mod(float64(rc), 1000.0))
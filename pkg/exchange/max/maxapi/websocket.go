@@ -47,6 +47,14 @@ type WebSocketService struct {
 	mu   sync.Mutex
 	conn *websocket.Conn
 
+	// dialer is the websocket dialer, websocket.DefaultDialer is used if it's nil
+	dialer *websocket.Dialer
+
+	// connectTimeout is the deadline of the handshake, and pingInterval is the interval of the ping messages,
+	// they're disabled if they're zero
+	connectTimeout time.Duration
+	pingInterval   time.Duration
+
 	reconnectC chan struct{}
 
 	// Subscriptions is the subscription request payloads that will be used for sending subscription request
@@ -110,12 +118,39 @@ func (s *WebSocketService) Auth() error {
 	return s.conn.WriteJSON(auth)
 }
 
+// SetDialer sets the websocket dialer, e.g., the dialer connecting through a proxy
+func (s *WebSocketService) SetDialer(dialer *websocket.Dialer) {
+	s.mu.Lock()
+	s.dialer = dialer
+	s.mu.Unlock()
+}
+
+// SetConnectionOptions sets the connect timeout and the ping interval of the connection
+func (s *WebSocketService) SetConnectionOptions(connectTimeout, pingInterval time.Duration) {
+	s.mu.Lock()
+	s.connectTimeout = connectTimeout
+	s.pingInterval = pingInterval
+	s.mu.Unlock()
+}
+
 func (s *WebSocketService) connect(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	dialer := websocket.DefaultDialer
-	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
+	if s.dialer != nil {
+		dialer = s.dialer
+	}
+
+	// the deadline only applies to the handshake, the connection lives with the context
+	dialCtx := ctx
+	if s.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, s.connectTimeout)
+		defer cancel()
+	}
+
+	conn, _, err := dialer.DialContext(dialCtx, s.baseURL, nil)
 	if err != nil {
 		return err
 	}
@@ -125,9 +160,33 @@ func (s *WebSocketService) connect(ctx context.Context) error {
 
 	go s.read(ctx)
 
+	if s.pingInterval > 0 {
+		go s.ping(ctx, conn, s.pingInterval)
+	}
+
 	return nil
 }
 
+// ping sends the ping messages of the connection until the connection is closed
+func (s *WebSocketService) ping(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// the control messages can be written concurrently with the other messages
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+				log.WithError(err).Debug("websocket ping error, the ping is stopped")
+				return
+			}
+		}
+	}
+}
+
 func (s *WebSocketService) emitReconnect() {
 	select {
 	case s.reconnectC <- struct{}{}:
@@ -258,6 +317,20 @@ func (s *WebSocketService) AddSubscription(subscription Subscription) {
 	s.Subscriptions = append(s.Subscriptions, subscription)
 }
 
+// RemoveSubscription removes the subscription request from the buffer, it takes effect on the next connect
+func (s *WebSocketService) RemoveSubscription(subscription Subscription) {
+	var subscriptions []Subscription
+	for _, sub := range s.Subscriptions {
+		if sub == subscription {
+			continue
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	s.Subscriptions = subscriptions
+}
+
 func (s *WebSocketService) Resubscribe() {
 	// Calling Resubscribe() by websocket is not enough to refresh orderbook.
 	// We still need to get orderbook snapshot by rest client.
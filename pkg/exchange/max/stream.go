@@ -158,6 +158,20 @@ func (s *Stream) SetPublicOnly() {
 }
 
 func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.websocketService.Subscribe(string(channel), toLocalSymbol(symbol), toLocalSubscribeOptions(options))
+}
+
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	opt := toLocalSubscribeOptions(options)
+	s.websocketService.RemoveSubscription(max.Subscription{
+		Channel:    string(channel),
+		Market:     toLocalSymbol(symbol),
+		Depth:      opt.Depth,
+		Resolution: opt.Resolution,
+	})
+}
+
+func toLocalSubscribeOptions(options types.SubscribeOptions) max.SubscribeOptions {
 	opt := max.SubscribeOptions{}
 
 	if len(options.Depth) > 0 {
@@ -172,7 +186,13 @@ func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.S
 		opt.Resolution = options.Interval
 	}
 
-	s.websocketService.Subscribe(string(channel), toLocalSymbol(symbol), opt)
+	return opt
+}
+
+// SetConnectionOptions sets the connect timeout and the ping interval of the websocket connection,
+// the ping messages are not sent by default
+func (s *Stream) SetConnectionOptions(options types.StreamConnectionOptions) {
+	s.websocketService.SetConnectionOptions(options.ConnectTimeout, options.KeepaliveInterval)
 }
 
 func (s *Stream) Connect(ctx context.Context) error {
@@ -24,7 +24,8 @@ func (v Value) Value() (driver.Value, error) {
 func (v *Value) Scan(src interface{}) error {
 	switch d := src.(type) {
 	case int64:
-		*v = Value(d)
+		// the databases store the whole number decimals as integers, e.g., sqlite3
+		*v = NewFromInt64(d)
 		return nil
 
 	case float64:
@@ -117,3 +117,26 @@ func TestNumFractionalDigits(t *testing.T) {
 		})
 	}
 }
+
+func TestValue_Scan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want float64
+	}{
+		{name: "int64", src: int64(30000), want: 30000.0},
+		{name: "float64", src: 0.5, want: 0.5},
+		{name: "bytes", src: []byte("1.25"), want: 1.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Value
+			if err := v.Scan(tt.src); err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if got := v.Float64(); got != tt.want {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package interact
+
+import (
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// ValidateAuth returns true if the payload matches the fixed auth token,
+// or it's a valid one-time password generated with the key.
+func ValidateAuth(payload, authToken string, key *otp.Key) bool {
+	if len(authToken) > 0 && payload == authToken {
+		return true
+	}
+
+	if key != nil {
+		return totp.Validate(payload, key.Secret())
+	}
+
+	return false
+}
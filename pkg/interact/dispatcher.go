@@ -0,0 +1,343 @@
+package interact
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("service", "interact")
+
+const queryTimeout = 15 * time.Second
+
+// defaultPnLReportRange is the range of the pnl command if the start time is not given
+const defaultPnLReportRange = 30 * 24 * time.Hour
+
+// SessionStatus is the status of an exchange session shown by the sessions command
+type SessionStatus struct {
+	Name      string
+	Exchange  string
+	Connected bool
+
+	// DisconnectedAt is the time the session stream was disconnected
+	DisconnectedAt *time.Time
+}
+
+// Environment is the part of the bbgo environment the commands query,
+// it's an interface because the notifier packages can not import the bbgo package.
+type Environment interface {
+	// SessionStatuses returns the statuses of the configured sessions
+	SessionStatuses() []SessionStatus
+
+	// QuerySessionBalances queries the live balances of the session from the exchange
+	QuerySessionBalances(ctx context.Context, sessionName string) (types.BalanceMap, error)
+
+	// NetExposure aggregates the positions of the asset across the sessions
+	NetExposure(ctx context.Context, asset string) (*types.NetExposure, error)
+
+	// NotifyPnLReports generates and notifies the PnL reports of the symbol since the time,
+	// the reports of the sessions and the capped start time are returned
+	NotifyPnLReports(ctx context.Context, sessionName, symbol string, since time.Time) (map[string]*pnl.AverageCostPnlReport, time.Time, error)
+}
+
+// Command is a query command shared by the chat interactions
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+}
+
+// Commands are the query commands handled by the dispatcher
+var Commands = []Command{
+	{Name: "balance", Usage: "balance [session]", Description: "show the live balances of the session, or all the sessions if the session name is not given"},
+	{Name: "exposure", Usage: "exposure <asset>", Description: "show the net exposure of the asset across the sessions, e.g., exposure BTC"},
+	{Name: "pnl", Usage: "pnl <symbol> [since]", Description: "send the PnL report of the symbol since the time to the pnl channels, e.g., pnl BTCUSDT 7d or pnl BTCUSDT 2021-06-01, defaults to 30d"},
+	{Name: "sessions", Usage: "sessions", Description: "show the configured sessions and their connection status"},
+	{Name: "status", Usage: "status", Description: "alias of sessions"},
+	{Name: "ping", Usage: "ping", Description: "reply pong, it's used to check that the bot is alive"},
+}
+
+// Usage returns the help lines of the query commands, the prefix is prepended to the command names
+func Usage(prefix string) string {
+	var sb strings.Builder
+	for _, command := range Commands {
+		sb.WriteString(fmt.Sprintf("%s%s\t- %s\n", prefix, command.Usage, command.Description))
+	}
+
+	return sb.String()
+}
+
+// Dispatcher dispatches the query commands to the environment,
+// the chat interactions (telegram, slack) share the dispatcher so that the commands behave the same.
+type Dispatcher struct {
+	mu      sync.Mutex
+	environ Environment
+}
+
+// SetEnvironment sets the environment queried by the commands
+func (d *Dispatcher) SetEnvironment(environ Environment) {
+	d.mu.Lock()
+	d.environ = environ
+	d.mu.Unlock()
+}
+
+// Dispatch runs the command with the arguments and returns the reply message,
+// ok is false if the command is not one of the query commands.
+func (d *Dispatcher) Dispatch(command, args string) (reply string, ok bool) {
+	d.mu.Lock()
+	environ := d.environ
+	d.mu.Unlock()
+
+	switch command {
+	case "balance", "exposure", "pnl", "sessions", "status":
+	case "ping":
+		// ping does not query the environment, so it replies even if the environment is not available
+		return "pong", true
+	default:
+		return "", false
+	}
+
+	if environ == nil {
+		return "The environment is not available", true
+	}
+
+	switch command {
+	case "balance":
+		return balance(environ, strings.TrimSpace(args)), true
+
+	case "exposure":
+		return exposure(environ, strings.TrimSpace(args)), true
+
+	case "pnl":
+		return pnlReport(environ, strings.Fields(args), time.Now()), true
+
+	default:
+		return formatSessionStatuses(environ.SessionStatuses(), time.Now()), true
+	}
+}
+
+// balance queries the balances of the given session, or all the sessions if the session name is empty
+func balance(environ Environment, sessionName string) string {
+	var sessionNames []string
+	if len(sessionName) > 0 {
+		sessionNames = []string{sessionName}
+	} else {
+		for _, status := range environ.SessionStatuses() {
+			sessionNames = append(sessionNames, status.Name)
+		}
+		sort.Strings(sessionNames)
+	}
+
+	if len(sessionNames) == 0 {
+		return "No session is configured"
+	}
+
+	var messages []string
+	for _, sessionName := range sessionNames {
+		ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+		balances, err := environ.QuerySessionBalances(ctx, sessionName)
+		cancel()
+
+		if err != nil {
+			log.WithError(err).Errorf("failed to query the balances of the session %s", sessionName)
+			messages = append(messages, fmt.Sprintf("%s: failed to query balances: %v", sessionName, err))
+			continue
+		}
+
+		messages = append(messages, formatBalances(sessionName, balances))
+	}
+
+	return strings.Join(messages, "\n\n")
+}
+
+// exposure queries the net exposure of the asset
+func exposure(environ Environment, asset string) string {
+	if len(asset) == 0 {
+		return "Usage: exposure <asset>"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	netExposure, err := environ.NetExposure(ctx, asset)
+	if err != nil {
+		log.WithError(err).Errorf("failed to query the net exposure of %s", asset)
+		return fmt.Sprintf("failed to query the net exposure of %s: %v", asset, err)
+	}
+
+	return formatNetExposure(netExposure)
+}
+
+// pnlReport generates the PnL reports of the symbol since the given time, the reports are notified by the environment,
+// and the reply is the summary of the reports
+func pnlReport(environ Environment, args []string, now time.Time) string {
+	if len(args) == 0 || len(args) > 2 {
+		return "Usage: pnl <symbol> [since]"
+	}
+
+	symbol := strings.ToUpper(args[0])
+	since := now.Add(-defaultPnLReportRange)
+	if len(args) == 2 {
+		var err error
+		if since, err = ParseSince(args[1], now); err != nil {
+			return err.Error()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reports, cappedSince, err := environ.NotifyPnLReports(ctx, "", symbol, since)
+	if err != nil {
+		log.WithError(err).Errorf("failed to generate the %s pnl report", symbol)
+		return fmt.Sprintf("failed to generate the %s pnl report: %v", symbol, err)
+	}
+
+	var sb strings.Builder
+	if cappedSince.After(since) {
+		sb.WriteString(fmt.Sprintf("The range is capped, the report starts from %s\n", cappedSince.Format(time.RFC3339)))
+	}
+
+	if len(reports) == 0 {
+		sb.WriteString(fmt.Sprintf("No %s trades since %s", symbol, cappedSince.Format(time.RFC3339)))
+		return sb.String()
+	}
+
+	var sessionNames []string
+	for sessionName := range reports {
+		sessionNames = append(sessionNames, sessionName)
+	}
+	sort.Strings(sessionNames)
+
+	sb.WriteString(fmt.Sprintf("%s PnL since %s:", symbol, cappedSince.Format(time.RFC3339)))
+	for _, sessionName := range sessionNames {
+		report := reports[sessionName]
+		sb.WriteString(fmt.Sprintf("\n%s: %d trades, profit %f, unrealized profit %f %s",
+			sessionName, report.NumTrades, report.Profit, report.UnrealizedProfit, report.Market.QuoteCurrency))
+	}
+
+	return sb.String()
+}
+
+// ParseSince parses the start time of the range, it's either a duration before now, e.g., "24h" or "7d",
+// or a date, e.g., "2021-06-01", or a RFC3339 time
+func ParseSince(text string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(text, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(text, "d")); err == nil && days > 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(text); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid since %q, expecting a duration like 24h or 7d, or a date like 2021-06-01", text)
+}
+
+// formatNetExposure formats the aggregated exposure, the notional values are sorted by the quote currency
+func formatNetExposure(exposure *types.NetExposure) string {
+	if len(exposure.Sessions) == 0 {
+		return fmt.Sprintf("%s: no exposure", exposure.Asset)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s net exposure: %f (long %f, short %f)",
+		exposure.Asset,
+		exposure.Net().Float64(),
+		exposure.Long.Float64(),
+		exposure.Short.Float64()))
+
+	var quoteCurrencies []string
+	for quoteCurrency := range exposure.Notionals {
+		quoteCurrencies = append(quoteCurrencies, quoteCurrency)
+	}
+	sort.Strings(quoteCurrencies)
+
+	for _, quoteCurrency := range quoteCurrencies {
+		sb.WriteString(fmt.Sprintf("\nnotional: %f %s", exposure.Notionals[quoteCurrency].Float64(), quoteCurrency))
+	}
+
+	for _, session := range exposure.Sessions {
+		sb.WriteString(fmt.Sprintf("\n%s (%s", session.Session, session.AccountType))
+		if len(session.Symbol) > 0 {
+			sb.WriteString(" " + session.Symbol)
+		}
+
+		sb.WriteString(fmt.Sprintf("): long %f, short %f", session.Long.Float64(), session.Short.Float64()))
+	}
+
+	return sb.String()
+}
+
+// formatBalances formats the non-zero balances sorted by the currency
+func formatBalances(sessionName string, balances types.BalanceMap) string {
+	var currencies []string
+	for currency, balance := range balances {
+		if balance.Total() == 0 {
+			continue
+		}
+		currencies = append(currencies, currency)
+	}
+
+	if len(currencies) == 0 {
+		return fmt.Sprintf("%s: no balance", sessionName)
+	}
+
+	sort.Strings(currencies)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s balances:", sessionName))
+	for _, currency := range currencies {
+		balance := balances[currency]
+		sb.WriteString(fmt.Sprintf("\n%s: %f (available %f, locked %f)",
+			currency,
+			balance.Total().Float64(),
+			balance.Available.Float64(),
+			balance.Locked.Float64()))
+	}
+
+	return sb.String()
+}
+
+// formatSessionStatuses formats the session statuses sorted by the session name
+func formatSessionStatuses(statuses []SessionStatus, now time.Time) string {
+	if len(statuses) == 0 {
+		return "No session is configured"
+	}
+
+	statuses = append([]SessionStatus(nil), statuses...)
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	var sb strings.Builder
+	sb.WriteString("Sessions:")
+	for _, status := range statuses {
+		sb.WriteString(fmt.Sprintf("\n%s (%s): ", status.Name, status.Exchange))
+		if status.Connected {
+			sb.WriteString("connected")
+		} else if status.DisconnectedAt != nil {
+			sb.WriteString(fmt.Sprintf("disconnected for %s", now.Sub(*status.DisconnectedAt).Truncate(time.Second)))
+		} else {
+			sb.WriteString("disconnected")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,214 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestFormatBalances(t *testing.T) {
+	balances := types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.5), Locked: fixedpoint.NewFromFloat(20)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.25)},
+		"ETH":  {Currency: "ETH"},
+	}
+
+	assert.Equal(t, "binance balances:\n"+
+		"BTC: 0.250000 (available 0.250000, locked 0.000000)\n"+
+		"USDT: 120.500000 (available 100.500000, locked 20.000000)",
+		formatBalances("binance", balances))
+
+	assert.Equal(t, "max: no balance", formatBalances("max", types.BalanceMap{}))
+}
+
+func TestFormatSessionStatuses(t *testing.T) {
+	now := time.Now()
+	disconnectedAt := now.Add(-90 * time.Second)
+
+	message := formatSessionStatuses([]SessionStatus{
+		{Name: "max", Exchange: "max", DisconnectedAt: &disconnectedAt},
+		{Name: "binance", Exchange: "binance", Connected: true},
+		{Name: "ftx", Exchange: "ftx"},
+	}, now)
+
+	assert.Equal(t, "Sessions:\n"+
+		"binance (binance): connected\n"+
+		"ftx (ftx): disconnected\n"+
+		"max (max): disconnected for 1m30s", message)
+
+	assert.Equal(t, "No session is configured", formatSessionStatuses(nil, now))
+}
+
+type testEnvironment struct {
+	statuses []SessionStatus
+	balances map[string]types.BalanceMap
+	exposure *types.NetExposure
+	reports  map[string]*pnl.AverageCostPnlReport
+}
+
+func (e *testEnvironment) SessionStatuses() []SessionStatus {
+	return e.statuses
+}
+
+func (e *testEnvironment) QuerySessionBalances(ctx context.Context, sessionName string) (types.BalanceMap, error) {
+	balances, ok := e.balances[sessionName]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+
+	return balances, nil
+}
+
+func (e *testEnvironment) NetExposure(ctx context.Context, asset string) (*types.NetExposure, error) {
+	if e.exposure == nil || e.exposure.Asset != asset {
+		return nil, errors.New("asset not found")
+	}
+
+	return e.exposure, nil
+}
+
+func (e *testEnvironment) NotifyPnLReports(ctx context.Context, sessionName, symbol string, since time.Time) (map[string]*pnl.AverageCostPnlReport, time.Time, error) {
+	if earliest := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC); since.Before(earliest) {
+		since = earliest
+	}
+
+	var reports = make(map[string]*pnl.AverageCostPnlReport)
+	for name, report := range e.reports {
+		if report.Symbol == symbol {
+			reports[name] = report
+		}
+	}
+
+	return reports, since, nil
+}
+
+func TestFormatNetExposure(t *testing.T) {
+	exposure := &types.NetExposure{Asset: "BTC"}
+	assert.Equal(t, "BTC: no exposure", formatNetExposure(exposure))
+
+	exposure.Add(types.SessionExposure{
+		Session:       "binance",
+		AccountType:   types.AccountTypeSpot,
+		Long:          fixedpoint.NewFromFloat(1.0),
+		Price:         fixedpoint.NewFromFloat(50000),
+		QuoteCurrency: "USDT",
+	})
+	exposure.Add(types.SessionExposure{
+		Session:       "binance-isolated",
+		AccountType:   types.AccountTypeIsolatedMargin,
+		Symbol:        "BTCUSDT",
+		Short:         fixedpoint.NewFromFloat(0.5),
+		Price:         fixedpoint.NewFromFloat(50000),
+		QuoteCurrency: "USDT",
+	})
+	exposure.Add(types.SessionExposure{
+		Session:       "max",
+		AccountType:   types.AccountTypeSpot,
+		Long:          fixedpoint.NewFromFloat(0.25),
+		Price:         fixedpoint.NewFromFloat(1400000),
+		QuoteCurrency: "TWD",
+	})
+
+	assert.Equal(t, "BTC net exposure: 0.750000 (long 1.250000, short 0.500000)\n"+
+		"notional: 350000.000000 TWD\n"+
+		"notional: 25000.000000 USDT\n"+
+		"binance (spot): long 1.000000, short 0.000000\n"+
+		"binance-isolated (isolated margin BTCUSDT): long 0.000000, short 0.500000\n"+
+		"max (spot): long 0.250000, short 0.000000", formatNetExposure(exposure))
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	var dispatcher Dispatcher
+
+	_, ok := dispatcher.Dispatch("auth", "")
+	assert.False(t, ok)
+
+	reply, ok := dispatcher.Dispatch("balance", "")
+	assert.True(t, ok)
+	assert.Equal(t, "The environment is not available", reply)
+
+	reply, ok = dispatcher.Dispatch("ping", "")
+	assert.True(t, ok)
+	assert.Equal(t, "pong", reply)
+
+	dispatcher.SetEnvironment(&testEnvironment{
+		statuses: []SessionStatus{
+			{Name: "max", Exchange: "max"},
+			{Name: "binance", Exchange: "binance", Connected: true},
+		},
+		balances: map[string]types.BalanceMap{
+			"binance": {"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)}},
+			"max":     {},
+		},
+	})
+
+	reply, _ = dispatcher.Dispatch("balance", " binance ")
+	assert.Equal(t, "binance balances:\nBTC: 0.500000 (available 0.500000, locked 0.000000)", reply)
+
+	reply, _ = dispatcher.Dispatch("balance", "")
+	assert.Equal(t, "binance balances:\nBTC: 0.500000 (available 0.500000, locked 0.000000)\n\nmax: no balance", reply)
+
+	reply, _ = dispatcher.Dispatch("balance", "ftx")
+	assert.Equal(t, "ftx: failed to query balances: session not found", reply)
+
+	reply, _ = dispatcher.Dispatch("exposure", "")
+	assert.Equal(t, "Usage: exposure <asset>", reply)
+
+	reply, _ = dispatcher.Dispatch("exposure", "ETH")
+	assert.Equal(t, "failed to query the net exposure of ETH: asset not found", reply)
+
+	reply, _ = dispatcher.Dispatch("status", "")
+	assert.Equal(t, "Sessions:\nbinance (binance): connected\nmax (max): disconnected", reply)
+}
+
+func TestDispatcher_PnL(t *testing.T) {
+	environ := &testEnvironment{
+		reports: map[string]*pnl.AverageCostPnlReport{
+			"max":     {Symbol: "BTCUSDT", NumTrades: 2, Profit: 10, Market: types.Market{QuoteCurrency: "USDT"}},
+			"binance": {Symbol: "BTCUSDT", NumTrades: 3, Profit: 20, UnrealizedProfit: 5, Market: types.Market{QuoteCurrency: "USDT"}},
+		},
+	}
+
+	now := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Usage: pnl <symbol> [since]", pnlReport(environ, nil, now))
+	assert.Equal(t, `invalid since "yesterday", expecting a duration like 24h or 7d, or a date like 2021-06-01`,
+		pnlReport(environ, []string{"BTCUSDT", "yesterday"}, now))
+
+	assert.Equal(t, "BTCUSDT PnL since 2021-06-24T00:00:00Z:\n"+
+		"binance: 3 trades, profit 20.000000, unrealized profit 5.000000 USDT\n"+
+		"max: 2 trades, profit 10.000000, unrealized profit 0.000000 USDT", pnlReport(environ, []string{"btcusdt", "7d"}, now))
+
+	assert.Equal(t, "The range is capped, the report starts from 2021-06-01T00:00:00Z\n"+
+		"No ETHUSDT trades since 2021-06-01T00:00:00Z", pnlReport(environ, []string{"ETHUSDT", "2021-01-01"}, now))
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	for text, expected := range map[string]time.Time{
+		"24h":                  time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC),
+		"30d":                  time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		"2021-06-15":           time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC),
+		"2021-06-15T08:00:00Z": time.Date(2021, 6, 15, 8, 0, 0, 0, time.UTC),
+	} {
+		since, err := ParseSince(text, now)
+		if assert.NoError(t, err, text) {
+			assert.Equal(t, expected, since, text)
+		}
+	}
+
+	_, err := ParseSince("-7d", now)
+	assert.Error(t, err)
+}
+
+func TestValidateAuth(t *testing.T) {
+	assert.True(t, ValidateAuth("itsme", "itsme", nil))
+	assert.False(t, ValidateAuth("", "", nil))
+	assert.False(t, ValidateAuth("wrong", "itsme", nil))
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddOrdersStatusIndex, downAddOrdersStatusIndex)
+
+}
+
+func upAddOrdersStatusIndex(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX orders_symbol_status ON orders (exchange, symbol, status, created_at);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddOrdersStatusIndex(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP INDEX orders_symbol_status ON orders;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
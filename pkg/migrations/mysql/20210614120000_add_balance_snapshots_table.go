@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddBalanceSnapshotsTable, downAddBalanceSnapshotsTable)
+
+}
+
+func upAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `balance_snapshots`\n(\n    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `session`        VARCHAR(30)     NOT NULL,\n    `exchange`       VARCHAR(24)     NOT NULL,\n    `currency`       VARCHAR(10)     NOT NULL,\n    `available`      DECIMAL(20, 8)  NOT NULL,\n    `locked`         DECIMAL(20, 8)  NOT NULL,\n    -- price is the price of the currency in the quote currency, it's zero if the price is unknown\n    `price`          DECIMAL(20, 8)  NOT NULL DEFAULT 0,\n    `quote_currency` VARCHAR(10)     NOT NULL,\n    -- created_at is the time of the snapshot, the balances of a session snapshot share the same time\n    `created_at`     DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `balance_snapshots_session_created_at` (`session`, `created_at`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `balance_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upTrades, downTrades)
+
+}
+
+func upTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE trades\n(\n    gid            BIGSERIAL      NOT NULL,\n    id             BIGINT,\n    order_id       BIGINT         NOT NULL,\n    exchange       VARCHAR(24)    NOT NULL DEFAULT '',\n    symbol         VARCHAR(20)    NOT NULL,\n    price          NUMERIC(16, 8) NOT NULL,\n    quantity       NUMERIC(16, 8) NOT NULL,\n    quote_quantity NUMERIC(16, 8) NOT NULL,\n    fee            NUMERIC(16, 8) NOT NULL,\n    fee_currency   VARCHAR(10)    NOT NULL,\n    is_buyer       BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_maker       BOOLEAN        NOT NULL DEFAULT FALSE,\n    side           VARCHAR(4)     NOT NULL DEFAULT '',\n    traded_at      TIMESTAMP(3)   NOT NULL,\n    is_margin      BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_isolated    BOOLEAN        NOT NULL DEFAULT FALSE,\n    pnl            NUMERIC        NULL,\n    strategy       VARCHAR(32)    NULL,\n    PRIMARY KEY (gid)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX trades_id ON trades (exchange, symbol, side, id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_symbol ON trades (exchange, symbol);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_symbol_fee_currency ON trades (exchange, symbol, fee_currency, traded_at);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_traded_at_symbol ON trades (exchange, traded_at, symbol);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_price_quantity ON trades (order_id, price, quantity);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS trades;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddPositionsTable, downAddPositionsTable)
+
+}
+
+func upAddPositionsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE positions\n(\n    gid            BIGSERIAL      NOT NULL,\n    session        VARCHAR(30)    NOT NULL,\n    exchange       VARCHAR(24)    NOT NULL,\n    symbol         VARCHAR(20)    NOT NULL,\n    base_currency  VARCHAR(10)    NOT NULL,\n    quote_currency VARCHAR(10)    NOT NULL,\n    -- base is negative for the short position\n    base           NUMERIC(20, 8) NOT NULL DEFAULT 0,\n    quote          NUMERIC(20, 8) NOT NULL DEFAULT 0,\n    average_cost   NUMERIC(20, 8) NOT NULL DEFAULT 0,\n    -- last_trade_gid is the gid of the last trade aggregated into the position\n    last_trade_gid BIGINT         NOT NULL DEFAULT 0,\n    updated_at     TIMESTAMP(3)   NOT NULL,\n    PRIMARY KEY (gid)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX positions_session_symbol ON positions (session, symbol);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddPositionsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS positions;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,44 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddSyncCursorsTable, downAddSyncCursorsTable)
+
+}
+
+func upAddSyncCursorsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `sync_cursors`\n(\n    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,\n    `session`       VARCHAR(30) NOT NULL,\n    `exchange`      VARCHAR(24) NOT NULL,\n    `symbol`        VARCHAR(20) NOT NULL,\n    -- last_trade_id and last_order_id are the IDs of the last synced trade and order\n    `last_trade_id` INTEGER     NOT NULL DEFAULT 0,\n    `last_order_id` INTEGER     NOT NULL DEFAULT 0,\n    -- synced_at is the time the last successful sync started\n    `synced_at`     DATETIME(3) NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX `sync_cursors_session_symbol` ON `sync_cursors` (`session`, `symbol`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddSyncCursorsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP INDEX IF EXISTS `sync_cursors_session_symbol`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `sync_cursors`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,301 @@
+package discordnotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+var log = logrus.WithField("service", "discord")
+
+const (
+	defaultAPIBaseURL = "https://discord.com/api/v10"
+
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Minute
+)
+
+// DiscordEmbedCreator is implemented by the objects that can be rendered into a discord embed
+type DiscordEmbedCreator interface {
+	DiscordEmbed() Embed
+}
+
+// DiscordFileCreator is implemented by the objects that can be uploaded as a discord attachment, e.g., a PnL chart
+type DiscordFileCreator interface {
+	DiscordFile() File
+}
+
+// File is a file attachment that will be uploaded with the message
+type File struct {
+	Name string
+	Data []byte
+}
+
+type Notifier struct {
+	client  *http.Client
+	token   string
+	baseURL string
+
+	// channel is the default channel ID
+	channel string
+
+	// channels maps the bbgo channel names to the discord channel IDs
+	channels map[string]string
+
+	// breaker drops the notifications while the API keeps failing
+	breaker *util.CircuitBreaker
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithChannels sets the channel name to discord channel ID mapping
+func WithChannels(channels map[string]string) NotifyOption {
+	return func(notifier *Notifier) {
+		for name, id := range channels {
+			notifier.channels[name] = id
+		}
+	}
+}
+
+// WithBaseURL overrides the discord API base URL
+func WithBaseURL(baseURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithCircuitBreaker sets the number of the consecutive failed requests to open the circuit breaker, and the cool-down
+// period the notifications are dropped before the request is tried again. The breaker is disabled if the threshold
+// is negative.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if cooldown <= 0 {
+			cooldown = defaultCooldown
+		}
+
+		notifier.breaker = util.NewCircuitBreaker("discord", failureThreshold, cooldown)
+	}
+}
+
+func New(token, channel string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		token:    token,
+		baseURL:  defaultAPIBaseURL,
+		channel:  channel,
+		channels: make(map[string]string),
+		breaker:  util.NewCircuitBreaker("discord", defaultFailureThreshold, defaultCooldown),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	return notifier
+}
+
+// CircuitBreaker returns the circuit breaker of the API requests
+func (n *Notifier) CircuitBreaker() *util.CircuitBreaker {
+	return n.breaker
+}
+
+// resolveChannel translates the bbgo channel name into the discord channel ID,
+// if the channel is not defined in the mapping, it's treated as a channel ID.
+func (n *Notifier) resolveChannel(channel string) string {
+	if len(channel) == 0 {
+		channel = n.channel
+	}
+
+	if id, ok := n.channels[channel]; ok {
+		return id
+	}
+
+	return channel
+}
+
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo(n.channel, format, args...)
+}
+
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	if err := n.TryNotifyTo(types.OutputFormatText, channel, format, args...); err == util.ErrCircuitOpen {
+		log.Debugf("discord circuit breaker is open, dropping the notification")
+	} else if err != nil {
+		log.WithError(err).
+			WithField("channel", n.resolveChannel(channel)).
+			Errorf("discord error: %s", err.Error())
+	}
+}
+
+// TryNotifyTo sends the notification like NotifyTo and returns the delivery error, the output format is ignored
+// because discord always renders the objects as the embeds
+func (n *Notifier) TryNotifyTo(_ types.OutputFormat, channel, format string, args ...interface{}) error {
+	channelID := n.resolveChannel(channel)
+
+	var embeds []Embed
+	var files []File
+	var objectArgsOffset = -1
+
+	for idx, arg := range args {
+		var embed *Embed
+		var file *File
+
+		switch a := arg.(type) {
+
+		// concrete type assert first
+		case Embed:
+			embed = &a
+
+		case File:
+			file = &a
+
+		case DiscordEmbedCreator:
+			e := a.DiscordEmbed()
+			embed = &e
+
+		case DiscordFileCreator:
+			f := a.DiscordFile()
+			file = &f
+
+		default:
+			embed = newEmbedFromObject(arg)
+
+		}
+
+		if embed == nil && file == nil {
+			continue
+		}
+
+		if objectArgsOffset == -1 {
+			objectArgsOffset = idx
+		}
+
+		if embed != nil {
+			embeds = append(embeds, *embed)
+		}
+
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+
+	var textArgs = args
+	if objectArgsOffset > -1 {
+		textArgs = args[:objectArgsOffset]
+	}
+
+	text := fmt.Sprintf(format, textArgs...)
+	message := &Message{Embeds: embeds}
+
+	// the rendered report text (TemplateTradeReport, TemplateOrderReport) goes into the embed description
+	if len(embeds) > 0 && len(embeds[0].Description) == 0 {
+		message.Embeds[0].Description = text
+	} else {
+		message.Content = text
+	}
+
+	return n.postMessage(context.Background(), channelID, message, files...)
+}
+
+// TestNotify sends the test message to the default channel and returns the delivery error
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	return n.postMessage(ctx, n.resolveChannel(""), &Message{Content: message})
+}
+
+func (n *Notifier) postMessage(ctx context.Context, channelID string, message *Message, files ...File) error {
+	if len(channelID) == 0 {
+		return errors.New("discord channel is not defined")
+	}
+
+	for idx, file := range files {
+		message.Attachments = append(message.Attachments, Attachment{ID: idx, Filename: file.Name})
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader = bytes.NewReader(payload)
+	var contentType = "application/json"
+
+	// when files are given, the message is sent as a multipart form with the json payload in the payload_json field
+	// and the files in the files[n] fields
+	if len(files) > 0 {
+		var buf bytes.Buffer
+		var writer = multipart.NewWriter(&buf)
+
+		if err := writer.WriteField("payload_json", string(payload)); err != nil {
+			return err
+		}
+
+		for idx, file := range files {
+			part, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", idx), file.Name)
+			if err != nil {
+				return err
+			}
+
+			if _, err := part.Write(file.Data); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		body = &buf
+		contentType = writer.FormDataContentType()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.baseURL+"/channels/"+channelID+"/messages", body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bot "+n.token)
+	req.Header.Set("Content-Type", contentType)
+
+	if err := n.breaker.Allow(); err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	// the rejected requests, e.g., 4xx, are not counted as the failures of the breaker since the server is reachable
+	if response.StatusCode >= 500 {
+		err := fmt.Errorf("discord server error: status %d, response: %s", response.StatusCode, response.String())
+		n.breaker.Report(err)
+		return err
+	}
+
+	n.breaker.Report(nil)
+
+	if response.IsError() {
+		return fmt.Errorf("discord api error: status %d, response: %s", response.StatusCode, response.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package discordnotifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testChart struct{}
+
+func (c testChart) DiscordFile() File {
+	return File{Name: "pnl.png", Data: []byte("png data")}
+}
+
+type request struct {
+	path        string
+	contentType string
+	message     Message
+	files       map[string][]byte
+}
+
+func newTestServer(t *testing.T, requests *[]request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bot token", r.Header.Get("Authorization"))
+
+		req := request{path: r.URL.Path, contentType: r.Header.Get("Content-Type")}
+		if r.ParseMultipartForm(1<<20) == nil {
+			assert.NoError(t, json.Unmarshal([]byte(r.FormValue("payload_json")), &req.message))
+
+			req.files = make(map[string][]byte)
+			for field, headers := range r.MultipartForm.File {
+				f, err := headers[0].Open()
+				assert.NoError(t, err)
+
+				data, err := ioutil.ReadAll(f)
+				assert.NoError(t, err)
+				req.files[field+":"+headers[0].Filename] = data
+			}
+		} else {
+			body, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(body, &req.message))
+		}
+
+		*requests = append(*requests, req)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestNotifier_ResolveChannel(t *testing.T) {
+	notifier := New("token", "100", WithChannels(map[string]string{
+		"trades": "200",
+	}))
+
+	assert.Equal(t, "100", notifier.resolveChannel(""))
+	assert.Equal(t, "200", notifier.resolveChannel("trades"))
+	assert.Equal(t, "300", notifier.resolveChannel("300"), "unknown channel names are treated as channel IDs")
+}
+
+func TestNotifier_NotifyTo(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	notifier := New("token", "100", WithBaseURL(server.URL+"/"), WithChannels(map[string]string{
+		"trades": "200",
+	}))
+
+	notifier.Notify("hello %s", "world")
+	notifier.NotifyTo("trades", "trade %s", "BTCUSDT", types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy})
+	notifier.NotifyTo("pnl", "report", pnl.AverageCostPnlReport{Symbol: "BTCUSDT"})
+
+	if assert.Len(t, requests, 3) {
+		assert.Equal(t, "/channels/100/messages", requests[0].path)
+		assert.Equal(t, "application/json", requests[0].contentType)
+		assert.Equal(t, "hello world", requests[0].message.Content)
+		assert.Empty(t, requests[0].message.Embeds)
+
+		// the text is rendered into the description of the first embed
+		assert.Equal(t, "/channels/200/messages", requests[1].path)
+		assert.Empty(t, requests[1].message.Content)
+		if assert.Len(t, requests[1].message.Embeds, 1) {
+			assert.Equal(t, "trade BTCUSDT", requests[1].message.Embeds[0].Description)
+			assert.Equal(t, "BTCUSDT Trade BUY", requests[1].message.Embeds[0].Title)
+		}
+
+		// the embed has its own description, so the text goes into the content
+		assert.Equal(t, "/channels/pnl/messages", requests[2].path)
+		assert.Equal(t, "report", requests[2].message.Content)
+		if assert.Len(t, requests[2].message.Embeds, 1) {
+			assert.Equal(t, "BTCUSDT Profit and Loss report", requests[2].message.Embeds[0].Title)
+		}
+	}
+}
+
+func TestNotifier_NotifyToWithFiles(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	notifier := New("token", "100", WithBaseURL(server.URL))
+	notifier.Notify("pnl chart of %s", "BTCUSDT", testChart{})
+
+	if assert.Len(t, requests, 1) {
+		assert.Contains(t, requests[0].contentType, "multipart/form-data")
+		assert.Equal(t, "pnl chart of BTCUSDT", requests[0].message.Content)
+		assert.Equal(t, []Attachment{{ID: 0, Filename: "pnl.png"}}, requests[0].message.Attachments)
+		assert.Equal(t, map[string][]byte{"files[0]:pnl.png": []byte("png data")}, requests[0].files)
+	}
+}
+
+func TestEmbedFromSlackAttachment(t *testing.T) {
+	kline := types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Open: 10, Close: 20}
+	embed := newEmbedFromObject(kline)
+	if assert.NotNil(t, embed) {
+		assert.Equal(t, "*BTCUSDT* KLine 1m", embed.Description)
+		assert.Equal(t, ColorFromHex(types.Green), embed.Color)
+		assert.NotEmpty(t, embed.Fields)
+	}
+
+	assert.NotNil(t, newEmbedFromObject(types.KLineWindow{kline}))
+	assert.Nil(t, newEmbedFromObject("text"))
+}
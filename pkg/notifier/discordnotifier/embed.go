@@ -0,0 +1,193 @@
+package discordnotifier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// slackAttachmentCreator is the interface implemented by the objects that can be rendered as a slack attachment,
+// we convert these attachments into embeds so that the objects supported by slack are also supported by discord.
+type slackAttachmentCreator interface {
+	SlackAttachment() slack.Attachment
+}
+
+// Message is the message payload of the discord create message API
+type Message struct {
+	Content     string       `json:"content,omitempty"`
+	Embeds      []Embed      `json:"embeds,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment describes the uploaded file of the files[n] form field
+type Attachment struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+}
+
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+}
+
+// ColorFromHex converts the hex color code like "#228B22" into the integer color value used by discord
+func ColorFromHex(hex string) int {
+	c, err := strconv.ParseInt(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int(c)
+}
+
+func TradeEmbed(trade types.Trade) Embed {
+	return Embed{
+		Title: fmt.Sprintf("%s Trade %s", trade.Symbol, trade.Side),
+		Color: ColorFromHex(trade.Side.Color()),
+		Fields: []EmbedField{
+			{Name: "Exchange", Value: trade.Exchange, Inline: true},
+			{Name: "Price", Value: util.FormatFloat(trade.Price, 2), Inline: true},
+			{Name: "Volume", Value: util.FormatFloat(trade.Quantity, 4), Inline: true},
+			{Name: "Quantity", Value: util.FormatFloat(trade.QuoteQuantity, 2)},
+			{Name: "Fee", Value: util.FormatFloat(trade.Fee, 4), Inline: true},
+			{Name: "FeeCurrency", Value: trade.FeeCurrency, Inline: true},
+		},
+		Timestamp: trade.Time.Time().Format(time.RFC3339),
+	}
+}
+
+func OrderEmbed(order types.Order) Embed {
+	return Embed{
+		Title: fmt.Sprintf("%s %s Order %s", order.Symbol, order.Type, order.Side),
+		Color: ColorFromHex(order.Side.Color()),
+		Fields: []EmbedField{
+			{Name: "Exchange", Value: order.Exchange, Inline: true},
+			{Name: "Status", Value: string(order.Status), Inline: true},
+			{Name: "Price", Value: util.FormatFloat(order.Price, 2), Inline: true},
+			{Name: "Executed Quantity", Value: util.FormatFloat(order.ExecutedQuantity, 4), Inline: true},
+			{Name: "Quantity", Value: util.FormatFloat(order.Quantity, 4), Inline: true},
+		},
+		Timestamp: order.UpdateTime.Time().Format(time.RFC3339),
+	}
+}
+
+func SubmitOrderEmbed(order types.SubmitOrder) Embed {
+	var fields = []EmbedField{
+		{Name: "Symbol", Value: order.Symbol, Inline: true},
+		{Name: "Side", Value: string(order.Side), Inline: true},
+		{Name: "Volume", Value: order.QuantityString, Inline: true},
+	}
+
+	if len(order.PriceString) > 0 {
+		fields = append(fields, EmbedField{Name: "Price", Value: order.PriceString, Inline: true})
+	}
+
+	return Embed{
+		Title:  string(order.Type) + " Order " + string(order.Side),
+		Color:  ColorFromHex(order.Side.Color()),
+		Fields: fields,
+	}
+}
+
+func PnlReportEmbed(report pnl.AverageCostPnlReport) Embed {
+	var color = types.Red
+	if report.UnrealizedProfit > 0 {
+		color = types.Green
+	}
+
+	return Embed{
+		Title:       report.Symbol + " Profit and Loss report",
+		Description: "Profit " + types.USD.FormatMoney(report.Profit),
+		Color:       ColorFromHex(color),
+		Fields: []EmbedField{
+			{Name: "Profit", Value: types.USD.FormatMoney(report.Profit)},
+			{Name: "Unrealized Profit", Value: types.USD.FormatMoney(report.UnrealizedProfit)},
+			{Name: "Current Price", Value: report.Market.FormatPrice(report.CurrentPrice), Inline: true},
+			{Name: "Average Cost", Value: report.Market.FormatPrice(report.AverageBidCost), Inline: true},
+			{Name: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeInUSD), Inline: true},
+			{Name: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Inline: true},
+			{Name: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Inline: true},
+		},
+		Footer:    &EmbedFooter{Text: report.StartTime.Format(time.RFC822)},
+		Timestamp: report.StartTime.Format(time.RFC3339),
+	}
+}
+
+// EmbedFromSlackAttachment converts the slack attachment into a discord embed
+func EmbedFromSlackAttachment(attachment slack.Attachment) Embed {
+	embed := Embed{
+		Title:       attachment.Title,
+		Description: attachment.Text,
+		Color:       ColorFromHex(attachment.Color),
+	}
+
+	if len(embed.Title) == 0 {
+		embed.Title = attachment.Fallback
+	}
+
+	for _, field := range attachment.Fields {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   field.Title,
+			Value:  field.Value,
+			Inline: field.Short,
+		})
+	}
+
+	if len(attachment.Footer) > 0 {
+		embed.Footer = &EmbedFooter{Text: attachment.Footer}
+	}
+
+	return embed
+}
+
+// newEmbedFromObject renders the known bbgo objects into discord embeds
+func newEmbedFromObject(obj interface{}) *Embed {
+	var embed Embed
+
+	switch o := obj.(type) {
+	case types.Trade:
+		embed = TradeEmbed(o)
+	case *types.Trade:
+		embed = TradeEmbed(*o)
+	case types.Order:
+		embed = OrderEmbed(o)
+	case *types.Order:
+		embed = OrderEmbed(*o)
+	case types.SubmitOrder:
+		embed = SubmitOrderEmbed(o)
+	case *types.SubmitOrder:
+		embed = SubmitOrderEmbed(*o)
+	case pnl.AverageCostPnlReport:
+		embed = PnlReportEmbed(o)
+	case *pnl.AverageCostPnlReport:
+		embed = PnlReportEmbed(*o)
+	case slackAttachmentCreator:
+		// types.KLine, types.KLineWindow and the other objects that can be rendered as a slack attachment
+		embed = EmbedFromSlackAttachment(o.SlackAttachment())
+	default:
+		return nil
+	}
+
+	return &embed
+}
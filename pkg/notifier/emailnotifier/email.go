@@ -0,0 +1,453 @@
+package emailnotifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("service", "email")
+
+const (
+	defaultSubject       = "[bbgo]"
+	defaultFlushInterval = 10 * time.Minute
+	defaultBatchSize     = 50
+)
+
+// TLSMode is how the connection to the SMTP server is secured
+type TLSMode string
+
+const (
+	// TLSModeStartTLS upgrades the plain connection with the STARTTLS command, this is the default mode
+	TLSModeStartTLS TLSMode = "starttls"
+
+	// TLSModeTLS connects to the SMTP server with implicit TLS, usually on port 465
+	TLSModeTLS TLSMode = "tls"
+
+	// TLSModeNone sends the emails without encryption
+	TLSModeNone TLSMode = "none"
+)
+
+// Attachment is the file attached to the email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentCreator is implemented by the objects that can be attached to the email, e.g., a rendered PnL chart
+type AttachmentCreator interface {
+	EmailAttachment() Attachment
+}
+
+type notification struct {
+	time        time.Time
+	channel     string
+	text        string
+	attachments []Attachment
+}
+
+// Notifier batches the notifications and sends them in one email
+// when the flush interval is reached or the number of the pending notifications reaches the batch size.
+type Notifier struct {
+	host string
+	port int
+	from string
+	to   []string
+
+	username string
+	password string
+
+	tlsMode            TLSMode
+	insecureSkipVerify bool
+
+	subject       string
+	flushInterval time.Duration
+	batchSize     int
+
+	// send sends the message to the recipients, it's replaced in the tests
+	send func(msg []byte) error
+
+	mu      sync.Mutex
+	pending []notification
+
+	flushC chan struct{}
+	doneC     chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithAuth sets the username and the password for the SMTP PLAIN authentication
+func WithAuth(username, password string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.username = username
+		notifier.password = password
+	}
+}
+
+// WithTLSMode sets how the connection is secured, the default mode is STARTTLS
+func WithTLSMode(mode TLSMode) NotifyOption {
+	return func(notifier *Notifier) {
+		if len(mode) > 0 {
+			notifier.tlsMode = mode
+		}
+	}
+}
+
+// WithInsecureSkipVerify skips the verification of the SMTP server certificate
+func WithInsecureSkipVerify(skip bool) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.insecureSkipVerify = skip
+	}
+}
+
+// WithSubject sets the subject prefix of the emails
+func WithSubject(subject string) NotifyOption {
+	return func(notifier *Notifier) {
+		if len(subject) > 0 {
+			notifier.subject = subject
+		}
+	}
+}
+
+// WithFlushInterval sets the max time a notification waits in the batch
+func WithFlushInterval(interval time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if interval > 0 {
+			notifier.flushInterval = interval
+		}
+	}
+}
+
+// WithBatchSize sets the number of the pending notifications that triggers the flush
+func WithBatchSize(size int) NotifyOption {
+	return func(notifier *Notifier) {
+		if size > 0 {
+			notifier.batchSize = size
+		}
+	}
+}
+
+func New(host string, port int, from string, to []string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		host:          host,
+		port:          port,
+		from:          from,
+		to:            to,
+		tlsMode:       TLSModeStartTLS,
+		subject:       defaultSubject,
+		flushInterval: defaultFlushInterval,
+		batchSize:     defaultBatchSize,
+		flushC:        make(chan struct{}, 1),
+		doneC:         make(chan struct{}),
+	}
+
+	notifier.send = notifier.sendMail
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	notifier.wg.Add(1)
+	go notifier.worker()
+
+	return notifier
+}
+
+func (n *Notifier) worker() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.doneC:
+			return
+
+		case <-ticker.C:
+		case <-n.flushC:
+		}
+
+		if err := n.Flush(); err != nil {
+			log.WithError(err).Errorf("email notification error: %s", err.Error())
+		}
+	}
+}
+
+// Close stops the flush worker and sends the pending notifications, it's safe to call Close more than once
+func (n *Notifier) Close() error {
+	n.closeOnce.Do(func() {
+		close(n.doneC)
+	})
+	n.wg.Wait()
+	return n.Flush()
+}
+
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo("", format, args...)
+}
+
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	var attachments []Attachment
+	var texts []string
+	var objectArgsOffset = -1
+
+	for idx, arg := range args {
+		switch a := arg.(type) {
+
+		case AttachmentCreator:
+			attachments = append(attachments, a.EmailAttachment())
+
+		case types.PlainText:
+			texts = append(texts, a.PlainText())
+
+		default:
+			continue
+		}
+
+		if objectArgsOffset == -1 {
+			objectArgsOffset = idx
+		}
+	}
+
+	var textArgs = args
+	if objectArgsOffset > -1 {
+		textArgs = args[:objectArgsOffset]
+	}
+
+	n.mu.Lock()
+	n.pending = append(n.pending, notification{
+		time:        time.Now(),
+		channel:     channel,
+		text:        strings.Join(append([]string{fmt.Sprintf(format, textArgs...)}, texts...), "\n"),
+		attachments: attachments,
+	})
+	full := len(n.pending) >= n.batchSize
+	n.mu.Unlock()
+
+	if full {
+		select {
+		case n.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends the pending notifications in one email
+func (n *Notifier) Flush() error {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	n.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	msg, err := n.buildMessage(pending, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return n.send(msg)
+}
+
+// TestNotify sends the test message in an email immediately, the pending notifications are not flushed
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	msg, err := n.buildMessage([]notification{{time: time.Now(), text: message}}, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return n.send(msg)
+}
+
+func (n *Notifier) buildSubject(pending []notification) string {
+	if len(pending) > 1 {
+		return fmt.Sprintf("%s %d notifications", n.subject, len(pending))
+	}
+
+	// use the first line of the notification text as the subject
+	return n.subject + " " + strings.SplitN(pending[0].text, "\n", 2)[0]
+}
+
+// buildMessage renders the notifications into a multipart MIME message,
+// the texts are rendered in the body and the attachments are added as the MIME attachments.
+func (n *Notifier) buildMessage(pending []notification, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer = multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.buildSubject(pending)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	var body strings.Builder
+	for i, p := range pending {
+		if i > 0 {
+			body.WriteString("\n\n")
+		}
+
+		body.WriteString("[" + p.time.Format("2006-01-02 15:04:05") + "]")
+		if len(p.channel) > 0 {
+			body.WriteString(" " + p.channel)
+		}
+
+		body.WriteString("\n" + p.text)
+	}
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(body.String())); err != nil {
+		return nil, err
+	}
+
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, p := range pending {
+		for _, attachment := range p.attachments {
+			contentType := attachment.ContentType
+			if len(contentType) == 0 {
+				contentType = "application/octet-stream"
+			}
+
+			part, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {contentType},
+				"Content-Transfer-Encoding": {"base64"},
+				"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if err := writeBase64(part, attachment.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes the base64 encoded data with the line length limit of RFC 2045
+func writeBase64(w io.Writer, data []byte) error {
+	const lineLength = 76
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := lineLength
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+
+		if _, err := w.Write([]byte(encoded[:n] + "\r\n")); err != nil {
+			return err
+		}
+
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+// sendMail sends the message through the SMTP server
+func (n *Notifier) sendMail(msg []byte) error {
+	addr := net.JoinHostPort(n.host, strconv.Itoa(n.port))
+	tlsConfig := &tls.Config{
+		ServerName:         n.host,
+		InsecureSkipVerify: n.insecureSkipVerify,
+	}
+
+	var client *smtp.Client
+	if n.tlsMode == TLSModeTLS {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+
+		client, err = smtp.NewClient(conn, n.host)
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		client, err = smtp.Dial(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer client.Close()
+
+	if n.tlsMode == TLSModeStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if len(n.username) > 0 {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
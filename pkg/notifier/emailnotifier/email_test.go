@@ -0,0 +1,161 @@
+package emailnotifier
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testChart struct{}
+
+func (c testChart) EmailAttachment() Attachment {
+	return Attachment{Filename: "pnl.png", ContentType: "image/png", Data: []byte("png data")}
+}
+
+type testSender struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (s *testSender) send(msg []byte) error {
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *testSender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.messages)
+}
+
+func newTestNotifier(options ...NotifyOption) (*Notifier, *testSender) {
+	sender := &testSender{}
+	notifier := New("smtp.example.com", 587, "bbgo@example.com", []string{"me@example.com"}, options...)
+	notifier.send = sender.send
+	return notifier, sender
+}
+
+type testPart struct {
+	contentType string
+	filename    string
+	body        string
+}
+
+func parseMessage(t *testing.T, msg []byte) (*mail.Message, []testPart) {
+	message, err := mail.ReadMessage(bytes.NewReader(msg))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	var parts []testPart
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+
+		// the quoted-printable body is decoded by the reader, the base64 attachments are not,
+		// the line breaks are normalized for the comparison
+		data, err := ioutil.ReadAll(part)
+		assert.NoError(t, err)
+		parts = append(parts, testPart{
+			contentType: part.Header.Get("Content-Type"),
+			filename:    part.FileName(),
+			body:        strings.Replace(string(data), "\r\n", "\n", -1),
+		})
+	}
+
+	return message, parts
+}
+
+func TestNotifier_Flush(t *testing.T) {
+	notifier, sender := newTestNotifier()
+	defer notifier.Close()
+
+	notifier.Notify("daily report of %s", "BTCUSDT", testChart{})
+	notifier.NotifyTo("#trades", "trade", types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy})
+	assert.Equal(t, 0, sender.count(), "the notifications should be batched")
+
+	assert.NoError(t, notifier.Flush())
+	if !assert.Equal(t, 1, sender.count()) {
+		return
+	}
+
+	message, parts := parseMessage(t, sender.messages[0])
+	assert.Equal(t, "bbgo@example.com", message.Header.Get("From"))
+	assert.Equal(t, "me@example.com", message.Header.Get("To"))
+	assert.Equal(t, "[bbgo] 2 notifications", message.Header.Get("Subject"))
+
+	if assert.Len(t, parts, 2) {
+		assert.Equal(t, "text/plain; charset=utf-8", parts[0].contentType)
+		assert.Contains(t, parts[0].body, "daily report of BTCUSDT\n")
+		assert.Contains(t, parts[0].body, "#trades\ntrade\n"+types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy}.PlainText())
+
+		assert.Equal(t, "image/png", parts[1].contentType)
+		assert.Equal(t, "pnl.png", parts[1].filename)
+		assert.Equal(t, "cG5nIGRhdGE=\n", parts[1].body)
+	}
+
+	assert.NoError(t, notifier.Flush())
+	assert.Equal(t, 1, sender.count(), "nothing to send")
+}
+
+func TestNotifier_BatchSize(t *testing.T) {
+	notifier, sender := newTestNotifier(WithBatchSize(2), WithSubject("[alert]"))
+	defer notifier.Close()
+
+	notifier.Notify("first")
+	notifier.Notify("second")
+
+	assert.Eventually(t, func() bool {
+		return sender.count() == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestNotifier_FlushInterval(t *testing.T) {
+	notifier, sender := newTestNotifier(WithFlushInterval(10*time.Millisecond), WithSubject("[alert]"))
+	defer notifier.Close()
+
+	notifier.Notify("price alert")
+
+	assert.Eventually(t, func() bool {
+		return sender.count() == 1
+	}, time.Second, time.Millisecond)
+
+	message, _ := parseMessage(t, sender.messages[0])
+	assert.Equal(t, "[alert] price alert", message.Header.Get("Subject"))
+}
+
+func TestNotifier_Close(t *testing.T) {
+	notifier, sender := newTestNotifier()
+	notifier.Notify("shutting down")
+
+	assert.NoError(t, notifier.Close())
+	assert.Equal(t, 1, sender.count(), "the pending notifications should be sent on close")
+}
+
+func TestNotifier_CloseTwice(t *testing.T) {
+	notifier, sender := newTestNotifier()
+	notifier.Notify("shutting down")
+
+	assert.NoError(t, notifier.Close())
+	assert.NoError(t, notifier.Close(), "the second close should not panic")
+	assert.Equal(t, 1, sender.count())
+}
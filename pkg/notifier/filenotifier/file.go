@@ -0,0 +1,283 @@
+package filenotifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("service", "file")
+
+// rotatedTimeFormat is the time suffix of the rotated log files
+const rotatedTimeFormat = "20060102T150405.000"
+
+// Object is the typed object attached to the notification, e.g., types.Trade or types.Order
+type Object struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Record is a JSON line of the log file
+type Record struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel,omitempty"`
+	Text    string    `json:"text"`
+	Objects []Object  `json:"objects,omitempty"`
+}
+
+// Notifier appends the notifications as JSON lines to the log file, the log file is rotated when it reaches the max size.
+// The rotated files are renamed with the rotation time suffix and never removed, so the records are kept as an audit log.
+type Notifier struct {
+	path    string
+	maxSize int64
+
+	// channels is the set of the channels to record, all the notifications are recorded if it's empty
+	channels map[string]struct{}
+
+	// mu protects the file and the size, the notifications may be sent from different stream callbacks
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithMaxSize sets the max size of the log file in bytes, the file is not rotated if the size is zero
+func WithMaxSize(size int64) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.maxSize = size
+	}
+}
+
+// WithChannels records only the notifications routed to the given channels,
+// the notifications sent to the default channel are not recorded in this case.
+func WithChannels(channels ...string) NotifyOption {
+	return func(notifier *Notifier) {
+		for _, channel := range channels {
+			notifier.channels[channel] = struct{}{}
+		}
+	}
+}
+
+// New opens the log file at the path for appending, the parent directory is created if it does not exist
+func New(path string, options ...NotifyOption) (*Notifier, error) {
+	notifier := &Notifier{
+		path:     path,
+		channels: make(map[string]struct{}),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := notifier.open(); err != nil {
+		return nil, err
+	}
+
+	return notifier, nil
+}
+
+func (n *Notifier) open() error {
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	n.file = file
+	n.size = info.Size()
+	return nil
+}
+
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo("", format, args...)
+}
+
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	if len(n.channels) > 0 {
+		if _, ok := n.channels[channel]; !ok {
+			return
+		}
+	}
+
+	var objects []Object
+	var objectArgsOffset = -1
+	for idx, arg := range args {
+		if !isObject(arg) {
+			continue
+		}
+
+		if objectArgsOffset == -1 {
+			objectArgsOffset = idx
+		}
+
+		objects = append(objects, Object{Type: typeName(arg), Data: arg})
+	}
+
+	var textArgs = args
+	if objectArgsOffset > -1 {
+		textArgs = args[:objectArgsOffset]
+	}
+
+	record := Record{
+		Time:    time.Now(),
+		Channel: channel,
+		Text:    fmt.Sprintf(format, textArgs...),
+		Objects: objects,
+	}
+
+	if err := n.write(record); err != nil {
+		log.WithError(err).Errorf("notification log error: %s", err.Error())
+	}
+}
+
+// TestNotify writes the test message to the log file and returns the write error,
+// the message is written even if the channels are filtered
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	return n.write(Record{Time: time.Now(), Text: message})
+}
+
+func (n *Notifier) write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.file == nil {
+		return fmt.Errorf("notification log file %s is closed", n.path)
+	}
+
+	if n.maxSize > 0 && n.size > 0 && n.size+int64(len(line)) > n.maxSize {
+		if err := n.rotate(record.Time); err != nil {
+			return err
+		}
+	}
+
+	written, err := n.file.Write(line)
+	n.size += int64(written)
+	return err
+}
+
+// rotate renames the current log file with the time suffix and opens a new one, the caller must hold the lock
+func (n *Notifier) rotate(now time.Time) error {
+	if err := n.file.Close(); err != nil {
+		return err
+	}
+
+	n.file = nil
+
+	rotatedPath := n.path + "." + now.Format(rotatedTimeFormat)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotatedPath); os.IsNotExist(err) {
+			break
+		}
+
+		rotatedPath = n.path + "." + now.Format(rotatedTimeFormat) + "-" + strconv.Itoa(i)
+	}
+
+	if err := os.Rename(n.path, rotatedPath); err != nil {
+		return err
+	}
+
+	return n.open()
+}
+
+// Close closes the log file, the notifications sent after closing are dropped
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.file == nil {
+		return nil
+	}
+
+	err := n.file.Close()
+	n.file = nil
+	return err
+}
+
+// ParseSize parses the size string like "512KB", "10MB" and "1GB" into bytes, the plain number is in bytes
+func ParseSize(s string) (int64, error) {
+	var units = []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q: %w", s, err)
+			}
+
+			return n * unit.size, nil
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+
+	return n, nil
+}
+
+// isObject returns true if the argument is a struct or a pointer to struct, which will be recorded as an object
+func isObject(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+
+	// time.Time is usually used as a format argument
+	switch arg.(type) {
+	case time.Time, *time.Time:
+		return false
+	}
+
+	t := reflect.TypeOf(arg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+func typeName(arg interface{}) string {
+	t := reflect.TypeOf(arg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
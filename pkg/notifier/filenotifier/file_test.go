@@ -0,0 +1,117 @@
+package filenotifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testObject struct {
+	Symbol string `json:"symbol"`
+}
+
+func readRecords(t *testing.T, dir string) (records []Record, files int) {
+	matches, err := filepath.Glob(filepath.Join(dir, "notifications.log*"))
+	assert.NoError(t, err)
+
+	for _, match := range matches {
+		f, err := os.Open(match)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record Record
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "each line should be a valid JSON record")
+			records = append(records, record)
+		}
+
+		f.Close()
+	}
+
+	return records, len(matches)
+}
+
+func TestNotifier_ConcurrentRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filenotifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	notifier, err := New(filepath.Join(dir, "notifications.log"), WithMaxSize(1024))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				notifier.NotifyTo("#trades", "trade %s", "BTCUSDT", &testObject{Symbol: "BTCUSDT"})
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, notifier.Close())
+
+	records, files := readRecords(t, dir)
+	assert.Len(t, records, 200)
+	assert.Greater(t, files, 1, "the log file should be rotated")
+
+	if assert.NotEmpty(t, records) {
+		assert.Equal(t, "#trades", records[0].Channel)
+		assert.Equal(t, "trade BTCUSDT", records[0].Text)
+		if assert.Len(t, records[0].Objects, 1) {
+			assert.Equal(t, "testObject", records[0].Objects[0].Type)
+		}
+	}
+}
+
+func TestNotifier_Channels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filenotifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	notifier, err := New(filepath.Join(dir, "notifications.log"), WithChannels("#btc"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	notifier.Notify("session %s connected", "binance")
+	notifier.NotifyTo("#eth", "trade %s", "ETHUSDT")
+	notifier.NotifyTo("#btc", "trade %s", "BTCUSDT")
+	assert.NoError(t, notifier.Close())
+
+	records, _ := readRecords(t, dir)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "trade BTCUSDT", records[0].Text)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"100":   100,
+		"512KB": 512 << 10,
+		"10mb":  10 << 20,
+		"1 GB":  1 << 30,
+	} {
+		size, err := ParseSize(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, size, s)
+	}
+
+	_, err := ParseSize("10XB")
+	assert.Error(t, err)
+}
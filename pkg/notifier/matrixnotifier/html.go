@@ -0,0 +1,80 @@
+package matrixnotifier
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// slackAttachmentCreator is the interface implemented by the objects that can be rendered as a slack attachment,
+// we render these attachments into HTML so that the objects supported by slack are also supported by matrix.
+type slackAttachmentCreator interface {
+	SlackAttachment() slack.Attachment
+}
+
+// TextHTML escapes the text and keeps its line breaks in the formatted body
+func TextHTML(text string) string {
+	return strings.Replace(html.EscapeString(text), "\n", "<br/>", -1)
+}
+
+var mrkdwnBoldRegexp = regexp.MustCompile(`\*([^*\n]+)\*`)
+
+// mrkdwnHTML renders the bold text of the slack mrkdwn, e.g., "*BTCUSDT* Trade", the other markups are kept as they are
+func mrkdwnHTML(text string) string {
+	return mrkdwnBoldRegexp.ReplaceAllString(TextHTML(text), "<b>$1</b>")
+}
+
+// HTMLFromSlackAttachment renders the slack attachment into HTML, the title is in bold and the fields are in a list
+func HTMLFromSlackAttachment(attachment slack.Attachment) string {
+	var sb strings.Builder
+
+	title := attachment.Title
+	if len(title) == 0 {
+		title = attachment.Fallback
+	}
+
+	if len(title) > 0 {
+		if len(attachment.Color) > 0 {
+			sb.WriteString(`<font color="` + html.EscapeString(attachment.Color) + `"><b>` + TextHTML(title) + `</b></font>`)
+		} else {
+			sb.WriteString("<b>" + TextHTML(title) + "</b>")
+		}
+	}
+
+	if len(attachment.Text) > 0 {
+		sb.WriteString("<p>" + mrkdwnHTML(attachment.Text) + "</p>")
+	}
+
+	if len(attachment.Fields) > 0 {
+		sb.WriteString("<ul>")
+		for _, field := range attachment.Fields {
+			sb.WriteString("<li><b>" + TextHTML(field.Title) + "</b>: " + TextHTML(field.Value) + "</li>")
+		}
+		sb.WriteString("</ul>")
+	}
+
+	if len(attachment.Footer) > 0 {
+		sb.WriteString("<small>" + TextHTML(attachment.Footer) + "</small>")
+	}
+
+	return sb.String()
+}
+
+// newHTMLFromObject renders the known bbgo objects into HTML, the empty string is returned for the other arguments
+func newHTMLFromObject(obj interface{}) string {
+	switch o := obj.(type) {
+	case slackAttachmentCreator:
+		// types.Trade, types.KLine, the pnl reports and the other objects that can be rendered as a slack attachment
+		return HTMLFromSlackAttachment(o.SlackAttachment())
+
+	case types.PlainText:
+		return TextHTML(o.PlainText())
+
+	}
+
+	return ""
+}
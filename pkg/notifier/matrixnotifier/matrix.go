@@ -0,0 +1,351 @@
+package matrixnotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+var log = logrus.WithField("service", "matrix")
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Minute
+)
+
+// the message formats of the matrix client-server API
+const (
+	MessageTypeText  = "m.text"
+	MessageTypeImage = "m.image"
+	MessageTypeFile  = "m.file"
+
+	FormatHTML = "org.matrix.custom.html"
+)
+
+// MatrixHTMLCreator is implemented by the objects that can be rendered into the formatted body of a matrix message
+type MatrixHTMLCreator interface {
+	MatrixHTML() string
+}
+
+// MatrixFileCreator is implemented by the objects that can be uploaded as matrix media, e.g., a PnL chart
+type MatrixFileCreator interface {
+	MatrixFile() File
+}
+
+// File is the file uploaded to the media repository of the homeserver and sent as an image or a file message
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// FileInfo is the info of the uploaded media
+type FileInfo struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int    `json:"size"`
+}
+
+// Message is the content of the m.room.message event
+type Message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+
+	// URL is the mxc:// URI of the uploaded media
+	URL  string    `json:"url,omitempty"`
+	Info *FileInfo `json:"info,omitempty"`
+}
+
+type Notifier struct {
+	client        *http.Client
+	homeserverURL string
+	accessToken   string
+
+	// room is the default room ID
+	room string
+
+	// rooms maps the bbgo channel names to the matrix room IDs
+	rooms map[string]string
+
+	// txnID is the sequence of the transaction IDs, the homeserver deduplicates the events by the transaction ID
+	txnID int64
+
+	// breaker drops the notifications while the API keeps failing
+	breaker *util.CircuitBreaker
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithRooms sets the channel name to matrix room ID mapping
+func WithRooms(rooms map[string]string) NotifyOption {
+	return func(notifier *Notifier) {
+		for name, id := range rooms {
+			notifier.rooms[name] = id
+		}
+	}
+}
+
+// WithTimeout sets the timeout of the requests to the homeserver
+func WithTimeout(timeout time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if timeout > 0 {
+			notifier.client.Timeout = timeout
+		}
+	}
+}
+
+// WithCircuitBreaker sets the number of the consecutive failed requests to open the circuit breaker, and the cool-down
+// period the notifications are dropped before the request is tried again. The breaker is disabled if the threshold
+// is negative.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if cooldown <= 0 {
+			cooldown = defaultCooldown
+		}
+
+		notifier.breaker = util.NewCircuitBreaker("matrix", failureThreshold, cooldown)
+	}
+}
+
+func New(homeserverURL, accessToken, room string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		client:        &http.Client{Timeout: defaultTimeout},
+		homeserverURL: strings.TrimSuffix(homeserverURL, "/"),
+		accessToken:   accessToken,
+		room:          room,
+		rooms:         make(map[string]string),
+		breaker:       util.NewCircuitBreaker("matrix", defaultFailureThreshold, defaultCooldown),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	return notifier
+}
+
+// CircuitBreaker returns the circuit breaker of the API requests
+func (n *Notifier) CircuitBreaker() *util.CircuitBreaker {
+	return n.breaker
+}
+
+// resolveRoom translates the bbgo channel name into the matrix room ID,
+// if the channel is not defined in the mapping, it's treated as a room ID.
+func (n *Notifier) resolveRoom(channel string) string {
+	if len(channel) == 0 {
+		channel = n.room
+	}
+
+	if id, ok := n.rooms[channel]; ok {
+		return id
+	}
+
+	return channel
+}
+
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo(n.room, format, args...)
+}
+
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	roomID := n.resolveRoom(channel)
+
+	var htmls []string
+	var files []File
+	var objectArgsOffset = -1
+
+	for idx, arg := range args {
+		var html string
+		var file *File
+
+		switch a := arg.(type) {
+
+		// concrete type assert first
+		case File:
+			file = &a
+
+		case MatrixFileCreator:
+			f := a.MatrixFile()
+			file = &f
+
+		case MatrixHTMLCreator:
+			html = a.MatrixHTML()
+
+		default:
+			html = newHTMLFromObject(arg)
+
+		}
+
+		if len(html) == 0 && file == nil {
+			continue
+		}
+
+		if objectArgsOffset == -1 {
+			objectArgsOffset = idx
+		}
+
+		if len(html) > 0 {
+			htmls = append(htmls, html)
+		}
+
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+
+	var textArgs = args
+	if objectArgsOffset > -1 {
+		textArgs = args[:objectArgsOffset]
+	}
+
+	text := fmt.Sprintf(format, textArgs...)
+	message := &Message{
+		MsgType:       MessageTypeText,
+		Body:          text,
+		Format:        FormatHTML,
+		FormattedBody: strings.Join(append([]string{TextHTML(text)}, htmls...), "<br/>"),
+	}
+
+	ctx := context.Background()
+	if err := n.sendMessage(ctx, roomID, message); err == util.ErrCircuitOpen {
+		log.Debugf("matrix circuit breaker is open, dropping the notification")
+		return
+	} else if err != nil {
+		log.WithError(err).
+			WithField("room", roomID).
+			Errorf("matrix error: %s", err.Error())
+	}
+
+	for _, file := range files {
+		if err := n.sendFile(ctx, roomID, file); err != nil {
+			log.WithError(err).
+				WithField("room", roomID).
+				Errorf("matrix upload error: %s", err.Error())
+		}
+	}
+}
+
+// TestNotify sends the test message to the default room and returns the delivery error
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	return n.sendMessage(ctx, n.resolveRoom(""), &Message{MsgType: MessageTypeText, Body: message})
+}
+
+// sendFile uploads the file to the media repository and sends the media message of the uploaded file
+func (n *Notifier) sendFile(ctx context.Context, roomID string, file File) error {
+	contentType := file.ContentType
+	if len(contentType) == 0 {
+		contentType = http.DetectContentType(file.Data)
+	}
+
+	contentURI, err := n.upload(ctx, file.Name, contentType, file.Data)
+	if err != nil {
+		return err
+	}
+
+	msgType := MessageTypeFile
+	if strings.HasPrefix(contentType, "image/") {
+		msgType = MessageTypeImage
+	}
+
+	return n.sendMessage(ctx, roomID, &Message{
+		MsgType: msgType,
+		Body:    file.Name,
+		URL:     contentURI,
+		Info:    &FileInfo{MimeType: contentType, Size: len(file.Data)},
+	})
+}
+
+// upload uploads the data to the media repository and returns the mxc:// content URI
+func (n *Notifier) upload(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	endpoint := n.homeserverURL + "/_matrix/media/r0/upload?filename=" + url.QueryEscape(name)
+
+	var result struct {
+		ContentURI string `json:"content_uri"`
+	}
+
+	if err := n.do(ctx, "POST", endpoint, contentType, data, &result); err != nil {
+		return "", err
+	}
+
+	if len(result.ContentURI) == 0 {
+		return "", errors.New("matrix upload response has no content uri")
+	}
+
+	return result.ContentURI, nil
+}
+
+func (n *Notifier) sendMessage(ctx context.Context, roomID string, message *Message) error {
+	if len(roomID) == 0 {
+		return errors.New("matrix room is not defined")
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	txnID := fmt.Sprintf("bbgo.%d.%d", time.Now().UnixNano(), atomic.AddInt64(&n.txnID, 1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message/%s",
+		n.homeserverURL, url.PathEscape(roomID), url.PathEscape(txnID))
+
+	return n.do(ctx, "PUT", endpoint, "application/json", payload, nil)
+}
+
+func (n *Notifier) do(ctx context.Context, method, endpoint, contentType string, body []byte, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+n.accessToken)
+	req.Header.Set("Content-Type", contentType)
+
+	if err := n.breaker.Allow(); err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	// the rejected requests, e.g., 4xx, are not counted as the failures of the breaker since the server is reachable
+	if response.StatusCode >= 500 {
+		err := fmt.Errorf("matrix server error: status %d, response: %s", response.StatusCode, response.String())
+		n.breaker.Report(err)
+		return err
+	}
+
+	n.breaker.Report(nil)
+
+	if response.IsError() {
+		return fmt.Errorf("matrix api error: status %d, response: %s", response.StatusCode, response.String())
+	}
+
+	if result != nil {
+		return response.DecodeJSON(result)
+	}
+
+	return nil
+}
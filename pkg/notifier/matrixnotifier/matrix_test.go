@@ -0,0 +1,114 @@
+package matrixnotifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testChart struct{}
+
+func (c testChart) MatrixFile() File {
+	return File{Name: "pnl.png", ContentType: "image/png", Data: []byte("png data")}
+}
+
+type request struct {
+	method      string
+	path        string
+	query       string
+	contentType string
+	message     Message
+	data        []byte
+}
+
+func newTestServer(t *testing.T, requests *[]request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		req := request{
+			method:      r.Method,
+			path:        r.URL.EscapedPath(),
+			query:       r.URL.RawQuery,
+			contentType: r.Header.Get("Content-Type"),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/_matrix/media/") {
+			req.data = body
+			*requests = append(*requests, req)
+			_, _ = w.Write([]byte(`{"content_uri":"mxc://example.org/pnl"}`))
+			return
+		}
+
+		assert.NoError(t, json.Unmarshal(body, &req.message))
+		*requests = append(*requests, req)
+		_, _ = w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+}
+
+func TestNotifier_ResolveRoom(t *testing.T) {
+	notifier := New("https://matrix.example.org", "token", "!default:example.org", WithRooms(map[string]string{
+		"trades": "!trades:example.org",
+	}))
+
+	assert.Equal(t, "!default:example.org", notifier.resolveRoom(""))
+	assert.Equal(t, "!trades:example.org", notifier.resolveRoom("trades"))
+	assert.Equal(t, "!other:example.org", notifier.resolveRoom("!other:example.org"), "unknown channel names are treated as room IDs")
+}
+
+func TestNotifier_NotifyTo(t *testing.T) {
+	var requests []request
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	notifier := New(server.URL+"/", "token", "!default:example.org", WithRooms(map[string]string{
+		"trades": "!trades:example.org",
+	}))
+
+	notifier.Notify("hello <%s>\nbye", "world")
+	notifier.NotifyTo("trades", "trade %s", "BTCUSDT", types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy})
+	notifier.NotifyTo("pnl", "report", testChart{})
+
+	if !assert.Len(t, requests, 5) {
+		return
+	}
+
+	assert.Equal(t, "PUT", requests[0].method)
+	assert.True(t, strings.HasPrefix(requests[0].path, "/_matrix/client/r0/rooms/%21default:example.org/send/m.room.message/"), requests[0].path)
+	assert.Equal(t, MessageTypeText, requests[0].message.MsgType)
+	assert.Equal(t, "hello <world>\nbye", requests[0].message.Body)
+	assert.Equal(t, FormatHTML, requests[0].message.Format)
+	assert.Equal(t, "hello &lt;world&gt;<br/>bye", requests[0].message.FormattedBody)
+
+	// the trade is rendered into the formatted body
+	assert.Contains(t, requests[1].path, "%21trades:example.org")
+	assert.Equal(t, "trade BTCUSDT", requests[1].message.Body)
+	assert.Contains(t, requests[1].message.FormattedBody, "<b>BTCUSDT</b> Trade BUY")
+	assert.Contains(t, requests[1].message.FormattedBody, "<li><b>Price</b>: 0.00</li>")
+	assert.NotEqual(t, requests[0].path, requests[1].path, "the transaction IDs are unique")
+
+	// the chart is uploaded as the media, then the image message refers to the uploaded media
+	assert.Equal(t, "report", requests[2].message.Body)
+	assert.Equal(t, "POST", requests[3].method)
+	assert.Equal(t, "/_matrix/media/r0/upload", requests[3].path)
+	assert.Equal(t, "filename=pnl.png", requests[3].query)
+	assert.Equal(t, "image/png", requests[3].contentType)
+	assert.Equal(t, []byte("png data"), requests[3].data)
+
+	assert.Contains(t, requests[4].path, "/rooms/pnl/")
+	assert.Equal(t, MessageTypeImage, requests[4].message.MsgType)
+	assert.Equal(t, "mxc://example.org/pnl", requests[4].message.URL)
+	if assert.NotNil(t, requests[4].message.Info) {
+		assert.Equal(t, len("png data"), requests[4].message.Info.Size)
+	}
+}
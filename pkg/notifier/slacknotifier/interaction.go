@@ -0,0 +1,352 @@
+package slacknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pquerna/otp"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const (
+	defaultAPIURL = "https://slack.com/api/"
+
+	reconnectDelay = 5 * time.Second
+)
+
+// Session is the persisted authorization state of the slack interaction
+type Session struct {
+	// AuthorizedUsers are the slack user IDs authorized by the auth command
+	AuthorizedUsers []string `json:"authorizedUsers"`
+
+	OneTimePasswordKey *otp.Key `json:"otpKey"`
+}
+
+func NewSession(key *otp.Key) Session {
+	return Session{
+		OneTimePasswordKey: key,
+	}
+}
+
+// HasAuthorizedUsers returns true if at least one user is authorized
+func (s *Session) HasAuthorizedUsers() bool {
+	return len(s.AuthorizedUsers) > 0
+}
+
+func (s *Session) IsAuthorized(userID string) bool {
+	for _, id := range s.AuthorizedUsers {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddAuthorizedUser adds the user to the authorized list, returns false if the user is already authorized
+func (s *Session) AddAuthorizedUser(userID string) bool {
+	if s.IsAuthorized(userID) {
+		return false
+	}
+
+	s.AuthorizedUsers = append(s.AuthorizedUsers, userID)
+	return true
+}
+
+// socketModeEnvelope is the message sent by the Socket Mode connection
+type socketModeEnvelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Reason     string          `json:"reason,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// socketModeAck acknowledges the envelope, the payload of the slash command ack is sent as the response message
+type socketModeAck struct {
+	EnvelopeID string      `json:"envelope_id"`
+	Payload    interface{} `json:"payload,omitempty"`
+}
+
+type ackPayload struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// Interaction handles the slash commands, e.g., "/bbgo balance main", with the Socket Mode connection,
+// the users are authorized by "/bbgo auth" with the auth token or the one-time password like the telegram interaction.
+type Interaction struct {
+	store service.Store
+
+	appToken string
+	apiURL   string
+	client   *http.Client
+
+	AuthToken string
+
+	// mu protects the session, the commands are handled in the connection goroutine
+	mu      sync.Mutex
+	session *Session
+
+	dispatcher interact.Dispatcher
+
+	// cancel stops the connection loop started by Start, doneC is closed when the loop returns
+	cancel context.CancelFunc
+	doneC  chan struct{}
+}
+
+type InteractionOption func(it *Interaction)
+
+// WithAPIURL overrides the slack API URL, which is used to open the Socket Mode connection
+func WithAPIURL(apiURL string) InteractionOption {
+	return func(it *Interaction) {
+		it.apiURL = strings.TrimRight(apiURL, "/") + "/"
+	}
+}
+
+// NewInteraction creates the interaction with the app-level token (xapp-...), which has the connections:write scope
+func NewInteraction(appToken string, store service.Store, options ...InteractionOption) *Interaction {
+	it := &Interaction{
+		store:    store,
+		appToken: appToken,
+		apiURL:   defaultAPIURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, o := range options {
+		o(it)
+	}
+
+	return it
+}
+
+func (it *Interaction) SetAuthToken(token string) {
+	it.AuthToken = token
+}
+
+// SetEnvironment sets the environment queried by the balance and the status commands
+func (it *Interaction) SetEnvironment(environ interact.Environment) {
+	it.dispatcher.SetEnvironment(environ)
+}
+
+// Start handles the slash commands until the context is canceled, the connection is re-opened when it's closed
+func (it *Interaction) Start(ctx context.Context, session Session) {
+	ctx, cancel := context.WithCancel(ctx)
+	doneC := make(chan struct{})
+	defer close(doneC)
+
+	it.mu.Lock()
+	it.session = &session
+	it.cancel = cancel
+	it.doneC = doneC
+	it.mu.Unlock()
+
+	for {
+		if err := it.serve(ctx); err != nil {
+			log.WithError(err).Errorf("slack socket mode connection error, reconnecting in %s...", reconnectDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// Stop closes the connection and waits until the connection loop returns or the context is done
+func (it *Interaction) Stop(ctx context.Context) error {
+	it.mu.Lock()
+	cancel, doneC := it.cancel, it.doneC
+	it.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-doneC:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// openConnection calls apps.connections.open to get the websocket URL of the Socket Mode connection
+func (it *Interaction) openConnection(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", it.apiURL+"apps.connections.open", nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+it.appToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := it.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		URL   string `json:"url"`
+		Error string `json:"error"`
+	}
+
+	if err := response.DecodeJSON(&result); err != nil {
+		return "", err
+	}
+
+	if !result.OK {
+		return "", fmt.Errorf("slack apps.connections.open error: %s", result.Error)
+	}
+
+	return result.URL, nil
+}
+
+// serve reads the envelopes from the connection until the connection is closed or refreshed
+func (it *Interaction) serve(ctx context.Context) error {
+	url, err := it.openConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var envelope socketModeEnvelope
+		if err := conn.ReadJSON(&envelope); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		switch envelope.Type {
+		case "hello":
+			log.Infof("slack socket mode connected")
+
+		case "disconnect":
+			log.Infof("slack socket mode disconnect requested: %s", envelope.Reason)
+			return nil
+
+		case "slash_commands":
+			var command slack.SlashCommand
+			if err := json.Unmarshal(envelope.Payload, &command); err != nil {
+				log.WithError(err).Error("can not parse the slack slash command")
+				continue
+			}
+
+			ack := socketModeAck{
+				EnvelopeID: envelope.EnvelopeID,
+				Payload: ackPayload{
+					ResponseType: "ephemeral",
+					Text:         it.HandleCommand(command),
+				},
+			}
+
+			if err := conn.WriteJSON(ack); err != nil {
+				return err
+			}
+
+		default:
+			// acknowledge the other events, so that slack won't retry them
+			if len(envelope.EnvelopeID) > 0 {
+				if err := conn.WriteJSON(socketModeAck{EnvelopeID: envelope.EnvelopeID}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// HandleCommand handles the slash command text, e.g., "auth 123456" or "balance main", and returns the reply message
+func (it *Interaction) HandleCommand(command slack.SlashCommand) string {
+	var name, args string
+	fields := strings.SplitN(strings.TrimSpace(command.Text), " ", 2)
+	name = fields[0]
+	if len(fields) == 2 {
+		args = fields[1]
+	}
+
+	switch name {
+	case "", "help":
+		return fmt.Sprintf("%[1]s help\t- show this help message\n"+
+			"%[1]s auth\t- authorize the current slack user with the authentication token or one-time password. ex. %[1]s auth my-token\n",
+			command.Command) + interact.Usage(command.Command+" ")
+
+	case "auth":
+		return it.handleAuth(command, strings.TrimSpace(args))
+	}
+
+	it.mu.Lock()
+	authorized := it.session != nil && it.session.IsAuthorized(command.UserID)
+	it.mu.Unlock()
+
+	if !authorized {
+		log.Warningf("unauthorized slack user tried to access bbgo: %s (%s)", command.UserName, command.UserID)
+		return "You are not authorized, please authorize with the auth command first"
+	}
+
+	reply, ok := it.dispatcher.Dispatch(name, args)
+	if !ok {
+		return fmt.Sprintf("Unknown command %s, see %s help", name, command.Command)
+	}
+
+	return reply
+}
+
+func (it *Interaction) handleAuth(command slack.SlashCommand, payload string) string {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if it.session == nil {
+		return "The bot is not ready yet, please try again later"
+	}
+
+	if !interact.ValidateAuth(payload, it.AuthToken, it.session.OneTimePasswordKey) {
+		return "Authorization failed. please check your auth token"
+	}
+
+	if it.session.AddAuthorizedUser(command.UserID) {
+		if err := it.store.Save(it.session); err != nil {
+			log.WithError(err).Error("can not persist the slack session")
+		}
+	}
+
+	return fmt.Sprintf("Hi %s, you are authorized, see %s help for the commands", command.UserName, command.Command)
+}
@@ -0,0 +1,135 @@
+package slacknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testEnvironment struct{}
+
+func (e *testEnvironment) SessionStatuses() []interact.SessionStatus {
+	return []interact.SessionStatus{{Name: "main", Exchange: "binance", Connected: true}}
+}
+
+func (e *testEnvironment) QuerySessionBalances(ctx context.Context, sessionName string) (types.BalanceMap, error) {
+	return types.BalanceMap{}, nil
+}
+
+func (e *testEnvironment) NetExposure(ctx context.Context, asset string) (*types.NetExposure, error) {
+	return &types.NetExposure{Asset: asset}, nil
+}
+
+func (e *testEnvironment) NotifyPnLReports(ctx context.Context, sessionName, symbol string, since time.Time) (map[string]*pnl.AverageCostPnlReport, time.Time, error) {
+	return nil, since, nil
+}
+
+func TestInteraction_HandleCommand(t *testing.T) {
+	store := service.NewMemoryService().NewStore("bbgo", "slack", "interaction")
+	it := NewInteraction("xapp-token", store)
+	it.SetAuthToken("itsme")
+	it.SetEnvironment(&testEnvironment{})
+	it.session = &Session{}
+
+	command := slack.SlashCommand{Command: "/bbgo", UserID: "U123", UserName: "alice"}
+
+	command.Text = "help"
+	assert.Contains(t, it.HandleCommand(command), "/bbgo balance [session]")
+
+	command.Text = "status"
+	assert.Equal(t, "You are not authorized, please authorize with the auth command first", it.HandleCommand(command))
+
+	command.Text = "auth wrong"
+	assert.Equal(t, "Authorization failed. please check your auth token", it.HandleCommand(command))
+
+	command.Text = "auth itsme"
+	assert.Equal(t, "Hi alice, you are authorized, see /bbgo help for the commands", it.HandleCommand(command))
+
+	var session Session
+	assert.NoError(t, store.Load(&session))
+	assert.Equal(t, []string{"U123"}, session.AuthorizedUsers)
+
+	command.Text = "status"
+	assert.Equal(t, "Sessions:\nmain (binance): connected", it.HandleCommand(command))
+
+	command.Text = "balance main"
+	assert.Equal(t, "main: no balance", it.HandleCommand(command))
+
+	command.Text = "trade"
+	assert.Equal(t, "Unknown command trade, see /bbgo help", it.HandleCommand(command))
+}
+
+func TestInteraction_SocketMode(t *testing.T) {
+	acks := make(chan socketModeAck, 1)
+	upgrader := websocket.Upgrader{}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/apps.connections.open":
+			assert.Equal(t, "Bearer xapp-token", r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":  true,
+				"url": "ws" + strings.TrimPrefix(server.URL, "http") + "/link",
+			})
+
+		case "/link":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			assert.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "hello"}))
+			assert.NoError(t, conn.WriteJSON(map[string]interface{}{
+				"envelope_id": "e1",
+				"type":        "slash_commands",
+				"payload":     slack.SlashCommand{Command: "/bbgo", Text: "status", UserID: "U123"},
+			}))
+
+			var ack struct {
+				EnvelopeID string     `json:"envelope_id"`
+				Payload    ackPayload `json:"payload"`
+			}
+			if assert.NoError(t, conn.ReadJSON(&ack)) {
+				acks <- socketModeAck{EnvelopeID: ack.EnvelopeID, Payload: ack.Payload}
+			}
+		}
+	}))
+	defer server.Close()
+
+	it := NewInteraction("xapp-token", service.NewMemoryService().NewStore("bbgo", "slack"), WithAPIURL(server.URL+"/api"))
+	it.SetEnvironment(&testEnvironment{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go it.Start(ctx, Session{AuthorizedUsers: []string{"U123"}})
+
+	select {
+	case ack := <-acks:
+		assert.Equal(t, "e1", ack.EnvelopeID)
+		assert.Equal(t, ackPayload{ResponseType: "ephemeral", Text: "Sessions:\nmain (binance): connected"}, ack.Payload)
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("slash command is not acknowledged")
+	}
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelStop()
+	assert.NoError(t, it.Stop(stopCtx))
+}
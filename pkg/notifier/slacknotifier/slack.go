@@ -6,6 +6,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 type SlackAttachmentCreator interface {
@@ -40,6 +42,16 @@ func (n *Notifier) Notify(format string, args ...interface{}) {
 }
 
 func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	if err := n.TryNotifyTo(types.OutputFormatText, channel, format, args...); err != nil {
+		log.WithError(err).
+			WithField("channel", channel).
+			Errorf("slack error: %s", err.Error())
+	}
+}
+
+// TryNotifyTo sends the notification like NotifyTo and returns the delivery error, the output format is ignored
+// because slack always renders the objects as the attachments
+func (n *Notifier) TryNotifyTo(_ types.OutputFormat, channel, format string, args ...interface{}) error {
 	if len(channel) == 0 {
 		channel = n.channel
 	}
@@ -76,13 +88,13 @@ func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
 	_, _, err := n.client.PostMessageContext(context.Background(), channel,
 		slack.MsgOptionText(fmt.Sprintf(format, nonSlackArgs...), true),
 		slack.MsgOptionAttachments(slackAttachments...))
-	if err != nil {
-		log.WithError(err).
-			WithField("channel", channel).
-			Errorf("slack error: %s", err.Error())
-	}
+	return err
+}
 
-	return
+// TestNotify sends the test message to the default channel and returns the delivery error
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	_, _, err := n.client.PostMessageContext(ctx, n.channel, slack.MsgOptionText(message, true))
+	return err
 }
 
 /*
@@ -0,0 +1,244 @@
+package smsnotifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+var log = logrus.WithField("service", "sms")
+
+const (
+	defaultBaseURL = "https://api.twilio.com"
+	defaultTimeout = 10 * time.Second
+
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Minute
+
+	// maxBodyLength is the max length of the message body, the long messages are truncated
+	maxBodyLength = 1600
+)
+
+// Notifier sends the notifications by SMS with the Twilio API,
+// only the notifications with the severity greater than or equal to the min severity are sent.
+type Notifier struct {
+	client *http.Client
+
+	baseURL    string
+	accountSID string
+	authToken  string
+
+	from string
+	to   []string
+
+	minSeverity types.Severity
+	timeout     time.Duration
+
+	wg sync.WaitGroup
+
+	// breaker drops the notifications while the API keeps failing
+	breaker *util.CircuitBreaker
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithMinSeverity sets the min severity of the notifications to send, defaults to critical
+func WithMinSeverity(severity types.Severity) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.minSeverity = severity
+	}
+}
+
+// WithBaseURL overrides the Twilio API base URL
+func WithBaseURL(baseURL string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithTimeout sets the timeout of the API request
+func WithTimeout(timeout time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if timeout > 0 {
+			notifier.timeout = timeout
+		}
+	}
+}
+
+// WithCircuitBreaker sets the number of the consecutive failed requests to open the circuit breaker, and the cool-down
+// period the notifications are dropped before the request is tried again. The breaker is disabled if the threshold
+// is negative.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if cooldown <= 0 {
+			cooldown = defaultCooldown
+		}
+
+		notifier.breaker = util.NewCircuitBreaker("sms", failureThreshold, cooldown)
+	}
+}
+
+func New(accountSID, authToken, from string, to []string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		client:      &http.Client{},
+		baseURL:     defaultBaseURL,
+		accountSID:  accountSID,
+		authToken:   authToken,
+		from:        from,
+		to:          to,
+		minSeverity: types.SeverityCritical,
+		timeout:     defaultTimeout,
+		breaker:     util.NewCircuitBreaker("sms", defaultFailureThreshold, defaultCooldown),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	return notifier
+}
+
+// Notify ignores the notifications without the severity, they are the routine reports
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyWithSeverity(types.SeverityInfo, "", format, args...)
+}
+
+// NotifyTo ignores the notifications without the severity, they are the routine reports
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	n.NotifyWithSeverity(types.SeverityInfo, channel, format, args...)
+}
+
+// NotifyWithSeverity sends the notification to all the recipients if the severity reaches the min severity,
+// the channel is ignored since the recipients are configured in the notifier.
+func (n *Notifier) NotifyWithSeverity(severity types.Severity, channel, format string, args ...interface{}) {
+	if severity < n.minSeverity {
+		return
+	}
+
+	var textArgs = args
+	for idx, arg := range args {
+		if isObject(arg) {
+			textArgs = args[:idx]
+			break
+		}
+	}
+
+	body := fmt.Sprintf(format, textArgs...)
+	if len(body) > maxBodyLength {
+		body = body[:maxBodyLength]
+	}
+
+	for _, to := range n.to {
+		n.wg.Add(1)
+		go func(to string) {
+			defer n.wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+			defer cancel()
+
+			if err := n.send(ctx, to, body); err == util.ErrCircuitOpen {
+				log.Debugf("sms circuit breaker is open, dropping the message to %s", to)
+			} else if err != nil {
+				log.WithError(err).Errorf("sms error: %s", err.Error())
+			}
+		}(to)
+	}
+}
+
+// CircuitBreaker returns the circuit breaker of the API requests
+func (n *Notifier) CircuitBreaker() *util.CircuitBreaker {
+	return n.breaker
+}
+
+// Flush waits until all the messages are sent or failed
+func (n *Notifier) Flush() {
+	n.wg.Wait()
+}
+
+// TestNotify sends the test message to all the recipients regardless of the min severity,
+// the first delivery error is returned
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	for _, to := range n.to {
+		if err := n.send(ctx, to, message); err != nil {
+			return fmt.Errorf("can not send sms to %s: %w", to, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, to, body string) error {
+	form := url.Values{}
+	form.Set("From", n.from)
+	form.Set("To", to)
+	form.Set("Body", body)
+
+	apiURL := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", n.baseURL, n.accountSID)
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(n.accountSID, n.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := n.breaker.Allow(); err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		n.breaker.Report(err)
+		return err
+	}
+
+	// the rejected requests, e.g., 4xx, are not counted as the failures of the breaker since the server is reachable
+	if response.StatusCode >= 500 {
+		err := fmt.Errorf("twilio server error: status %d, response: %s", response.StatusCode, response.String())
+		n.breaker.Report(err)
+		return err
+	}
+
+	n.breaker.Report(nil)
+
+	if response.IsError() {
+		return fmt.Errorf("twilio request error: status %d, response: %s", response.StatusCode, response.String())
+	}
+
+	return nil
+}
+
+// isObject returns true if the argument is a struct or a pointer to struct, which is not a part of the text
+func isObject(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+
+	switch arg.(type) {
+	case time.Time, *time.Time, error:
+		return false
+	}
+
+	t := reflect.TypeOf(arg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
@@ -0,0 +1,73 @@
+package smsnotifier
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testMessage struct {
+	path, from, to, body string
+	username, password   string
+}
+
+func TestNotifier_NotifyWithSeverity(t *testing.T) {
+	var mu sync.Mutex
+	var messages []testMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		username, password, _ := r.BasicAuth()
+
+		mu.Lock()
+		messages = append(messages, testMessage{
+			path:     r.URL.Path,
+			from:     r.PostForm.Get("From"),
+			to:       r.PostForm.Get("To"),
+			body:     r.PostForm.Get("Body"),
+			username: username,
+			password: password,
+		})
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	notifier := New("AC123", "token", "+15550000000", []string{"+15551111111", "+15552222222"}, WithBaseURL(server.URL))
+
+	notifier.Notify("trade %s", "BTCUSDT", &types.Trade{Symbol: "BTCUSDT"})
+	notifier.NotifyTo("#trades", "order %s", "BTCUSDT")
+	notifier.NotifyWithSeverity(types.SeverityWarning, "", "warning %s", "BTCUSDT")
+	notifier.Flush()
+	assert.Empty(t, messages, "only the critical notifications should be sent")
+
+	notifier.NotifyWithSeverity(types.SeverityCritical, "", "session %s disconnected: %v", "binance", errors.New("EOF"), &types.Trade{})
+	notifier.Flush()
+
+	if assert.Len(t, messages, 2) {
+		sort.Slice(messages, func(i, j int) bool { return messages[i].to < messages[j].to })
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", messages[0].path)
+		assert.Equal(t, "+15550000000", messages[0].from)
+		assert.Equal(t, "+15551111111", messages[0].to)
+		assert.Equal(t, "+15552222222", messages[1].to)
+		assert.Equal(t, "session binance disconnected: EOF", messages[0].body)
+		assert.Equal(t, "AC123", messages[0].username)
+		assert.Equal(t, "token", messages[0].password)
+	}
+
+	messages = nil
+	notifier = New("AC123", "token", "+15550000000", []string{"+15551111111"},
+		WithBaseURL(server.URL), WithMinSeverity(types.SeverityWarning))
+	notifier.NotifyWithSeverity(types.SeverityWarning, "", "warning")
+	notifier.Flush()
+	assert.Len(t, messages, 1)
+}
@@ -1,22 +1,68 @@
 package telegramnotifier
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/tucnak/telebot.v2"
 
+	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/service"
 )
 
 var log = logrus.WithField("service", "telegram")
 
 type Session struct {
-	Owner              *telebot.User `json:"owner"`
-	Chat               *telebot.Chat `json:"chat"`
-	OneTimePasswordKey *otp.Key      `json:"otpKey"`
+	// Owner and Chat are the single owner fields used by the older version,
+	// they will be migrated into the Subscribers list when the session is loaded.
+	//
+	// Deprecated: use Subscribers instead
+	Owner *telebot.User `json:"owner"`
+
+	// Deprecated: use Subscribers instead
+	Chat *telebot.Chat `json:"chat"`
+
+	// Subscribers are the authorized users and their chats, notifications will be broadcast to all of them
+	Subscribers []Subscriber `json:"subscribers"`
+
+	OneTimePasswordKey *otp.Key `json:"otpKey"`
+}
+
+type Subscriber struct {
+	User *telebot.User `json:"user"`
+	Chat *telebot.Chat `json:"chat"`
+
+	// AuthorizedAt is the time the chat is authorized, it's zero for the chats authorized by the older version
+	AuthorizedAt time.Time `json:"authorizedAt"`
+}
+
+func (s Subscriber) String() string {
+	var username = "unknown"
+	if s.User != nil {
+		username = s.User.Username
+		if len(username) == 0 {
+			username = strconv.Itoa(s.User.ID)
+		}
+	}
+
+	var chatID int64
+	if s.Chat != nil {
+		chatID = s.Chat.ID
+	}
+
+	var authorizedAt = "unknown"
+	if !s.AuthorizedAt.IsZero() {
+		authorizedAt = s.AuthorizedAt.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%s (chat ID: %d, authorized at: %s)", username, chatID, authorizedAt)
 }
 
 func NewSession(key *otp.Key) Session {
@@ -27,6 +73,94 @@ func NewSession(key *otp.Key) Session {
 	}
 }
 
+// Migrate moves the legacy single owner into the subscriber list, returns true if the session is changed
+func (s *Session) Migrate() bool {
+	if s.Owner == nil || s.Chat == nil {
+		return false
+	}
+
+	s.AddSubscriber(s.Owner, s.Chat)
+	s.Owner = nil
+	s.Chat = nil
+	return true
+}
+
+// HasSubscribers returns true if there is at least one authorized chat
+func (s *Session) HasSubscribers() bool {
+	return len(s.Subscribers) > 0
+}
+
+// AddSubscriber adds the chat to the subscriber list, returns false if the chat is already subscribed
+func (s *Session) AddSubscriber(user *telebot.User, chat *telebot.Chat) bool {
+	if s.IsSubscribed(chat.ID) {
+		return false
+	}
+
+	s.Subscribers = append(s.Subscribers, Subscriber{User: user, Chat: chat, AuthorizedAt: time.Now()})
+	return true
+}
+
+// RemoveSubscriber removes the chat from the subscriber list, returns false if the chat is not subscribed
+func (s *Session) RemoveSubscriber(chatID int64) bool {
+	for idx, subscriber := range s.Subscribers {
+		if subscriber.Chat != nil && subscriber.Chat.ID == chatID {
+			s.Subscribers = append(s.Subscribers[:idx], s.Subscribers[idx+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Session) IsSubscribed(chatID int64) bool {
+	for _, subscriber := range s.Subscribers {
+		if subscriber.Chat != nil && subscriber.Chat.ID == chatID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscriber returns the subscriber of the chat
+func (s *Session) Subscriber(chatID int64) (Subscriber, bool) {
+	for _, subscriber := range s.Subscribers {
+		if subscriber.Chat != nil && subscriber.Chat.ID == chatID {
+			return subscriber, true
+		}
+	}
+
+	return Subscriber{}, false
+}
+
+// IsAdmin returns true if the user is one of the admins, the first authorized user is the admin if no admin is given
+func (s *Session) IsAdmin(userID int, admins []int) bool {
+	if len(admins) > 0 {
+		for _, id := range admins {
+			if id == userID {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return len(s.Subscribers) > 0 && s.Subscribers[0].User != nil && s.Subscribers[0].User.ID == userID
+}
+
+// IsAuthorized returns true if the user is the one who authorized the chat,
+// in a group chat, the other members of the group are not authorized.
+func (s *Session) IsAuthorized(userID int, chatID int64) bool {
+	for _, subscriber := range s.Subscribers {
+		if subscriber.Chat != nil && subscriber.Chat.ID == chatID &&
+			subscriber.User != nil && subscriber.User.ID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
 //go:generate callbackgen -type Interaction
 type Interaction struct {
 	store service.Store
@@ -35,21 +169,42 @@ type Interaction struct {
 
 	AuthToken string
 
+	// admins are the user IDs who can list and revoke the authorized chats
+	admins []int
+
+	// mu protects the session subscribers, the bot handlers and the notifiers run in different goroutines
+	mu      sync.Mutex
 	session *Session
 
+	// dispatcher handles the query commands shared with the other chat interactions
+	dispatcher interact.Dispatcher
+
+	// startedC is closed when the bot starts polling, doneC is closed when the bot stops polling
+	startedC chan struct{}
+	doneC    chan struct{}
+	stopOnce sync.Once
+
 	StartCallbacks []func()
 	AuthCallbacks  []func(user *telebot.User)
 }
 
 func NewInteraction(bot *telebot.Bot, store service.Store) *Interaction {
 	interaction := &Interaction{
-		store: store,
-		bot:   bot,
+		store:    store,
+		bot:      bot,
+		startedC: make(chan struct{}),
+		doneC:    make(chan struct{}),
 	}
 
 	bot.Handle("/help", interaction.HandleHelp)
 	bot.Handle("/auth", interaction.HandleAuth)
 	bot.Handle("/info", interaction.HandleInfo)
+	bot.Handle("/unsubscribe", interaction.HandleUnsubscribe)
+	bot.Handle("/whoami", interaction.HandleWhoAmI)
+	bot.Handle("/revoke", interaction.HandleRevoke)
+	for _, command := range interact.Commands {
+		bot.Handle("/"+command.Name, interaction.HandleCommand)
+	}
 	return interaction
 }
 
@@ -57,36 +212,69 @@ func (it *Interaction) SetAuthToken(token string) {
 	it.AuthToken = token
 }
 
+// SetAdmins sets the user IDs of the admins, the first authorized user is the admin if no admin is set
+func (it *Interaction) SetAdmins(admins []int) {
+	it.admins = admins
+}
+
 func (it *Interaction) Session() *Session {
 	return it.session
 }
 
 func (it *Interaction) HandleInfo(m *telebot.Message) {
-	if it.session.Owner == nil || it.session.Chat == nil {
+	it.mu.Lock()
+	authorized := it.session != nil && it.session.IsAuthorized(m.Sender.ID, m.Chat.ID)
+	it.mu.Unlock()
+
+	if !authorized {
+		log.Warningf("incorrect user tried to access bot! sender: %+v", m.Sender)
 		return
 	}
 
-	if m.Sender.ID != it.session.Owner.ID {
-		log.Warningf("incorrect user tried to access bot! sender: %+v", m.Sender)
-	} else {
-		if _, err := it.bot.Send(it.session.Chat,
-			fmt.Sprintf("Welcome! your username: %s, user ID: %d",
-				it.session.Owner.Username,
-				it.session.Owner.ID,
-			)); err != nil {
-			log.WithError(err).Error("failed to send telegram message")
-		}
+	if _, err := it.bot.Send(m.Chat,
+		fmt.Sprintf("Welcome! your username: %s, user ID: %d",
+			m.Sender.Username,
+			m.Sender.ID,
+		)); err != nil {
+		log.WithError(err).Error("failed to send telegram message")
 	}
 }
 
-func (it *Interaction) SendToOwner(message string) {
-	if it.session.Chat == nil {
-		return
+// Broadcast sends the message to all the subscribed chats
+func (it *Interaction) Broadcast(message string) {
+	_ = it.broadcast(message)
+}
+
+// broadcast sends the message to all the subscribed chats, the last send error is returned
+func (it *Interaction) broadcast(message string) (err error) {
+	it.mu.Lock()
+	var chats []*telebot.Chat
+	if it.session != nil {
+		for _, subscriber := range it.session.Subscribers {
+			chats = append(chats, subscriber.Chat)
+		}
 	}
+	it.mu.Unlock()
 
-	if _, err := it.bot.Send(it.session.Chat, message); err != nil {
-		log.WithError(err).Error("failed to send message to the owner")
+	if len(chats) == 0 {
+		return errors.New("no telegram chat is subscribed, please authorize the chat with /auth")
 	}
+
+	for _, chat := range chats {
+		if _, sendErr := it.bot.Send(chat, message); sendErr != nil {
+			log.WithError(sendErr).Errorf("failed to send message to the chat %d", chat.ID)
+			err = sendErr
+		}
+	}
+
+	return err
+}
+
+// SendToOwner sends the message to the subscribers
+//
+// Deprecated: use Broadcast instead
+func (it *Interaction) SendToOwner(message string) {
+	it.Broadcast(message)
 }
 
 func (it *Interaction) HandleHelp(m *telebot.Message) {
@@ -94,64 +282,250 @@ func (it *Interaction) HandleHelp(m *telebot.Message) {
 help	- show this help message
 auth	- authorize current telegram user to access telegram bot with authentication token or one-time password. ex. /auth my-token
 info	- show information about current chat
-`
+unsubscribe	- stop sending the notifications to the current chat
+whoami	- show your authorization, the admins also see all the authorized chats
+revoke	- (admin only) revoke the authorization of the chat, ex. /revoke 123456789
+` + interact.Usage("")
 	if _, err := it.bot.Send(m.Chat, message); err != nil {
 		log.WithError(err).Error("failed to send help message")
 	}
 }
 
-func (it *Interaction) HandleAuth(m *telebot.Message) {
-	if len(it.AuthToken) > 0 && m.Payload == it.AuthToken {
-		it.session.Owner = m.Sender
-		it.session.Chat = m.Chat
+func (it *Interaction) validateAuthPayload(payload string) bool {
+	it.mu.Lock()
+	var key *otp.Key
+	if it.session != nil {
+		key = it.session.OneTimePasswordKey
+	}
+	it.mu.Unlock()
+
+	return interact.ValidateAuth(payload, it.AuthToken, key)
+}
 
-		if _, err := it.bot.Send(m.Chat, fmt.Sprintf("Hi %s, I know you, I will send you the notifications!", m.Sender.Username)); err != nil {
+func (it *Interaction) HandleAuth(m *telebot.Message) {
+	if !it.validateAuthPayload(m.Payload) {
+		if _, err := it.bot.Send(m.Chat, "Authorization failed. please check your auth token"); err != nil {
 			log.WithError(err).Error("telegram send error")
 		}
+		return
+	}
 
-		if err := it.store.Save(it.session); err != nil {
-			log.WithError(err).Error("can not persist telegram chat user")
+	it.mu.Lock()
+	if it.session == nil {
+		// the session is set asynchronously by Start, the auth token could be received before that
+		it.mu.Unlock()
+		if _, err := it.bot.Send(m.Chat, "The bot is not ready yet, please try again later"); err != nil {
+			log.WithError(err).Error("telegram send error")
 		}
+		return
+	}
 
-		it.EmitAuth(m.Sender)
+	it.session.AddSubscriber(m.Sender, m.Chat)
+	err := it.store.Save(it.session)
+	it.mu.Unlock()
 
-	} else if it.session != nil && it.session.OneTimePasswordKey != nil {
+	if err != nil {
+		log.WithError(err).Error("can not persist telegram chat user")
+	}
 
-		if totp.Validate(m.Payload, it.session.OneTimePasswordKey.Secret()) {
-			it.session.Owner = m.Sender
-			it.session.Chat = m.Chat
+	if _, err := it.bot.Send(m.Chat, fmt.Sprintf("Hi %s, I know you, I will send you the notifications!", m.Sender.Username)); err != nil {
+		log.WithError(err).Error("telegram send error")
+	}
 
-			if _, err := it.bot.Send(m.Chat, fmt.Sprintf("Hi %s, I know you, I will send you the notifications!", m.Sender.Username)); err != nil {
-				log.WithError(err).Error("telegram send error")
-			}
+	it.EmitAuth(m.Sender)
+}
 
-			if err := it.store.Save(it.session); err != nil {
-				log.WithError(err).Error("can not persist telegram chat user")
-			}
+// SetEnvironment sets the environment queried by the balance and the sessions commands
+func (it *Interaction) SetEnvironment(environ interact.Environment) {
+	it.dispatcher.SetEnvironment(environ)
+}
+
+// HandleCommand replies the query commands, e.g., /balance and /sessions, only the authorized users can query
+func (it *Interaction) HandleCommand(m *telebot.Message) {
+	it.mu.Lock()
+	authorized := it.session != nil && it.session.IsAuthorized(m.Sender.ID, m.Chat.ID)
+	it.mu.Unlock()
+
+	if !authorized {
+		log.Warningf("incorrect user tried to access bot! sender: %+v", m.Sender)
+		return
+	}
+
+	reply, ok := it.dispatcher.Dispatch(commandName(m.Text), m.Payload)
+	if !ok {
+		return
+	}
+
+	if _, err := it.bot.Send(m.Chat, reply); err != nil {
+		log.WithError(err).Error("telegram send error")
+	}
+}
+
+// commandName returns the command name of the message text, the text could be "/balance@bbgo_bot binance" in a group chat
+func commandName(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	command := strings.TrimPrefix(fields[0], "/")
+	if idx := strings.Index(command, "@"); idx >= 0 {
+		command = command[:idx]
+	}
+
+	return command
+}
+
+// HandleUnsubscribe removes the current chat from the subscribers, only the user who authorized the chat can unsubscribe it
+func (it *Interaction) HandleUnsubscribe(m *telebot.Message) {
+	it.mu.Lock()
+	removed := it.session != nil &&
+		it.session.IsAuthorized(m.Sender.ID, m.Chat.ID) &&
+		it.session.RemoveSubscriber(m.Chat.ID)
+	var err error
+	if removed {
+		err = it.store.Save(it.session)
+	}
+	it.mu.Unlock()
+
+	if !removed {
+		if _, err := it.bot.Send(m.Chat, "You are not subscribed"); err != nil {
+			log.WithError(err).Error("telegram send error")
+		}
+		return
+	}
 
-			it.EmitAuth(m.Sender)
+	if err != nil {
+		log.WithError(err).Error("can not persist telegram chat user")
+	}
+
+	if _, err := it.bot.Send(m.Chat, fmt.Sprintf("Bye %s, I will not send you the notifications anymore", m.Sender.Username)); err != nil {
+		log.WithError(err).Error("telegram send error")
+	}
+}
 
-		} else {
-			if _, err := it.bot.Send(m.Chat, "Authorization failed. please check your auth token"); err != nil {
-				log.WithError(err).Error("telegram send error")
+// HandleWhoAmI replies the authorization of the current chat, the admins also get all the authorized chats
+func (it *Interaction) HandleWhoAmI(m *telebot.Message) {
+	it.mu.Lock()
+	var message string
+	authorized := it.session != nil && it.session.IsAuthorized(m.Sender.ID, m.Chat.ID)
+	if authorized {
+		subscriber, _ := it.session.Subscriber(m.Chat.ID)
+		message = fmt.Sprintf("You are %s, user ID: %d", subscriber, m.Sender.ID)
+
+		if it.session.IsAdmin(m.Sender.ID, it.admins) {
+			message += "\n\nYou are an admin, the authorized chats:"
+			for _, s := range it.session.Subscribers {
+				message += "\n- " + s.String()
 			}
 		}
+	}
+	it.mu.Unlock()
 
-	} else {
-		if _, err := it.bot.Send(m.Chat, "Authorization failed. please check your auth token"); err != nil {
+	if !authorized {
+		log.Warningf("incorrect user tried to access bot! sender: %+v", m.Sender)
+		return
+	}
+
+	if _, err := it.bot.Send(m.Chat, message); err != nil {
+		log.WithError(err).Error("telegram send error")
+	}
+}
+
+// HandleRevoke removes the chat of the payload from the subscribers, only the admins can revoke the chats.
+// The notifications are not sent to the revoked chat anymore since the broadcast reads the subscribers of the session.
+func (it *Interaction) HandleRevoke(m *telebot.Message) {
+	chatID, parseErr := strconv.ParseInt(strings.TrimSpace(m.Payload), 10, 64)
+
+	it.mu.Lock()
+	admin := it.session != nil &&
+		it.session.IsAuthorized(m.Sender.ID, m.Chat.ID) &&
+		it.session.IsAdmin(m.Sender.ID, it.admins)
+
+	var revoked Subscriber
+	var removed bool
+	var err error
+	if admin && parseErr == nil {
+		revoked, removed = it.session.Subscriber(chatID)
+		if removed {
+			it.session.RemoveSubscriber(chatID)
+			err = it.store.Save(it.session)
+		}
+	}
+	it.mu.Unlock()
+
+	if !admin {
+		log.Warningf("non-admin user tried to revoke the chat! sender: %+v", m.Sender)
+		return
+	}
+
+	var reply string
+	switch {
+	case parseErr != nil:
+		reply = "Usage: /revoke <chat ID>, the chat IDs are listed by /whoami"
+	case !removed:
+		reply = fmt.Sprintf("Chat %d is not authorized", chatID)
+	default:
+		reply = fmt.Sprintf("Revoked %s", revoked)
+	}
+
+	if err != nil {
+		log.WithError(err).Error("can not persist telegram chat user")
+	}
+
+	if _, err := it.bot.Send(m.Chat, reply); err != nil {
+		log.WithError(err).Error("telegram send error")
+	}
+
+	if removed && revoked.Chat != nil && revoked.Chat.ID != m.Chat.ID {
+		if _, err := it.bot.Send(revoked.Chat, "Your authorization is revoked by the admin, I will not send you the notifications anymore"); err != nil {
 			log.WithError(err).Error("telegram send error")
 		}
 	}
 }
 
 func (it *Interaction) Start(session Session) {
+	session.Migrate()
+
+	it.mu.Lock()
 	it.session = &session
+	subscribers := append([]Subscriber(nil), session.Subscribers...)
+	it.mu.Unlock()
 
-	if it.session.Owner != nil && it.session.Chat != nil {
-		if _, err := it.bot.Send(it.session.Chat, fmt.Sprintf("Hi %s, I'm back", it.session.Owner.Username)); err != nil {
+	for _, subscriber := range subscribers {
+		if subscriber.User == nil || subscriber.Chat == nil {
+			continue
+		}
+
+		if _, err := it.bot.Send(subscriber.Chat, fmt.Sprintf("Hi %s, I'm back", subscriber.User.Username)); err != nil {
 			log.WithError(err).Error("failed to send telegram message")
 		}
 	}
 
+	close(it.startedC)
 	it.bot.Start()
+	close(it.doneC)
+}
+
+// Stop stops the bot poller and waits until the bot stops or the context is done,
+// it returns immediately if the bot is not started.
+func (it *Interaction) Stop(ctx context.Context) error {
+	select {
+	case <-it.startedC:
+	default:
+		return nil
+	}
+
+	// the bot stop channel is not buffered, send the stop signal in a goroutine to honor the context
+	it.stopOnce.Do(func() {
+		go it.bot.Stop()
+	})
+
+	select {
+	case <-it.doneC:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,97 @@
+package telegramnotifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/tucnak/telebot.v2"
+)
+
+func TestSession_Migrate(t *testing.T) {
+	var session Session
+	err := json.Unmarshal([]byte(`{"owner":{"id":123,"username":"alice"},"chat":{"id":456,"type":"private"}}`), &session)
+	assert.NoError(t, err)
+
+	assert.True(t, session.Migrate())
+	assert.Nil(t, session.Owner)
+	assert.Nil(t, session.Chat)
+	if assert.Len(t, session.Subscribers, 1) {
+		assert.Equal(t, int64(456), session.Subscribers[0].Chat.ID)
+		assert.Equal(t, "alice", session.Subscribers[0].User.Username)
+	}
+
+	// migrating twice should not change anything
+	assert.False(t, session.Migrate())
+	assert.Len(t, session.Subscribers, 1)
+}
+
+func TestSession_Subscribers(t *testing.T) {
+	session := NewSession(nil)
+	assert.False(t, session.HasSubscribers())
+
+	assert.True(t, session.AddSubscriber(&telebot.User{ID: 1}, &telebot.Chat{ID: 10}))
+	assert.True(t, session.AddSubscriber(&telebot.User{ID: 2}, &telebot.Chat{ID: 20}))
+	assert.False(t, session.AddSubscriber(&telebot.User{ID: 1}, &telebot.Chat{ID: 10}), "duplicated chat should be ignored")
+	assert.Len(t, session.Subscribers, 2)
+
+	assert.True(t, session.RemoveSubscriber(10))
+	assert.False(t, session.RemoveSubscriber(10))
+	assert.False(t, session.IsSubscribed(10))
+	assert.True(t, session.IsSubscribed(20))
+}
+
+func TestSession_IsAuthorized(t *testing.T) {
+	session := NewSession(nil)
+	session.AddSubscriber(&telebot.User{ID: 1}, &telebot.Chat{ID: -100, Type: telebot.ChatGroup})
+
+	assert.True(t, session.IsAuthorized(1, -100))
+	assert.False(t, session.IsAuthorized(2, -100), "the other group members are not authorized")
+	assert.False(t, session.IsAuthorized(1, 10))
+}
+
+func TestSession_IsAdmin(t *testing.T) {
+	session := NewSession(nil)
+	assert.False(t, session.IsAdmin(1, nil), "no one is authorized")
+
+	session.AddSubscriber(&telebot.User{ID: 1}, &telebot.Chat{ID: 10})
+	session.AddSubscriber(&telebot.User{ID: 2}, &telebot.Chat{ID: 20})
+
+	assert.True(t, session.IsAdmin(1, nil), "the first authorized user is the admin")
+	assert.False(t, session.IsAdmin(2, nil))
+	assert.True(t, session.IsAdmin(2, []int{2, 3}))
+	assert.False(t, session.IsAdmin(1, []int{2, 3}))
+
+	session.RemoveSubscriber(10)
+	assert.True(t, session.IsAdmin(2, nil), "the next authorized user becomes the admin")
+}
+
+func TestSubscriber_AuthorizedAt(t *testing.T) {
+	session := NewSession(nil)
+	session.AddSubscriber(&telebot.User{ID: 1, Username: "alice"}, &telebot.Chat{ID: 10})
+
+	subscriber, ok := session.Subscriber(10)
+	if assert.True(t, ok) {
+		assert.WithinDuration(t, time.Now(), subscriber.AuthorizedAt, time.Minute)
+	}
+
+	_, ok = session.Subscriber(20)
+	assert.False(t, ok)
+
+	subscriber.AuthorizedAt = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "alice (chat ID: 10, authorized at: 2021-06-01T00:00:00Z)", subscriber.String())
+
+	// the chats authorized by the older version have no authorization time
+	var legacy Subscriber
+	assert.NoError(t, json.Unmarshal([]byte(`{"user":{"id":2},"chat":{"id":20,"type":"private"}}`), &legacy))
+	assert.True(t, legacy.AuthorizedAt.IsZero())
+	assert.Equal(t, "2 (chat ID: 20, authorized at: unknown)", legacy.String())
+}
+
+func TestCommandName(t *testing.T) {
+	assert.Equal(t, "balance", commandName("/balance binance"))
+	assert.Equal(t, "balance", commandName("/balance@bbgo_bot binance"))
+	assert.Equal(t, "sessions", commandName("/sessions"))
+	assert.Equal(t, "", commandName(""))
+}
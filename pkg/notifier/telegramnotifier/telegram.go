@@ -1,6 +1,7 @@
 package telegramnotifier
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/c9s/bbgo/pkg/types"
@@ -25,11 +26,23 @@ func New(interaction *Interaction, options ...NotifyOption) *Notifier {
 	return notifier
 }
 
+// TestNotify sends the test message to all the subscribed chats and returns the delivery error
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	return n.interaction.broadcast(message)
+}
+
 func (n *Notifier) Notify(format string, args ...interface{}) {
 	n.NotifyTo("", format, args...)
 }
 
-func (n *Notifier) NotifyTo(_, format string, args ...interface{}) {
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	// the send errors are logged by the interaction
+	_ = n.TryNotifyTo(types.OutputFormatText, channel, format, args...)
+}
+
+// TryNotifyTo broadcasts the notification to the subscribed chats like NotifyTo and returns the delivery error,
+// the channel and the output format are ignored
+func (n *Notifier) TryNotifyTo(_ types.OutputFormat, _, format string, args ...interface{}) error {
 	var textArgsOffset = -1
 	var texts []string
 
@@ -51,10 +64,15 @@ func (n *Notifier) NotifyTo(_, format string, args ...interface{}) {
 	log.Infof(format, simpleArgs...)
 
 	message := fmt.Sprintf(format, simpleArgs...)
-	n.interaction.SendToOwner(message)
+	if err := n.interaction.broadcast(message); err != nil {
+		return err
+	}
 
 	for _, text := range texts {
-		n.interaction.SendToOwner(text)
+		if err := n.interaction.broadcast(text); err != nil {
+			return err
+		}
 	}
 
+	return nil
 }
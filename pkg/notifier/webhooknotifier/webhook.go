@@ -0,0 +1,438 @@
+package webhooknotifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+var log = logrus.WithField("service", "webhook")
+
+// SignatureHeader is the header that carries the HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-BBGO-Signature"
+
+const (
+	defaultTimeout        = 10 * time.Second
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultQueueSize      = 100
+
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Minute
+)
+
+// Object is the typed object attached to the notification, e.g., types.Trade or types.Order
+type Object struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Payload is the JSON body posted to the webhook URL,
+// the text is omitted in the json output format if the typed objects are attached.
+type Payload struct {
+	Channel string    `json:"channel,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	Objects []Object  `json:"objects,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+type Notifier struct {
+	client *http.Client
+
+	url    string
+	secret string
+
+	// channels maps the channel names to the webhook URLs
+	channels map[string]string
+
+	timeout        time.Duration
+	maxRetries     int
+	initialBackoff time.Duration
+
+	// breaker drops the notifications while the webhook server keeps failing
+	breaker *util.CircuitBreaker
+
+	// queue is the delivery queue, NotifyTo is called from the stream callbacks,
+	// so the deliveries are sent by the worker goroutine to avoid blocking the stream.
+	queue     chan delivery
+	queueSize int
+
+	// pending is the number of the queued deliveries not delivered or failed yet, idle is signaled when it's zero
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pending int
+	closed  bool
+
+	// doneC is closed when the worker exits
+	doneC chan struct{}
+}
+
+type delivery struct {
+	url     string
+	payload Payload
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithSecret sets the secret for signing the payload
+func WithSecret(secret string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.secret = secret
+	}
+}
+
+// WithChannels sets the channel name to webhook URL overrides
+func WithChannels(channels map[string]string) NotifyOption {
+	return func(notifier *Notifier) {
+		for channel, url := range channels {
+			notifier.channels[channel] = url
+		}
+	}
+}
+
+// WithTimeout sets the timeout of a notification delivery, including the retries
+func WithTimeout(timeout time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if timeout > 0 {
+			notifier.timeout = timeout
+		}
+	}
+}
+
+// WithMaxRetries sets the max number of retries when the webhook server responds 5xx
+func WithMaxRetries(maxRetries int) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.maxRetries = maxRetries
+	}
+}
+
+// WithQueueSize sets the max number of the pending deliveries, notifications are dropped when the queue is full
+func WithQueueSize(size int) NotifyOption {
+	return func(notifier *Notifier) {
+		if size > 0 {
+			notifier.queueSize = size
+		}
+	}
+}
+
+// WithCircuitBreaker sets the number of the consecutive failed deliveries to open the circuit breaker, and the cool-down
+// period the notifications are dropped before the delivery is tried again. The breaker is disabled if the threshold
+// is negative.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) NotifyOption {
+	return func(notifier *Notifier) {
+		if cooldown <= 0 {
+			cooldown = defaultCooldown
+		}
+
+		notifier.breaker = util.NewCircuitBreaker("webhook", failureThreshold, cooldown)
+	}
+}
+
+func New(url string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		client:         &http.Client{},
+		url:            url,
+		channels:       make(map[string]string),
+		timeout:        defaultTimeout,
+		maxRetries:     defaultMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		queueSize:      defaultQueueSize,
+		breaker:        util.NewCircuitBreaker("webhook", defaultFailureThreshold, defaultCooldown),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	notifier.idle = sync.NewCond(&notifier.mu)
+	notifier.queue = make(chan delivery, notifier.queueSize)
+	notifier.doneC = make(chan struct{})
+	go notifier.worker()
+
+	return notifier
+}
+
+func (n *Notifier) worker() {
+	defer close(n.doneC)
+
+	for d := range n.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+		if err := n.post(ctx, d.url, d.payload); errors.Is(err, util.ErrCircuitOpen) {
+			log.WithField("channel", d.payload.Channel).Debugf("webhook circuit breaker is open, dropping the notification: %s", d.payload.Text)
+		} else if err != nil {
+			log.WithError(err).
+				WithField("channel", d.payload.Channel).
+				Errorf("webhook error: %s", err.Error())
+		}
+		cancel()
+		n.done()
+	}
+}
+
+// done marks a queued delivery as delivered or failed
+func (n *Notifier) done() {
+	n.mu.Lock()
+	n.pending--
+	if n.pending == 0 {
+		n.idle.Broadcast()
+	}
+	n.mu.Unlock()
+}
+
+// CircuitBreaker returns the circuit breaker of the deliveries
+func (n *Notifier) CircuitBreaker() *util.CircuitBreaker {
+	return n.breaker
+}
+
+// Flush waits until all the queued notifications are delivered or failed
+func (n *Notifier) Flush() {
+	n.mu.Lock()
+	for n.pending > 0 {
+		n.idle.Wait()
+	}
+	n.mu.Unlock()
+}
+
+// Close stops accepting the notifications, and waits until the queued notifications are delivered and the worker
+// exits, the notifications sent after Close are dropped. It's safe to call Close more than once.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	<-n.doneC
+	return nil
+}
+
+func (n *Notifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo("", format, args...)
+}
+
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	n.NotifyToWithFormat(types.OutputFormatText, channel, format, args...)
+}
+
+// NotifyToWithFormat sends the notification in the given output format,
+// the rendered text is dropped in the json format when the typed objects are attached.
+func (n *Notifier) NotifyToWithFormat(outputFormat types.OutputFormat, channel, format string, args ...interface{}) {
+	url := n.resolveURL(channel)
+	if len(url) == 0 {
+		return
+	}
+
+	payload := newPayload(outputFormat, channel, format, args...)
+
+	// the queue is closed under the lock, so the delivery is enqueued under the lock without blocking
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		log.WithField("channel", channel).Warnf("webhook notifier is closed, dropping the notification: %s", payload.Text)
+		return
+	}
+
+	select {
+	case n.queue <- delivery{url: url, payload: payload}:
+		n.pending++
+	default:
+		log.WithField("channel", channel).Warnf("webhook delivery queue is full, dropping the notification: %s", payload.Text)
+	}
+}
+
+// TryNotifyTo posts the notification synchronously like TestNotify, and returns the delivery error after the retries,
+// it's used by the failover delivery
+func (n *Notifier) TryNotifyTo(outputFormat types.OutputFormat, channel, format string, args ...interface{}) error {
+	url := n.resolveURL(channel)
+	if len(url) == 0 {
+		return fmt.Errorf("webhook url of channel %q is not defined", channel)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+
+	return n.post(ctx, url, newPayload(outputFormat, channel, format, args...))
+}
+
+// resolveURL returns the webhook URL of the channel, the default URL is used if the channel is not mapped
+func (n *Notifier) resolveURL(channel string) string {
+	if u, ok := n.channels[channel]; ok {
+		return u
+	}
+
+	return n.url
+}
+
+// newPayload renders the notification text and collects the typed objects of the arguments
+func newPayload(outputFormat types.OutputFormat, channel, format string, args ...interface{}) Payload {
+	var objects []Object
+	var objectArgsOffset = -1
+	for idx, arg := range args {
+		if !isObject(arg) {
+			continue
+		}
+
+		if objectArgsOffset == -1 {
+			objectArgsOffset = idx
+		}
+
+		objects = append(objects, Object{Type: typeName(arg), Data: arg})
+	}
+
+	var textArgs = args
+	if objectArgsOffset > -1 {
+		textArgs = args[:objectArgsOffset]
+	}
+
+	payload := Payload{
+		Channel: channel,
+		Text:    fmt.Sprintf(format, textArgs...),
+		Objects: objects,
+		Time:    time.Now(),
+	}
+
+	if outputFormat == types.OutputFormatJSON && len(objects) > 0 {
+		payload.Text = ""
+	}
+
+	return payload
+}
+
+// TestNotify posts the test message to the default webhook URL synchronously and returns the delivery error
+func (n *Notifier) TestNotify(ctx context.Context, message string) error {
+	if len(n.url) == 0 {
+		return errors.New("webhook url is not defined")
+	}
+
+	return n.post(ctx, n.url, Payload{Text: message, Time: time.Now()})
+}
+
+// post sends the payload to the url, ErrCircuitOpen is returned without sending if the circuit breaker is open
+func (n *Notifier) post(ctx context.Context, url string, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if err := n.breaker.Allow(); err != nil {
+		return err
+	}
+
+	return n.deliver(ctx, url, body)
+}
+
+// deliver sends the body to the url and reports the result to the circuit breaker, it retries with the jittered
+// exponential backoff when the server responds 5xx. The rejected requests, e.g., 4xx, are not counted as the failures
+// since the server is reachable.
+func (n *Notifier) deliver(ctx context.Context, url string, body []byte) error {
+	backoff := n.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := n.send(ctx, url, body)
+		if err == nil || (!retryable && ctx.Err() == nil) {
+			n.breaker.Report(nil)
+			return err
+		}
+
+		if !retryable || attempt >= n.maxRetries {
+			n.breaker.Report(err)
+			return err
+		}
+
+		delay := util.JitteredBackoff(backoff)
+		log.WithError(err).Warnf("webhook delivery failed, retrying in %s...", delay)
+
+		select {
+		case <-ctx.Done():
+			n.breaker.Report(err)
+			return ctx.Err()
+
+		case <-time.After(delay):
+			backoff *= 2
+		}
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, url string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// network errors are retryable unless the context is done
+		return ctx.Err() == nil, err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return true, err
+	}
+
+	if response.StatusCode >= 500 {
+		return true, fmt.Errorf("webhook server error: status %d, response: %s", response.StatusCode, response.String())
+	}
+
+	if response.IsError() {
+		return false, fmt.Errorf("webhook request error: status %d, response: %s", response.StatusCode, response.String())
+	}
+
+	return false, nil
+}
+
+// Sign signs the body with HMAC-SHA256 and returns the hex encoded signature
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isObject returns true if the argument is a struct or a pointer to struct, which will be attached as an object
+func isObject(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+
+	// time.Time is usually used as a format argument
+	switch arg.(type) {
+	case time.Time, *time.Time:
+		return false
+	}
+
+	t := reflect.TypeOf(arg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+func typeName(arg interface{}) string {
+	t := reflect.TypeOf(arg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
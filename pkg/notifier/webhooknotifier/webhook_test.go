@@ -0,0 +1,241 @@
+package webhooknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+type testObject struct {
+	Symbol string `json:"symbol"`
+}
+
+func TestNotifier_NotifyTo(t *testing.T) {
+	var requests int32
+	var payload Payload
+	var signature string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request fails with 5xx, it should be retried
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &payload))
+
+		signature = r.Header.Get(SignatureHeader)
+		assert.Equal(t, "sha256="+Sign("secret", body), signature)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New("", WithSecret("secret"), WithChannels(map[string]string{
+		"trades": server.URL,
+	}))
+	notifier.initialBackoff = time.Millisecond
+
+	notifier.NotifyTo("trades", "trade %s", "BTCUSDT", &testObject{Symbol: "BTCUSDT"})
+	notifier.Flush()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, "trades", payload.Channel)
+	assert.Equal(t, "trade BTCUSDT", payload.Text)
+	if assert.Len(t, payload.Objects, 1) {
+		assert.Equal(t, "testObject", payload.Objects[0].Type)
+	}
+}
+
+func TestNotifier_NotifyToWithFormat(t *testing.T) {
+	var payloads []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL)
+	notifier.NotifyToWithFormat(types.OutputFormatJSON, "", "trade %s", "BTCUSDT", &testObject{Symbol: "BTCUSDT"})
+	notifier.NotifyToWithFormat(types.OutputFormatJSON, "", "session %s connected", "binance")
+	notifier.Flush()
+
+	if assert.Len(t, payloads, 2) {
+		_, hasText := payloads[0]["text"]
+		assert.False(t, hasText, "the text should be omitted when the objects are attached")
+		assert.Len(t, payloads[0]["objects"], 1)
+		assert.Equal(t, "session binance connected", payloads[1]["text"])
+	}
+}
+
+func TestNotifier_NoRetryOnClientError(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL)
+	notifier.initialBackoff = time.Millisecond
+	notifier.Notify("hello")
+	notifier.Flush()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestNotifier_NonBlocking(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL, WithQueueSize(1))
+
+	// the first notification is being delivered, the second one is queued and the third one is dropped
+	done := make(chan struct{})
+	go func() {
+		notifier.Notify("1")
+		for atomic.LoadInt32(&requests) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		notifier.Notify("2")
+		notifier.Notify("3")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("NotifyTo should not block on a slow webhook server")
+	}
+
+	close(release)
+	notifier.Flush()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestNotifier_TestNotify(t *testing.T) {
+	var payload Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := New(server.URL).TestNotify(context.Background(), "test")
+	assert.EqualError(t, err, "webhook request error: status 403, response: ")
+	assert.Equal(t, "test", payload.Text)
+
+	assert.Error(t, New("").TestNotify(context.Background(), "test"), "the default url is not defined")
+}
+
+func TestNotifier_CircuitBreaker(t *testing.T) {
+	var requests int32
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL, WithMaxRetries(0), WithCircuitBreaker(2, 50*time.Millisecond))
+	notifier.initialBackoff = time.Millisecond
+
+	for i := 0; i < 4; i++ {
+		notifier.Notify("hello %d", i)
+	}
+	notifier.Flush()
+
+	// the breaker opens after 2 consecutive failures, the rest are dropped without sending
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, util.CircuitOpen, notifier.CircuitBreaker().State())
+	assert.Equal(t, int64(2), notifier.CircuitBreaker().Dropped())
+
+	assert.Equal(t, util.ErrCircuitOpen, notifier.TestNotify(context.Background(), "test"))
+
+	// the breaker half-opens after the cool-down period, and the successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, util.CircuitHalfOpen, notifier.CircuitBreaker().State())
+
+	atomic.StoreInt32(&healthy, 1)
+	assert.NoError(t, notifier.TestNotify(context.Background(), "test"))
+	assert.Equal(t, util.CircuitClosed, notifier.CircuitBreaker().State())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestNotifier_Close(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL)
+	notifier.Notify("first")
+	notifier.Notify("second")
+
+	assert.NoError(t, notifier.Close())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the queued notifications should be delivered on close")
+
+	notifier.Notify("dropped")
+	notifier.Flush()
+	assert.NoError(t, notifier.Close(), "the second close should not panic")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the notifications after close should be dropped")
+}
+
+func TestNotifier_FlushConcurrently(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(server.URL, WithQueueSize(100))
+	defer notifier.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				notifier.Notify("notification %d", j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			notifier.Flush()
+		}()
+	}
+	wg.Wait()
+
+	notifier.Flush()
+	assert.Equal(t, int32(40), atomic.LoadInt32(&requests))
+}
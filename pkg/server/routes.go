@@ -72,7 +72,9 @@ func (s *Server) newEngine() *gin.Engine {
 
 	r.GET("/api/environment/syncing", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"syncing": s.Environ.IsSyncing(),
+			"syncing":      s.Environ.IsSyncing(),
+			"lastSyncTime": s.Environ.LastSyncTime(),
+			"interrupted":  s.Environ.IsSyncInterrupted(),
 		})
 	})
 
@@ -88,6 +90,26 @@ func (s *Server) newEngine() *gin.Engine {
 		})
 	})
 
+	r.GET("/api/environment/maintenance", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"paused": s.Environ.IsPaused(),
+		})
+	})
+
+	r.POST("/api/environment/maintenance/pause", func(c *gin.Context) {
+		s.Environ.Pause()
+		c.JSON(http.StatusOK, gin.H{
+			"paused": true,
+		})
+	})
+
+	r.POST("/api/environment/maintenance/resume", func(c *gin.Context) {
+		s.Environ.Resume()
+		c.JSON(http.StatusOK, gin.H{
+			"paused": false,
+		})
+	})
+
 	r.GET("/api/trades", func(c *gin.Context) {
 		if s.Environ.TradeService == nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
@@ -479,6 +501,9 @@ func (s *Server) setupSaveConfig(c *gin.Context) {
 	if s.Environ.DatabaseService != nil {
 		envVars["DB_DRIVER"] = s.Environ.DatabaseService.Driver
 		envVars["DB_DSN"] = s.Environ.DatabaseService.DSN
+		if len(s.Environ.DatabaseService.ReplicaDSN) > 0 {
+			envVars["DB_DSN_REPLICA"] = s.Environ.DatabaseService.ReplicaDSN
+		}
 	}
 
 	dotenvFile := ".env.local"
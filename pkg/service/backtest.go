@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/datatype"
 	batch2 "github.com/c9s/bbgo/pkg/exchange/batch"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -48,6 +49,122 @@ func (s *BacktestService) SyncKLineByInterval(ctx context.Context, exchange type
 	return nil
 }
 
+// ImportKLines imports the klines in the time range [startTime, endTime) from the exchange, and returns the number of
+// the imported klines. If the stored klines cover the beginning of the range, e.g., the previous import was interrupted,
+// the import resumes from the last stored kline. The klines that are already stored are skipped.
+func (s *BacktestService) ImportKLines(ctx context.Context, exchange types.Exchange, symbol string, interval types.Interval, startTime, endTime time.Time) (int, error) {
+	first, err := s.queryKLineTimeInRange(exchange.Name(), symbol, interval, startTime, endTime, "ASC")
+	if err != nil {
+		return 0, err
+	}
+
+	if first != nil && first.StartTime.Time().Before(startTime.Add(interval.Duration())) {
+		last, err := s.queryKLineTimeInRange(exchange.Name(), symbol, interval, startTime, endTime, "DESC")
+		if err != nil {
+			return 0, err
+		}
+
+		log.Infof("resuming the %s %s kline import from %s", symbol, interval, last.EndTime.Time())
+		startTime = last.EndTime.Time().Add(time.Millisecond)
+	}
+
+	batch := &batch2.KLineBatchQuery{Exchange: exchange}
+	klineC, errC := batch.Query(ctx, symbol, interval, startTime, endTime)
+
+	var imported = 0
+	for k := range klineC {
+		if !k.StartTime.Before(endTime) {
+			continue
+		}
+
+		if len(k.Exchange) == 0 {
+			k.Exchange = exchange.Name().String()
+		}
+
+		exists, err := s.exists(k)
+		if err != nil {
+			return imported, err
+		}
+
+		if exists {
+			continue
+		}
+
+		if err := s.Insert(k); err != nil {
+			return imported, err
+		}
+
+		imported++
+	}
+
+	if err := <-errC; err != nil {
+		return imported, err
+	}
+
+	return imported, nil
+}
+
+// klineTime is the time range of the stored kline,
+// datatype.Time is used since the sqlite3 driver returns the datetime as string
+type klineTime struct {
+	StartTime datatype.Time `db:"start_time"`
+	EndTime   datatype.Time `db:"end_time"`
+}
+
+// queryKLineTimeInRange queries the first or the last kline started in the time range [startTime, endTime) by the order
+func (s *BacktestService) queryKLineTimeInRange(ex types.ExchangeName, symbol string, interval types.Interval, startTime, endTime time.Time, order string) (*klineTime, error) {
+	sql := "SELECT `start_time`, `end_time` FROM `binance_klines` WHERE `symbol` = :symbol AND `interval` = :interval AND `start_time` >= :start_time AND `start_time` < :end_time ORDER BY `start_time` " + order + " LIMIT 1"
+	sql = strings.ReplaceAll(sql, "binance_klines", ex.String()+"_klines")
+
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"symbol":     symbol,
+		"interval":   interval,
+		"start_time": startTime,
+		"end_time":   endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var t klineTime
+	if err := rows.StructScan(&t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *BacktestService) exists(kline types.KLine) (bool, error) {
+	sql := "SELECT COUNT(*) FROM `binance_klines` WHERE `symbol` = :symbol AND `interval` = :interval AND `start_time` = :start_time"
+	sql = strings.ReplaceAll(sql, "binance_klines", kline.Exchange+"_klines")
+
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"symbol":     kline.Symbol,
+		"interval":   kline.Interval,
+		"start_time": kline.StartTime,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	var count int
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return false, err
+		}
+	}
+
+	return count > 0, rows.Err()
+}
+
 func (s *BacktestService) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
 	endTime := time.Now()
 	for interval := range types.SupportedIntervals {
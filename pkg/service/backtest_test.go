@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// klineExchange serves the given klines by pages, the other exchange methods are not implemented
+type klineExchange struct {
+	types.Exchange
+
+	klines  []types.KLine
+	limit   int
+	queries int
+}
+
+func (e *klineExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *klineExchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	e.queries++
+
+	var klines []types.KLine
+	for _, k := range e.klines {
+		if k.StartTime.Before(*options.StartTime) {
+			continue
+		}
+
+		klines = append(klines, k)
+		if len(klines) == e.limit {
+			break
+		}
+	}
+
+	return klines, nil
+}
+
+func newTestKLines(startTime time.Time, interval types.Interval, n int) (klines []types.KLine) {
+	for i := 0; i < n; i++ {
+		t := startTime.Add(time.Duration(i) * interval.Duration())
+		klines = append(klines, types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  interval,
+			StartTime: t,
+			EndTime:   t.Add(interval.Duration() - time.Millisecond),
+			Open:      100.5,
+			High:      101.5,
+			Low:       99.5,
+			Close:     100.5,
+			Volume:    1.5,
+			Closed:    true,
+		})
+	}
+
+	return klines
+}
+
+func TestBacktestService_ImportKLines(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &BacktestService{DB: xdb}
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ex := &klineExchange{
+		klines: newTestKLines(startTime, types.Interval1h, 10),
+		limit:  3,
+	}
+
+	// the first import stops at the 6th kline
+	imported, err := service.ImportKLines(ctx, ex, "BTCUSDT", types.Interval1h, startTime, startTime.Add(6*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, imported)
+
+	// the second import resumes from the 7th kline
+	ex.queries = 0
+	endTime := startTime.Add(10 * time.Hour)
+	imported, err = service.ImportKLines(ctx, ex, "BTCUSDT", types.Interval1h, startTime, endTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, imported)
+	assert.Equal(t, 2, ex.queries, "the import should be resumed from the last stored kline")
+
+	// the stored klines are skipped
+	imported, err = service.ImportKLines(ctx, ex, "BTCUSDT", types.Interval1h, startTime.Add(-time.Hour), endTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, imported)
+
+	var count int
+	err = xdb.Get(&count, "SELECT COUNT(*) FROM `binance_klines`")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, count)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BalanceSnapshot is the balance of a currency in a session at the snapshot time
+type BalanceSnapshot struct {
+	GID       int64              `json:"gid" db:"gid"`
+	Session   string             `json:"session" db:"session"`
+	Exchange  types.ExchangeName `json:"exchange" db:"exchange"`
+	Currency  string             `json:"currency" db:"currency"`
+	Available fixedpoint.Value   `json:"available" db:"available"`
+	Locked    fixedpoint.Value   `json:"locked" db:"locked"`
+
+	// Price is the price of the currency in QuoteCurrency, it's zero if the price is unknown
+	Price         fixedpoint.Value `json:"price" db:"price"`
+	QuoteCurrency string           `json:"quoteCurrency" db:"quote_currency"`
+
+	// Time is the snapshot time, the balances of a session snapshot share the same time
+	Time datatype.Time `json:"time" db:"created_at"`
+}
+
+// Total returns the available and the locked balance
+func (s BalanceSnapshot) Total() fixedpoint.Value {
+	return s.Available + s.Locked
+}
+
+// QuoteValue returns the total balance valued in QuoteCurrency
+func (s BalanceSnapshot) QuoteValue() fixedpoint.Value {
+	return s.Total().Mul(s.Price)
+}
+
+// BalanceHistoryPoint is the total value of a session snapshot in the quote currency
+type BalanceHistoryPoint struct {
+	Time          time.Time        `json:"time"`
+	QuoteCurrency string           `json:"quoteCurrency"`
+	Value         fixedpoint.Value `json:"value"`
+
+	// Balances are the balances of the snapshot, including the balances without the price
+	Balances []BalanceSnapshot `json:"balances"`
+}
+
+type BalanceSnapshotService struct {
+	DB *sqlx.DB
+}
+
+// Insert inserts the balances of a session snapshot in a transaction, so that a snapshot is never stored partially
+func (s *BalanceSnapshotService) Insert(ctx context.Context, snapshots []BalanceSnapshot) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO balance_snapshots (session, exchange, currency, available, locked, price, quote_currency, created_at)
+			VALUES (:session, :exchange, :currency, :available, :locked, :price, :quote_currency, :created_at)`, snapshot)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query queries the balance snapshots of the session in the time range [since, until) ordered by the snapshot time
+func (s *BalanceSnapshotService) Query(ctx context.Context, session string, since, until time.Time) ([]BalanceSnapshot, error) {
+	rows, err := s.DB.NamedQueryContext(ctx, `
+			SELECT * FROM balance_snapshots
+			WHERE session = :session AND created_at >= :since AND created_at < :until
+			ORDER BY created_at ASC, gid ASC`, map[string]interface{}{
+		"session": session,
+		"since":   since,
+		"until":   until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var snapshots []BalanceSnapshot
+	for rows.Next() {
+		var snapshot BalanceSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return snapshots, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// QueryHistory queries the balance snapshots of the session in the time range [since, until) and sums up the
+// value of each snapshot, the balances without the price are not counted in the value.
+func (s *BalanceSnapshotService) QueryHistory(ctx context.Context, session string, since, until time.Time) ([]BalanceHistoryPoint, error) {
+	snapshots, err := s.Query(ctx, session, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []BalanceHistoryPoint
+	for _, snapshot := range snapshots {
+		t := snapshot.Time.Time()
+		if len(history) == 0 || !history[len(history)-1].Time.Equal(t) {
+			history = append(history, BalanceHistoryPoint{
+				Time:          t,
+				QuoteCurrency: snapshot.QuoteCurrency,
+			})
+		}
+
+		point := &history[len(history)-1]
+		point.Value += snapshot.QuoteValue()
+		point.Balances = append(point.Balances, snapshot)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBalanceSnapshotService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &BalanceSnapshotService{DB: xdb}
+
+	t1 := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	snapshot := func(session, currency string, available, price float64, t time.Time) BalanceSnapshot {
+		return BalanceSnapshot{
+			Session:       session,
+			Exchange:      types.ExchangeBinance,
+			Currency:      currency,
+			Available:     fixedpoint.NewFromFloat(available),
+			Locked:        fixedpoint.NewFromFloat(0.5),
+			Price:         fixedpoint.NewFromFloat(price),
+			QuoteCurrency: "USDT",
+			Time:          datatype.Time(t),
+		}
+	}
+
+	assert.NoError(t, service.Insert(ctx, []BalanceSnapshot{
+		snapshot("binance", "BTC", 0.5, 30000.0, t1),
+		snapshot("binance", "USDT", 999.5, 1.0, t1),
+		// the price of the unlisted currency is unknown
+		snapshot("binance", "XYZ", 9.5, 0, t1),
+	}))
+	assert.NoError(t, service.Insert(ctx, []BalanceSnapshot{
+		snapshot("binance", "BTC", 0.5, 32000.0, t2),
+		snapshot("binance", "USDT", 999.5, 1.0, t2),
+	}))
+	assert.NoError(t, service.Insert(ctx, []BalanceSnapshot{
+		snapshot("max", "BTC", 1.5, 30000.0, t1),
+	}))
+
+	snapshots, err := service.Query(ctx, "binance", t1, t2)
+	if assert.NoError(t, err) {
+		assert.Len(t, snapshots, 3, "the end of the time range is excluded")
+		assert.Equal(t, "BTC", snapshots[0].Currency)
+		assert.Equal(t, 1.0, snapshots[0].Total().Float64())
+	}
+
+	history, err := service.QueryHistory(ctx, "binance", t1, t2.Add(time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, history, 2) {
+		assert.True(t, t1.Equal(history[0].Time))
+		assert.Equal(t, "USDT", history[0].QuoteCurrency)
+		assert.Equal(t, 31000.0, history[0].Value.Float64())
+		assert.Len(t, history[0].Balances, 3)
+
+		assert.True(t, t2.Equal(history[1].Time))
+		assert.Equal(t, 33000.0, history[1].Value.Float64())
+	}
+
+	history, err = service.QueryHistory(ctx, "ftx", t1, t2)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
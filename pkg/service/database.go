@@ -2,22 +2,50 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/c9s/rockhopper"
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	// register the postgres driver, it's not registered by rockhopper if it's built with the no_postgres tag
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
 
 	mysqlMigrations "github.com/c9s/bbgo/pkg/migrations/mysql"
+	postgresMigrations "github.com/c9s/bbgo/pkg/migrations/postgres"
 	sqlite3Migrations "github.com/c9s/bbgo/pkg/migrations/sqlite3"
 )
 
+// the connection pool defaults of the network databases (mysql and postgres)
+const (
+	defaultMaxOpenConns    = 10
+	defaultMaxIdleConns    = 5
+	defaultConnMaxLifetime = 5 * time.Minute
+)
+
+// DefaultDatabaseConnectTimeout is the connect timeout used when the context has no deadline
+const DefaultDatabaseConnectTimeout = 30 * time.Second
+
 type DatabaseService struct {
 	Driver string
 	DSN    string
 	DB     *sqlx.DB
+
+	// ReplicaDSN is the optional DSN of the read replica, the read-only queries are routed to the replica
+	ReplicaDSN string
+
+	// ReaderDB is the connection pool of the read replica, it's the same pool as DB if the replica is not configured
+	ReaderDB *sqlx.DB
 }
 
 func NewDatabaseService(driver, dsn string) *DatabaseService {
+	return NewDatabaseServiceWithReplica(driver, dsn, "")
+}
+
+// NewDatabaseServiceWithReplica creates the database service with the read replica DSN,
+// the empty replica DSN means the reads and the writes share the primary database.
+func NewDatabaseServiceWithReplica(driver, dsn, replicaDSN string) *DatabaseService {
 	if driver == "mysql" {
 		var err error
 		dsn, err = ReformatMysqlDSN(dsn)
@@ -25,41 +53,153 @@ func NewDatabaseService(driver, dsn string) *DatabaseService {
 			// incorrect mysql dsn is logical exception
 			panic(err)
 		}
+
+		if len(replicaDSN) > 0 {
+			replicaDSN, err = ReformatMysqlDSN(replicaDSN)
+			if err != nil {
+				panic(err)
+			}
+		}
 	}
 
 	return &DatabaseService{
-		Driver: driver,
-		DSN:    dsn,
+		Driver:     driver,
+		DSN:        dsn,
+		ReplicaDSN: replicaDSN,
 	}
 
 }
 
-func (s *DatabaseService) Connect() error {
-	var err error
-	s.DB, err = sqlx.Connect(s.Driver, s.DSN)
-	return err
+// Connect opens the connection pool and pings the database to verify the connectivity,
+// DefaultDatabaseConnectTimeout is applied if the context has no deadline.
+func (s *DatabaseService) Connect(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultDatabaseConnectTimeout)
+		defer cancel()
+	}
+
+	// ConnectContext closes the connection pool if the ping fails
+	db, err := sqlx.ConnectContext(ctx, s.Driver, s.DSN)
+	if err != nil {
+		return err
+	}
+
+	s.configurePool(db)
+
+	readerDB := db
+	if len(s.ReplicaDSN) > 0 {
+		readerDB, err = sqlx.ConnectContext(ctx, s.Driver, s.ReplicaDSN)
+		if err != nil {
+			if closeErr := db.Close(); closeErr != nil {
+				log.WithError(closeErr).Error("can not close the primary database")
+			}
+
+			return fmt.Errorf("can not connect the read replica: %w", err)
+		}
+
+		s.configurePool(readerDB)
+	}
+
+	s.DB = db
+	s.ReaderDB = readerDB
+	return nil
+}
+
+func (s *DatabaseService) configurePool(db *sqlx.DB) {
+	switch s.Driver {
+	case "mysql", "postgres":
+		// the server may close the idle connections, recycle the connections before that happens
+		db.SetMaxOpenConns(defaultMaxOpenConns)
+		db.SetMaxIdleConns(defaultMaxIdleConns)
+		db.SetConnMaxLifetime(defaultConnMaxLifetime)
+
+	case "sqlite3":
+		// sqlite3 allows only one writer at a time, share one connection to avoid the "database is locked" error
+		// when the symbols are synced concurrently
+		db.SetMaxOpenConns(1)
+	}
+}
+
+// HasReplica returns true if the read-only queries are routed to a separate read replica
+func (s *DatabaseService) HasReplica() bool {
+	return s.ReaderDB != nil && s.ReaderDB != s.DB
 }
 
 func (s *DatabaseService) Close() error {
+	if s.HasReplica() {
+		if err := s.ReaderDB.Close(); err != nil {
+			log.WithError(err).Error("can not close the read replica")
+		}
+	}
+
 	return s.DB.Close()
 }
 
-func (s *DatabaseService) Upgrade(ctx context.Context) error {
-	dialect, err := rockhopper.LoadDialect(s.Driver)
-	if err != nil {
+// Optimize reclaims the unused space of the database after the records are deleted, VACUUM rebuilds the whole
+// database file of sqlite3, and the tables of the pruned records are optimized for mysql and postgres.
+func (s *DatabaseService) Optimize(ctx context.Context, tables ...string) error {
+	switch s.Driver {
+	case "sqlite3":
+		_, err := s.DB.ExecContext(ctx, `VACUUM`)
 		return err
+
+	case "mysql":
+		for _, table := range tables {
+			if _, err := s.DB.ExecContext(ctx, `OPTIMIZE TABLE `+table); err != nil {
+				return err
+			}
+		}
+
+	case "postgres":
+		for _, table := range tables {
+			if _, err := s.DB.ExecContext(ctx, `VACUUM ANALYZE `+table); err != nil {
+				return err
+			}
+		}
+
 	}
 
-	var migrations rockhopper.MigrationSlice
+	return nil
+}
+
+// SchemaVersionError is returned by CheckVersion if the schema version of the database doesn't match the latest
+// migration of the binary
+type SchemaVersionError struct {
+	Current  int64
+	Expected int64
+}
 
+func (e *SchemaVersionError) Error() string {
+	if e.Current < e.Expected {
+		return fmt.Sprintf("database schema version %d is older than the expected version %d, the migrations are not applied", e.Current, e.Expected)
+	}
+
+	return fmt.Sprintf("database schema version %d does not match the expected version %d", e.Current, e.Expected)
+}
+
+func (s *DatabaseService) migrations() rockhopper.MigrationSlice {
 	switch s.Driver {
 	case "sqlite3":
-		migrations = sqlite3Migrations.Migrations()
+		return sqlite3Migrations.Migrations()
 	case "mysql":
-		migrations = mysqlMigrations.Migrations()
+		return mysqlMigrations.Migrations()
+	case "postgres":
+		return postgresMigrations.Migrations()
 
 	}
 
+	return nil
+}
+
+func (s *DatabaseService) Upgrade(ctx context.Context) error {
+	dialect, err := rockhopper.LoadDialect(s.Driver)
+	if err != nil {
+		return err
+	}
+
+	migrations := s.migrations()
+
 	// sqlx.DB is different from sql.DB
 	rh := rockhopper.New(s.Driver, dialect, s.DB.DB)
 
@@ -75,6 +215,50 @@ func (s *DatabaseService) Upgrade(ctx context.Context) error {
 	return nil
 }
 
+// CheckVersion verifies the schema version of the database matches the latest migration of the binary without
+// running any statement that modifies the database, it's used when the migrations are applied out-of-band.
+// A *SchemaVersionError is returned if the versions don't match.
+func (s *DatabaseService) CheckVersion(ctx context.Context) error {
+	dialect, err := rockhopper.LoadDialect(s.Driver)
+	if err != nil {
+		return err
+	}
+
+	var expected int64
+	if migrations := s.migrations(); len(migrations) > 0 {
+		expected = migrations[len(migrations)-1].Version
+	}
+
+	// CurrentVersion creates the version table if it doesn't exist, read the migration records instead
+	rh := rockhopper.New(s.Driver, dialect, s.DB.DB)
+	records, err := rh.LoadMigrationRecords()
+	if err != nil {
+		return fmt.Errorf("can not load the migration records: %w", err)
+	}
+
+	// the records are in the descending order, the latest record of a version tells whether it's applied or rolled back
+	var current int64
+	var rolledBack = make(map[int64]struct{})
+	for _, record := range records {
+		if _, ok := rolledBack[record.VersionID]; ok {
+			continue
+		}
+
+		if record.IsApplied {
+			current = record.VersionID
+			break
+		}
+
+		rolledBack[record.VersionID] = struct{}{}
+	}
+
+	if current != expected {
+		return &SchemaVersionError{Current: current, Expected: expected}
+	}
+
+	return nil
+}
+
 func ReformatMysqlDSN(dsn string) (string, error) {
 	config, err := mysql.ParseDSN(dsn)
 	if err != nil {
@@ -42,3 +42,17 @@ func prepareDB(t *testing.T) (*rockhopper.DB, error) {
 
 	return db, err
 }
+
+func TestPostgresMigrations(t *testing.T) {
+	var loader rockhopper.SqlMigrationLoader
+	migrations, err := loader.Load("../../migrations/postgres")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NotEmpty(t, migrations)
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.UpStatements, m.Source)
+		assert.NotEmpty(t, m.DownStatements, m.Source)
+	}
+}
@@ -15,6 +15,10 @@ type DepositService struct {
 
 // Sync syncs the withdraw records into db
 func (s *DepositService) Sync(ctx context.Context, ex types.Exchange) error {
+	return s.sync(ctx, ex, nil)
+}
+
+func (s *DepositService) sync(ctx context.Context, ex types.Exchange, progress progressFunc) error {
 	txnIDs := map[string]struct{}{}
 
 	// query descending
@@ -43,7 +47,9 @@ func (s *DepositService) Sync(ctx context.Context, ex types.Exchange) error {
 		return err
 	}
 
-	for _, deposit := range deposits {
+	for idx, deposit := range deposits {
+		progress.report(idx + 1)
+
 		if _, exists := txnIDs[deposit.TransactionID]; exists {
 			continue
 		}
@@ -57,7 +63,7 @@ func (s *DepositService) Sync(ctx context.Context, ex types.Exchange) error {
 }
 
 func (s *DepositService) QueryLast(ex types.ExchangeName, limit int) ([]types.Deposit, error) {
-	sql := "SELECT * FROM `deposits` WHERE `exchange` = :exchange ORDER BY `time` DESC LIMIT :limit"
+	sql := "SELECT * FROM deposits WHERE exchange = :exchange ORDER BY time DESC LIMIT :limit"
 	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
 		"exchange": ex,
 		"limit":    limit,
@@ -74,7 +80,33 @@ func (s *DepositService) Query(exchangeName types.ExchangeName) ([]types.Deposit
 	args := map[string]interface{}{
 		"exchange": exchangeName,
 	}
-	sql := "SELECT * FROM `deposits` WHERE `exchange` = :exchange ORDER BY `time` ASC"
+	sql := "SELECT * FROM deposits WHERE exchange = :exchange ORDER BY time ASC"
+	rows, err := s.DB.NamedQuery(sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QuerySince queries the deposits of the exchange since the given time, the empty asset means all assets.
+// The deposits are stored by the exchange name, not by the session, so the records of all the accounts on the
+// exchange are returned.
+func (s *DepositService) QuerySince(exchangeName types.ExchangeName, asset string, since time.Time) ([]types.Deposit, error) {
+	args := map[string]interface{}{
+		"exchange": exchangeName,
+		"asset":    asset,
+		"since":    since,
+	}
+
+	sql := "SELECT * FROM deposits WHERE exchange = :exchange AND time >= :since"
+	if len(asset) > 0 {
+		sql += " AND asset = :asset"
+	}
+	sql += " ORDER BY time ASC"
+
 	rows, err := s.DB.NamedQuery(sql, args)
 	if err != nil {
 		return nil, err
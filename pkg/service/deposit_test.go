@@ -36,4 +36,12 @@ func TestDepositService(t *testing.T) {
 	deposits, err := service.Query(types.ExchangeMax)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, deposits)
+
+	deposits, err = service.QuerySince(types.ExchangeMax, "BTC", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, deposits, 1)
+
+	deposits, err = service.QuerySince(types.ExchangeMax, "ETH", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, deposits)
 }
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// csvTimeFormat is the time format of the exported csv files, the times are converted to UTC
+const csvTimeFormat = time.RFC3339
+
+var tradeCSVHeader = []string{
+	"exchange", "symbol", "id", "order_id", "side", "price", "quantity", "quote_quantity",
+	"fee", "fee_currency", "is_maker", "is_margin", "is_isolated", "traded_at",
+}
+
+var orderCSVHeader = []string{
+	"exchange", "symbol", "order_id", "client_order_id", "side", "order_type", "status", "price", "stop_price",
+	"quantity", "executed_quantity", "time_in_force", "is_margin", "is_isolated", "created_at", "updated_at",
+}
+
+// ExportCSVOptions selects the records to export
+type ExportCSVOptions struct {
+	Exchange types.ExchangeName
+
+	// Symbol is the symbol to export, all symbols are exported if it's empty
+	Symbol string
+
+	IsMargin   bool
+	IsIsolated bool
+
+	// Since and Until is the time range [Since, Until) of the records, the zero Until means no upper bound
+	Since time.Time
+	Until time.Time
+}
+
+// whereSQL returns the where clause and the named arguments of the options, the time column is filtered by the time range
+func (options ExportCSVOptions) whereSQL(timeColumn string) (string, map[string]interface{}) {
+	where := []string{
+		"exchange = :exchange",
+		"is_margin = :is_margin",
+		"is_isolated = :is_isolated",
+		timeColumn + " >= :since",
+	}
+
+	args := map[string]interface{}{
+		"exchange":    options.Exchange,
+		"is_margin":   options.IsMargin,
+		"is_isolated": options.IsIsolated,
+		"since":       options.Since,
+	}
+
+	if len(options.Symbol) > 0 {
+		where = append(where, "symbol = :symbol")
+		args["symbol"] = options.Symbol
+	}
+
+	if !options.Until.IsZero() {
+		where = append(where, timeColumn+" < :until")
+		args["until"] = options.Until
+	}
+
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// ExportCSV writes the trades as csv rows ordered by the trade time, the rows are written while scanning,
+// so that the large history is not loaded into the memory.
+func (s *TradeService) ExportCSV(w io.Writer, options ExportCSVOptions) error {
+	where, args := options.whereSQL("traded_at")
+	rows, err := s.reader().NamedQuery(`SELECT * FROM trades`+where+` ORDER BY traded_at ASC, gid ASC`, args)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(tradeCSVHeader); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var trade types.Trade
+		if err := rows.StructScan(&trade); err != nil {
+			return err
+		}
+
+		if err := writer.Write([]string{
+			trade.Exchange,
+			trade.Symbol,
+			strconv.FormatInt(trade.ID, 10),
+			strconv.FormatUint(trade.OrderID, 10),
+			string(trade.Side),
+			formatCSVFloat(trade.Price),
+			formatCSVFloat(trade.Quantity),
+			formatCSVFloat(trade.QuoteQuantity),
+			formatCSVFloat(trade.Fee),
+			trade.FeeCurrency,
+			strconv.FormatBool(trade.IsMaker),
+			strconv.FormatBool(trade.IsMargin),
+			strconv.FormatBool(trade.IsIsolated),
+			trade.Time.Time().UTC().Format(csvTimeFormat),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportCSV writes the orders as csv rows ordered by the creation time, the rows are written while scanning,
+// so that the large history is not loaded into the memory.
+func (s *OrderService) ExportCSV(w io.Writer, options ExportCSVOptions) error {
+	where, args := options.whereSQL("created_at")
+	rows, err := s.reader().NamedQuery(`SELECT * FROM orders`+where+` ORDER BY created_at ASC, gid ASC`, args)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(orderCSVHeader); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var order types.Order
+		if err := rows.StructScan(&order); err != nil {
+			return err
+		}
+
+		if err := writer.Write([]string{
+			order.Exchange,
+			order.Symbol,
+			strconv.FormatUint(order.OrderID, 10),
+			order.ClientOrderID,
+			string(order.Side),
+			string(order.Type),
+			string(order.Status),
+			formatCSVFloat(order.Price),
+			formatCSVFloat(order.StopPrice),
+			formatCSVFloat(order.Quantity),
+			formatCSVFloat(order.ExecutedQuantity),
+			order.TimeInForce,
+			strconv.FormatBool(order.IsMargin),
+			strconv.FormatBool(order.IsIsolated),
+			order.CreationTime.Time().UTC().Format(csvTimeFormat),
+			order.UpdateTime.Time().UTC().Format(csvTimeFormat),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTradeService_ExportCSV(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &TradeService{DB: xdb}
+
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, trade := range []types.Trade{
+		{ID: 3, Symbol: "ETHUSDT", Side: types.SideTypeSell, Price: 2500.5, Quantity: 0.5, Fee: 1.25, FeeCurrency: "USDT", Time: datatype.Time(since.Add(2 * time.Hour))},
+		{ID: 1, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 35000.5, Quantity: 0.01, Fee: 0.00001, FeeCurrency: "BTC", IsMaker: true, Time: datatype.Time(since.Add(time.Hour))},
+		// the trade before the since time
+		{ID: 2, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 34000.5, Quantity: 0.01, Time: datatype.Time(since.Add(-time.Hour))},
+		// the margin trade
+		{ID: 4, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 34000.5, Quantity: 0.01, IsMargin: true, Time: datatype.Time(since.Add(time.Hour))},
+	} {
+		trade.Exchange = string(types.ExchangeBinance)
+		trade.OrderID = uint64(100 + i)
+		trade.QuoteQuantity = trade.Price * trade.Quantity
+		assert.NoError(t, service.Insert(trade))
+	}
+
+	var buf bytes.Buffer
+	err = service.ExportCSV(&buf, ExportCSVOptions{Exchange: types.ExchangeBinance, Since: since})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "exchange,symbol,id,order_id,side,price,quantity,quote_quantity,fee,fee_currency,is_maker,is_margin,is_isolated,traded_at\n"+
+			"binance,BTCUSDT,1,101,BUY,35000.5,0.01,350.005,0.00001,BTC,true,false,false,2021-06-01T01:00:00Z\n"+
+			"binance,ETHUSDT,3,100,SELL,2500.5,0.5,1250.25,1.25,USDT,false,false,false,2021-06-01T02:00:00Z\n", buf.String())
+	}
+
+	buf.Reset()
+	err = service.ExportCSV(&buf, ExportCSVOptions{Exchange: types.ExchangeBinance, Symbol: "ETHUSDT", Since: since, Until: since.Add(2 * time.Hour)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "exchange,symbol,id,order_id,side,price,quantity,quote_quantity,fee,fee_currency,is_maker,is_margin,is_isolated,traded_at\n", buf.String())
+	}
+}
+
+func TestOrderService_ExportCSV(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &OrderService{DB: xdb}
+
+	createdAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, service.Insert(types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: "client-1",
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			Type:          types.OrderTypeLimit,
+			Quantity:      0.01,
+			Price:         30000.5,
+			TimeInForce:   "GTC",
+		},
+		Exchange:         string(types.ExchangeBinance),
+		OrderID:          1,
+		Status:           types.OrderStatusFilled,
+		ExecutedQuantity: 0.01,
+		CreationTime:     datatype.Time(createdAt),
+		UpdateTime:       datatype.Time(createdAt.Add(time.Minute)),
+	}))
+
+	var buf bytes.Buffer
+	err = service.ExportCSV(&buf, ExportCSVOptions{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "exchange,symbol,order_id,client_order_id,side,order_type,status,price,stop_price,quantity,executed_quantity,time_in_force,is_margin,is_isolated,created_at,updated_at\n"+
+			"binance,BTCUSDT,1,client-1,BUY,LIMIT,FILLED,30000.5,0,0.01,0.01,GTC,false,false,2021-06-01T00:00:00Z,2021-06-01T00:01:00Z\n", buf.String())
+	}
+}
@@ -16,73 +16,115 @@ import (
 
 type OrderService struct {
 	DB *sqlx.DB
+
+	// ReaderDB is the optional connection pool of the read replica, the read-only queries use it if it's set
+	ReaderDB *sqlx.DB
+}
+
+// reader returns the connection pool for the read-only queries
+func (s *OrderService) reader() *sqlx.DB {
+	if s.ReaderDB != nil {
+		return s.ReaderDB
+	}
+
+	return s.DB
 }
 
 func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
+	_, err := s.sync(ctx, exchange, symbol, startTime, 0, DefaultSyncWindows[exchange.Name()], nil, log.WithField("symbol", symbol))
+	return err
+}
+
+// sync syncs the closed orders after the given order ID or the last stored order, whichever is greater,
+// the time range is queried in the windows of the given size if the window is not zero.
+// The ID of the last synced order is returned, and the logs are written with the fields of the given logger.
+func (s *OrderService) sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time, lastID uint64, window time.Duration, progress progressFunc, logger *log.Entry) (uint64, error) {
 	isMargin := false
 	isIsolated := false
 	if marginExchange, ok := exchange.(types.MarginExchange); ok {
 		marginSettings := marginExchange.GetMarginSettings()
 		isMargin = marginSettings.IsMargin
 		isIsolated = marginSettings.IsIsolatedMargin
-		if marginSettings.IsIsolatedMargin {
+		if marginSettings.IsIsolatedMargin && !marginSettings.IsIsolatedMarginSymbol(symbol) {
 			symbol = marginSettings.IsolatedMarginSymbol
 		}
 	}
 
-	records, err := s.QueryLast(exchange.Name(), symbol, isMargin, isIsolated, 50)
+	// the primary database is queried since the replica may lag behind the inserts
+	records, err := s.queryLast(s.DB, exchange.Name(), symbol, isMargin, isIsolated, 50)
 	if err != nil {
-		return err
+		return lastID, err
 	}
 
 	orderKeys := make(map[uint64]struct{})
 
-	var lastID uint64 = 0
 	if len(records) > 0 {
 		for _, record := range records {
 			orderKeys[record.OrderID] = struct{}{}
 		}
 
-		lastID = records[0].OrderID
+		if records[0].OrderID > lastID {
+			lastID = records[0].OrderID
+		}
+
 		startTime = records[0].CreationTime.Time()
 	}
 
-	b := &batch.ClosedOrderBatchQuery{Exchange: exchange}
+	b := &batch.ClosedOrderBatchQuery{Exchange: exchange, MaxWindow: window}
 	ordersC, errC := b.Query(ctx, symbol, startTime, time.Now(), lastID)
+	var fetched, inserted = 0, 0
 	for order := range ordersC {
 		select {
 
 		case <-ctx.Done():
-			return ctx.Err()
+			return lastID, ctx.Err()
 
 		case err := <-errC:
 			if err != nil {
-				return err
+				return lastID, err
 			}
 
 		default:
 
 		}
 
+		fetched++
+		progress.report(fetched)
+
+		if order.OrderID > lastID {
+			lastID = order.OrderID
+		}
+
 		if _, exists := orderKeys[order.OrderID]; exists {
 			continue
 		}
 
 		if err := s.Insert(order); err != nil {
-			return err
+			return lastID, err
 		}
+
+		inserted++
 	}
 
-	return <-errC
+	if err := <-errC; err != nil {
+		return lastID, err
+	}
+
+	logger.Infof("%s %s orders synced: %d fetched, %d inserted", exchange.Name(), symbol, fetched, inserted)
+	return lastID, nil
 }
 
 
 // QueryLast queries the last order from the database
 func (s *OrderService) QueryLast(ex types.ExchangeName, symbol string, isMargin, isIsolated bool, limit int) ([]types.Order, error) {
+	return s.queryLast(s.reader(), ex, symbol, isMargin, isIsolated, limit)
+}
+
+func (s *OrderService) queryLast(db *sqlx.DB, ex types.ExchangeName, symbol string, isMargin, isIsolated bool, limit int) ([]types.Order, error) {
 	log.Infof("querying last order exchange = %s AND symbol = %s AND is_margin = %v AND is_isolated = %v", ex, symbol, isMargin, isIsolated)
 
 	sql := `SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated ORDER BY gid DESC LIMIT :limit`
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+	rows, err := db.NamedQuery(sql, map[string]interface{}{
 		"exchange":    ex,
 		"symbol":      symbol,
 		"is_margin":   isMargin,
@@ -98,6 +140,44 @@ func (s *OrderService) QueryLast(ex types.ExchangeName, symbol string, isMargin,
 	return s.scanRows(rows)
 }
 
+// QueryByStatus queries the orders of the symbol with the given status created since the given time, ordered by the creation time
+func (s *OrderService) QueryByStatus(ex types.ExchangeName, symbol string, isMargin, isIsolated bool, status types.OrderStatus, since time.Time) ([]types.Order, error) {
+	sql := `SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND status = :status AND created_at >= :since AND is_margin = :is_margin AND is_isolated = :is_isolated ORDER BY created_at ASC, gid ASC`
+	rows, err := s.reader().NamedQuery(sql, map[string]interface{}{
+		"exchange":    ex,
+		"symbol":      symbol,
+		"status":      status,
+		"since":       since,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "query orders by status error")
+	}
+
+	defer rows.Close()
+	return s.scanRows(rows)
+}
+
+// DeleteBefore deletes the orders of the exchange symbol created before the given time in batches,
+// the margin settings of the exchange are applied as the sync does. The number of the deleted orders is returned.
+func (s *OrderService) DeleteBefore(ctx context.Context, exchange types.Exchange, symbol string, until time.Time) (int64, error) {
+	symbol, isMargin, isIsolated := tradeQueryFlags(exchange, symbol)
+	deleted, err := deleteInBatches(ctx, s.DB, "orders", `exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated AND created_at < :until`, map[string]interface{}{
+		"exchange":    exchange.Name(),
+		"symbol":      symbol,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+		"until":       until,
+	})
+	if err != nil {
+		return deleted, errors.Wrap(err, "delete orders error")
+	}
+
+	return deleted, nil
+}
+
 type AggOrder struct {
 	types.Order
 	AveragePrice *float64 `json:"averagePrice" db:"average_price"`
@@ -113,7 +193,7 @@ type QueryOrdersOptions struct {
 func (s *OrderService) Query(options QueryOrdersOptions) ([]AggOrder, error) {
 	sql := genOrderSQL(options)
 
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+	rows, err := s.reader().NamedQuery(sql, map[string]interface{}{
 		"exchange": options.Exchange,
 		"symbol":   options.Symbol,
 		"gid":      options.LastGID,
@@ -139,21 +219,22 @@ func genOrderSQL(options QueryOrdersOptions) string {
 	if options.LastGID > 0 {
 		switch ordering {
 		case "ASC":
-			where = append(where, "gid > :gid")
+			where = append(where, "orders.gid > :gid")
 		case "DESC":
-			where = append(where, "gid < :gid")
+			where = append(where, "orders.gid < :gid")
 
 		}
 	}
 
 	if len(options.Exchange) > 0 {
-		where = append(where, "exchange = :exchange")
+		where = append(where, "orders.exchange = :exchange")
 	}
 	if len(options.Symbol) > 0 {
-		where = append(where, "symbol = :symbol")
+		where = append(where, "orders.symbol = :symbol")
 	}
 
-	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
+	// COALESCE is supported by mysql, sqlite3 and postgres, IFNULL is not supported by postgres
+	sql := `SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
 		` LEFT JOIN trades AS t ON (t.order_id = orders.order_id)`
 	if len(where) > 0 {
 		sql += ` WHERE ` + strings.Join(where, " AND ")
@@ -193,12 +274,20 @@ func (s *OrderService) scanRows(rows *sqlx.Rows) (orders []types.Order, err erro
 }
 
 func (s *OrderService) Insert(order types.Order) (err error) {
-	if s.DB.DriverName() == "mysql" {
+	switch s.DB.DriverName() {
+	case "mysql":
 		_, err = s.DB.NamedExec(`
 			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_isolated)
 			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_isolated)
 			ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at`, order)
 		return err
+
+	case "postgres":
+		_, err = s.DB.NamedExec(`
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_isolated)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_isolated)
+			ON CONFLICT (order_id, exchange) DO UPDATE SET status=EXCLUDED.status, executed_quantity=EXCLUDED.executed_quantity, is_working=EXCLUDED.is_working, updated_at=EXCLUDED.updated_at`, order)
+		return err
 	}
 
 	_, err = s.DB.NamedExec(`
@@ -1,24 +1,92 @@
 package service
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func Test_genOrderSQL(t *testing.T) {
 	t.Run("accept empty options", func(t *testing.T) {
 		o := QueryOrdersOptions{}
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 	})
 
 	t.Run("different ordering ", func(t *testing.T) {
 		o := QueryOrdersOptions{}
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 		o.Ordering = "ASC"
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 		o.Ordering = "DESC"
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 500", genOrderSQL(o))
 	})
 
 }
+
+func TestOrderService_QueryByStatus(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &OrderService{DB: xdb}
+
+	now := time.Now().UTC()
+	newOrder := func(orderID uint64, status types.OrderStatus, createdAt time.Time) types.Order {
+		return types.Order{
+			SubmitOrder: types.SubmitOrder{
+				Symbol:      "BTCUSDT",
+				Side:        types.SideTypeBuy,
+				Type:        types.OrderTypeLimit,
+				Quantity:    0.01,
+				Price:       30000.5,
+				TimeInForce: "GTC",
+			},
+			Exchange:     string(types.ExchangeBinance),
+			OrderID:      orderID,
+			Status:       status,
+			CreationTime: datatype.Time(createdAt),
+			UpdateTime:   datatype.Time(createdAt),
+		}
+	}
+
+	assert.NoError(t, service.Insert(newOrder(1, types.OrderStatusNew, now.Add(-2*time.Hour))))
+	assert.NoError(t, service.Insert(newOrder(2, types.OrderStatusNew, now.Add(-30*time.Minute))))
+	assert.NoError(t, service.Insert(newOrder(3, types.OrderStatusFilled, now.Add(-20*time.Minute))))
+	assert.NoError(t, service.Insert(newOrder(4, types.OrderStatusNew, now.Add(-10*time.Minute))))
+
+	orders, err := service.QueryByStatus(types.ExchangeBinance, "BTCUSDT", false, false, types.OrderStatusNew, now.Add(-time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, orders, 2) {
+		assert.Equal(t, uint64(2), orders[0].OrderID)
+		assert.Equal(t, uint64(4), orders[1].OrderID)
+	}
+
+	orders, err = service.QueryByStatus(types.ExchangeBinance, "BTCUSDT", true, false, types.OrderStatusNew, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, orders, "the margin orders should be queried separately")
+
+	orders, err = service.QueryLast(types.ExchangeBinance, "BTCUSDT", false, false, 2)
+	if assert.NoError(t, err) && assert.Len(t, orders, 2) {
+		assert.Equal(t, uint64(4), orders[0].OrderID)
+		assert.Equal(t, uint64(3), orders[1].OrderID)
+	}
+
+	deleted, err := service.DeleteBefore(context.Background(), &tradeExchange{}, "BTCUSDT", now.Add(-25*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	orders, err = service.QueryLast(types.ExchangeBinance, "BTCUSDT", false, false, 10)
+	if assert.NoError(t, err) && assert.Len(t, orders, 2) {
+		assert.Equal(t, uint64(4), orders[0].OrderID)
+		assert.Equal(t, uint64(3), orders[1].OrderID)
+	}
+}
@@ -11,12 +11,45 @@ type Store interface {
 }
 
 type RedisPersistenceConfig struct {
-	Host     string `yaml:"host" json:"host" env:"REDIS_HOST"`
-	Port     string `yaml:"port" json:"port" env:"REDIS_PORT"`
+	Host string `yaml:"host" json:"host" env:"REDIS_HOST"`
+	Port string `yaml:"port" json:"port" env:"REDIS_PORT"`
+
+	// Username is the ACL username of redis 6, the default user is used if it's not set
+	Username string `yaml:"username,omitempty" json:"username,omitempty" env:"REDIS_USERNAME"`
 	Password string `yaml:"password,omitempty" json:"password,omitempty" env:"REDIS_PASSWORD"`
 	DB       int    `yaml:"db" json:"db" env:"REDIS_DB"`
+
+	// TLS connects to the redis server with TLS, it's enabled if CACert is set
+	TLS bool `yaml:"tls,omitempty" json:"tls,omitempty" env:"REDIS_TLS"`
+
+	// CACert is the path of the PEM encoded CA certificates to verify the server, the system roots are used if it's not set
+	CACert string `yaml:"caCert,omitempty" json:"caCert,omitempty" env:"REDIS_CA_CERT"`
+
+	// ServerName is the server name to verify the certificate, defaults to Host
+	ServerName string `yaml:"serverName,omitempty" json:"serverName,omitempty" env:"REDIS_TLS_SERVER_NAME"`
+
+	// InsecureSkipVerify skips the verification of the server certificate, it's only for testing
+	InsecureSkipVerify bool `yaml:"tlsSkipVerify,omitempty" json:"tlsSkipVerify,omitempty" env:"REDIS_TLS_SKIP_VERIFY"`
 }
 
 type JsonPersistenceConfig struct {
 	Directory string `yaml:"directory" json:"directory"`
 }
+
+type BoltPersistenceConfig struct {
+	// File is the path of the BoltDB database file
+	File string `yaml:"file" json:"file"`
+}
+
+type DynamoDBPersistenceConfig struct {
+	// Table is the table name, the table has the string partition key "namespace" and the string sort key "id"
+	Table  string `yaml:"table" json:"table" env:"DYNAMODB_TABLE"`
+	Region string `yaml:"region" json:"region" env:"AWS_REGION"`
+
+	// Endpoint overrides the regional endpoint, e.g., http://localhost:8000 for DynamoDB local
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty" env:"DYNAMODB_ENDPOINT"`
+
+	AccessKeyID     string `yaml:"accessKeyID,omitempty" json:"accessKeyID,omitempty" env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secretAccessKey,omitempty" json:"secretAccessKey,omitempty" env:"AWS_SECRET_ACCESS_KEY"`
+	SessionToken    string `yaml:"sessionToken,omitempty" json:"sessionToken,omitempty" env:"AWS_SESSION_TOKEN"`
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucketName = []byte("persistence")
+
+type BoltPersistenceService struct {
+	db *bolt.DB
+}
+
+func NewBoltPersistenceService(config *BoltPersistenceConfig) (*BoltPersistenceService, error) {
+	// the file is locked by the opened db, fail fast if another process is holding it
+	db, err := bolt.Open(config.File, 0666, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltPersistenceService{
+		db: db,
+	}, nil
+}
+
+func (s *BoltPersistenceService) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltPersistenceService) NewStore(id string, subIDs ...string) Store {
+	if len(subIDs) > 0 {
+		id += ":" + strings.Join(subIDs, ":")
+	}
+
+	return &BoltStore{
+		db: s.db,
+		ID: id,
+	}
+}
+
+// Keys lists the keys of the persistence bucket, the keys are split by ":" into the store ids and the sub ids like
+// NewStore joins them
+func (s *BoltPersistenceService) Keys() (keys []PersistenceKey, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucketName).ForEach(func(k, v []byte) error {
+			keys = append(keys, splitPersistenceKey(string(k)))
+			return nil
+		})
+	})
+
+	return keys, err
+}
+
+type BoltStore struct {
+	db *bolt.DB
+
+	ID string
+}
+
+func (store *BoltStore) Load(val interface{}) error {
+	var data []byte
+	err := store.db.View(func(tx *bolt.Tx) error {
+		// the value is only valid in the transaction, copy it out
+		data = append(data, tx.Bucket(boltBucketName).Get([]byte(store.ID))...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return ErrPersistenceNotExists
+	}
+
+	return json.Unmarshal(data, val)
+}
+
+func (store *BoltStore) Save(val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucketName).Put([]byte(store.ID), data)
+	})
+}
+
+func (store *BoltStore) Reset() error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucketName).Delete([]byte(store.ID))
+	})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestBoltPersistenceService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	boltService, err := NewBoltPersistenceService(&BoltPersistenceConfig{
+		File: filepath.Join(dir, "bbgo.db"),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer boltService.Close()
+
+	store := boltService.NewStore("bbgo", "test")
+	assert.NotNil(t, store)
+
+	var fp fixedpoint.Value
+	err = store.Load(&fp)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+
+	fp = fixedpoint.NewFromFloat(3.1415)
+	err = store.Save(&fp)
+	assert.NoError(t, err, "should store value without error")
+
+	var fp2 fixedpoint.Value
+	err = store.Load(&fp2)
+	assert.NoError(t, err, "should load value without error")
+	assert.Equal(t, fp, fp2)
+
+	// the stores of different sub IDs should not share the value
+	var fp3 fixedpoint.Value
+	err = boltService.NewStore("bbgo", "test2").Load(&fp3)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+
+	err = store.Reset()
+	assert.NoError(t, err)
+
+	err = store.Load(&fp2)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+}
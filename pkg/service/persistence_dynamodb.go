@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const (
+	dynamoDBService       = "dynamodb"
+	dynamoDBTargetPrefix  = "DynamoDB_20120810."
+	dynamoDBContentType   = "application/x-amz-json-1.0"
+	dynamoDBTimeout       = 10 * time.Second
+	dynamoDBDefaultItemID = "default"
+
+	awsSigningAlgorithm = "AWS4-HMAC-SHA256"
+	awsTimeFormat       = "20060102T150405Z"
+	awsDateFormat       = "20060102"
+)
+
+// DynamoDBPersistenceService stores the values in a single DynamoDB table, the store id is the partition key "namespace"
+// and the sub ids are joined as the sort key "id", the value is stored as the JSON string attribute "value".
+//
+// The table should be created with the string partition key "namespace" and the string sort key "id".
+// Only the static credentials are supported, they're read from the config or the AWS_* env vars.
+type DynamoDBPersistenceService struct {
+	config   DynamoDBPersistenceConfig
+	endpoint string
+	client   *http.Client
+}
+
+func NewDynamoDBPersistenceService(config *DynamoDBPersistenceConfig) *DynamoDBPersistenceService {
+	endpoint := config.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = fmt.Sprintf("https://dynamodb.%s.amazonaws.com", config.Region)
+	}
+
+	return &DynamoDBPersistenceService{
+		config:   *config,
+		endpoint: strings.TrimRight(endpoint, "/") + "/",
+		client:   &http.Client{Timeout: dynamoDBTimeout},
+	}
+}
+
+func (s *DynamoDBPersistenceService) NewStore(id string, subIDs ...string) Store {
+	itemID := strings.Join(subIDs, ":")
+	if len(itemID) == 0 {
+		// the key attributes can not be empty
+		itemID = dynamoDBDefaultItemID
+	}
+
+	return &DynamoDBStore{
+		service:   s,
+		Namespace: id,
+		ID:        itemID,
+	}
+}
+
+// dynamoDBError is the error response of the DynamoDB API
+type dynamoDBError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+type dynamoDBAttribute struct {
+	S string `json:"S"`
+}
+
+// call sends the DynamoDB API request of the action and decodes the response into the output
+func (s *DynamoDBPersistenceService) call(ctx context.Context, action string, input, output interface{}) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", dynamoDBContentType)
+	req.Header.Set("X-Amz-Target", dynamoDBTargetPrefix+action)
+	if len(s.config.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	signAWSRequest(req, payload, s.config.AccessKeyID, s.config.SecretAccessKey, s.config.Region, dynamoDBService, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if response.IsError() {
+		var apiErr dynamoDBError
+		if err := response.DecodeJSON(&apiErr); err != nil || len(apiErr.Type) == 0 {
+			return fmt.Errorf("dynamodb %s error: status %d, response: %s", action, response.StatusCode, response.String())
+		}
+
+		// the error type is prefixed with the service namespace, e.g., com.amazonaws.dynamodb.v20120810#ResourceNotFoundException
+		errType := apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+		return fmt.Errorf("dynamodb %s error: %s: %s", action, errType, apiErr.Message)
+	}
+
+	if output == nil {
+		return nil
+	}
+
+	return response.DecodeJSON(output)
+}
+
+// Keys scans the keys of the table page by page, the store id is the namespace and the sub ids are split from the id
+func (s *DynamoDBPersistenceService) Keys() (keys []PersistenceKey, err error) {
+	var startKey map[string]dynamoDBAttribute
+	for {
+		input := map[string]interface{}{
+			"TableName":                s.config.Table,
+			"ProjectionExpression":     "#namespace, #id",
+			"ExpressionAttributeNames": map[string]string{"#namespace": "namespace", "#id": "id"},
+		}
+
+		if startKey != nil {
+			input["ExclusiveStartKey"] = startKey
+		}
+
+		var output struct {
+			Items            []map[string]dynamoDBAttribute `json:"Items"`
+			LastEvaluatedKey map[string]dynamoDBAttribute   `json:"LastEvaluatedKey"`
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), dynamoDBTimeout)
+		err := s.call(ctx, "Scan", input, &output)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range output.Items {
+			key := PersistenceKey{ID: item["namespace"].S}
+			if id := item["id"].S; id != dynamoDBDefaultItemID {
+				key.SubIDs = strings.Split(id, ":")
+			}
+
+			keys = append(keys, key)
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return keys, nil
+		}
+
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+type DynamoDBStore struct {
+	service *DynamoDBPersistenceService
+
+	Namespace string
+	ID        string
+}
+
+func (store *DynamoDBStore) key() map[string]dynamoDBAttribute {
+	return map[string]dynamoDBAttribute{
+		"namespace": {S: store.Namespace},
+		"id":        {S: store.ID},
+	}
+}
+
+func (store *DynamoDBStore) Load(val interface{}) error {
+	input := map[string]interface{}{
+		"TableName":      store.service.config.Table,
+		"Key":            store.key(),
+		"ConsistentRead": true,
+	}
+
+	var output struct {
+		Item map[string]dynamoDBAttribute `json:"Item"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoDBTimeout)
+	defer cancel()
+
+	if err := store.service.call(ctx, "GetItem", input, &output); err != nil {
+		return err
+	}
+
+	value, ok := output.Item["value"]
+	if !ok || len(value.S) == 0 {
+		return ErrPersistenceNotExists
+	}
+
+	return json.Unmarshal([]byte(value.S), val)
+}
+
+func (store *DynamoDBStore) Save(val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	item := store.key()
+	item["value"] = dynamoDBAttribute{S: string(data)}
+
+	input := map[string]interface{}{
+		"TableName": store.service.config.Table,
+		"Item":      item,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoDBTimeout)
+	defer cancel()
+
+	return store.service.call(ctx, "PutItem", input, nil)
+}
+
+func (store *DynamoDBStore) Reset() error {
+	input := map[string]interface{}{
+		"TableName": store.service.config.Table,
+		"Key":       store.key(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoDBTimeout)
+	defer cancel()
+
+	return store.service.call(ctx, "DeleteItem", input, nil)
+}
+
+// signAWSRequest signs the request with the AWS signature version 4, all the headers of the request and the host are signed
+func signAWSRequest(req *http.Request, payload []byte, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(awsTimeFormat)
+	date := now.Format(awsDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQueryString(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		awsSigningAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigningAlgorithm, accessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQueryString(query url.Values) string {
+	var keys []string
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, awsURIEscape(key)+"="+awsURIEscape(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEscape escapes the string as the AWS signature requires, the space is escaped as %20 instead of +
+func awsURIEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
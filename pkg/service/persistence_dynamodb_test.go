@@ -0,0 +1,131 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAWSRequest(t *testing.T) {
+	// the get-vanilla case of the AWS signature version 4 test suite
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signAWSRequest(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
+
+// testDynamoDBServer is an in-memory DynamoDB server which handles the item actions
+func testDynamoDBServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	items := make(map[string]map[string]dynamoDBAttribute)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, dynamoDBContentType, r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+
+		var input struct {
+			TableName string                       `json:"TableName"`
+			Key       map[string]dynamoDBAttribute `json:"Key"`
+			Item      map[string]dynamoDBAttribute `json:"Item"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&input)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", dynamoDBContentType)
+		if input.TableName != "bbgo" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), dynamoDBTargetPrefix) {
+		case "GetItem":
+			key := input.Key["namespace"].S + "/" + input.Key["id"].S
+			if item, ok := items[key]; ok {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"Item": item})
+				return
+			}
+
+		case "PutItem":
+			items[input.Item["namespace"].S+"/"+input.Item["id"].S] = input.Item
+
+		case "DeleteItem":
+			delete(items, input.Key["namespace"].S+"/"+input.Key["id"].S)
+
+		case "Scan":
+			var scanned []map[string]dynamoDBAttribute
+			for _, item := range items {
+				scanned = append(scanned, map[string]dynamoDBAttribute{"namespace": item["namespace"], "id": item["id"]})
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Items": scanned})
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+}
+
+func TestDynamoDBStore(t *testing.T) {
+	server := testDynamoDBServer(t)
+	defer server.Close()
+
+	s := NewDynamoDBPersistenceService(&DynamoDBPersistenceConfig{
+		Table:           "bbgo",
+		Region:          "ap-northeast-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+
+	store := s.NewStore("state", "grid", "BTCUSDT")
+
+	var val map[string]int
+	assert.Equal(t, ErrPersistenceNotExists, store.Load(&val))
+
+	assert.NoError(t, store.Save(map[string]int{"orders": 3}))
+	assert.NoError(t, store.Load(&val))
+	assert.Equal(t, map[string]int{"orders": 3}, val)
+
+	assert.Equal(t, ErrPersistenceNotExists, s.NewStore("state", "grid").Load(&val), "the stores should not share the item")
+
+	assert.NoError(t, s.NewStore("state").Save(1))
+	keys, err := s.Keys()
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []PersistenceKey{
+			{ID: "state", SubIDs: []string{"grid", "BTCUSDT"}},
+			{ID: "state"},
+		}, keys)
+	}
+	assert.NoError(t, s.NewStore("state").Reset())
+
+	assert.NoError(t, store.Reset())
+	assert.Equal(t, ErrPersistenceNotExists, store.Load(&val))
+
+	missing := NewDynamoDBPersistenceService(&DynamoDBPersistenceConfig{
+		Table:           "missing",
+		Region:          "ap-northeast-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	assert.EqualError(t, missing.NewStore("state").Save(val), "dynamodb PutItem error: ResourceNotFoundException: Requested resource not found")
+}
@@ -1,21 +1,105 @@
 package service
 
+import (
+	"fmt"
+	"io"
+)
+
 type PersistenceServiceFacade struct {
-	Redis  *RedisPersistenceService
-	Json   *JsonPersistenceService
-	Memory *MemoryService
+	Redis    *RedisPersistenceService
+	DynamoDB *DynamoDBPersistenceService
+	Bolt     *BoltPersistenceService
+	Json     *JsonPersistenceService
+	Memory   *MemoryService
+
+	// Primary is the type of the persistence service returned by Get, e.g., "redis", "dynamodb", "bolt", "json" or "memory"
+	Primary string
 }
 
-// Get returns the preferred persistence service by fallbacks
-// Redis will be preferred at the first position.
+// Select returns the persistence service of the given type,
+// an error is returned if the type is unknown or the service is not configured.
+func (facade *PersistenceServiceFacade) Select(t string) (PersistenceService, error) {
+	switch t {
+	case "redis":
+		if facade.Redis != nil {
+			return facade.Redis, nil
+		}
+
+	case "dynamodb":
+		if facade.DynamoDB != nil {
+			return facade.DynamoDB, nil
+		}
+
+	case "bolt":
+		if facade.Bolt != nil {
+			return facade.Bolt, nil
+		}
+
+	case "json":
+		if facade.Json != nil {
+			return facade.Json, nil
+		}
+
+	case "memory":
+		if facade.Memory != nil {
+			return facade.Memory, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported persistence type %s", t)
+	}
+
+	return nil, fmt.Errorf("persistence type %s is not configured", t)
+}
+
+// Get returns the primary persistence service if it's set, otherwise the preferred persistence service by fallbacks
+// Redis will be preferred at the first position, then DynamoDB, Bolt and Json.
 func (facade *PersistenceServiceFacade) Get() PersistenceService {
+	if facade.Primary != "" {
+		if service, err := facade.Select(facade.Primary); err == nil {
+			return service
+		}
+	}
+
 	if facade.Redis != nil {
 		return facade.Redis
 	}
 
+	if facade.DynamoDB != nil {
+		return facade.DynamoDB
+	}
+
+	if facade.Bolt != nil {
+		return facade.Bolt
+	}
+
 	if facade.Json != nil {
 		return facade.Json
 	}
 
 	return facade.Memory
 }
+
+// IsDurable reports whether the persistence service returned by Get survives the restart,
+// the memory service is the only non-durable persistence service.
+func (facade *PersistenceServiceFacade) IsDurable() bool {
+	service := facade.Get()
+	if service == nil {
+		return false
+	}
+
+	_, isMemory := service.(*MemoryService)
+	return !isMemory
+}
+
+// ExportAll writes all the stored values of the persistence service returned by Get as a single JSON document,
+// the document can be imported into another persistence service by ImportAll, e.g., to move from json to redis.
+// The memory service can not be exported or imported.
+func (facade *PersistenceServiceFacade) ExportAll(w io.Writer) error {
+	return ExportPersistence(facade.Get(), w)
+}
+
+// ImportAll restores the values of the document written by ExportAll into the persistence service returned by Get
+func (facade *PersistenceServiceFacade) ImportAll(r io.Reader) error {
+	return ImportPersistence(facade.Get(), r)
+}
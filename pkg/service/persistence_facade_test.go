@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistenceServiceFacade_Get(t *testing.T) {
+	redisService, err := NewRedisPersistenceService(&RedisPersistenceConfig{Host: "127.0.0.1", Port: "6379"})
+	assert.NoError(t, err)
+
+	facade := &PersistenceServiceFacade{
+		Redis:  redisService,
+		Json:   &JsonPersistenceService{Directory: "var/data"},
+		Memory: NewMemoryService(),
+	}
+
+	assert.Equal(t, facade.Redis, facade.Get(), "redis is preferred by default")
+
+	facade.Primary = "json"
+	assert.Equal(t, facade.Json, facade.Get())
+
+	facade.Primary = "memory"
+	assert.Equal(t, facade.Memory, facade.Get())
+
+	facade.DynamoDB = NewDynamoDBPersistenceService(&DynamoDBPersistenceConfig{Table: "bbgo", Region: "us-east-1"})
+	facade.Primary = "dynamodb"
+	assert.Equal(t, facade.DynamoDB, facade.Get())
+}
+
+func TestPersistenceServiceFacade_Select(t *testing.T) {
+	facade := &PersistenceServiceFacade{
+		Json:   &JsonPersistenceService{Directory: "var/data"},
+		Memory: NewMemoryService(),
+	}
+
+	service, err := facade.Select("json")
+	assert.NoError(t, err)
+	assert.Equal(t, facade.Json, service)
+
+	_, err = facade.Select("redis")
+	assert.EqualError(t, err, "persistence type redis is not configured")
+
+	_, err = facade.Select("mongodb")
+	assert.EqualError(t, err, "unsupported persistence type mongodb")
+}
+
+func TestPersistenceServiceFacade_IsDurable(t *testing.T) {
+	facade := &PersistenceServiceFacade{
+		Memory: NewMemoryService(),
+	}
+	assert.False(t, facade.IsDurable())
+
+	facade.Json = &JsonPersistenceService{Directory: "var/data"}
+	assert.True(t, facade.IsDurable())
+
+	facade.Primary = "memory"
+	assert.False(t, facade.IsDurable(), "the memory service is selected as the primary")
+}
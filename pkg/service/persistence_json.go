@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type JsonPersistenceService struct {
@@ -18,6 +19,38 @@ func (s *JsonPersistenceService) NewStore(id string, subIDs ...string) Store {
 	}
 }
 
+// Keys lists the stores of the json files under the directory, the sub ids are the sub directories of the file
+func (s *JsonPersistenceService) Keys() (keys []PersistenceKey, err error) {
+	if _, err := os.Stat(s.Directory); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	err = filepath.Walk(s.Directory, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Directory, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+
+		key := PersistenceKey{ID: strings.TrimSuffix(info.Name(), ".json")}
+		if rel != "." {
+			key.SubIDs = strings.Split(rel, string(filepath.Separator))
+		}
+
+		keys = append(keys, key)
+		return nil
+	})
+
+	return keys, err
+}
+
 type JsonStore struct {
 	ID        string
 	Directory string
@@ -38,7 +71,7 @@ func (store JsonStore) Reset() error {
 
 func (store JsonStore) Load(val interface{}) error {
 	if _, err := os.Stat(store.Directory); os.IsNotExist(err) {
-		if err2 := os.Mkdir(store.Directory, 0777); err2 != nil {
+		if err2 := os.MkdirAll(store.Directory, 0777); err2 != nil {
 			return err2
 		}
 	}
@@ -63,7 +96,7 @@ func (store JsonStore) Load(val interface{}) error {
 
 func (store JsonStore) Save(val interface{}) error {
 	if _, err := os.Stat(store.Directory); os.IsNotExist(err) {
-		if err2 := os.Mkdir(store.Directory, 0777); err2 != nil {
+		if err2 := os.MkdirAll(store.Directory, 0777); err2 != nil {
 			return err2
 		}
 	}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"strings"
 
@@ -13,17 +17,63 @@ type RedisPersistenceService struct {
 	redis *redis.Client
 }
 
-func NewRedisPersistenceService(config *RedisPersistenceConfig) *RedisPersistenceService {
+// NewRedisPersistenceService creates the redis client of the config, an error is returned if the TLS config is invalid.
+// The client connects lazily, call Ping to check the connection.
+func NewRedisPersistenceService(config *RedisPersistenceConfig) (*RedisPersistenceService, error) {
+	tlsConfig, err := newRedisTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	client := redis.NewClient(&redis.Options{
-		Addr: net.JoinHostPort(config.Host, config.Port),
-		// Username:           "", // username is only for redis 6.0
-		Password: config.Password, // no password set
-		DB:       config.DB,       // use default DB
+		Addr:      net.JoinHostPort(config.Host, config.Port),
+		Username:  config.Username, // username is only for redis 6.0
+		Password:  config.Password, // no password set
+		DB:        config.DB,       // use default DB
+		TLSConfig: tlsConfig,
 	})
 
 	return &RedisPersistenceService{
 		redis: client,
+	}, nil
+}
+
+// newRedisTLSConfig returns the TLS config of the redis connection, nil is returned if TLS is not enabled
+func newRedisTLSConfig(config *RedisPersistenceConfig) (*tls.Config, error) {
+	if !config.TLS && len(config.CACert) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if len(tlsConfig.ServerName) == 0 {
+		tlsConfig.ServerName = config.Host
+	}
+
+	if len(config.CACert) > 0 {
+		pem, err := ioutil.ReadFile(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("can not read the redis CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate is found in the redis CA certificate %s", config.CACert)
+		}
+
+		tlsConfig.RootCAs = pool
 	}
+
+	return tlsConfig, nil
+}
+
+// Ping checks the connection to the redis server
+func (s *RedisPersistenceService) Ping(ctx context.Context) error {
+	return s.redis.Ping(ctx).Err()
 }
 
 func (s *RedisPersistenceService) NewStore(id string, subIDs ...string) Store {
@@ -37,6 +87,18 @@ func (s *RedisPersistenceService) NewStore(id string, subIDs ...string) Store {
 	}
 }
 
+// Keys scans all the keys of the redis db, the keys are split by ":" into the store ids and the sub ids like NewStore
+// joins them, so the db should be dedicated to bbgo to be exported
+func (s *RedisPersistenceService) Keys() (keys []PersistenceKey, err error) {
+	ctx := context.Background()
+	iter := s.redis.Scan(ctx, 0, "*", 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, splitPersistenceKey(iter.Val()))
+	}
+
+	return keys, iter.Err()
+}
+
 type RedisStore struct {
 	redis *redis.Client
 
@@ -1,7 +1,18 @@
 package service
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -9,17 +20,18 @@ import (
 )
 
 func TestRedisPersistentService(t *testing.T) {
-	redisService := NewRedisPersistenceService(&RedisPersistenceConfig{
+	redisService, err := NewRedisPersistenceService(&RedisPersistenceConfig{
 		Host: "127.0.0.1",
 		Port: "6379",
 		DB:   0,
 	})
+	assert.NoError(t, err)
 	assert.NotNil(t, redisService)
 
 	store := redisService.NewStore("bbgo", "test")
 	assert.NotNil(t, store)
 
-	err := store.Reset()
+	err = store.Reset()
 	assert.NoError(t, err)
 
 	var fp fixedpoint.Value
@@ -39,3 +51,63 @@ func TestRedisPersistentService(t *testing.T) {
 	err = store.Reset()
 	assert.NoError(t, err)
 }
+
+func TestNewRedisTLSConfig(t *testing.T) {
+	tlsConfig, err := newRedisTLSConfig(&RedisPersistenceConfig{Host: "127.0.0.1", Port: "6379"})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "TLS is not enabled")
+
+	tlsConfig, err = newRedisTLSConfig(&RedisPersistenceConfig{Host: "redis.example.com", Port: "6380", TLS: true})
+	if assert.NoError(t, err) && assert.NotNil(t, tlsConfig) {
+		assert.Equal(t, "redis.example.com", tlsConfig.ServerName)
+		assert.Nil(t, tlsConfig.RootCAs, "the system roots are used")
+	}
+
+	dir, err := ioutil.TempDir("", "bbgo-redis")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	caCert := filepath.Join(dir, "ca.pem")
+	assert.NoError(t, ioutil.WriteFile(caCert, newTestCACertificate(t), 0600))
+
+	tlsConfig, err = newRedisTLSConfig(&RedisPersistenceConfig{Host: "10.0.0.1", Port: "6380", CACert: caCert, ServerName: "redis.internal"})
+	if assert.NoError(t, err) && assert.NotNil(t, tlsConfig) {
+		assert.Equal(t, "redis.internal", tlsConfig.ServerName)
+		assert.NotNil(t, tlsConfig.RootCAs)
+	}
+
+	invalidCert := filepath.Join(dir, "invalid.pem")
+	assert.NoError(t, ioutil.WriteFile(invalidCert, []byte("not a certificate"), 0600))
+
+	_, err = newRedisTLSConfig(&RedisPersistenceConfig{Host: "10.0.0.1", Port: "6380", CACert: invalidCert})
+	assert.Error(t, err)
+
+	_, err = NewRedisPersistenceService(&RedisPersistenceConfig{Host: "10.0.0.1", Port: "6380", CACert: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+}
+
+func newTestCACertificate(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "bbgo test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
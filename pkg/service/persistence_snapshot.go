@@ -0,0 +1,126 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PersistenceSnapshotVersion is the version of the snapshot document written by ExportPersistence
+const PersistenceSnapshotVersion = 1
+
+// PersistenceKey is the store id and the sub ids of a stored value, see PersistenceService.NewStore
+type PersistenceKey struct {
+	ID     string   `json:"id"`
+	SubIDs []string `json:"subIDs,omitempty"`
+}
+
+func (k PersistenceKey) String() string {
+	return strings.Join(append([]string{k.ID}, k.SubIDs...), ":")
+}
+
+// IterablePersistenceService is implemented by the persistence services that can list the keys of their stored values,
+// the service must implement it to be exported or imported, the values of the memory service are not JSON documents
+type IterablePersistenceService interface {
+	PersistenceService
+
+	Keys() ([]PersistenceKey, error)
+}
+
+// PersistenceSnapshot is the backend independent JSON document of all the stored values of a persistence service
+type PersistenceSnapshot struct {
+	Version int                        `json:"version"`
+	Entries []PersistenceSnapshotEntry `json:"entries"`
+}
+
+// PersistenceSnapshotEntry is a stored value, the value is kept as the JSON document saved by the store
+type PersistenceSnapshotEntry struct {
+	PersistenceKey
+
+	Value json.RawMessage `json:"value"`
+}
+
+// ExportPersistence writes all the stored values of the service as a snapshot document, the entries are sorted by the key
+func ExportPersistence(service PersistenceService, w io.Writer) error {
+	iterable, ok := service.(IterablePersistenceService)
+	if !ok {
+		return fmt.Errorf("persistence service %T can not be exported", service)
+	}
+
+	keys, err := iterable.Keys()
+	if err != nil {
+		return fmt.Errorf("can not list the persistence keys: %w", err)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	snapshot := PersistenceSnapshot{
+		Version: PersistenceSnapshotVersion,
+		Entries: []PersistenceSnapshotEntry{},
+	}
+
+	for _, key := range keys {
+		var value json.RawMessage
+		if err := service.NewStore(key.ID, key.SubIDs...).Load(&value); err != nil {
+			if err == ErrPersistenceNotExists {
+				continue
+			}
+
+			return fmt.Errorf("can not load the persistence value of %s: %w", key, err)
+		}
+
+		snapshot.Entries = append(snapshot.Entries, PersistenceSnapshotEntry{PersistenceKey: key, Value: value})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// ImportPersistence saves the values of the snapshot document into the service, the existing values of the same keys
+// are overwritten, and the other values are kept
+func ImportPersistence(service PersistenceService, r io.Reader) error {
+	if _, ok := service.(IterablePersistenceService); !ok {
+		return fmt.Errorf("persistence service %T can not be imported", service)
+	}
+
+	var snapshot PersistenceSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("can not decode the persistence snapshot: %w", err)
+	}
+
+	if snapshot.Version != PersistenceSnapshotVersion {
+		return fmt.Errorf("unsupported persistence snapshot version %d", snapshot.Version)
+	}
+
+	for _, entry := range snapshot.Entries {
+		if len(entry.ID) == 0 {
+			return fmt.Errorf("persistence snapshot entry %s has no id", entry.PersistenceKey)
+		}
+
+		if len(entry.Value) == 0 {
+			return fmt.Errorf("persistence snapshot entry %s has no value", entry.PersistenceKey)
+		}
+
+		if err := service.NewStore(entry.ID, entry.SubIDs...).Save(entry.Value); err != nil {
+			return fmt.Errorf("can not save the persistence value of %s: %w", entry.PersistenceKey, err)
+		}
+	}
+
+	return nil
+}
+
+// splitPersistenceKey splits the key joined by ":" into the store id and the sub ids,
+// the ids containing ":" can not be told apart
+func splitPersistenceKey(key string) PersistenceKey {
+	parts := strings.Split(key, ":")
+	if len(parts) == 1 {
+		return PersistenceKey{ID: key}
+	}
+
+	return PersistenceKey{ID: parts[0], SubIDs: parts[1:]}
+}
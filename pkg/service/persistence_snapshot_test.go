@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type testPersistenceState struct {
+	Orders   []uint64         `json:"orders"`
+	Position fixedpoint.Value `json:"position"`
+	Note     string           `json:"note"`
+}
+
+func TestPersistenceServiceFacade_ExportAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	boltService, err := NewBoltPersistenceService(&BoltPersistenceConfig{File: filepath.Join(dir, "bbgo.db")})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer boltService.Close()
+
+	facade := &PersistenceServiceFacade{
+		Json:    &JsonPersistenceService{Directory: filepath.Join(dir, "json")},
+		Bolt:    boltService,
+		Primary: "json",
+	}
+
+	state := testPersistenceState{Orders: []uint64{1, 2}, Position: fixedpoint.NewFromFloat(0.125), Note: "grid"}
+	assert.NoError(t, facade.Json.NewStore("default", "grid", "grid-BTCUSDT").Save(&state))
+	assert.NoError(t, facade.Json.NewStore("bbgo", "deposit-address", "binance", "BTC").Save("bc1q"))
+	assert.NoError(t, facade.Json.NewStore("counter").Save(3))
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, facade.ExportAll(&buf)) {
+		return
+	}
+
+	assert.True(t, strings.HasPrefix(buf.String(), "{\n  \"version\": 1,"), buf.String())
+	assert.Contains(t, buf.String(), `"subIDs": [`)
+
+	// restore the snapshot into the bolt service
+	facade.Primary = "bolt"
+	if !assert.NoError(t, facade.ImportAll(&buf)) {
+		return
+	}
+
+	var restored testPersistenceState
+	assert.NoError(t, boltService.NewStore("default", "grid", "grid-BTCUSDT").Load(&restored))
+	assert.Equal(t, state, restored)
+
+	var address string
+	assert.NoError(t, boltService.NewStore("bbgo", "deposit-address", "binance", "BTC").Load(&address))
+	assert.Equal(t, "bc1q", address)
+
+	var counter int
+	assert.NoError(t, boltService.NewStore("counter").Load(&counter))
+	assert.Equal(t, 3, counter)
+
+	// the bolt keys are split back into the same store ids and sub ids
+	buf.Reset()
+	assert.NoError(t, facade.ExportAll(&buf))
+
+	var exported bytes.Buffer
+	assert.NoError(t, ExportPersistence(facade.Json, &exported))
+	assert.Equal(t, exported.String(), buf.String())
+}
+
+func TestImportPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jsonService := &JsonPersistenceService{Directory: dir}
+
+	err = ImportPersistence(jsonService, strings.NewReader(`{"version": 2, "entries": []}`))
+	assert.EqualError(t, err, "unsupported persistence snapshot version 2")
+
+	err = ImportPersistence(jsonService, strings.NewReader(`{"version": 1, "entries": [{"id": "bbgo", "subIDs": ["sync"]}]}`))
+	assert.EqualError(t, err, "persistence snapshot entry bbgo:sync has no value")
+
+	err = ImportPersistence(NewMemoryService(), strings.NewReader(`{"version": 1, "entries": []}`))
+	assert.EqualError(t, err, "persistence service *service.MemoryService can not be imported")
+
+	err = ExportPersistence(NewMemoryService(), ioutil.Discard)
+	assert.EqualError(t, err, "persistence service *service.MemoryService can not be exported")
+}
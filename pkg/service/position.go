@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Position is the average cost position of a session symbol aggregated from the stored trades
+type Position struct {
+	GID           int64              `json:"gid" db:"gid"`
+	Session       string             `json:"session" db:"session"`
+	Exchange      types.ExchangeName `json:"exchange" db:"exchange"`
+	Symbol        string             `json:"symbol" db:"symbol"`
+	BaseCurrency  string             `json:"baseCurrency" db:"base_currency"`
+	QuoteCurrency string             `json:"quoteCurrency" db:"quote_currency"`
+
+	// Base is negative for the short position
+	Base        fixedpoint.Value `json:"base" db:"base"`
+	Quote       fixedpoint.Value `json:"quote" db:"quote"`
+	AverageCost fixedpoint.Value `json:"averageCost" db:"average_cost"`
+
+	// LastTradeGID is the gid of the last trade aggregated into the position,
+	// the trades stored after it are aggregated on the next update
+	LastTradeGID int64 `json:"lastTradeGID" db:"last_trade_gid"`
+
+	UpdatedAt datatype.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type PositionService struct {
+	DB *sqlx.DB
+}
+
+// Query queries the position of the session symbol, nil is returned if the position was never stored
+func (s *PositionService) Query(ctx context.Context, session, symbol string) (*Position, error) {
+	rows, err := s.DB.NamedQueryContext(ctx, `SELECT * FROM positions WHERE session = :session AND symbol = :symbol`, map[string]interface{}{
+		"session": session,
+		"symbol":  symbol,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var position Position
+	if err := rows.StructScan(&position); err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// QueryBySession queries the positions of all the symbols of the session, ordered by the symbol
+func (s *PositionService) QueryBySession(ctx context.Context, session string) ([]Position, error) {
+	rows, err := s.DB.NamedQueryContext(ctx, `SELECT * FROM positions WHERE session = :session ORDER BY symbol ASC`, map[string]interface{}{
+		"session": session,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var positions []Position
+	for rows.Next() {
+		var position Position
+		if err := rows.StructScan(&position); err != nil {
+			return nil, err
+		}
+
+		positions = append(positions, position)
+	}
+
+	return positions, rows.Err()
+}
+
+// Save inserts the position or updates the existing position of the same session symbol
+func (s *PositionService) Save(ctx context.Context, position Position) (err error) {
+	switch s.DB.DriverName() {
+	case "mysql":
+		_, err = s.DB.NamedExecContext(ctx, `
+			INSERT INTO positions (session, exchange, symbol, base_currency, quote_currency, base, quote, average_cost, last_trade_gid, updated_at)
+			VALUES (:session, :exchange, :symbol, :base_currency, :quote_currency, :base, :quote, :average_cost, :last_trade_gid, :updated_at)
+			ON DUPLICATE KEY UPDATE exchange=:exchange, base_currency=:base_currency, quote_currency=:quote_currency, base=:base, quote=:quote, average_cost=:average_cost, last_trade_gid=:last_trade_gid, updated_at=:updated_at`, position)
+		return err
+	}
+
+	// both sqlite3 and postgres support the ON CONFLICT clause
+	_, err = s.DB.NamedExecContext(ctx, `
+			INSERT INTO positions (session, exchange, symbol, base_currency, quote_currency, base, quote, average_cost, last_trade_gid, updated_at)
+			VALUES (:session, :exchange, :symbol, :base_currency, :quote_currency, :base, :quote, :average_cost, :last_trade_gid, :updated_at)
+			ON CONFLICT (session, symbol) DO UPDATE SET exchange=EXCLUDED.exchange, base_currency=EXCLUDED.base_currency, quote_currency=EXCLUDED.quote_currency, base=EXCLUDED.base, quote=EXCLUDED.quote, average_cost=EXCLUDED.average_cost, last_trade_gid=EXCLUDED.last_trade_gid, updated_at=EXCLUDED.updated_at`, position)
+	return err
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPositionService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &PositionService{DB: xdb}
+
+	position, err := service.Query(ctx, "binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, position, "the position was never stored")
+
+	updatedAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	stored := Position{
+		Session:       "binance",
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		Base:          fixedpoint.NewFromFloat(1.5),
+		Quote:         fixedpoint.NewFromFloat(-60000.0),
+		AverageCost:   fixedpoint.NewFromFloat(40000.0),
+		LastTradeGID:  10,
+		UpdatedAt:     datatype.Time(updatedAt),
+	}
+	assert.NoError(t, service.Save(ctx, stored))
+
+	// saving the position of the same session symbol again updates the stored position
+	stored.Base = fixedpoint.NewFromFloat(-0.5)
+	stored.LastTradeGID = 12
+	stored.UpdatedAt = datatype.Time(updatedAt.Add(time.Hour))
+	assert.NoError(t, service.Save(ctx, stored))
+
+	position, err = service.Query(ctx, "binance", "BTCUSDT")
+	assert.NoError(t, err)
+	if assert.NotNil(t, position) {
+		assert.Equal(t, "BTC", position.BaseCurrency)
+		assert.Equal(t, -0.5, position.Base.Float64())
+		assert.Equal(t, 40000.0, position.AverageCost.Float64())
+		assert.Equal(t, int64(12), position.LastTradeGID)
+		assert.True(t, updatedAt.Add(time.Hour).Equal(position.UpdatedAt.Time()))
+	}
+
+	stored.Symbol = "ETHUSDT"
+	assert.NoError(t, service.Save(ctx, stored))
+
+	positions, err := service.QueryBySession(ctx, "binance")
+	assert.NoError(t, err)
+	if assert.Len(t, positions, 2) {
+		assert.Equal(t, "BTCUSDT", positions[0].Symbol)
+		assert.Equal(t, "ETHUSDT", positions[1].Symbol)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultDeleteBatchSize is the number of the rows deleted by one statement when the old records are pruned,
+// so that the table is not locked for a long time by a huge delete
+const DefaultDeleteBatchSize = 5000
+
+// deleteInBatches deletes the rows of the table matching the where clause until no row is left,
+// the number of the deleted rows is returned.
+func deleteInBatches(ctx context.Context, db *sqlx.DB, table, where string, arg map[string]interface{}) (int64, error) {
+	var sql string
+	switch db.DriverName() {
+	case "mysql":
+		// mysql doesn't support LIMIT in the IN subquery, but it supports DELETE ... LIMIT
+		sql = fmt.Sprintf(`DELETE FROM %s WHERE %s LIMIT %d`, table, where, DefaultDeleteBatchSize)
+	default:
+		// sqlite3 and postgres don't support DELETE ... LIMIT
+		sql = fmt.Sprintf(`DELETE FROM %s WHERE gid IN (SELECT gid FROM %s WHERE %s LIMIT %d)`, table, table, where, DefaultDeleteBatchSize)
+	}
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		result, err := db.NamedExecContext(ctx, sql, arg)
+		if err != nil {
+			return deleted, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += affected
+		if affected < DefaultDeleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
@@ -17,8 +17,6 @@ import (
 
 // RewardService collects the reward records from the exchange,
 // currently it's only available for MAX exchange.
-// TODO: add summary query for calculating the reward amounts
-// CREATE VIEW reward_summary_by_years AS SELECT YEAR(created_at) as year, reward_type, currency, SUM(quantity) FROM rewards WHERE reward_type != 'airdrop' GROUP BY YEAR(created_at), reward_type, currency ORDER BY year DESC;
 type RewardService struct {
 	DB *sqlx.DB
 }
@@ -38,6 +36,10 @@ func (s *RewardService) QueryLast(ex types.ExchangeName, limit int) ([]types.Rew
 }
 
 func (s *RewardService) Sync(ctx context.Context, exchange types.Exchange) error {
+	return s.sync(ctx, exchange, nil)
+}
+
+func (s *RewardService) sync(ctx context.Context, exchange types.Exchange, progress progressFunc) error {
 	service, ok := exchange.(types.ExchangeRewardService)
 	if !ok {
 		return ErrExchangeRewardServiceNotImplemented
@@ -64,6 +66,7 @@ func (s *RewardService) Sync(ctx context.Context, exchange types.Exchange) error
 	batchQuery := &batch.RewardBatchQuery{Service: service}
 	rewardsC, errC := batchQuery.Query(ctx, startTime, time.Now())
 
+	var fetched = 0
 	for reward := range rewardsC {
 		select {
 
@@ -79,6 +82,9 @@ func (s *RewardService) Sync(ctx context.Context, exchange types.Exchange) error
 
 		}
 
+		fetched++
+		progress.report(fetched)
+
 		if _, ok := rewardKeys[reward.UUID]; ok {
 			continue
 		}
@@ -94,6 +100,76 @@ func (s *RewardService) Sync(ctx context.Context, exchange types.Exchange) error
 }
 
 
+// RewardSummary is the aggregated rewards of a currency
+type RewardSummary struct {
+	Currency string           `json:"currency"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Count is the number of the reward records
+	Count int `json:"count"`
+
+	// QuantityByType is the reward quantity of each reward type
+	QuantityByType map[types.RewardType]fixedpoint.Value `json:"quantityByType"`
+}
+
+// QueryRange queries the rewards created in the time range [since, until),
+// including the spent rewards. All reward types are returned if no reward type is given.
+func (s *RewardService) QueryRange(ctx context.Context, ex types.ExchangeName, since, until time.Time, rewardTypes ...types.RewardType) ([]types.Reward, error) {
+	sql := "SELECT * FROM `rewards` WHERE `exchange` = :exchange AND `created_at` >= :since AND `created_at` < :until ORDER BY `created_at` ASC"
+	rows, err := s.DB.NamedQueryContext(ctx, sql, map[string]interface{}{
+		"exchange": ex,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rewards, err := s.scanRows(rows)
+	if err != nil || len(rewardTypes) == 0 {
+		return rewards, err
+	}
+
+	var filtered []types.Reward
+	for _, reward := range rewards {
+		for _, rewardType := range rewardTypes {
+			if reward.Type == rewardType {
+				filtered = append(filtered, reward)
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+// AggregateByCurrency sums up the rewards created in the time range [since, until) by currency
+func (s *RewardService) AggregateByCurrency(ctx context.Context, ex types.ExchangeName, since, until time.Time, rewardTypes ...types.RewardType) (map[string]*RewardSummary, error) {
+	rewards, err := s.QueryRange(ctx, ex, since, until, rewardTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*RewardSummary)
+	for _, reward := range rewards {
+		summary, ok := summaries[reward.Currency]
+		if !ok {
+			summary = &RewardSummary{
+				Currency:       reward.Currency,
+				QuantityByType: make(map[types.RewardType]fixedpoint.Value),
+			}
+			summaries[reward.Currency] = summary
+		}
+
+		summary.Quantity = summary.Quantity.Add(reward.Quantity)
+		summary.QuantityByType[reward.Type] = summary.QuantityByType[reward.Type].Add(reward.Quantity)
+		summary.Count++
+	}
+
+	return summaries, nil
+}
 
 type CurrencyPositionMap map[string]fixedpoint.Value
 
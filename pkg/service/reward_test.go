@@ -140,3 +140,49 @@ func TestRewardService_AggregateUnspentCurrencyPosition(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, fixedpoint.Value(1), v)
 }
+
+func TestRewardService_AggregateByCurrency(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &RewardService{DB: xdb}
+
+	now := time.Now()
+
+	for _, reward := range []types.Reward{
+		{UUID: "test01", Type: "commission", Currency: "MAX", Quantity: fixedpoint.NewFromFloat(1.5), Spent: true, CreatedAt: datatype.Time(now.Add(-time.Hour))},
+		{UUID: "test02", Type: "holding", Currency: "MAX", Quantity: fixedpoint.NewFromFloat(2.5), CreatedAt: datatype.Time(now.Add(-time.Minute))},
+		{UUID: "test03", Type: "commission", Currency: "BTC", Quantity: fixedpoint.NewFromFloat(0.1), CreatedAt: datatype.Time(now.Add(-time.Minute))},
+		{UUID: "test04", Type: "commission", Currency: "BTC", Quantity: fixedpoint.NewFromFloat(0.2), CreatedAt: datatype.Time(now.Add(-48 * time.Hour))},
+	} {
+		reward.Exchange = types.ExchangeMax
+		reward.State = "done"
+		assert.NoError(t, service.Insert(reward))
+	}
+
+	rewards, err := service.QueryRange(ctx, types.ExchangeMax, now.Add(-24*time.Hour), now, types.RewardCommission)
+	assert.NoError(t, err)
+	assert.Len(t, rewards, 2)
+
+	summaries, err := service.AggregateByCurrency(ctx, types.ExchangeMax, now.Add(-24*time.Hour), now)
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+
+	if summary, ok := summaries["MAX"]; assert.True(t, ok) {
+		assert.Equal(t, 2, summary.Count, "the spent reward should be included")
+		assert.InDelta(t, 4.0, summary.Quantity.Float64(), 1e-8)
+		assert.InDelta(t, 2.5, summary.QuantityByType[types.RewardHolding].Float64(), 1e-8)
+	}
+
+	if summary, ok := summaries["BTC"]; assert.True(t, ok) {
+		assert.Equal(t, 1, summary.Count, "the reward out of the time range should be excluded")
+		assert.InDelta(t, 0.1, summary.Quantity.Float64(), 1e-8)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const (
+	s3Service       = "s3"
+	s3Timeout       = 30 * time.Second
+	s3DefaultRegion = "us-east-1"
+)
+
+// S3Config is the config of the S3 requests, the credentials are read from the AWS_* env vars.
+// Only the static credentials are supported, the request is not signed if the credentials are not set.
+type S3Config struct {
+	Region string `yaml:"region" json:"region" env:"AWS_REGION"`
+
+	// Endpoint overrides the regional endpoint, e.g., http://localhost:9000 for the S3 compatible storages
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty" env:"AWS_S3_ENDPOINT"`
+
+	AccessKeyID     string `yaml:"accessKeyID,omitempty" json:"accessKeyID,omitempty" env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secretAccessKey,omitempty" json:"secretAccessKey,omitempty" env:"AWS_SECRET_ACCESS_KEY"`
+	SessionToken    string `yaml:"sessionToken,omitempty" json:"sessionToken,omitempty" env:"AWS_SESSION_TOKEN"`
+}
+
+// GetS3Object downloads the object of the bucket with the path-style request
+func GetS3Object(ctx context.Context, config *S3Config, bucket, key string) ([]byte, error) {
+	region := config.Region
+	if len(region) == 0 {
+		region = s3DefaultRegion
+	}
+
+	endpoint := config.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/" + bucket + "/" + strings.TrimLeft(key, "/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.AccessKeyID) > 0 {
+		// S3 requires the payload hash header, it's the hash of the empty payload for GET
+		payloadHash := sha256.Sum256(nil)
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+		if len(config.SessionToken) > 0 {
+			req.Header.Set("X-Amz-Security-Token", config.SessionToken)
+		}
+
+		signAWSRequest(req, nil, config.AccessKeyID, config.SecretAccessKey, region, s3Service, time.Now())
+	}
+
+	client := &http.Client{Timeout: s3Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.IsError() {
+		return nil, fmt.Errorf("s3 get object s3://%s/%s error: status %d, response: %s", bucket, key, response.StatusCode, response.String())
+	}
+
+	return response.Body, nil
+}
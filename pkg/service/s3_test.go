@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetS3Object(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-west-2/s3/aws4_request")
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", r.Header.Get("X-Amz-Content-Sha256"))
+
+		if r.URL.Path != "/configs/bbgo.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+			return
+		}
+
+		_, _ = w.Write([]byte("sessions: {}\n"))
+	}))
+	defer server.Close()
+
+	config := &S3Config{Region: "us-west-2", Endpoint: server.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"}
+
+	content, err := GetS3Object(context.Background(), config, "configs", "/bbgo.yaml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sessions: {}\n", string(content))
+	}
+
+	_, err = GetS3Object(context.Background(), config, "configs", "missing.yaml")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "status 404")
+	}
+}
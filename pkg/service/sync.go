@@ -5,45 +5,155 @@ import (
 	"errors"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/datatype"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
 var ErrNotImplemented = errors.New("not implemented")
 var ErrExchangeRewardServiceNotImplemented = errors.New("exchange does not implement ExchangeRewardService interface")
 
+// SyncRecordType is the type of the records being synced
+type SyncRecordType string
+
+const (
+	SyncRecordTrade    SyncRecordType = "trade"
+	SyncRecordOrder    SyncRecordType = "order"
+	SyncRecordDeposit  SyncRecordType = "deposit"
+	SyncRecordWithdraw SyncRecordType = "withdraw"
+	SyncRecordReward   SyncRecordType = "reward"
+)
+
+// SyncProgress is the progress event emitted during the sync
+type SyncProgress struct {
+	Exchange types.ExchangeName
+	// Symbol is empty for the records that are not bound to a symbol, e.g., deposits, withdraws and rewards
+	Symbol string
+	Type   SyncRecordType
+	// Records is the number of the records fetched so far
+	Records int
+}
+
+// SyncProgressHandler receives the progress events during the sync
+type SyncProgressHandler func(progress SyncProgress)
+
+// progressFunc is called with the number of the records fetched so far
+type progressFunc func(records int)
+
+func (f progressFunc) report(records int) {
+	if f != nil {
+		f(records)
+	}
+}
+
+func (h SyncProgressHandler) forRecords(exchange types.ExchangeName, symbol string, recordType SyncRecordType) progressFunc {
+	if h == nil {
+		return nil
+	}
+
+	h(SyncProgress{Exchange: exchange, Symbol: symbol, Type: recordType})
+	return func(records int) {
+		h(SyncProgress{Exchange: exchange, Symbol: symbol, Type: recordType, Records: records})
+	}
+}
+
+// DefaultSyncWindows are the maximum time ranges of the closed order queries accepted by the exchanges,
+// the long sync time range is split into the windows, so that the back-fill is not truncated.
+var DefaultSyncWindows = map[types.ExchangeName]time.Duration{
+	types.ExchangeBinance: 24 * time.Hour,
+}
+
 type SyncService struct {
 	TradeService    *TradeService
 	OrderService    *OrderService
 	RewardService   *RewardService
 	WithdrawService *WithdrawService
 	DepositService  *DepositService
+	CursorService   *SyncCursorService
+
+	// LockService prevents the processes sharing the database from syncing the same session at the same time
+	LockService *SyncLockService
+
+	// Windows overrides the sync windows of the exchanges, DefaultSyncWindows is used for the exchanges not listed
+	Windows map[types.ExchangeName]time.Duration
+}
+
+// syncWindow returns the sync window of the exchange, zero means the time range is not split
+func (s *SyncService) syncWindow(exchange types.ExchangeName) time.Duration {
+	if window, ok := s.Windows[exchange]; ok {
+		return window
+	}
+
+	return DefaultSyncWindows[exchange]
 }
 
-// SyncSessionSymbols syncs the trades from the given exchange session
+// SyncSessionSymbols syncs the trades from the given exchange session,
+// the requests are limited by the rate limiter of the exchange client if the session rate limit is configured.
 func (s *SyncService) SyncSessionSymbols(ctx context.Context, exchange types.Exchange, startTime time.Time, symbols ...string) error {
+	return s.SyncSessionSymbolsWithProgress(ctx, exchange, startTime, nil, symbols...)
+}
+
+// SyncSessionSymbolsWithProgress syncs the trades from the given exchange session,
+// the progress handler is called when the sync of a record type starts and when a record is fetched.
+func (s *SyncService) SyncSessionSymbolsWithProgress(ctx context.Context, exchange types.Exchange, startTime time.Time, progress SyncProgressHandler, symbols ...string) error {
 	for _, symbol := range symbols {
-		if err := s.TradeService.Sync(ctx, exchange, symbol); err != nil {
-			return err
+		cursor := &SyncCursor{
+			Exchange: exchange.Name(),
+			Symbol:   symbol,
+			SyncedAt: datatype.Time(startTime),
 		}
 
-		if err := s.OrderService.Sync(ctx, exchange, symbol, startTime); err != nil {
+		if err := s.SyncSymbol(ctx, exchange, cursor, progress); err != nil {
 			return err
 		}
 	}
 
-	if err := s.DepositService.Sync(ctx, exchange); err != nil {
+	return s.SyncAccountRecords(ctx, exchange, progress)
+}
+
+// SyncSymbol syncs the trades and the orders of the cursor symbol from the position of the cursor,
+// the cursor is moved to the last synced trade and order when the sync is done.
+// The sync logs carry the session, the exchange and the symbol fields of the cursor.
+func (s *SyncService) SyncSymbol(ctx context.Context, exchange types.Exchange, cursor *SyncCursor, progress SyncProgressHandler) error {
+	syncedAt := time.Now()
+	logger := log.WithFields(log.Fields{
+		"session":  cursor.Session,
+		"exchange": exchange.Name(),
+		"symbol":   cursor.Symbol,
+	})
+
+	lastTradeID, err := s.TradeService.sync(ctx, exchange, cursor.Symbol, cursor.LastTradeID, progress.forRecords(exchange.Name(), cursor.Symbol, SyncRecordTrade), logger)
+	if err != nil {
+		return err
+	}
+
+	lastOrderID, err := s.OrderService.sync(ctx, exchange, cursor.Symbol, cursor.SyncedAt.Time(), cursor.LastOrderID, s.syncWindow(exchange.Name()), progress.forRecords(exchange.Name(), cursor.Symbol, SyncRecordOrder), logger)
+	if err != nil {
+		return err
+	}
+
+	cursor.LastTradeID = lastTradeID
+	cursor.LastOrderID = lastOrderID
+	cursor.SyncedAt = datatype.Time(syncedAt)
+	return nil
+}
+
+// SyncAccountRecords syncs the records that are not bound to a symbol, i.e., deposits, withdraws and rewards
+func (s *SyncService) SyncAccountRecords(ctx context.Context, exchange types.Exchange, progress SyncProgressHandler) error {
+	if err := s.DepositService.sync(ctx, exchange, progress.forRecords(exchange.Name(), "", SyncRecordDeposit)); err != nil {
 		if err != ErrNotImplemented {
 			return err
 		}
 	}
 
-	if err := s.WithdrawService.Sync(ctx, exchange); err != nil {
+	if err := s.WithdrawService.sync(ctx, exchange, progress.forRecords(exchange.Name(), "", SyncRecordWithdraw)); err != nil {
 		if err != ErrNotImplemented {
 			return err
 		}
 	}
 
-	if err := s.RewardService.Sync(ctx, exchange); err != nil {
+	if err := s.RewardService.sync(ctx, exchange, progress.forRecords(exchange.Name(), "", SyncRecordReward)); err != nil {
 		if err != ErrExchangeRewardServiceNotImplemented {
 			return err
 		}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SyncCursor records where the last sync of a session symbol stopped
+type SyncCursor struct {
+	GID      int64              `json:"gid" db:"gid"`
+	Session  string             `json:"session" db:"session"`
+	Exchange types.ExchangeName `json:"exchange" db:"exchange"`
+	Symbol   string             `json:"symbol" db:"symbol"`
+
+	// LastTradeID is the ID of the last synced trade
+	LastTradeID int64 `json:"lastTradeID" db:"last_trade_id"`
+
+	// LastOrderID is the ID of the last synced order
+	LastOrderID uint64 `json:"lastOrderID" db:"last_order_id"`
+
+	// SyncedAt is the time the last successful sync started, the next sync queries the orders from this time
+	SyncedAt datatype.Time `json:"syncedAt" db:"synced_at"`
+}
+
+type SyncCursorService struct {
+	DB *sqlx.DB
+}
+
+// Load loads the cursor of the given session symbol, nil is returned if the symbol was never synced
+func (s *SyncCursorService) Load(session, symbol string) (*SyncCursor, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM sync_cursors WHERE session = :session AND symbol = :symbol`, map[string]interface{}{
+		"session": session,
+		"symbol":  symbol,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var cursor SyncCursor
+	if err := rows.StructScan(&cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+// QueryBySession queries the cursors of all the synced symbols of the session, ordered by the symbol
+func (s *SyncCursorService) QueryBySession(session string) ([]SyncCursor, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM sync_cursors WHERE session = :session ORDER BY symbol ASC`, map[string]interface{}{
+		"session": session,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var cursors []SyncCursor
+	for rows.Next() {
+		var cursor SyncCursor
+		if err := rows.StructScan(&cursor); err != nil {
+			return nil, err
+		}
+
+		cursors = append(cursors, cursor)
+	}
+
+	return cursors, rows.Err()
+}
+
+// Save inserts the cursor or updates the existing cursor of the same session symbol
+func (s *SyncCursorService) Save(cursor SyncCursor) (err error) {
+	switch s.DB.DriverName() {
+	case "mysql":
+		_, err = s.DB.NamedExec(`
+			INSERT INTO sync_cursors (session, exchange, symbol, last_trade_id, last_order_id, synced_at)
+			VALUES (:session, :exchange, :symbol, :last_trade_id, :last_order_id, :synced_at)
+			ON DUPLICATE KEY UPDATE exchange=:exchange, last_trade_id=:last_trade_id, last_order_id=:last_order_id, synced_at=:synced_at`, cursor)
+		return err
+	}
+
+	// both sqlite3 and postgres support the ON CONFLICT clause
+	_, err = s.DB.NamedExec(`
+			INSERT INTO sync_cursors (session, exchange, symbol, last_trade_id, last_order_id, synced_at)
+			VALUES (:session, :exchange, :symbol, :last_trade_id, :last_order_id, :synced_at)
+			ON CONFLICT (session, symbol) DO UPDATE SET exchange=EXCLUDED.exchange, last_trade_id=EXCLUDED.last_trade_id, last_order_id=EXCLUDED.last_order_id, synced_at=EXCLUDED.synced_at`, cursor)
+	return err
+}
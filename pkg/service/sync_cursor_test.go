@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSyncCursorService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &SyncCursorService{DB: xdb}
+
+	cursor, err := service.Load("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor, "the symbol was never synced")
+
+	syncedAt := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	err = service.Save(SyncCursor{
+		Session:     "binance",
+		Exchange:    types.ExchangeBinance,
+		Symbol:      "BTCUSDT",
+		LastTradeID: 100,
+		LastOrderID: 200,
+		SyncedAt:    datatype.Time(syncedAt),
+	})
+	assert.NoError(t, err)
+
+	// saving the cursor of the same session symbol again updates the stored cursor
+	err = service.Save(SyncCursor{
+		Session:     "binance",
+		Exchange:    types.ExchangeBinance,
+		Symbol:      "BTCUSDT",
+		LastTradeID: 101,
+		LastOrderID: 201,
+		SyncedAt:    datatype.Time(syncedAt.Add(time.Hour)),
+	})
+	assert.NoError(t, err)
+
+	cursor, err = service.Load("binance", "BTCUSDT")
+	assert.NoError(t, err)
+	if assert.NotNil(t, cursor) {
+		assert.Equal(t, types.ExchangeBinance, cursor.Exchange)
+		assert.Equal(t, int64(101), cursor.LastTradeID)
+		assert.Equal(t, uint64(201), cursor.LastOrderID)
+		assert.True(t, syncedAt.Add(time.Hour).Equal(cursor.SyncedAt.Time()))
+	}
+
+	cursor, err = service.Load("max", "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor, "cursors are stored per session")
+
+	assert.NoError(t, service.Save(SyncCursor{Session: "binance", Exchange: types.ExchangeBinance, Symbol: "ETHUSDT", SyncedAt: datatype.Time(syncedAt)}))
+	cursors, err := service.QueryBySession("binance")
+	if assert.NoError(t, err) && assert.Len(t, cursors, 2) {
+		assert.Equal(t, "BTCUSDT", cursors[0].Symbol)
+		assert.Equal(t, "ETHUSDT", cursors[1].Symbol)
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSyncLocked is returned when the sync lock is held by another process
+var ErrSyncLocked = errors.New("sync is locked by another process")
+
+// SyncLockService acquires the database advisory locks, so that the processes sharing the same database
+// do not sync the same session at the same time. The advisory lock is bound to the database connection,
+// it's released by the database server when the process crashes.
+//
+// sqlite3 does not support the advisory lock, the lock is always acquired for sqlite3.
+type SyncLockService struct {
+	DB *sqlx.DB
+}
+
+// SyncLock is the acquired sync lock, it should be released by Unlock
+type SyncLock struct {
+	name   string
+	driver string
+
+	// conn is the dedicated connection that holds the advisory lock
+	conn *sql.Conn
+}
+
+// TryLock acquires the lock of the given name without waiting, ErrSyncLocked is returned if the lock is held by another process
+func (s *SyncLockService) TryLock(ctx context.Context, name string) (*SyncLock, error) {
+	lock := &SyncLock{name: name, driver: s.DB.DriverName()}
+
+	var query string
+	switch lock.driver {
+	case "mysql":
+		query = "SELECT GET_LOCK(?, 0) = 1"
+
+	case "postgres":
+		query = "SELECT pg_try_advisory_lock(hashtext($1))"
+
+	default:
+		return lock, nil
+	}
+
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, query, name).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, ErrSyncLocked
+	}
+
+	lock.conn = conn
+	return lock, nil
+}
+
+// Unlock releases the lock and returns the dedicated connection to the pool
+func (l *SyncLock) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+
+	var query string
+	switch l.driver {
+	case "mysql":
+		query = "SELECT RELEASE_LOCK(?)"
+
+	case "postgres":
+		query = "SELECT pg_advisory_unlock(hashtext($1))"
+	}
+
+	_, err := l.conn.ExecContext(ctx, query, l.name)
+	if closeErr := l.conn.Close(); err == nil {
+		err = closeErr
+	}
+
+	l.conn = nil
+	return err
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncLockService_SQLite(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &SyncLockService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	ctx := context.Background()
+	lock, err := service.TryLock(ctx, "bbgo-sync:binance")
+	assert.NoError(t, err)
+	assert.Nil(t, lock.conn, "sqlite3 should not hold a dedicated connection")
+
+	// the sqlite3 database is shared by one connection, the data queries should not be blocked by the lock
+	_, err = db.Exec("SELECT 1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, lock.Unlock(ctx))
+}
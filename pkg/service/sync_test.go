@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSyncProgressHandler(t *testing.T) {
+	var events []SyncProgress
+	var handler SyncProgressHandler = func(progress SyncProgress) {
+		events = append(events, progress)
+	}
+
+	progress := handler.forRecords(types.ExchangeBinance, "BTCUSDT", SyncRecordTrade)
+	progress.report(1)
+	progress.report(2)
+
+	assert.Equal(t, []SyncProgress{
+		{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Type: SyncRecordTrade, Records: 0},
+		{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Type: SyncRecordTrade, Records: 1},
+		{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Type: SyncRecordTrade, Records: 2},
+	}, events)
+
+	// nil handler should be safe
+	var nilHandler SyncProgressHandler
+	nilHandler.forRecords(types.ExchangeBinance, "BTCUSDT", SyncRecordTrade).report(1)
+}
+
+func TestSyncService_syncWindow(t *testing.T) {
+	s := &SyncService{}
+	assert.Equal(t, 24*time.Hour, s.syncWindow(types.ExchangeBinance))
+	assert.Equal(t, time.Duration(0), s.syncWindow(types.ExchangeMax), "the time range is not split by default")
+
+	s.Windows = map[types.ExchangeName]time.Duration{
+		types.ExchangeMax:     7 * 24 * time.Hour,
+		types.ExchangeBinance: 0,
+	}
+	assert.Equal(t, 7*24*time.Hour, s.syncWindow(types.ExchangeMax))
+	assert.Equal(t, time.Duration(0), s.syncWindow(types.ExchangeBinance), "the default window can be disabled")
+}
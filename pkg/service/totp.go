@@ -10,7 +10,20 @@ import (
 	"github.com/spf13/viper"
 )
 
+// TotpKeyOptions sets the issuer and the account name of the generated totp key,
+// they are shown in the authenticator app to tell the keys apart.
+type TotpKeyOptions struct {
+	Issuer      string
+	AccountName string
+}
+
 func NewDefaultTotpKey() (*otp.Key, error) {
+	return NewTotpKey(TotpKeyOptions{})
+}
+
+// NewTotpKey generates the totp key with the options, the totp-issuer and the totp-account-name flags take precedence
+// over the options, the hostname and the USER env var are used if neither of them is set.
+func NewTotpKey(options TotpKeyOptions) (*otp.Key, error) {
 	if keyURL := viper.GetString("totp-key-url"); len(keyURL) > 0 {
 		return otp.NewKeyFromURL(keyURL)
 	}
@@ -22,6 +35,14 @@ func NewDefaultTotpKey() (*otp.Key, error) {
 	totpIssuer := viper.GetString("totp-issuer")
 	totpAccountName := viper.GetString("totp-account-name")
 
+	if len(totpIssuer) == 0 {
+		totpIssuer = options.Issuer
+	}
+
+	if len(totpAccountName) == 0 {
+		totpAccountName = options.AccountName
+	}
+
 	if len(totpIssuer) == 0 {
 		hostname, err := os.Hostname()
 		if err != nil {
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTotpKey(t *testing.T) {
+	key, err := NewTotpKey(TotpKeyOptions{Issuer: "bbgo-grid", AccountName: "grid_bot"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bbgo-grid", key.Issuer())
+		assert.Equal(t, "grid_bot", key.AccountName())
+	}
+
+	viper.Set("totp-account-name", "alice")
+	defer viper.Set("totp-account-name", "")
+
+	key, err = NewTotpKey(TotpKeyOptions{Issuer: "bbgo-grid", AccountName: "grid_bot"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bbgo-grid", key.Issuer())
+		assert.Equal(t, "alice", key.AccountName(), "the flag should take precedence over the options")
+	}
+}
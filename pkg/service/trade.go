@@ -22,6 +22,9 @@ type QueryTradesOptions struct {
 	Symbol   string
 	LastGID  int64
 
+	// Since excludes the trades traded before the time, the trades are not filtered by the time if it's zero
+	Since time.Time
+
 	// ASC or DESC
 	Ordering string
 	Limit    int
@@ -44,38 +47,53 @@ type TradingVolumeQueryOptions struct {
 
 type TradeService struct {
 	DB *sqlx.DB
+
+	// ReaderDB is the optional connection pool of the read replica, the read-only queries use it if it's set
+	ReaderDB *sqlx.DB
 }
 
 func NewTradeService(db *sqlx.DB) *TradeService {
-	return &TradeService{db}
+	return &TradeService{DB: db}
 }
 
-func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol string) error {
-	isMargin := false
-	isIsolated := false
-	if marginExchange, ok := exchange.(types.MarginExchange); ok {
-		marginSettings := marginExchange.GetMarginSettings()
-		isMargin = marginSettings.IsMargin
-		isIsolated = marginSettings.IsIsolatedMargin
-		if marginSettings.IsIsolatedMargin {
-			symbol = marginSettings.IsolatedMarginSymbol
-		}
+// reader returns the connection pool for the read-only queries
+func (s *TradeService) reader() *sqlx.DB {
+	if s.ReaderDB != nil {
+		return s.ReaderDB
 	}
 
-	// records descending ordered
-	records, err := s.QueryLast(exchange.Name(), symbol, isMargin, isIsolated, 50)
+	return s.DB
+}
+
+func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol string) error {
+	_, err := s.sync(ctx, exchange, symbol, 0, nil, log.WithField("symbol", symbol))
+	return err
+}
+
+// sync syncs the trades from the given trade ID or the last stored trade, whichever is greater,
+// the ID of the last synced trade is returned. The logs are written with the fields of the given logger.
+func (s *TradeService) sync(ctx context.Context, exchange types.Exchange, symbol string, lastTradeID int64, progress progressFunc, logger *log.Entry) (int64, error) {
+	symbol, isMargin, isIsolated := tradeQueryFlags(exchange, symbol)
+
+	// records descending ordered, the primary database is queried since the replica may lag behind the inserts
+	records, err := s.queryLast(s.DB, exchange.Name(), symbol, isMargin, isIsolated, 50)
 	if err != nil {
-		return err
+		return lastTradeID, err
 	}
 
 	var tradeKeys = map[types.TradeKey]struct{}{}
-	var lastTradeID int64 = 1
 	if len(records) > 0 {
 		for _, record := range records {
 			tradeKeys[record.Key()] = struct{}{}
 		}
 
-		lastTradeID = records[0].ID
+		if records[0].ID > lastTradeID {
+			lastTradeID = records[0].ID
+		}
+	}
+
+	if lastTradeID == 0 {
+		lastTradeID = 1
 	}
 
 	b := &batch.TradeBatchQuery{Exchange: exchange}
@@ -83,19 +101,28 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 		LastTradeID: lastTradeID,
 	})
 
+	var fetched = 0
+	var stats TradeInsertStats
 	for trade := range tradeC {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return lastTradeID, ctx.Err()
 
 		case err := <-errC:
 			if err != nil {
-				return err
+				return lastTradeID, err
 			}
 
 		default:
 		}
 
+		fetched++
+		progress.report(fetched)
+
+		if trade.ID > lastTradeID {
+			lastTradeID = trade.ID
+		}
+
 		key := trade.Key()
 		if _, exists := tradeKeys[key]; exists {
 			continue
@@ -103,7 +130,7 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 
 		tradeKeys[key] = struct{}{}
 
-		log.Infof("inserting trade: %s %d %s %-4s price: %-13f volume: %-11f %5s %s",
+		logger.Infof("inserting trade: %s %d %s %-4s price: %-13f volume: %-11f %5s %s",
 			trade.Exchange,
 			trade.ID,
 			trade.Symbol,
@@ -113,12 +140,24 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 			trade.MakerOrTakerLabel(),
 			trade.Time.String())
 
-		if err := s.Insert(trade); err != nil {
-			return err
+		inserted, err := s.insert(trade)
+		if err != nil {
+			return lastTradeID, err
 		}
+
+		if inserted {
+			stats.Inserted++
+		} else {
+			stats.Skipped++
+		}
+	}
+
+	if err := <-errC; err != nil {
+		return lastTradeID, err
 	}
 
-	return <-errC
+	logger.Infof("%s %s trades synced: %d inserted, %d skipped", exchange.Name(), symbol, stats.Inserted, stats.Skipped)
+	return lastTradeID, nil
 }
 
 
@@ -133,15 +172,18 @@ func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVo
 
 	sql := ""
 	driverName := s.DB.DriverName()
-	if driverName == "mysql" {
+	switch driverName {
+	case "mysql":
 		sql = generateMysqlTradingVolumeQuerySQL(options)
-	} else {
+	case "postgres":
+		sql = generatePostgresTradingVolumeQuerySQL(options)
+	default:
 		sql = generateSqliteTradingVolumeSQL(options)
 	}
 
 	log.Info(sql)
 
-	rows, err := s.DB.NamedQuery(sql, args)
+	rows, err := s.reader().NamedQuery(sql, args)
 	if err != nil {
 		return nil, errors.Wrap(err, "query last trade error")
 	}
@@ -265,12 +307,69 @@ func generateMysqlTradingVolumeQuerySQL(options TradingVolumeQueryOptions) strin
 	return sql
 }
 
+func generatePostgresTimeRangeClauses(timeRangeColumn, period string) (selectors []string, groupBys []string, orderBys []string) {
+	year := "CAST(EXTRACT(YEAR FROM " + timeRangeColumn + ") AS INTEGER)"
+	month := "CAST(EXTRACT(MONTH FROM " + timeRangeColumn + ") AS INTEGER)"
+	day := "CAST(EXTRACT(DAY FROM " + timeRangeColumn + ") AS INTEGER)"
+
+	switch period {
+	case "month":
+		selectors = append(selectors, year+" AS year", month+" AS month")
+		groupBys = append([]string{month, year}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC")
+
+	case "year":
+		selectors = append(selectors, year+" AS year")
+		groupBys = append([]string{year}, groupBys...)
+		orderBys = append(orderBys, "year ASC")
+
+	case "day":
+		fallthrough
+
+	default:
+		selectors = append(selectors, year+" AS year", month+" AS month", day+" AS day")
+		groupBys = append([]string{day, month, year}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC", "day ASC")
+	}
+
+	return
+}
+
+func generatePostgresTradingVolumeQuerySQL(options TradingVolumeQueryOptions) string {
+	timeRangeColumn := "traded_at"
+	sel, groupBys, orderBys := generatePostgresTimeRangeClauses(timeRangeColumn, options.GroupByPeriod)
+
+	switch options.SegmentBy {
+	case "symbol":
+		sel = append(sel, "symbol")
+		groupBys = append([]string{"symbol"}, groupBys...)
+		orderBys = append(orderBys, "symbol")
+	case "exchange":
+		sel = append(sel, "exchange")
+		groupBys = append([]string{"exchange"}, groupBys...)
+		orderBys = append(orderBys, "exchange")
+	}
+
+	sel = append(sel, "SUM(quantity * price) AS quote_volume")
+	where := []string{timeRangeColumn + " > :start_time"}
+	sql := `SELECT ` + strings.Join(sel, ", ") + ` FROM trades` +
+		` WHERE ` + strings.Join(where, " AND ") +
+		` GROUP BY ` + strings.Join(groupBys, ", ") +
+		` ORDER BY ` + strings.Join(orderBys, ", ")
+
+	return sql
+}
+
 // QueryLast queries the last trade from the database
 func (s *TradeService) QueryLast(ex types.ExchangeName, symbol string, isMargin, isIsolated bool, limit int) ([]types.Trade, error) {
+	return s.queryLast(s.reader(), ex, symbol, isMargin, isIsolated, limit)
+}
+
+func (s *TradeService) queryLast(db *sqlx.DB, ex types.ExchangeName, symbol string, isMargin, isIsolated bool, limit int) ([]types.Trade, error) {
 	log.Debugf("querying last trade exchange = %s AND symbol = %s AND is_margin = %v AND is_isolated = %v", ex, symbol, isMargin, isIsolated)
 
 	sql := "SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated ORDER BY gid DESC LIMIT :limit"
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+	rows, err := db.NamedQuery(sql, map[string]interface{}{
 		"symbol":      symbol,
 		"exchange":    ex,
 		"is_margin":   isMargin,
@@ -286,9 +385,30 @@ func (s *TradeService) QueryLast(ex types.ExchangeName, symbol string, isMargin,
 	return s.scanRows(rows)
 }
 
+// QueryAfterGID queries the trades stored after the given gid in the storing order, it's for aggregating the newly
+// stored trades incrementally. The primary database is queried since the replica may lag behind the inserts.
+func (s *TradeService) QueryAfterGID(ctx context.Context, ex types.ExchangeName, symbol string, isMargin, isIsolated bool, gid int64, limit int) ([]types.Trade, error) {
+	sql := "SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated AND gid > :gid ORDER BY gid ASC LIMIT :limit"
+	rows, err := s.DB.NamedQueryContext(ctx, sql, map[string]interface{}{
+		"exchange":    ex,
+		"symbol":      symbol,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+		"gid":         gid,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
 func (s *TradeService) QueryForTradingFeeCurrency(ex types.ExchangeName, symbol string, feeCurrency string) ([]types.Trade, error) {
 	sql := "SELECT * FROM trades WHERE exchange = :exchange AND (symbol = :symbol OR fee_currency = :fee_currency) ORDER BY traded_at ASC"
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+	rows, err := s.reader().NamedQuery(sql, map[string]interface{}{
 		"exchange":     ex,
 		"symbol":       symbol,
 		"fee_currency": feeCurrency,
@@ -310,8 +430,10 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 	args := map[string]interface{}{
 		"exchange": options.Exchange,
 		"symbol":   options.Symbol,
+		"gid":      options.LastGID,
+		"since":    options.Since,
 	}
-	rows, err := s.DB.NamedQuery(sql, args)
+	rows, err := s.reader().NamedQuery(sql, args)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +464,7 @@ func (s *TradeService) Load(ctx context.Context, id int64) (*types.Trade, error)
 }
 
 func (s *TradeService) Mark(ctx context.Context, id int64, strategyID string) error {
-	result, err := s.DB.NamedExecContext(ctx, "UPDATE `trades` SET `strategy` = :strategy WHERE `id` = :id", map[string]interface{}{
+	result, err := s.DB.NamedExecContext(ctx, "UPDATE trades SET strategy = :strategy WHERE id = :id", map[string]interface{}{
 		"id":       id,
 		"strategy": strategyID,
 	})
@@ -363,7 +485,7 @@ func (s *TradeService) Mark(ctx context.Context, id int64, strategyID string) er
 }
 
 func (s *TradeService) UpdatePnL(ctx context.Context, id int64, pnl float64) error {
-	result, err := s.DB.NamedExecContext(ctx, "UPDATE `trades` SET `pnl` = :pnl WHERE `id` = :id", map[string]interface{}{
+	result, err := s.DB.NamedExecContext(ctx, "UPDATE trades SET pnl = :pnl WHERE id = :id", map[string]interface{}{
 		"id":  id,
 		"pnl": pnl,
 	})
@@ -410,6 +532,10 @@ func queryTradesSQL(options QueryTradesOptions) string {
 		}
 	}
 
+	if !options.Since.IsZero() {
+		where = append(where, "traded_at >= :since")
+	}
+
 	sql := `SELECT * FROM trades`
 
 	if len(where) > 0 {
@@ -425,6 +551,24 @@ func queryTradesSQL(options QueryTradesOptions) string {
 	return sql
 }
 
+// DeleteBefore deletes the trades of the exchange symbol traded before the given time in batches,
+// the margin settings of the exchange are applied as the sync does. The number of the deleted trades is returned.
+func (s *TradeService) DeleteBefore(ctx context.Context, exchange types.Exchange, symbol string, until time.Time) (int64, error) {
+	symbol, isMargin, isIsolated := tradeQueryFlags(exchange, symbol)
+	deleted, err := deleteInBatches(ctx, s.DB, "trades", `exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated AND traded_at < :until`, map[string]interface{}{
+		"exchange":    exchange.Name(),
+		"symbol":      symbol,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+		"until":       until,
+	})
+	if err != nil {
+		return deleted, errors.Wrap(err, "delete trades error")
+	}
+
+	return deleted, nil
+}
+
 func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err error) {
 	for rows.Next() {
 		var trade types.Trade
@@ -438,10 +582,61 @@ func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err erro
 	return trades, rows.Err()
 }
 
+// TradeInsertStats is the number of the inserted trades and the duplicated trades skipped by the insert
+type TradeInsertStats struct {
+	Inserted int `json:"inserted"`
+	Skipped  int `json:"skipped"`
+}
+
+// Insert inserts the trade, the trade is skipped if it's already stored
 func (s *TradeService) Insert(trade types.Trade) error {
-	_, err := s.DB.NamedExec(`
-			INSERT INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_isolated)
-			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_isolated)`,
-		trade)
+	_, err := s.insert(trade)
 	return err
 }
+
+// InsertTrades inserts the trades and skips the stored trades, so that re-syncing the overlapped time range is safe
+func (s *TradeService) InsertTrades(trades []types.Trade) (TradeInsertStats, error) {
+	var stats TradeInsertStats
+	for _, trade := range trades {
+		inserted, err := s.insert(trade)
+		if err != nil {
+			return stats, err
+		}
+
+		if inserted {
+			stats.Inserted++
+		} else {
+			stats.Skipped++
+		}
+	}
+
+	return stats, nil
+}
+
+// insert inserts the trade and ignores the conflict of the unique key (exchange, symbol, side, id),
+// the side is a part of the key since the self-trade has the same trade ID on both sides.
+// It returns false if the trade is already stored.
+func (s *TradeService) insert(trade types.Trade) (bool, error) {
+	sql := `INSERT INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_isolated)
+			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_isolated)`
+
+	switch s.DB.DriverName() {
+	case "mysql":
+		// the affected rows is 0 if the duplicated row is not changed
+		sql += ` ON DUPLICATE KEY UPDATE id = id`
+	default:
+		sql += ` ON CONFLICT (exchange, symbol, side, id) DO NOTHING`
+	}
+
+	result, err := s.DB.NamedExec(sql, trade)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// reconcileQueryLimit is the page size of the exchange trade query
+const reconcileQueryLimit = 1000
+
+// TradeReconciliation is the two-way diff between the exchange trades and the stored trades of a symbol
+type TradeReconciliation struct {
+	Exchange  types.ExchangeName `json:"exchange"`
+	Symbol    string             `json:"symbol"`
+	StartTime time.Time          `json:"startTime"`
+	EndTime   time.Time          `json:"endTime"`
+
+	// Matched is the number of the trades that are found both on the exchange and in the database
+	Matched int `json:"matched"`
+
+	// Missing is the exchange trades that are not stored in the database, e.g., the trades executed while bbgo was offline
+	Missing []types.Trade `json:"missing,omitempty"`
+
+	// Unknown is the stored trades that are not found on the exchange, the records may be corrupted
+	Unknown []types.Trade `json:"unknown,omitempty"`
+
+	// Inserted is the number of the missing trades inserted into the database
+	Inserted int `json:"inserted"`
+}
+
+// Consistent returns true if the exchange trades and the stored trades are the same
+func (r *TradeReconciliation) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Unknown) == 0
+}
+
+// Reconcile compares the exchange trades of the symbol traded in the time range [since, now) with the stored trades,
+// the missing trades are inserted if insertMissing is true. The unknown trades are reported only, since they
+// need to be checked manually.
+func (s *TradeService) Reconcile(ctx context.Context, exchange types.Exchange, symbol string, since time.Time, insertMissing bool) (*TradeReconciliation, error) {
+	until := time.Now()
+
+	// the symbol is overridden by the isolated margin symbol like the trade sync
+	symbol, isMargin, isIsolated := tradeQueryFlags(exchange, symbol)
+
+	remoteTrades, err := queryExchangeTrades(ctx, exchange, symbol, since, until)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can not query the %s trades from the exchange", symbol)
+	}
+
+	localTrades, err := s.QueryRange(exchange.Name(), symbol, isMargin, isIsolated, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &TradeReconciliation{
+		Exchange:  exchange.Name(),
+		Symbol:    symbol,
+		StartTime: since,
+		EndTime:   until,
+	}
+
+	var localKeys = make(map[types.TradeKey]struct{}, len(localTrades))
+	for _, trade := range localTrades {
+		localKeys[trade.Key()] = struct{}{}
+	}
+
+	var remoteKeys = make(map[types.TradeKey]struct{}, len(remoteTrades))
+	for _, trade := range remoteTrades {
+		remoteKeys[trade.Key()] = struct{}{}
+
+		if _, ok := localKeys[trade.Key()]; ok {
+			reconciliation.Matched++
+		} else {
+			reconciliation.Missing = append(reconciliation.Missing, trade)
+		}
+	}
+
+	for _, trade := range localTrades {
+		if _, ok := remoteKeys[trade.Key()]; !ok {
+			reconciliation.Unknown = append(reconciliation.Unknown, trade)
+		}
+	}
+
+	if insertMissing {
+		for _, trade := range reconciliation.Missing {
+			inserted, err := s.insert(trade)
+			if err != nil {
+				return reconciliation, errors.Wrapf(err, "can not insert the missing trade %d", trade.ID)
+			}
+
+			// the trade may be stored by the concurrent sync
+			if inserted {
+				reconciliation.Inserted++
+			}
+		}
+	}
+
+	return reconciliation, nil
+}
+
+// QueryRange queries the stored trades of the symbol traded in the time range [since, until)
+func (s *TradeService) QueryRange(ex types.ExchangeName, symbol string, isMargin, isIsolated bool, since, until time.Time) ([]types.Trade, error) {
+	sql := "SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated AND traded_at >= :since AND traded_at < :until ORDER BY traded_at ASC"
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"exchange":    ex,
+		"symbol":      symbol,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+		"since":       since,
+		"until":       until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query trades error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// queryExchangeTrades queries the exchange trades of the symbol traded in the time range [since, until),
+// the first page is queried by the start time and the following pages are queried by the last trade ID.
+func queryExchangeTrades(ctx context.Context, exchange types.Exchange, symbol string, since, until time.Time) ([]types.Trade, error) {
+	var trades []types.Trade
+	var tradeKeys = make(map[types.TradeKey]struct{})
+
+	options := &types.TradeQueryOptions{StartTime: &since, Limit: reconcileQueryLimit}
+	for {
+		page, err := exchange.QueryTrades(ctx, symbol, options)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastTradeID = options.LastTradeID
+		var added = 0
+		for _, trade := range page {
+			if trade.ID > lastTradeID {
+				lastTradeID = trade.ID
+			}
+
+			key := trade.Key()
+			if _, ok := tradeKeys[key]; ok {
+				continue
+			}
+
+			tradeKeys[key] = struct{}{}
+			added++
+
+			if trade.Time.Time().Before(since) || !trade.Time.Time().Before(until) {
+				continue
+			}
+
+			trades = append(trades, trade)
+		}
+
+		if added == 0 {
+			return trades, nil
+		}
+
+		options = &types.TradeQueryOptions{LastTradeID: lastTradeID, Limit: reconcileQueryLimit}
+	}
+}
+
+// tradeQueryFlags returns the symbol and the margin flags of the stored trades of the exchange symbol,
+// the trades of an isolated margin exchange are stored with the isolated margin symbol.
+func tradeQueryFlags(exchange types.Exchange, symbol string) (querySymbol string, isMargin, isIsolated bool) {
+	querySymbol = symbol
+	if marginExchange, ok := exchange.(types.MarginExchange); ok {
+		marginSettings := marginExchange.GetMarginSettings()
+		isMargin = marginSettings.IsMargin
+		isIsolated = marginSettings.IsIsolatedMargin
+		if marginSettings.IsIsolatedMargin && !marginSettings.IsIsolatedMarginSymbol(symbol) {
+			querySymbol = marginSettings.IsolatedMarginSymbol
+		}
+	}
+
+	return querySymbol, isMargin, isIsolated
+}
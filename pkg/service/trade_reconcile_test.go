@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// tradeExchange serves the given trades by pages, the other exchange methods are not implemented
+type tradeExchange struct {
+	types.Exchange
+
+	trades []types.Trade
+	limit  int
+}
+
+func (e *tradeExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *tradeExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	var trades []types.Trade
+	for _, trade := range e.trades {
+		// the last trade ID is inclusive like binance
+		if options.LastTradeID > 0 && trade.ID < options.LastTradeID {
+			continue
+		}
+
+		if options.StartTime != nil && trade.Time.Time().Before(*options.StartTime) {
+			continue
+		}
+
+		trades = append(trades, trade)
+		if len(trades) == e.limit {
+			break
+		}
+	}
+
+	return trades, nil
+}
+
+func newTestTrade(id int64, tradeTime time.Time) types.Trade {
+	return types.Trade{
+		ID:            id,
+		OrderID:       uint64(id),
+		Exchange:      types.ExchangeBinance.String(),
+		Price:         1000.5,
+		Quantity:      0.5,
+		QuoteQuantity: 500.25,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Time:          datatype.Time(tradeTime),
+	}
+}
+
+func TestTradeService_Reconcile(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &TradeService{DB: xdb}
+
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+
+	ex := &tradeExchange{limit: 2}
+	for i := int64(1); i <= 5; i++ {
+		ex.trades = append(ex.trades, newTestTrade(i, since.Add(time.Duration(i)*time.Hour)))
+	}
+
+	// the trade before the reconcile time range is ignored
+	ex.trades = append([]types.Trade{newTestTrade(0, since.Add(-time.Hour))}, ex.trades...)
+
+	assert.NoError(t, service.Insert(ex.trades[1]))
+	assert.NoError(t, service.Insert(ex.trades[2]))
+	assert.NoError(t, service.Insert(newTestTrade(99, since.Add(10*time.Hour))))
+
+	reconciliation, err := service.Reconcile(ctx, ex, "BTCUSDT", since, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, reconciliation.Consistent())
+	assert.Equal(t, 2, reconciliation.Matched)
+	if assert.Len(t, reconciliation.Missing, 3) {
+		assert.Equal(t, int64(3), reconciliation.Missing[0].ID)
+	}
+	if assert.Len(t, reconciliation.Unknown, 1) {
+		assert.Equal(t, int64(99), reconciliation.Unknown[0].ID)
+	}
+	assert.Equal(t, 0, reconciliation.Inserted)
+
+	reconciliation, err = service.Reconcile(ctx, ex, "BTCUSDT", since, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, reconciliation.Inserted)
+
+	reconciliation, err = service.Reconcile(ctx, ex, "BTCUSDT", since, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, reconciliation.Matched)
+	assert.Empty(t, reconciliation.Missing)
+	assert.Len(t, reconciliation.Unknown, 1, "the unknown trades should not be removed")
+}
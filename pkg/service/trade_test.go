@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +56,76 @@ func Test_tradeService(t *testing.T) {
 	assert.Equal(t, 10.0, tradeRecord.PnL.Float64)
 }
 
+func TestTradeService_InsertTrades(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &TradeService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	trades := []types.Trade{
+		{ID: 1, OrderID: 1, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1000.0, Quantity: 0.1},
+		{ID: 2, OrderID: 2, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1000.0, Quantity: 0.1},
+	}
+
+	stats, err := service.InsertTrades(trades)
+	assert.NoError(t, err)
+	assert.Equal(t, TradeInsertStats{Inserted: 2}, stats)
+
+	// the overlapped trades are skipped, the self-trade of the other side is inserted
+	trades = append(trades, types.Trade{ID: 2, OrderID: 3, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 1000.0, Quantity: 0.1})
+	stats, err = service.InsertTrades(trades)
+	assert.NoError(t, err)
+	assert.Equal(t, TradeInsertStats{Inserted: 1, Skipped: 2}, stats)
+
+	assert.NoError(t, service.Insert(trades[0]), "the duplicated trade is not an error")
+
+	records, err := service.QueryLast("binance", "BTCUSDT", false, false, 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestTradeService_DeleteBefore(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &TradeService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	now := time.Now().UTC()
+	_, err = service.InsertTrades([]types.Trade{
+		newTestTrade(1, now.Add(-3*time.Hour)),
+		newTestTrade(2, now.Add(-2*time.Hour)),
+		newTestTrade(3, now.Add(-time.Minute)),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ethTrade := newTestTrade(4, now.Add(-3*time.Hour))
+	ethTrade.Symbol = "ETHUSDT"
+	assert.NoError(t, service.Insert(ethTrade))
+
+	deleted, err := service.DeleteBefore(context.Background(), &tradeExchange{}, "BTCUSDT", now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	trades, err := service.QueryLast(types.ExchangeBinance, "BTCUSDT", false, false, 10)
+	if assert.NoError(t, err) && assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(3), trades[0].ID)
+	}
+
+	trades, err = service.QueryLast(types.ExchangeBinance, "ETHUSDT", false, false, 10)
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1, "the other symbols should not be deleted")
+}
+
 func Test_queryTradingVolumeSQL(t *testing.T) {
 	t.Run("group by different period", func(t *testing.T) {
 		o := TradingVolumeQueryOptions{
@@ -72,6 +143,14 @@ func Test_queryTradingVolumeSQL(t *testing.T) {
 		}
 	})
 
+	t.Run("postgres", func(t *testing.T) {
+		o := TradingVolumeQueryOptions{
+			GroupByPeriod: "year",
+			SegmentBy:     "symbol",
+		}
+		assert.Equal(t, "SELECT CAST(EXTRACT(YEAR FROM traded_at) AS INTEGER) AS year, symbol, SUM(quantity * price) AS quote_volume FROM trades WHERE traded_at > :start_time GROUP BY symbol, CAST(EXTRACT(YEAR FROM traded_at) AS INTEGER) ORDER BY year ASC, symbol", generatePostgresTradingVolumeQuerySQL(o))
+	})
+
 }
 
 func Test_queryTradesSQL(t *testing.T) {
@@ -95,6 +174,13 @@ func Test_queryTradesSQL(t *testing.T) {
 		assert.Equal(t, "SELECT * FROM trades WHERE gid < :gid ORDER BY gid DESC LIMIT 500", queryTradesSQL(QueryTradesOptions{LastGID: 1, Ordering: "DESC", Limit: 500}))
 	})
 
+	t.Run("filter by traded time", func(t *testing.T) {
+		assert.Equal(t, "SELECT * FROM trades WHERE symbol = :symbol AND traded_at >= :since ORDER BY gid ASC", queryTradesSQL(QueryTradesOptions{
+			Symbol: "btc",
+			Since:  time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		}))
+	})
+
 	t.Run("convert all options", func(t *testing.T) {
 		assert.Equal(t, "SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND gid < :gid ORDER BY gid DESC LIMIT 500", queryTradesSQL(QueryTradesOptions{
 			Exchange: "max",
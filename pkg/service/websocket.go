@@ -18,6 +18,12 @@ type WebsocketClientBase struct {
 	reconnectC        chan struct{}
 	reconnectDuration time.Duration
 
+	// dialer is the websocket dialer, websocket.DefaultDialer is used if it's nil
+	dialer *websocket.Dialer
+
+	// connectTimeout is the deadline of the handshake, no deadline is set if it's zero
+	connectTimeout time.Duration
+
 	connectedCallbacks    []func(conn *websocket.Conn)
 	disconnectedCallbacks []func(conn *websocket.Conn)
 	messageCallbacks      []func(message []byte)
@@ -75,9 +81,31 @@ func (s *WebsocketClientBase) Reconnect() {
 	}
 }
 
+// SetDialer sets the websocket dialer, e.g., the dialer connecting through a proxy, it should be called before Connect
+func (s *WebsocketClientBase) SetDialer(dialer *websocket.Dialer) {
+	s.dialer = dialer
+}
+
+// SetConnectTimeout sets the deadline of the handshake, it should be called before Connect
+func (s *WebsocketClientBase) SetConnectTimeout(timeout time.Duration) {
+	s.connectTimeout = timeout
+}
+
 func (s *WebsocketClientBase) connect(ctx context.Context) error {
 	dialer := websocket.DefaultDialer
-	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
+	if s.dialer != nil {
+		dialer = s.dialer
+	}
+
+	// the deadline only applies to the handshake, the connection lives with the context
+	dialCtx := ctx
+	if s.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, s.connectTimeout)
+		defer cancel()
+	}
+
+	conn, _, err := dialer.DialContext(dialCtx, s.baseURL, nil)
 	if err != nil {
 		return err
 	}
@@ -15,6 +15,10 @@ type WithdrawService struct {
 
 // Sync syncs the withdraw records into db
 func (s *WithdrawService) Sync(ctx context.Context, ex types.Exchange) error {
+	return s.sync(ctx, ex, nil)
+}
+
+func (s *WithdrawService) sync(ctx context.Context, ex types.Exchange, progress progressFunc) error {
 	txnIDs := map[string]struct{}{}
 
 	// query descending
@@ -43,7 +47,9 @@ func (s *WithdrawService) Sync(ctx context.Context, ex types.Exchange) error {
 		return err
 	}
 
-	for _, withdraw := range withdraws {
+	for idx, withdraw := range withdraws {
+		progress.report(idx + 1)
+
 		if _, exists := txnIDs[withdraw.TransactionID]; exists {
 			continue
 		}
@@ -57,7 +63,7 @@ func (s *WithdrawService) Sync(ctx context.Context, ex types.Exchange) error {
 }
 
 func (s *WithdrawService) QueryLast(ex types.ExchangeName, limit int) ([]types.Withdraw, error) {
-	sql := "SELECT * FROM `withdraws` WHERE `exchange` = :exchange ORDER BY `time` DESC LIMIT :limit"
+	sql := "SELECT * FROM withdraws WHERE exchange = :exchange ORDER BY time DESC LIMIT :limit"
 	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
 		"exchange": ex,
 		"limit":    limit,
@@ -74,7 +80,33 @@ func (s *WithdrawService) Query(exchangeName types.ExchangeName) ([]types.Withdr
 	args := map[string]interface{}{
 		"exchange": exchangeName,
 	}
-	sql := "SELECT * FROM `withdraws` WHERE `exchange` = :exchange ORDER BY `time` ASC"
+	sql := "SELECT * FROM withdraws WHERE exchange = :exchange ORDER BY time ASC"
+	rows, err := s.DB.NamedQuery(sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QuerySince queries the withdraws of the exchange since the given time, the empty asset means all assets.
+// The withdraws are stored by the exchange name, not by the session, so the records of all the accounts on the
+// exchange are returned.
+func (s *WithdrawService) QuerySince(exchangeName types.ExchangeName, asset string, since time.Time) ([]types.Withdraw, error) {
+	args := map[string]interface{}{
+		"exchange": exchangeName,
+		"asset":    asset,
+		"since":    since,
+	}
+
+	sql := "SELECT * FROM withdraws WHERE exchange = :exchange AND time >= :since"
+	if len(asset) > 0 {
+		sql += " AND asset = :asset"
+	}
+	sql += " ORDER BY time ASC"
+
 	rows, err := s.DB.NamedQuery(sql, args)
 	if err != nil {
 		return nil, err
@@ -38,4 +38,29 @@ func TestWithdrawService(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, withdraws)
 	assert.Equal(t, types.ExchangeMax, withdraws[0].Exchange)
+
+	err = service.Insert(types.Withdraw{
+		Exchange:      types.ExchangeMax,
+		Asset:         "USDT",
+		Amount:        100.0,
+		Address:       "test",
+		TransactionID: "02",
+		Network:       "erc20",
+		ApplyTime:     datatype.Time(time.Now()),
+	})
+	assert.NoError(t, err)
+
+	withdraws, err = service.QuerySince(types.ExchangeMax, "USDT", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, withdraws, 1) {
+		assert.Equal(t, "02", withdraws[0].TransactionID)
+	}
+
+	withdraws, err = service.QuerySince(types.ExchangeMax, "", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, withdraws, 2)
+
+	withdraws, err = service.QuerySince(types.ExchangeMax, "", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, withdraws)
 }
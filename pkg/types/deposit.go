@@ -1,8 +1,12 @@
 package types
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/slack-go/slack"
+
 	"github.com/c9s/bbgo/pkg/datatype"
 )
 
@@ -38,3 +42,54 @@ type Deposit struct {
 func (d Deposit) EffectiveTime() time.Time {
 	return d.Time.Time()
 }
+
+// ErrDepositAddressNotFound is returned by QueryDepositAddress if the deposit address of the asset is not created yet
+var ErrDepositAddressNotFound = errors.New("deposit address not found")
+
+// DepositAddress is the deposit address of an asset on the exchange account
+type DepositAddress struct {
+	Exchange ExchangeName `json:"exchange"`
+	Asset    string       `json:"asset"`
+
+	// Network is the chain of the address, e.g., "ERC20", it's empty if the exchange does not tell
+	Network string `json:"network,omitempty"`
+
+	Address string `json:"address"`
+
+	// AddressTag is the memo or the destination tag required by some assets, e.g., XRP
+	AddressTag string `json:"addressTag,omitempty"`
+}
+
+func (a DepositAddress) PlainText() string {
+	text := fmt.Sprintf("%s %s deposit address: %s", a.Exchange, a.Asset, a.Address)
+	if len(a.Network) > 0 {
+		text += fmt.Sprintf(" (%s)", a.Network)
+	}
+
+	if len(a.AddressTag) > 0 {
+		text += fmt.Sprintf(", tag: %s", a.AddressTag)
+	}
+
+	return text
+}
+
+func (a DepositAddress) SlackAttachment() slack.Attachment {
+	fields := []slack.AttachmentField{
+		{Title: "Exchange", Value: string(a.Exchange), Short: true},
+		{Title: "Asset", Value: a.Asset, Short: true},
+		{Title: "Address", Value: a.Address},
+	}
+
+	if len(a.Network) > 0 {
+		fields = append(fields, slack.AttachmentField{Title: "Network", Value: a.Network, Short: true})
+	}
+
+	if len(a.AddressTag) > 0 {
+		fields = append(fields, slack.AttachmentField{Title: "Tag", Value: a.AddressTag, Short: true})
+	}
+
+	return slack.Attachment{
+		Text:   fmt.Sprintf("*%s* Deposit Address", a.Asset),
+		Fields: fields,
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const DateFormat = "2006-01-02"
@@ -95,11 +98,45 @@ type ExchangeMarketDataService interface {
 	QueryKLines(ctx context.Context, symbol string, interval Interval, options KLineQueryOptions) ([]KLine, error)
 }
 
+// RateLimitedExchange is implemented by the exchanges that can limit the request rate of the REST API client
+type RateLimitedExchange interface {
+	SetRateLimiter(limiter *rate.Limiter)
+}
+
+// SandboxExchange is implemented by the exchanges that provide the testnet (sandbox) endpoints,
+// UseSandbox switches the REST API client and the streams of the exchange to the testnet endpoints.
+type SandboxExchange interface {
+	UseSandbox()
+}
+
+// ProxyExchange is implemented by the exchanges that can connect the REST API client and the streams through a proxy,
+// SetProxy only affects the streams created after it's called
+type ProxyExchange interface {
+	SetProxy(proxyURL *url.URL)
+}
+
+// ServerTimeExchange is implemented by the exchanges that expose the server time endpoint,
+// the server time is compared with the local clock since the signed requests fail if the clock drifts
+type ServerTimeExchange interface {
+	QueryServerTime(ctx context.Context) (time.Time, error)
+}
+
 type ExchangeTransferService interface {
 	QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []Deposit, err error)
 	QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []Withdraw, err error)
 }
 
+// ExchangeDepositAddressService is implemented by the exchanges that can query the deposit address of an asset,
+// ErrDepositAddressNotFound is returned if the address is not created yet
+type ExchangeDepositAddressService interface {
+	QueryDepositAddress(ctx context.Context, asset string) (*DepositAddress, error)
+}
+
+// DepositAddressCreator is implemented by the exchanges that require the deposit address to be created before it's used
+type DepositAddressCreator interface {
+	CreateDepositAddress(ctx context.Context, asset string) (*DepositAddress, error)
+}
+
 type ExchangeRewardService interface {
 	QueryRewards(ctx context.Context, startTime time.Time) ([]Reward, error)
 }
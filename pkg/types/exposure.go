@@ -0,0 +1,75 @@
+package types
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// AccountType is the account type of an exposure
+type AccountType string
+
+const (
+	AccountTypeSpot           = AccountType("spot")
+	AccountTypeMargin         = AccountType("margin")
+	AccountTypeIsolatedMargin = AccountType("isolated margin")
+)
+
+// SessionExposure is the position of an asset in a session account
+type SessionExposure struct {
+	Session     string      `json:"session"`
+	Exchange    string      `json:"exchange"`
+	AccountType AccountType `json:"accountType"`
+
+	// Symbol is the isolated margin symbol of the position, it's empty for the spot and the cross margin accounts
+	Symbol string `json:"symbol,omitempty"`
+
+	// Long is the held quantity, Short is the borrowed quantity including the interest
+	Long  fixedpoint.Value `json:"long"`
+	Short fixedpoint.Value `json:"short"`
+
+	// Price is the last price of the asset in QuoteCurrency, the notional is not calculated if it's zero
+	Price         fixedpoint.Value `json:"price,omitempty"`
+	QuoteCurrency string           `json:"quoteCurrency,omitempty"`
+}
+
+// Net returns the long quantity minus the short quantity
+func (e SessionExposure) Net() fixedpoint.Value {
+	return e.Long - e.Short
+}
+
+// Notional returns the net notional value in QuoteCurrency
+func (e SessionExposure) Notional() fixedpoint.Value {
+	return e.Net().Mul(e.Price)
+}
+
+// NetExposure is the aggregated exposure of an asset across the sessions
+type NetExposure struct {
+	Asset string `json:"asset"`
+
+	Long  fixedpoint.Value `json:"long"`
+	Short fixedpoint.Value `json:"short"`
+
+	// Notionals is the net notional value by the quote currency, the sessions may quote the asset in different currencies
+	Notionals map[string]fixedpoint.Value `json:"notionals"`
+
+	Sessions []SessionExposure `json:"sessions"`
+}
+
+// Net returns the aggregated long quantity minus the aggregated short quantity
+func (e *NetExposure) Net() fixedpoint.Value {
+	return e.Long - e.Short
+}
+
+// Add adds the session exposure to the aggregation
+func (e *NetExposure) Add(exposure SessionExposure) {
+	e.Long += exposure.Long
+	e.Short += exposure.Short
+	e.Sessions = append(e.Sessions, exposure)
+
+	if exposure.Price > 0 && len(exposure.QuoteCurrency) > 0 {
+		if e.Notionals == nil {
+			e.Notionals = make(map[string]fixedpoint.Value)
+		}
+
+		e.Notionals[exposure.QuoteCurrency] += exposure.Notional()
+	}
+}
@@ -0,0 +1,20 @@
+package types
+
+// FuturesExchange is implemented by the exchanges supporting the futures (perpetual) account,
+// UseFutures switches the REST API client and the user data stream of the exchange to the futures account.
+type FuturesExchange interface {
+	UseFutures()
+	GetFuturesSettings() FuturesSettings
+}
+
+type FuturesSettings struct {
+	IsFutures bool
+}
+
+func (s FuturesSettings) GetFuturesSettings() FuturesSettings {
+	return s
+}
+
+func (s *FuturesSettings) UseFutures() {
+	s.IsFutures = true
+}
@@ -1,6 +1,10 @@
 package types
 
-import "github.com/c9s/bbgo/pkg/fixedpoint"
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
 
 type MarginExchange interface {
 	UseMargin()
@@ -9,24 +13,59 @@ type MarginExchange interface {
 	// QueryMarginAccount(ctx context.Context) (*binance.MarginAccount, error)
 }
 
+// MarginAccountService is implemented by the exchanges supporting the cross margin account query
+type MarginAccountService interface {
+	QueryMarginAccount(ctx context.Context) (*MarginAccount, error)
+}
+
+// IsolatedMarginAccountService is implemented by the exchanges supporting the isolated margin account query,
+// all the isolated margin symbols are queried if no symbol is given
+type IsolatedMarginAccountService interface {
+	QueryIsolatedMarginAccount(ctx context.Context, symbols ...string) (*IsolatedMarginAccount, error)
+}
+
 type MarginSettings struct {
-	IsMargin             bool
-	IsIsolatedMargin     bool
+	IsMargin         bool
+	IsIsolatedMargin bool
+
+	// IsolatedMarginSymbol is the first isolated margin symbol, which is used for the user data stream
 	IsolatedMarginSymbol string
+
+	// IsolatedMarginSymbols are all the isolated margin symbols of the account
+	IsolatedMarginSymbols []string
 }
 
 func (e MarginSettings) GetMarginSettings() MarginSettings {
 	return e
 }
 
+// IsIsolatedMarginSymbol returns true if the symbol is one of the isolated margin symbols
+func (e MarginSettings) IsIsolatedMarginSymbol(symbol string) bool {
+	for _, s := range e.IsolatedMarginSymbols {
+		if s == symbol {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e *MarginSettings) UseMargin() {
 	e.IsMargin = true
 }
 
+// UseIsolatedMargin enables the isolated margin of the symbol, it can be called for each of the isolated margin symbols
 func (e *MarginSettings) UseIsolatedMargin(symbol string) {
 	e.IsMargin = true
 	e.IsIsolatedMargin = true
-	e.IsolatedMarginSymbol = symbol
+
+	if len(e.IsolatedMarginSymbol) == 0 {
+		e.IsolatedMarginSymbol = symbol
+	}
+
+	if !e.IsIsolatedMarginSymbol(symbol) {
+		e.IsolatedMarginSymbols = append(e.IsolatedMarginSymbols, symbol)
+	}
 }
 
 // MarginAccount is for the cross margin account
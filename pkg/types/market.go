@@ -21,6 +21,21 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	return d.parse(o)
+}
+
+// UnmarshalYAML parses the duration string like "10s" or the number of seconds from the yaml config
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var o interface{}
+
+	if err := unmarshal(&o); err != nil {
+		return err
+	}
+
+	return d.parse(o)
+}
+
+func (d *Duration) parse(o interface{}) error {
 	switch t := o.(type) {
 	case string:
 		dd, err := time.ParseDuration(t)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestFormatQuantity(t *testing.T) {
@@ -66,3 +67,23 @@ func TestDurationParse(t *testing.T) {
 		})
 	}
 }
+
+func TestDurationParseYAML(t *testing.T) {
+	type A struct {
+		Duration Duration `yaml:"duration"`
+	}
+
+	var tests = map[string]Duration{
+		"duration: 1":    Duration(time.Second),
+		"duration: 1.5":  Duration(time.Second + 500*time.Millisecond),
+		"duration: 10s":  Duration(10 * time.Second),
+		"duration: 2m3s": Duration(2*time.Minute + 3*time.Second),
+	}
+
+	for input, expected := range tests {
+		var a A
+		err := yaml.Unmarshal([]byte(input), &a)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, a.Duration, input)
+	}
+}
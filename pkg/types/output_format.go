@@ -0,0 +1,28 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFormat is the output format of the reports and the notified objects
+type OutputFormat string
+
+const (
+	// OutputFormatText is the human-readable text rendered from the templates
+	OutputFormatText OutputFormat = "text"
+
+	// OutputFormatJSON is the typed object marshaled to JSON
+	OutputFormatJSON OutputFormat = "json"
+)
+
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch OutputFormat(strings.ToLower(s)) {
+	case "", OutputFormatText:
+		return OutputFormatText, nil
+	case OutputFormatJSON:
+		return OutputFormatJSON, nil
+	}
+
+	return OutputFormatText, fmt.Errorf("unknown output format %q, valid formats are text and json", s)
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a notification, the notifiers can filter the notifications by the severity
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+
+	return SeverityInfo, fmt.Errorf("unknown severity %q", s)
+}
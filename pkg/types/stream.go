@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"time"
 )
 
 type Stream interface {
@@ -13,6 +14,27 @@ type Stream interface {
 	Close() error
 }
 
+// Unsubscriber is implemented by the streams that can remove the subscriptions, the subscriptions are sent when the
+// stream connects, so the removed subscriptions take effect on the next connect
+type Unsubscriber interface {
+	Unsubscribe(channel Channel, symbol string, options SubscribeOptions)
+}
+
+// StreamConnectionOptions tunes the websocket connection of the stream, the zero values keep the stream defaults
+type StreamConnectionOptions struct {
+	// ConnectTimeout is the deadline of each connect attempt, including the handshake
+	ConnectTimeout time.Duration
+
+	// KeepaliveInterval is the interval of the websocket ping messages
+	KeepaliveInterval time.Duration
+}
+
+// TunableStream is implemented by the streams of which the websocket connection can be tuned,
+// the options are applied to the connections made after they're set
+type TunableStream interface {
+	SetConnectionOptions(options StreamConnectionOptions)
+}
+
 type Channel string
 
 var BookChannel = Channel("book")
@@ -57,6 +79,19 @@ func (stream *StandardStream) Subscribe(channel Channel, symbol string, options
 	})
 }
 
+func (stream *StandardStream) Unsubscribe(channel Channel, symbol string, options SubscribeOptions) {
+	var subscriptions []Subscription
+	for _, s := range stream.Subscriptions {
+		if s.Channel == channel && s.Symbol == symbol && s.Options == options {
+			continue
+		}
+
+		subscriptions = append(subscriptions, s)
+	}
+
+	stream.Subscriptions = subscriptions
+}
+
 // SubscribeOptions provides the standard stream options
 type SubscribeOptions struct {
 	Interval string `json:"interval,omitempty"`
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker.Allow when the requests are short-circuited
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitBreakerState int
+
+const (
+	// CircuitClosed lets all the requests through
+	CircuitClosed CircuitBreakerState = iota
+
+	// CircuitOpen short-circuits the requests until the cool-down period is over
+	CircuitOpen
+
+	// CircuitHalfOpen lets one probe request through to test the recovery
+	CircuitHalfOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreaker stops calling a failing remote service. It opens after the consecutive failures reach the threshold,
+// the requests are rejected with ErrCircuitOpen during the cool-down period, and then it half-opens to let one probe
+// request through. The breaker closes if the probe succeeds, otherwise it opens again for another cool-down period.
+type CircuitBreaker struct {
+	Name string
+
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu       sync.Mutex
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	dropped  int64
+
+	// now is replaced in the tests
+	now func() time.Time
+}
+
+// NewCircuitBreaker creates the breaker, the breaker never opens if the failure threshold is not positive
+func NewCircuitBreaker(name string, failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Name:             name,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		now:              time.Now,
+	}
+}
+
+// Allow returns ErrCircuitOpen if the request should be dropped, the caller must Report the result of an allowed
+// request, so that the half-open breaker can let the next probe through
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.dropped++
+			return ErrCircuitOpen
+		}
+
+		b.setState(CircuitHalfOpen)
+		b.probing = true
+		return nil
+
+	case CircuitHalfOpen:
+		if b.probing {
+			b.dropped++
+			return ErrCircuitOpen
+		}
+
+		b.probing = true
+		return nil
+	}
+
+	return nil
+}
+
+// Report records the result of the request, a nil error resets the consecutive failures
+func (b *CircuitBreaker) Report(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if err == nil {
+		b.failures = 0
+		if b.state != CircuitClosed {
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.open()
+
+	case CircuitClosed:
+		if b.failureThreshold > 0 && b.failures >= b.failureThreshold {
+			b.open()
+		}
+	}
+}
+
+// State returns the current state, the open breaker is reported as half-open once the cool-down period is over
+func (b *CircuitBreaker) State() CircuitBreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+
+	return b.state
+}
+
+// Dropped returns the number of the requests rejected by the breaker
+func (b *CircuitBreaker) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+func (b *CircuitBreaker) open() {
+	b.openedAt = b.now()
+	b.setState(CircuitOpen)
+}
+
+func (b *CircuitBreaker) setState(state CircuitBreakerState) {
+	if b.state == state {
+		return
+	}
+
+	entry := log.WithField("circuit", b.Name)
+	switch state {
+	case CircuitOpen:
+		entry.Warnf("circuit breaker %s is open after %d consecutive failures, the requests are dropped for %s", b.Name, b.failures, b.cooldown)
+	case CircuitHalfOpen:
+		entry.Infof("circuit breaker %s is half-open, probing the recovery", b.Name)
+	case CircuitClosed:
+		entry.Infof("circuit breaker %s is closed", b.Name)
+	}
+
+	b.state = state
+}
+
+// JitteredBackoff returns a random duration between the half of the backoff and the backoff, so that the retries of
+// the concurrent clients are spread out
+func JitteredBackoff(backoff time.Duration) time.Duration {
+	if backoff <= 1 {
+		return backoff
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
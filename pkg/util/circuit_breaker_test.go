@@ -0,0 +1,70 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("test", 3, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	failure := errors.New("server error")
+
+	// the success resets the consecutive failures
+	for _, err := range []error{failure, failure, nil, failure, failure} {
+		assert.NoError(t, breaker.Allow())
+		breaker.Report(err)
+	}
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	assert.NoError(t, breaker.Allow())
+	breaker.Report(failure)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+	assert.Equal(t, int64(1), breaker.Dropped())
+
+	// only one probe is allowed after the cool-down period
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+	assert.Equal(t, int64(2), breaker.Dropped())
+
+	// the failed probe opens the breaker for another cool-down period
+	breaker.Report(failure)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, ErrCircuitOpen, breaker.Allow())
+
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, breaker.Allow())
+	breaker.Report(nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	breaker := NewCircuitBreaker("test", 0, time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, breaker.Allow())
+		breaker.Report(errors.New("server error"))
+	}
+
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestJitteredBackoff(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := JitteredBackoff(time.Second)
+		assert.True(t, d >= 500*time.Millisecond && d <= time.Second, d.String())
+	}
+
+	assert.Equal(t, time.Duration(0), JitteredBackoff(0))
+}
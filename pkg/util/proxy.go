@@ -0,0 +1,101 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+// ParseProxyURL parses the proxy URL, the supported schemes are socks5, http and https.
+// The username and the password override the user info of the URL if the username is not empty.
+func ParseProxyURL(rawURL, username, password string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "socks5", "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, the supported schemes are socks5, http and https", u.Scheme)
+	}
+
+	if len(u.Host) == 0 {
+		return nil, fmt.Errorf("proxy url has no host")
+	}
+
+	if len(username) > 0 {
+		u.User = url.UserPassword(username, password)
+	}
+
+	return u, nil
+}
+
+// NewProxyHTTPClient returns a copy of the http client that sends the requests through the proxy, the given client is
+// not modified since it may be shared, e.g., http.DefaultClient. The transport of the client must be an *http.Transport
+// or nil, so it's applied before the rate limit, which wraps the transport.
+func NewProxyHTTPClient(client *http.Client, proxyURL *url.URL) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var transport *http.Transport
+	if t, ok := client.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	proxied := *client
+	proxied.Transport = transport
+	return &proxied
+}
+
+// NewProxyWebsocketDialer returns the websocket dialer that connects through the proxy,
+// the default dialer is returned if the proxy URL is nil
+func NewProxyWebsocketDialer(proxyURL *url.URL) *websocket.Dialer {
+	if proxyURL == nil {
+		return websocket.DefaultDialer
+	}
+
+	dialer := *websocket.DefaultDialer
+
+	u := *proxyURL
+	if u.Scheme == "https" {
+		// the websocket dialer tunnels through the http proxies only,
+		// so the tls connection to the https proxy is made by the net dialer
+		host := u.Hostname()
+		if len(u.Port()) == 0 {
+			u.Host = net.JoinHostPort(host, "443")
+		}
+
+		proxyAddr := u.Host
+		u.Scheme = "http"
+
+		dialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, network, addr)
+			if err != nil || addr != proxyAddr {
+				return conn, err
+			}
+
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+			if err := tlsConn.Handshake(); err != nil {
+				conn.Close()
+				return nil, err
+			}
+
+			return tlsConn, nil
+		}
+	}
+
+	dialer.Proxy = http.ProxyURL(&u)
+	return &dialer
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -17,3 +18,37 @@ func NewValidLimiter(r rate.Limit, b int) (*rate.Limiter, error) {
 	}
 	return rate.NewLimiter(r, b), nil
 }
+
+// RateLimitedTransport waits for the limiter before sending each request,
+// the request is canceled if its context is done while waiting.
+type RateLimitedTransport struct {
+	Limiter *rate.Limiter
+
+	// Transport is the underlying transport, http.DefaultTransport is used if it's nil
+	Transport http.RoundTripper
+}
+
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return transport.RoundTrip(req)
+}
+
+// NewRateLimitedHTTPClient returns a copy of the http client that waits for the limiter before sending each request,
+// the given client is not modified since it may be shared, e.g., http.DefaultClient.
+func NewRateLimitedHTTPClient(client *http.Client, limiter *rate.Limiter) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	limited := *client
+	limited.Transport = &RateLimitedTransport{Limiter: limiter, Transport: client.Transport}
+	return &limited
+}
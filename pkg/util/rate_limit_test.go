@@ -1,6 +1,10 @@
 package util
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -41,3 +45,37 @@ func TestShouldDelay(t *testing.T) {
 		assert.True(t, ShouldDelay(limiter, minInterval) > 0)
 	}
 }
+
+func TestNewRateLimitedHTTPClient(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRateLimitedHTTPClient(nil, rate.NewLimiter(rate.Every(50*time.Millisecond), 1))
+	assert.Nil(t, http.DefaultClient.Transport, "the given client should not be modified")
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond), "the requests should be limited")
+
+	// the request is canceled while waiting for the limiter
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
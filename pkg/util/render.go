@@ -7,13 +7,19 @@ import (
 )
 
 func Render(tpl string, args interface{}) string {
-	var buf = bytes.NewBuffer(nil)
 	tmpl, err := template.New("tmp").Parse(tpl)
 	if err != nil {
 		logrus.WithError(err).Error("template error")
 		return ""
 	}
-	err = tmpl.Execute(buf, args)
+
+	return RenderTemplate(tmpl, args)
+}
+
+// RenderTemplate renders the parsed template, the empty string is returned if the template fails
+func RenderTemplate(tmpl *template.Template, args interface{}) string {
+	var buf = bytes.NewBuffer(nil)
+	err := tmpl.Execute(buf, args)
 	if err != nil {
 		logrus.WithError(err).Error("template error")
 		return ""
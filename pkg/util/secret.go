@@ -0,0 +1,124 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const (
+	// EncryptedSecretPrefix is the prefix of the encrypted secret values in the config
+	EncryptedSecretPrefix = "enc:"
+
+	// MasterKeyEnvVar is the env var of the base64 encoded 32 bytes master key that decrypts the secret values
+	MasterKeyEnvVar = "BBGO_MASTER_KEY"
+
+	masterKeySize = 32
+	nonceSize     = 24
+)
+
+var ErrMasterKeyNotSet = fmt.Errorf("the master key is not set, please set the %s env var", MasterKeyEnvVar)
+
+// IsEncryptedSecret returns true if the value is encrypted by EncryptSecret
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, EncryptedSecretPrefix)
+}
+
+// GenerateMasterKey generates a random master key and returns it in base64
+func GenerateMasterKey() (string, error) {
+	var key [masterKeySize]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key[:]), nil
+}
+
+// ParseMasterKey decodes the base64 encoded master key
+func ParseMasterKey(encoded string) (*[masterKeySize]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+
+	if len(data) != masterKeySize {
+		return nil, fmt.Errorf("invalid master key: the key should be %d bytes, got %d bytes", masterKeySize, len(data))
+	}
+
+	var key [masterKeySize]byte
+	copy(key[:], data)
+	return &key, nil
+}
+
+// MasterKeyFromEnv reads the master key from the BBGO_MASTER_KEY env var
+func MasterKeyFromEnv() (*[masterKeySize]byte, error) {
+	encoded, ok := os.LookupEnv(MasterKeyEnvVar)
+	if !ok || len(encoded) == 0 {
+		return nil, ErrMasterKeyNotSet
+	}
+
+	return ParseMasterKey(encoded)
+}
+
+// EncryptSecret encrypts the value with NaCl secretbox,
+// the result is the enc: prefix followed by the base64 encoded nonce and the sealed box.
+func EncryptSecret(value string, key *[masterKeySize]byte) (string, error) {
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return "", err
+	}
+
+	sealed := secretbox.Seal(nonce[:], []byte(value), &nonce, key)
+	return EncryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts the value encrypted by EncryptSecret
+func DecryptSecret(value string, key *[masterKeySize]byte) (string, error) {
+	if !IsEncryptedSecret(value) {
+		return "", errors.New("the secret value is not encrypted")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedSecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted secret: %w", err)
+	}
+
+	if len(data) < nonceSize+secretbox.Overhead {
+		return "", errors.New("invalid encrypted secret: the data is too short")
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[:nonceSize])
+
+	decrypted, ok := secretbox.Open(nil, data[nonceSize:], &nonce, key)
+	if !ok {
+		return "", errors.New("can not decrypt the secret, the master key may be wrong")
+	}
+
+	return string(decrypted), nil
+}
+
+// ResolveSecret returns the plaintext value, the encrypted value is decrypted with the master key from the env var,
+// and the vault secret reference is fetched from Vault. The plaintext value is returned as it is.
+func ResolveSecret(value string) (string, error) {
+	if IsVaultSecret(value) {
+		return ResolveVaultSecret(value)
+	}
+
+	if !IsEncryptedSecret(value) {
+		return value, nil
+	}
+
+	key, err := MasterKeyFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	return DecryptSecret(value, key)
+}
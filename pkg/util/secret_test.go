@@ -0,0 +1,72 @@
+package util
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	encodedKey, err := GenerateMasterKey()
+	assert.NoError(t, err)
+
+	key, err := ParseMasterKey(encodedKey)
+	assert.NoError(t, err)
+
+	encrypted, err := EncryptSecret("my-api-secret", key)
+	assert.NoError(t, err)
+	assert.True(t, IsEncryptedSecret(encrypted))
+	assert.NotContains(t, encrypted, "my-api-secret")
+
+	decrypted, err := DecryptSecret(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-api-secret", decrypted)
+
+	otherEncodedKey, err := GenerateMasterKey()
+	assert.NoError(t, err)
+
+	otherKey, err := ParseMasterKey(otherEncodedKey)
+	assert.NoError(t, err)
+
+	_, err = DecryptSecret(encrypted, otherKey)
+	assert.Error(t, err, "should not decrypt with the wrong key")
+
+	_, err = DecryptSecret("enc:AAAA", key)
+	assert.Error(t, err, "should reject the truncated data")
+}
+
+func TestParseMasterKey(t *testing.T) {
+	_, err := ParseMasterKey("not base64!")
+	assert.Error(t, err)
+
+	_, err = ParseMasterKey("c2hvcnQ=")
+	assert.EqualError(t, err, "invalid master key: the key should be 32 bytes, got 5 bytes")
+}
+
+func TestResolveSecret(t *testing.T) {
+	encodedKey, err := GenerateMasterKey()
+	assert.NoError(t, err)
+
+	key, err := ParseMasterKey(encodedKey)
+	assert.NoError(t, err)
+
+	encrypted, err := EncryptSecret("my-api-key", key)
+	assert.NoError(t, err)
+
+	os.Unsetenv(MasterKeyEnvVar)
+
+	value, err := ResolveSecret("plaintext-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "plaintext-key", value, "the plaintext value should be kept")
+
+	_, err = ResolveSecret(encrypted)
+	assert.Equal(t, ErrMasterKeyNotSet, err)
+
+	os.Setenv(MasterKeyEnvVar, encodedKey)
+	defer os.Unsetenv(MasterKeyEnvVar)
+
+	value, err = ResolveSecret(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-api-key", value)
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultSecretPrefix is the prefix of the secret values stored in Vault,
+// e.g., vault:secret/data/bbgo#binance_key reads the binance_key field of the secret/data/bbgo secret.
+const VaultSecretPrefix = "vault:"
+
+// IsVaultSecret returns true if the value refers to a Vault secret
+func IsVaultSecret(value string) bool {
+	return strings.HasPrefix(value, VaultSecretPrefix)
+}
+
+// ParseVaultSecret splits the vault secret reference into the secret path and the field name
+func ParseVaultSecret(value string) (path, field string, err error) {
+	ref := strings.TrimPrefix(value, VaultSecretPrefix)
+
+	idx := strings.LastIndex(ref, "#")
+	if idx <= 0 || idx == len(ref)-1 {
+		return "", "", fmt.Errorf("invalid vault secret %q, expecting vault:<path>#<field>", value)
+	}
+
+	return strings.Trim(ref[:idx], "/"), ref[idx+1:], nil
+}
+
+// VaultClient reads the secrets from the Vault HTTP API, the secrets are cached for the process lifetime.
+// Both the KV version 1 and version 2 secret engines are supported.
+type VaultClient struct {
+	Address string
+	Token   string
+
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]map[string]interface{}
+}
+
+func NewVaultClient(address, token string) *VaultClient {
+	return &VaultClient{
+		Address:    strings.TrimRight(address, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		cache:      make(map[string]map[string]interface{}),
+	}
+}
+
+// NewVaultClientFromEnv creates the vault client with the VAULT_ADDR and VAULT_TOKEN env vars
+func NewVaultClientFromEnv() (*VaultClient, error) {
+	address, ok := os.LookupEnv("VAULT_ADDR")
+	if !ok || len(address) == 0 {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+
+	token, ok := os.LookupEnv("VAULT_TOKEN")
+	if !ok || len(token) == 0 {
+		return nil, errors.New("VAULT_TOKEN is not set")
+	}
+
+	return NewVaultClient(address, token), nil
+}
+
+// Resolve returns the field value of the vault secret reference
+func (c *VaultClient) Resolve(ctx context.Context, value string) (string, error) {
+	path, field, err := ParseVaultSecret(value)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := c.Read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	return fmt.Sprintf("%v", v), nil
+}
+
+// Read reads the secret data of the path, the data is loaded from the cache if the path is read before
+func (c *VaultClient) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if data, ok := c.cache[path]; ok {
+		return data, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.Address+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault secret %s read error: unexpected status %s", path, resp.Status)
+	}
+
+	var payload struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("vault secret %s read error: %w", path, err)
+	}
+
+	data := payload.Data
+
+	// the KV version 2 engine wraps the secret data with the metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	c.cache[path] = data
+	return data, nil
+}
+
+var defaultVaultClient struct {
+	once   sync.Once
+	client *VaultClient
+	err    error
+}
+
+// ResolveVaultSecret resolves the vault secret reference with the client created from the env vars
+func ResolveVaultSecret(value string) (string, error) {
+	defaultVaultClient.once.Do(func() {
+		defaultVaultClient.client, defaultVaultClient.err = NewVaultClientFromEnv()
+	})
+
+	if defaultVaultClient.err != nil {
+		return "", defaultVaultClient.err
+	}
+
+	return defaultVaultClient.client.Resolve(context.Background(), value)
+}
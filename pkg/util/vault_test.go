@@ -0,0 +1,70 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVaultSecret(t *testing.T) {
+	path, field, err := ParseVaultSecret("vault:secret/data/bbgo#binance_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret/data/bbgo", path)
+	assert.Equal(t, "binance_key", field)
+
+	_, _, err = ParseVaultSecret("vault:secret/data/bbgo")
+	assert.Error(t, err, "the field is required")
+
+	_, _, err = ParseVaultSecret("vault:secret/data/bbgo#")
+	assert.Error(t, err, "the field should not be empty")
+}
+
+func TestVaultClient_Resolve(t *testing.T) {
+	var numRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+
+		if r.Header.Get("X-Vault-Token") != "s.test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/bbgo":
+			w.Write([]byte(`{"data":{"data":{"binance_key":"my-key","binance_secret":"my-secret"},"metadata":{"version":1}}}`))
+		case "/v1/kv/bbgo":
+			w.Write([]byte(`{"data":{"max_key":"my-max-key"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewVaultClient(server.URL+"/", "s.test-token")
+	ctx := context.Background()
+
+	value, err := client.Resolve(ctx, "vault:secret/data/bbgo#binance_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-key", value)
+
+	value, err = client.Resolve(ctx, "vault:secret/data/bbgo#binance_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-secret", value)
+	assert.Equal(t, 1, numRequests, "the secret should be cached")
+
+	value, err = client.Resolve(ctx, "vault:kv/bbgo#max_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-max-key", value)
+
+	_, err = client.Resolve(ctx, "vault:secret/data/bbgo#ftx_key")
+	assert.EqualError(t, err, "vault secret secret/data/bbgo has no field ftx_key")
+
+	_, err = client.Resolve(ctx, "vault:secret/data/unknown#key")
+	assert.Error(t, err)
+
+	_, err = NewVaultClient(server.URL, "s.wrong-token").Resolve(ctx, "vault:secret/data/bbgo#binance_key")
+	assert.Error(t, err)
+}